@@ -1,7 +1,9 @@
 package server
 
 import (
+	"backend/internal/jobs"
 	"backend/internal/router"
+	"backend/internal/storage"
 	"backend/internal/utils"
 	"log"
 	"net/http"
@@ -12,6 +14,58 @@ func StartServer() {
 	// Initialise JWT secret
 	utils.InitJwtSecret()
 
+	// Initialise registration mode
+	utils.InitRegistrationMode()
+
+	// Initialise proof-of-work settings
+	utils.InitProofOfWork()
+
+	// Initialise username policy
+	utils.InitUsernamePolicy()
+
+	// Initialise deleted content policy
+	utils.InitDeletionPolicy()
+
+	// Initialise thread locking policy
+	utils.InitLockPolicy()
+
+	// Initialise thread ranking policy
+	utils.InitRankingPolicy()
+
+	// Initialise thread view counting
+	utils.InitViewPolicy()
+
+	// Initialise notification policy
+	utils.InitNotificationPolicy()
+
+	// Initialise draft expiry
+	utils.InitDraftPolicy()
+
+	// Initialise languages of highlighted code blocks
+	utils.InitHighlightPolicy()
+
+	// Initialise related threads cache
+	utils.InitRelatedThreadsPolicy()
+
+	// Initialise repost rejection
+	utils.InitRepostPolicy()
+
+	// Initialise attachment limits and storage
+	utils.InitAttachmentPolicy()
+	storage.InitBlobStore()
+
+	// Start background jobs
+	jobs.StartPurgeJob()
+	jobs.StartAutoLockJob()
+	jobs.StartRankingJob()
+	jobs.StartViewJob()
+	jobs.StartNotificationJob()
+	jobs.StartSchedulerJob()
+	jobs.StartMarkdownJob()
+	jobs.StartUsernameSkeletonJob()
+	jobs.StartAttachmentJob()
+	jobs.StartFingerprintJob()
+
 	// Start server
 	http.Handle("/", router.SetupRouter())
 
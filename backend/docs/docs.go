@@ -18,99 +18,121 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/comment/create": {
+        "/announcement": {
+            "get": {
+                "description": "Retrieves the site-wide announcements that have not expired, latest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles announcement retrieval requests",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Thread"
+                            }
+                        }
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/attachment": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Creates a new comment for the given thread",
+                "description": "Uploads a file to attach to a thread or comment by passing its ID in attachment_ids when creating it.\nThe type of the file is detected from its contents. PNG, JPEG and GIF images, PDF documents and\nplain text files are accepted. The metadata of images is stripped and thumbnails are made of large\nimages. Attachments that are not attached within ATTACHMENT_ORPHAN_HOURS are deleted.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comment"
+                    "attachment"
                 ],
-                "summary": "Handles comment creation requests",
+                "summary": "Handles attachment upload requests",
                 "parameters": [
                     {
-                        "description": "Comment data",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateCommentRequest"
-                        }
+                        "type": "file",
+                        "description": "File to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Comment"
+                            "$ref": "#/definitions/models.Attachment"
                         }
                     },
                     "400": {
-                        "description": "Invalid data"
+                        "description": "Invalid image"
                     },
                     "401": {
                         "description": "Invalid JWT token"
                     },
+                    "403": {
+                        "description": "Email not verified"
+                    },
                     "405": {
                         "description": "Method not allowed"
                     },
+                    "413": {
+                        "description": "File too large"
+                    },
+                    "415": {
+                        "description": "Unsupported file type"
+                    },
                     "500": {
                         "description": "Internal server error"
                     }
                 }
             }
         },
-        "/comment/{id}": {
-            "put": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
+        "/attachment/{id}": {
+            "get": {
+                "description": "Retrieves the file of an attachment. Images are shown inline, other files are downloaded.\nAttachments of deleted threads and comments are not found.",
+                "produces": [
+                    "application/octet-stream"
                 ],
-                "description": "Updates a comment",
                 "tags": [
-                    "comment"
+                    "attachment"
                 ],
-                "summary": "Handles comment update requests",
+                "summary": "Handles attachment download requests",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Comment UUID",
+                        "description": "Attachment UUID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Comment data",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateCommentRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
-                    },
-                    "400": {
-                        "description": "Invalid data"
-                    },
-                    "401": {
-                        "description": "Invalid JWT token"
+                        "description": "File",
+                        "schema": {
+                            "type": "file"
+                        }
                     },
-                    "403": {
-                        "description": "No permission to update comment"
+                    "404": {
+                        "description": "Attachment not found"
                     },
                     "405": {
                         "description": "Method not allowed"
@@ -119,22 +141,23 @@ const docTemplate = `{
                         "description": "Internal server error"
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
+            }
+        },
+        "/attachment/{id}/thumbnail": {
+            "get": {
+                "description": "Retrieves the thumbnail of an image attachment, which fits in 320 by 320 pixels. Images that are\nalready small enough are their own thumbnails. Other files have no thumbnail.",
+                "produces": [
+                    "image/png",
+                    "image/jpeg"
                 ],
-                "description": "Deletes a comment",
                 "tags": [
-                    "comment"
+                    "attachment"
                 ],
-                "summary": "Handles comment deletion requests",
+                "summary": "Handles attachment thumbnail requests",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Comment UUID",
+                        "description": "Attachment UUID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -142,13 +165,13 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
-                    },
-                    "401": {
-                        "description": "Invalid JWT token"
+                        "description": "Thumbnail",
+                        "schema": {
+                            "type": "file"
+                        }
                     },
-                    "403": {
-                        "description": "No permission to delete comment"
+                    "404": {
+                        "description": "Thumbnail not found"
                     },
                     "405": {
                         "description": "Method not allowed"
@@ -159,45 +182,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/thread/create": {
-            "post": {
+        "/bookmark": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Creates a new thread",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Retrieves the bookmarks of the user, latest first, together with the thread or comment they point to",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "thread"
+                    "bookmark"
                 ],
-                "summary": "Handles thread creation requests",
+                "summary": "Handles bookmark retrieval requests",
                 "parameters": [
                     {
-                        "description": "Thread data",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateThreadRequest"
-                        }
+                        "type": "string",
+                        "description": "Only return bookmarks in this folder",
+                        "name": "folder",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Page number, default '1'",
+                        "name": "p",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Thread"
+                            "$ref": "#/definitions/models.GetBookmarksResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid data"
-                    },
                     "401": {
                         "description": "Invalid JWT token"
                     },
@@ -210,51 +230,61 @@ const docTemplate = `{
                 }
             }
         },
-        "/thread/search": {
+        "/bookmark/folders": {
             "get": {
-                "description": "Retrieves threads matching the given query",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Retrieves the names of the folders the user has bookmarks in, in alphabetical order",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "thread"
+                    "bookmark"
                 ],
-                "summary": "Handles thread search requests",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Search query",
-                        "name": "q",
-                        "in": "query",
-                        "required": true
+                "summary": "Handles bookmark folder retrieval requests",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
                     },
-                    {
-                        "enum": [
-                            "created_time_asc",
-                            "created_time_desc",
-                            "num_comments_asc",
-                            "num_comments_desc"
-                        ],
-                        "type": "string",
-                        "description": "Sorting order, default 'created_time_desc'",
-                        "name": "order",
-                        "in": "query"
+                    "401": {
+                        "description": "Invalid JWT token"
                     },
-                    {
-                        "type": "string",
-                        "description": "Page number, default '1'",
-                        "name": "p",
-                        "in": "query"
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
                     }
+                }
+            }
+        },
+        "/category": {
+            "get": {
+                "description": "Retrieves all categories as a tree of top-level categories and their subcategories, ordered by their\nposition and then by name. Each category has the number of threads in it and in its subcategories.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "category"
                 ],
+                "summary": "Handles category retrieval requests",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SearchThreadResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Category"
+                            }
                         }
                     },
                     "405": {
@@ -266,9 +296,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/thread/{id}": {
-            "get": {
-                "description": "Retrieves the thread with the given ID",
+        "/category/create": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a new category, as a subcategory of the given parent category if one is given.\nThe slug must be unique and may only contain lowercase letters and digits separated by single hyphens.\nOnly users with at least the posting role can create threads in the category. Only available to admins.",
                 "consumes": [
                     "application/json"
                 ],
@@ -276,43 +311,56 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "thread"
+                    "category"
                 ],
-                "summary": "Handles thread retrieval requests",
+                "summary": "Handles category creation requests",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Thread ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Category data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CategoryRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Thread"
+                            "$ref": "#/definitions/models.Category"
                         }
                     },
-                    "404": {
-                        "description": "Thread not found"
+                    "400": {
+                        "description": "Invalid parent category"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "No permission to create categories"
                     },
                     "405": {
                         "description": "Method not allowed"
                     },
+                    "409": {
+                        "description": "Slug already in use"
+                    },
                     "500": {
                         "description": "Internal server error"
                     }
                 }
-            },
+            }
+        },
+        "/category/{id}": {
             "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Updates a thread",
+                "description": "Updates the category with the given ID. The category can be moved under another parent category, but\nnot under itself or one of its subcategories. Only available to admins.",
                 "consumes": [
                     "application/json"
                 ],
@@ -320,43 +368,52 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "thread"
+                    "category"
                 ],
-                "summary": "Handles thread update requests",
+                "summary": "Handles category update requests",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Thread UUID",
+                        "description": "Category ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Thread data",
+                        "description": "Category data",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateThreadRequest"
+                            "$ref": "#/definitions/models.CategoryRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Category"
+                        }
                     },
                     "400": {
-                        "description": "Invalid data"
+                        "description": "Invalid parent category"
                     },
                     "401": {
                         "description": "Invalid JWT token"
                     },
                     "403": {
-                        "description": "No permission to update thread"
+                        "description": "No permission to update categories"
+                    },
+                    "404": {
+                        "description": "Category not found"
                     },
                     "405": {
                         "description": "Method not allowed"
                     },
+                    "409": {
+                        "description": "Slug already in use"
+                    },
                     "500": {
                         "description": "Internal server error"
                     }
@@ -368,21 +425,15 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Deletes the thread with the given ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Deletes the category with the given ID. Categories with subcategories or threads, including deleted\nand scheduled threads, cannot be deleted, and neither can the General category. Only available to admins.",
                 "tags": [
-                    "thread"
+                    "category"
                 ],
-                "summary": "Handles thread deletion requests",
+                "summary": "Handles category deletion requests",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Thread ID",
+                        "description": "Category ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -392,60 +443,63 @@ const docTemplate = `{
                     "200": {
                         "description": "OK"
                     },
+                    "400": {
+                        "description": "Cannot delete the default category"
+                    },
                     "401": {
                         "description": "Invalid JWT token"
                     },
                     "403": {
-                        "description": "No permission to delete thread"
+                        "description": "No permission to delete categories"
+                    },
+                    "404": {
+                        "description": "Category not found"
                     },
                     "405": {
                         "description": "Method not allowed"
                     },
+                    "409": {
+                        "description": "Category not empty"
+                    },
                     "500": {
                         "description": "Internal server error"
                     }
                 }
             }
         },
-        "/thread/{thread_id}/comments": {
+        "/challenge": {
             "get": {
-                "description": "Retrieves comments for the given thread",
+                "description": "Issues a proof-of-work challenge for the given purpose.\nThe challenge and solution are sent in the X-Pow-Challenge and X-Pow-Solution headers of the protected request.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "comment"
+                    "challenge"
                 ],
-                "summary": "Handles comment retrieval requests",
+                "summary": "Handles proof-of-work challenge requests",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Thread UUID",
-                        "name": "thread_id",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "enum": [
-                            "created_time_asc",
-                            "created_time_desc"
+                            "register",
+                            "thread"
                         ],
                         "type": "string",
-                        "description": "Sorting order, default 'created_time_asc'",
-                        "name": "order",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Page number, default '1'",
-                        "name": "p",
-                        "in": "query"
+                        "description": "What the challenge will be used for",
+                        "name": "purpose",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.GetCommentResponse"
+                            "$ref": "#/definitions/models.PowChallenge"
                         }
                     },
+                    "400": {
+                        "description": "Invalid purpose"
+                    },
                     "405": {
                         "description": "Method not allowed"
                     },
@@ -455,9 +509,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/create": {
+        "/comment/create": {
             "post": {
-                "description": "Registers a new user with the given username and password",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a new comment for the given thread, with up to 10 attachments uploaded by the user that are\nnot attached to anything yet",
                 "consumes": [
                     "application/json"
                 ],
@@ -465,17 +524,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "comment"
                 ],
-                "summary": "Handles registration requests",
+                "summary": "Handles comment creation requests",
                 "parameters": [
                     {
-                        "description": "Username and password",
+                        "description": "Comment data",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.AuthRequest"
+                            "$ref": "#/definitions/models.CreateCommentRequest"
                         }
                     }
                 ],
@@ -483,57 +542,248 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.AuthResponse"
+                            "$ref": "#/definitions/models.Comment"
                         }
                     },
                     "400": {
-                        "description": "Incorrect username/password"
+                        "description": "Invalid attachments"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "Email not verified"
+                    },
+                    "404": {
+                        "description": "Thread not found"
                     },
                     "405": {
                         "description": "Method not allowed"
                     },
+                    "423": {
+                        "description": "Thread is locked"
+                    },
                     "500": {
                         "description": "Internal server error"
                     }
                 }
             }
         },
-        "/user/login": {
-            "post": {
-                "description": "Logs in a user with the given username and password",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
+        "/comment/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Updates a comment",
                 "tags": [
-                    "user"
+                    "comment"
                 ],
-                "summary": "Handles login requests",
+                "summary": "Handles comment update requests",
                 "parameters": [
                     {
-                        "description": "Username and password",
+                        "type": "string",
+                        "description": "Comment UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment data",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.AuthRequest"
+                            "$ref": "#/definitions/models.UpdateCommentRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Invalid data"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "No permission to update comment"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes a comment. The comment can be restored until it is purged.",
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Handles comment deletion requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "No permission to delete comment"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/comment/{id}/bookmark": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Bookmarks the comment with the given ID for the user, or updates the folder and note of the bookmark",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookmark"
+                ],
+                "summary": "Handles comment bookmark requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Bookmark data",
+                        "name": "data",
+                        "in": "body",
                         "schema": {
-                            "$ref": "#/definitions/models.AuthResponse"
+                            "$ref": "#/definitions/models.BookmarkRequest"
                         }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Invalid data"
                     },
                     "401": {
-                        "description": "Incorrect username/password"
+                        "description": "Invalid JWT token"
+                    },
+                    "404": {
+                        "description": "Comment not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes the bookmark of the user on the comment with the given ID",
+                "tags": [
+                    "bookmark"
+                ],
+                "summary": "Handles comment bookmark removal requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "404": {
+                        "description": "Bookmark not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/comment/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Restores the deleted comment with the given ID.\nThe creator can restore a comment they deleted within the restore window, and moderators can restore\nany deleted comment until it is purged.\nComments of a deleted thread stay hidden until the thread is restored.",
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Handles comment restore requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "No permission to restore comment"
+                    },
+                    "404": {
+                        "description": "Deleted comment not found"
                     },
                     "405": {
                         "description": "Method not allowed"
@@ -543,71 +793,3460 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.AuthRequest": {
+        },
+        "/comment/{id}/revisions": {
+            "get": {
+                "description": "Retrieves the previous versions of the comment with the given ID, oldest first.\nRevision n is the version of the comment before its n-th edit, with the user who made the edit.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Handles comment revision history requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CommentRevision"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Comment not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/comment/{id}/revisions/diff": {
+            "get": {
+                "description": "Compares two versions of the comment with the given ID.\nVersions are numbered from 1, where num_revisions + 1 is the current version of the comment.\nBy default, the current version is compared with the version before it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Handles comment revision comparison requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to compare from, default 'to - 1'",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to compare to, default the current version",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RevisionDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data"
+                    },
+                    "404": {
+                        "description": "Comment not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/comment/{id}/vote": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upvotes or downvotes the comment with the given ID, replacing any previous vote of the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Handles comment vote requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Vote data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.VoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.VoteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "Email not verified"
+                    },
+                    "404": {
+                        "description": "Comment not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes the vote of the user on the comment with the given ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Handles comment vote removal requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.VoteResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "404": {
+                        "description": "Comment not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/draft": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves the drafts of the user, most recently updated first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "draft"
+                ],
+                "summary": "Handles draft list requests",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Draft"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/draft/{context}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves the draft of the user for the given context",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "draft"
+                ],
+                "summary": "Handles draft retrieval requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Draft context",
+                        "name": "context",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Draft"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "404": {
+                        "description": "Draft not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Saves a draft for the given context, replacing any previous draft for the same context.\nThe context is one of 'new_thread', 'reply:\u003cthread id\u003e', 'edit_thread:\u003cthread id\u003e' or 'edit_comment:\u003ccomment id\u003e'.\nDrafts are deleted when the thread or comment is posted, or after not being updated for some time.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "draft"
+                ],
+                "summary": "Handles draft save requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Draft context",
+                        "name": "context",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Draft data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SaveDraftRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Draft"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes the draft of the user for the given context",
+                "tags": [
+                    "draft"
+                ],
+                "summary": "Handles draft deletion requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Draft context",
+                        "name": "context",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "404": {
+                        "description": "Draft not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/invite": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves all invite codes, latest first. Only available to admins.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invite"
+                ],
+                "summary": "Handles invite code retrieval requests",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.InviteCode"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "No permission to view invite codes"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/invite/create": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a new invite code. Only available to admins.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invite"
+                ],
+                "summary": "Handles invite code creation requests",
+                "parameters": [
+                    {
+                        "description": "Invite code data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateInviteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.InviteCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "No permission to create invite codes"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/invite/{code}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revokes the given invite code. Only available to admins.",
+                "tags": [
+                    "invite"
+                ],
+                "summary": "Handles invite code deletion requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invite code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "No permission to delete invite codes"
+                    },
+                    "404": {
+                        "description": "Invite code not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/notification": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves the notifications of the user, latest first, together with the title of their thread",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Handles notification retrieval requests",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only return unread notifications",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Page number, default '1'",
+                        "name": "p",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetNotificationsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/notification/read": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Marks all notifications of the user as read",
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Handles requests to mark all notifications as read",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/notification/unread": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves the number of unread notifications of the user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Handles unread notification count requests",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UnreadNotificationsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/notification/{id}/read": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Marks the notification with the given ID as read",
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Handles notification read requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Notification ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "404": {
+                        "description": "Notification not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/registration": {
+            "get": {
+                "description": "Returns the registration mode, which decides whether an email or invite code is required to register.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Handles registration mode requests",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RegistrationResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/render": {
+            "post": {
+                "description": "Renders a thread or comment body as CommonMark and sanitises the HTML, in the same way as the\nbody_html of threads and comments. Only mentions of existing users are rendered as links.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "render"
+                ],
+                "summary": "Handles body preview requests",
+                "parameters": [
+                    {
+                        "description": "Body to render",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RenderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RenderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/render/stylesheet": {
+            "get": {
+                "description": "Retrieves the stylesheet of a theme for the highlighted code blocks in rendered bodies.\nHighlighted code is marked with CSS classes only, so any theme can be used.",
+                "produces": [
+                    "text/css"
+                ],
+                "tags": [
+                    "render"
+                ],
+                "summary": "Handles highlighted code stylesheet requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "github",
+                        "description": "Name of the theme, e.g. github, monokai or dracula",
+                        "name": "theme",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stylesheet",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Theme not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    }
+                }
+            }
+        },
+        "/thread/create": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a new thread. If publish_at is in the future, the thread is scheduled to be published at that\ntime instead, and the scheduled thread is returned with status 202. Only its creator can see it until then.\nA poll with 2 to 10 options can optionally be attached to the thread, as well as up to 10\nattachments uploaded by the user that are not attached to anything yet.\nIf REPOST_WINDOW_HOURS is set, a thread with the same title and body as a thread the user posted within\nthat many hours is rejected.\nThe thread is created in the given category, or in the General category if none is given. Categories\ncan restrict posting to moderators or admins.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread creation requests",
+                "parameters": [
+                    {
+                        "description": "Thread data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateThreadRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Proof-of-work challenge from /challenge, if required",
+                        "name": "X-Pow-Challenge",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Proof-of-work solution, if required",
+                        "name": "X-Pow-Solution",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Thread"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScheduledThread"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid proof of work"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "No permission to post in category"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "409": {
+                        "description": "Duplicate thread"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/scheduled": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves the threads scheduled by the user that have not been published yet, earliest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles scheduled thread list requests",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ScheduledThread"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/scheduled/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Changes when the scheduled thread with the given ID is published",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles scheduled thread reschedule requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Scheduled thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Publish time",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RescheduleThreadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScheduledThread"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid poll"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "404": {
+                        "description": "Scheduled thread not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Cancels the scheduled thread with the given ID, so that it is never published",
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles scheduled thread cancellation requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Scheduled thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "404": {
+                        "description": "Scheduled thread not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/search": {
+            "get": {
+                "description": "Retrieves threads matching the given query. If a category is given, only threads in that category or\nits subcategories are retrieved.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread search requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "created_time_asc",
+                            "created_time_desc",
+                            "num_comments_asc",
+                            "num_comments_desc",
+                            "score_desc",
+                            "hot_desc",
+                            "trending_desc",
+                            "views_desc"
+                        ],
+                        "type": "string",
+                        "description": "Sorting order, default 'created_time_desc'",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Page number, default '1'",
+                        "name": "p",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Slug of the category to search in",
+                        "name": "category",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SearchThreadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/similar": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves up to 5 threads that are likely duplicates of a draft thread, so that the user can be warned\nbefore creating it. Threads are matched by the trigram similarity of their titles to the draft title,\nor by the SimHash fingerprints of their bodies if the draft body is given. Near-duplicate bodies are\nreturned first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles duplicate thread detection requests",
+                "parameters": [
+                    {
+                        "description": "Draft thread",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SimilarThreadsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SimilarThread"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/{id}": {
+            "get": {
+                "description": "Retrieves the thread with the given ID and records a view of the thread.\nViews are counted at most once per user or IP address within a time window.\nThe results of the poll of the thread, if any, are included.\nIf the thread was merged into another thread, redirects to that thread instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread retrieval requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Thread"
+                        }
+                    },
+                    "301": {
+                        "description": "Thread was merged into another thread"
+                    },
+                    "404": {
+                        "description": "Thread not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates a thread",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread update requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Thread data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateThreadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Invalid data"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "No permission to update thread"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes the thread with the given ID. The thread can be restored until it is purged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread deletion requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "No permission to delete thread"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/{id}/bookmark": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Bookmarks the thread with the given ID for the user, or updates the folder and note of the bookmark",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookmark"
+                ],
+                "summary": "Handles thread bookmark requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Bookmark data",
+                        "name": "data",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.BookmarkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Invalid data"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "404": {
+                        "description": "Thread not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes the bookmark of the user on the thread with the given ID",
+                "tags": [
+                    "bookmark"
+                ],
+                "summary": "Handles thread bookmark removal requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "404": {
+                        "description": "Bookmark not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/{id}/lock": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Locks the thread with the given ID so that no new comments can be added, with an optional reason.\nThe creator of the thread and moderators can lock a thread.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread lock requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Lock reason",
+                        "name": "data",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.LockThreadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Invalid data"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "No permission to lock thread"
+                    },
+                    "404": {
+                        "description": "Thread not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/{id}/merge": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Merges the thread with the given ID into the target thread. The opening post of the thread becomes a\ncomment on the target thread and all of its comments are moved there, ordered by their creation time.\nSubscriptions, bookmarks, notifications, tags, pins and the poll are moved to the target thread, and the\nthread is replaced by a redirect to it. Threads that both have a poll cannot be merged.\nOnly moderators can merge threads.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread merge requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Target thread",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MergeThreadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Thread"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "No permission to merge threads"
+                    },
+                    "404": {
+                        "description": "Thread not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "409": {
+                        "description": "Both threads have polls"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/{id}/move": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Moves the thread with the given ID to another category. The posting role of the category does not\napply, as only moderators can move threads.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread move requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Target category",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MoveThreadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Thread"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid category"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "No permission to move threads"
+                    },
+                    "404": {
+                        "description": "Thread not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/{id}/pin": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Pins the thread with the given ID so that it is listed before other threads. Only available to moderators.\nA thread can be pinned everywhere or only when searching for a tag, and can be made a site-wide announcement.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread pin requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Pin data",
+                        "name": "data",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.PinThreadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Invalid data"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "No permission to pin thread"
+                    },
+                    "404": {
+                        "description": "Thread not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes the pin of the thread with the given ID. Only available to moderators.",
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread unpin requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag the thread is pinned for, default the pin that applies everywhere",
+                        "name": "tag",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "No permission to unpin thread"
+                    },
+                    "404": {
+                        "description": "Pin not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/{id}/poll": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Attaches a poll to the thread with the given ID, or replaces its poll. Only the creator of the thread\ncan do this. Once the poll has votes, its options and whether it is multiple choice or anonymous\nare locked, and only its closing time can be changed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles poll creation and update requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Poll data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatePollRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Poll"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid poll"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "Not thread creator"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "409": {
+                        "description": "Poll options are locked"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/{id}/poll/vote": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Votes on the poll of the thread with the given ID, replacing any previous votes of the user.\nExactly one option must be chosen if the poll is single choice.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles poll vote requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Positions of the options voted for",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PollVoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Poll"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "Poll is closed"
+                    },
+                    "404": {
+                        "description": "Poll not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes the votes of the user on the poll of the thread with the given ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles poll vote removal requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Poll"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "Poll is closed"
+                    },
+                    "404": {
+                        "description": "Poll not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/{id}/related": {
+            "get": {
+                "description": "Retrieves up to 5 threads related to the thread with the given ID, ranked by the number of tags they\nshare with it and by the full-text similarity of their titles and bodies to its title.\nResults are cached, and the cache is cleared whenever the tags of a thread change.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles related thread requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RelatedThread"
+                            }
+                        }
+                    },
+                    "301": {
+                        "description": "Thread was merged into another thread"
+                    },
+                    "404": {
+                        "description": "Thread not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Restores the deleted thread with the given ID.\nThe creator can restore a thread they deleted within the restore window, and moderators can restore\nany deleted thread until it is purged.",
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread restore requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "No permission to restore thread"
+                    },
+                    "404": {
+                        "description": "Deleted thread not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/{id}/revisions": {
+            "get": {
+                "description": "Retrieves the previous versions of the thread with the given ID, oldest first.\nRevision n is the version of the thread before its n-th edit, with the user who made the edit.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread revision history requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ThreadRevision"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Thread not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/{id}/revisions/diff": {
+            "get": {
+                "description": "Compares two versions of the thread with the given ID.\nVersions are numbered from 1, where num_revisions + 1 is the current version of the thread.\nBy default, the current version is compared with the version before it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread revision comparison requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to compare from, default 'to - 1'",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to compare to, default the current version",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RevisionDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data"
+                    },
+                    "404": {
+                        "description": "Thread not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/{id}/split": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Moves up to 100 comments of the thread with the given ID to another thread. If target_thread_id is\ngiven, the comments are moved to that thread. Otherwise a new thread is created with the given title\nand tags, and the earliest of the comments becomes its opening post, keeping its creator and creation\ntime. Comments keep their creation time, so they are ordered among the comments of the thread they are\nmoved to. Deleted comments cannot be moved. Only moderators can split threads.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread split requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comments to move and where to move them",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SplitThreadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Thread"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid comments"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "No permission to split threads"
+                    },
+                    "404": {
+                        "description": "Thread not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/{id}/subscription": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Subscribes the user to new comments on the thread with the given ID, or mutes the thread if is_muted is\ntrue. Users are subscribed automatically to threads they create or comment on unless they have muted them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread subscription requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subscription data",
+                        "name": "data",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.SubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Invalid data"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "404": {
+                        "description": "Thread not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes the subscription or mute of the user on the thread with the given ID.\nThe user is subscribed again automatically if they comment on the thread.",
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread unsubscription requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "404": {
+                        "description": "Subscription not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/{id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Unlocks the thread with the given ID so that new comments can be added again.\nModerators can unlock any thread, while the creator cannot unlock a thread locked by a moderator.",
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread unlock requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "No permission to unlock thread"
+                    },
+                    "404": {
+                        "description": "Thread not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/{id}/vote": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upvotes or downvotes the thread with the given ID, replacing any previous vote of the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread vote requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Vote data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.VoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.VoteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "403": {
+                        "description": "Email not verified"
+                    },
+                    "404": {
+                        "description": "Thread not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes the vote of the user on the thread with the given ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "thread"
+                ],
+                "summary": "Handles thread vote removal requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.VoteResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "404": {
+                        "description": "Thread not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/thread/{thread_id}/comments": {
+            "get": {
+                "description": "Retrieves comments for the given thread",
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Handles comment retrieval requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Thread UUID",
+                        "name": "thread_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "created_time_asc",
+                            "created_time_desc",
+                            "score_desc"
+                        ],
+                        "type": "string",
+                        "description": "Sorting order, default 'created_time_asc'",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Page number, default '1'",
+                        "name": "p",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetCommentResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/create": {
+            "post": {
+                "description": "Registers a new user with the given username and password.\nDepending on the registration mode, an email or invite code may also be required.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Handles registration requests",
+                "parameters": [
+                    {
+                        "description": "Username and password",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AuthRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Proof-of-work challenge from /challenge, if required",
+                        "name": "X-Pow-Challenge",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Proof-of-work solution, if required",
+                        "name": "X-Pow-Solution",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid proof of work"
+                    },
+                    "403": {
+                        "description": "Registration is closed"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/login": {
+            "post": {
+                "description": "Logs in a user with the given username and password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Handles login requests",
+                "parameters": [
+                    {
+                        "description": "Username and password",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AuthRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data"
+                    },
+                    "401": {
+                        "description": "Incorrect username/password"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/rename": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Changes the username of the current user. All threads and comments are moved to the new username,\nand links to the old username redirect to the new one. Returns a new token for the new username.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Handles username change requests",
+                "parameters": [
+                    {
+                        "description": "New username",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeUsernameRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Username already exists"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "429": {
+                        "description": "Username changed too recently"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/verify": {
+            "post": {
+                "description": "Verifies the email of the user who was sent the given token",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Handles email verification requests",
+                "parameters": [
+                    {
+                        "description": "Verification token",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.VerifyEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Invalid or expired token"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/verify/resend": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sends a new verification email to the current user",
+                "tags": [
+                    "user"
+                ],
+                "summary": "Handles verification email resend requests",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Email already verified"
+                    },
+                    "401": {
+                        "description": "Invalid JWT token"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/{username}": {
+            "get": {
+                "description": "Retrieves the number of threads and comments created by the given user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Handles user profile retrieval requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserProfile"
+                        }
+                    },
+                    "301": {
+                        "description": "User has been renamed"
+                    },
+                    "404": {
+                        "description": "User not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/{username}/comments": {
+            "get": {
+                "description": "Retrieves the comments created by the given user, together with the title of their thread",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Handles user comment retrieval requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "created_time_asc",
+                            "created_time_desc",
+                            "score_desc"
+                        ],
+                        "type": "string",
+                        "description": "Sorting order, default 'created_time_desc'",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Page number, default '1'",
+                        "name": "p",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetUserCommentsResponse"
+                        }
+                    },
+                    "301": {
+                        "description": "User has been renamed"
+                    },
+                    "404": {
+                        "description": "User not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/user/{username}/threads": {
+            "get": {
+                "description": "Retrieves the threads created by the given user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Handles user thread retrieval requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "created_time_asc",
+                            "created_time_desc",
+                            "num_comments_asc",
+                            "num_comments_desc",
+                            "score_desc"
+                        ],
+                        "type": "string",
+                        "description": "Sorting order, default 'created_time_desc'",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Page number, default '1'",
+                        "name": "p",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SearchThreadResponse"
+                        }
+                    },
+                    "301": {
+                        "description": "User has been renamed"
+                    },
+                    "404": {
+                        "description": "User not found"
+                    },
+                    "405": {
+                        "description": "Method not allowed"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.Attachment": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "created_time": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "has_thumbnail": {
+                    "type": "boolean"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AuthRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "invite_code": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AuthResponse": {
+            "type": "object",
+            "properties": {
+                "is_verified": {
+                    "type": "boolean"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Bookmark": {
+            "type": "object",
+            "properties": {
+                "comment_body": {
+                    "type": "string"
+                },
+                "comment_id": {
+                    "type": "string"
+                },
+                "created_time": {
+                    "type": "string"
+                },
+                "creator": {
+                    "type": "string"
+                },
+                "folder": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "thread_id": {
+                    "type": "string"
+                },
+                "thread_title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BookmarkRequest": {
+            "type": "object",
+            "properties": {
+                "folder": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Category": {
+            "type": "object",
+            "properties": {
+                "created_time": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "num_threads": {
+                    "type": "integer"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "posting_role": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "subcategories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Category"
+                    }
+                },
+                "total_threads": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CategoryRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "posting_role": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ChangeUsernameRequest": {
+            "type": "object",
+            "properties": {
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Comment": {
+            "type": "object",
+            "properties": {
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Attachment"
+                    }
+                },
+                "body": {
+                    "type": "string"
+                },
+                "body_html": {
+                    "type": "string"
+                },
+                "created_time": {
+                    "type": "string"
+                },
+                "creator": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_edited": {
+                    "type": "boolean"
+                },
+                "my_vote": {
+                    "type": "integer"
+                },
+                "num_revisions": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "thread_id": {
+                    "type": "string"
+                },
+                "updated_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CommentRevision": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "edited_time": {
+                    "type": "string"
+                },
+                "editor": {
+                    "type": "string"
+                },
+                "revision_number": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreateCommentRequest": {
+            "type": "object",
+            "properties": {
+                "attachment_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "body": {
+                    "type": "string"
+                },
+                "thread_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateInviteRequest": {
+            "type": "object",
+            "properties": {
+                "expires_time": {
+                    "type": "string"
+                },
+                "max_uses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreatePollRequest": {
+            "type": "object",
+            "properties": {
+                "close_time": {
+                    "type": "string"
+                },
+                "is_anonymous": {
+                    "type": "boolean"
+                },
+                "is_multiple_choice": {
+                    "type": "boolean"
+                },
+                "options": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.CreateThreadRequest": {
+            "type": "object",
+            "properties": {
+                "attachment_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "body": {
+                    "type": "string"
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "poll": {
+                    "$ref": "#/definitions/models.CreatePollRequest"
+                },
+                "publish_at": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DiffSegment": {
+            "type": "object",
+            "properties": {
+                "text": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Draft": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "context": {
+                    "type": "string"
+                },
+                "created_time": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GetBookmarksResponse": {
+            "type": "object",
+            "properties": {
+                "bookmarks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Bookmark"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GetCommentResponse": {
+            "type": "object",
+            "properties": {
+                "comments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Comment"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GetNotificationsResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "notifications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Notification"
+                    }
+                }
+            }
+        },
+        "models.GetUserCommentsResponse": {
+            "type": "object",
+            "properties": {
+                "comments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserComment"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.InviteCode": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "created_time": {
+                    "type": "string"
+                },
+                "creator": {
+                    "type": "string"
+                },
+                "expires_time": {
+                    "type": "string"
+                },
+                "max_uses": {
+                    "type": "integer"
+                },
+                "num_uses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LockThreadRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MergeThreadRequest": {
+            "type": "object",
+            "properties": {
+                "target_thread_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MoveThreadRequest": {
+            "type": "object",
+            "properties": {
+                "category_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Notification": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "comment_id": {
+                    "type": "string"
+                },
+                "created_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_read": {
+                    "type": "boolean"
+                },
+                "thread_id": {
+                    "type": "string"
+                },
+                "thread_title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PinThreadRequest": {
+            "type": "object",
+            "properties": {
+                "expires_time": {
+                    "type": "string"
+                },
+                "is_announcement": {
+                    "type": "boolean"
+                },
+                "tag": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Poll": {
+            "type": "object",
+            "properties": {
+                "close_time": {
+                    "type": "string"
+                },
+                "is_anonymous": {
+                    "type": "boolean"
+                },
+                "is_closed": {
+                    "type": "boolean"
+                },
+                "is_multiple_choice": {
+                    "type": "boolean"
+                },
+                "my_votes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "num_voters": {
+                    "type": "integer"
+                },
+                "options": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PollOption"
+                    }
+                }
+            }
+        },
+        "models.PollOption": {
+            "type": "object",
+            "properties": {
+                "num_votes": {
+                    "type": "integer"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "voters": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.PollVoteRequest": {
+            "type": "object",
+            "properties": {
+                "options": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.PowChallenge": {
+            "type": "object",
+            "properties": {
+                "challenge": {
+                    "type": "string"
+                },
+                "difficulty": {
+                    "type": "integer"
+                },
+                "expires_time": {
+                    "type": "string"
+                },
+                "required": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.RegistrationResponse": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RelatedThread": {
+            "type": "object",
+            "properties": {
+                "created_time": {
+                    "type": "string"
+                },
+                "creator": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "num_comments": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RenderRequest": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RenderResponse": {
             "type": "object",
             "properties": {
-                "password": {
-                    "type": "string"
-                },
-                "username": {
+                "body_html": {
                     "type": "string"
                 }
             }
         },
-        "models.AuthResponse": {
+        "models.RescheduleThreadRequest": {
             "type": "object",
             "properties": {
-                "token": {
-                    "type": "string"
-                },
-                "username": {
+                "publish_at": {
                     "type": "string"
                 }
             }
         },
-        "models.Comment": {
+        "models.RevisionDiff": {
             "type": "object",
             "properties": {
                 "body": {
-                    "type": "string"
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DiffSegment"
+                    }
                 },
-                "created_time": {
-                    "type": "string"
+                "from": {
+                    "type": "integer"
                 },
-                "creator": {
-                    "type": "string"
+                "tags_added": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "id": {
-                    "type": "string"
+                "tags_removed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "thread_id": {
-                    "type": "string"
+                "title": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DiffSegment"
+                    }
                 },
-                "updated_time": {
-                    "type": "string"
+                "to": {
+                    "type": "integer"
                 }
             }
         },
-        "models.CreateCommentRequest": {
+        "models.SaveDraftRequest": {
             "type": "object",
             "properties": {
                 "body": {
                     "type": "string"
                 },
-                "thread_id": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
                     "type": "string"
                 }
             }
         },
-        "models.CreateThreadRequest": {
+        "models.ScheduledThread": {
             "type": "object",
             "properties": {
+                "attachment_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "body": {
                     "type": "string"
                 },
+                "category_id": {
+                    "type": "string"
+                },
+                "created_time": {
+                    "type": "string"
+                },
+                "creator": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "poll": {
+                    "$ref": "#/definitions/models.CreatePollRequest"
+                },
+                "publish_at": {
+                    "type": "string"
+                },
                 "tags": {
                     "type": "array",
                     "items": {
@@ -619,40 +4258,106 @@ const docTemplate = `{
                 }
             }
         },
-        "models.GetCommentResponse": {
+        "models.SearchThreadResponse": {
             "type": "object",
             "properties": {
-                "comments": {
+                "threads": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Comment"
+                        "$ref": "#/definitions/models.Thread"
                     }
                 },
-                "count": {
+                "total_threads": {
                     "type": "integer"
                 }
             }
         },
-        "models.SearchThreadResponse": {
+        "models.SimilarThread": {
             "type": "object",
             "properties": {
-                "threads": {
+                "created_time": {
+                    "type": "string"
+                },
+                "creator": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_near_duplicate": {
+                    "type": "boolean"
+                },
+                "num_comments": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "title_similarity": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.SimilarThreadsRequest": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SplitThreadRequest": {
+            "type": "object",
+            "properties": {
+                "comment_ids": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Thread"
+                        "type": "string"
                     }
                 },
-                "total_threads": {
-                    "type": "integer"
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "target_thread_id": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SubscriptionRequest": {
+            "type": "object",
+            "properties": {
+                "is_muted": {
+                    "type": "boolean"
                 }
             }
         },
         "models.Thread": {
             "type": "object",
             "properties": {
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Attachment"
+                    }
+                },
                 "body": {
                     "type": "string"
                 },
+                "body_html": {
+                    "type": "string"
+                },
+                "category_id": {
+                    "type": "string"
+                },
                 "created_time": {
                     "type": "string"
                 },
@@ -662,9 +4367,42 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
+                "is_announcement": {
+                    "type": "boolean"
+                },
+                "is_bookmarked": {
+                    "type": "boolean"
+                },
+                "is_edited": {
+                    "type": "boolean"
+                },
+                "is_locked": {
+                    "type": "boolean"
+                },
+                "is_pinned": {
+                    "type": "boolean"
+                },
+                "is_subscribed": {
+                    "type": "boolean"
+                },
+                "lock_reason": {
+                    "type": "string"
+                },
+                "my_vote": {
+                    "type": "integer"
+                },
                 "num_comments": {
                     "type": "integer"
                 },
+                "num_revisions": {
+                    "type": "integer"
+                },
+                "poll": {
+                    "$ref": "#/definitions/models.Poll"
+                },
+                "score": {
+                    "type": "integer"
+                },
                 "tags": {
                     "type": "array",
                     "items": {
@@ -676,6 +4414,43 @@ const docTemplate = `{
                 },
                 "updated_time": {
                     "type": "string"
+                },
+                "view_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ThreadRevision": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "edited_time": {
+                    "type": "string"
+                },
+                "editor": {
+                    "type": "string"
+                },
+                "revision_number": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UnreadNotificationsResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
                 }
             }
         },
@@ -703,6 +4478,94 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "models.UserComment": {
+            "type": "object",
+            "properties": {
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Attachment"
+                    }
+                },
+                "body": {
+                    "type": "string"
+                },
+                "body_html": {
+                    "type": "string"
+                },
+                "created_time": {
+                    "type": "string"
+                },
+                "creator": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_edited": {
+                    "type": "boolean"
+                },
+                "my_vote": {
+                    "type": "integer"
+                },
+                "num_revisions": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "thread_id": {
+                    "type": "string"
+                },
+                "thread_title": {
+                    "type": "string"
+                },
+                "updated_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserProfile": {
+            "type": "object",
+            "properties": {
+                "num_comments": {
+                    "type": "integer"
+                },
+                "num_threads": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.VerifyEmailRequest": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.VoteRequest": {
+            "type": "object",
+            "properties": {
+                "value": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.VoteResponse": {
+            "type": "object",
+            "properties": {
+                "my_vote": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -2,6 +2,8 @@ package database
 
 import (
 	"backend/internal/models"
+	"backend/internal/utils"
+	"encoding/json"
 	"fmt"
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -17,39 +19,100 @@ func FormatPgUuid(uuid pgtype.UUID) string {
 		uuid.Bytes[10:16])
 }
 
+// formatPgBodyHtml Returns the cached HTML of a body, or renders the body again if the cached HTML was rendered by
+// a different version of the renderer and has not been refreshed yet. Users are not looked up here, so mentions are
+// rendered as text until the markdown job refreshes the HTML.
+func formatPgBodyHtml(body string, bodyHtml string, bodyHtmlVersion int32) string {
+	if bodyHtmlVersion != utils.MarkdownVersion {
+		return utils.RenderMarkdown(body, nil)
+	}
+	return bodyHtml
+}
+
 // FormatPgComment Formats a database.Comment into a models.Comment
 func FormatPgComment(pgComment Comment) models.Comment {
 	return models.Comment{
-		ID:          FormatPgUuid(pgComment.ID),
-		Body:        pgComment.Body,
-		Creator:     pgComment.Creator,
-		ThreadID:    FormatPgUuid(pgComment.ThreadID),
-		CreatedTime: pgComment.CreatedTime.Time,
-		UpdatedTime: pgComment.UpdatedTime.Time,
+		ID:           FormatPgUuid(pgComment.ID),
+		Body:         pgComment.Body,
+		BodyHtml:     formatPgBodyHtml(pgComment.Body, pgComment.BodyHtml, pgComment.BodyHtmlVersion),
+		Creator:      pgComment.Creator,
+		ThreadID:     FormatPgUuid(pgComment.ThreadID),
+		CreatedTime:  pgComment.CreatedTime.Time,
+		UpdatedTime:  pgComment.UpdatedTime.Time,
+		IsEdited:     pgComment.NumRevisions > 0,
+		NumRevisions: pgComment.NumRevisions,
+		Score:        pgComment.Score,
 	}
 }
 
-// FormatPgComments Formats a slice of database.Comment into a slice of models.Comment
-func FormatPgComments(pgComments []Comment) []models.Comment {
+// FormatPgComments Formats a slice of database.GetCommentsRow into a slice of models.Comment
+func FormatPgComments(pgComments []GetCommentsRow) []models.Comment {
 	comments := []models.Comment{}
 	for _, pgComment := range pgComments {
-		comments = append(comments, FormatPgComment(pgComment))
+		comment := FormatPgComment(pgComment.Comment)
+		comment.MyVote = pgComment.MyVote
+		comments = append(comments, comment)
+	}
+	return comments
+}
+
+// FormatPgUserComment Formats a database.GetCommentsByCreatorRow into a models.UserComment
+func FormatPgUserComment(pgComment GetCommentsByCreatorRow) models.UserComment {
+	return models.UserComment{
+		Comment: models.Comment{
+			ID:           FormatPgUuid(pgComment.ID),
+			Body:         pgComment.Body,
+			BodyHtml:     formatPgBodyHtml(pgComment.Body, pgComment.BodyHtml, pgComment.BodyHtmlVersion),
+			Creator:      pgComment.Creator,
+			ThreadID:     FormatPgUuid(pgComment.ThreadID),
+			CreatedTime:  pgComment.CreatedTime.Time,
+			UpdatedTime:  pgComment.UpdatedTime.Time,
+			IsEdited:     pgComment.NumRevisions > 0,
+			NumRevisions: pgComment.NumRevisions,
+			Score:        pgComment.Score,
+			MyVote:       pgComment.MyVote,
+		},
+		ThreadTitle: pgComment.ThreadTitle,
+	}
+}
+
+// FormatPgUserComments Formats a slice of database.GetCommentsByCreatorRow into a slice of models.UserComment
+func FormatPgUserComments(pgComments []GetCommentsByCreatorRow) []models.UserComment {
+	comments := []models.UserComment{}
+	for _, pgComment := range pgComments {
+		comments = append(comments, FormatPgUserComment(pgComment))
 	}
 	return comments
 }
 
 // FormatPgThread Formats a database.GetThreadDetailsRow into a models.Thread
 func FormatPgThread(pgThread GetThreadDetailsRow) models.Thread {
-	return models.Thread{
-		ID:          FormatPgUuid(pgThread.ID),
-		Title:       pgThread.Title,
-		Body:        pgThread.Body,
-		Creator:     pgThread.Creator,
-		CreatedTime: pgThread.CreatedTime.Time,
-		UpdatedTime: pgThread.UpdatedTime.Time,
-		NumComments: pgThread.NumComments,
-		Tags:        pgThread.Tags,
+	thread := models.Thread{
+		ID:             FormatPgUuid(pgThread.ID),
+		Title:          pgThread.Title,
+		Body:           pgThread.Body,
+		BodyHtml:       formatPgBodyHtml(pgThread.Body, pgThread.BodyHtml, pgThread.BodyHtmlVersion),
+		Creator:        pgThread.Creator,
+		CategoryId:     FormatPgUuid(pgThread.CategoryID),
+		CreatedTime:    pgThread.CreatedTime.Time,
+		UpdatedTime:    pgThread.UpdatedTime.Time,
+		NumComments:    pgThread.NumComments,
+		Tags:           pgThread.Tags,
+		IsEdited:       pgThread.NumRevisions > 0,
+		NumRevisions:   pgThread.NumRevisions,
+		IsLocked:       pgThread.IsLocked,
+		IsPinned:       pgThread.IsPinned,
+		IsAnnouncement: pgThread.IsAnnouncement,
+		Score:          pgThread.Score,
+		MyVote:         pgThread.MyVote,
+		ViewCount:      pgThread.ViewCount,
+		IsBookmarked:   pgThread.IsBookmarked,
+		IsSubscribed:   pgThread.IsSubscribed,
+	}
+	if pgThread.LockReason.Valid {
+		thread.LockReason = &pgThread.LockReason.String
 	}
+	return thread
 }
 
 // FormatPgThreads Formats a slice of database.GetThreadsByCriteriaRow into a slice of models.Thread
@@ -61,3 +124,322 @@ func FormatPgThreads(pgThread []GetThreadsByCriteriaRow) []models.Thread {
 	}
 	return threads
 }
+
+// FormatPgAnnouncements Formats a slice of database.GetAnnouncementsRow into a slice of models.Thread
+func FormatPgAnnouncements(pgThreads []GetAnnouncementsRow) []models.Thread {
+	threads := []models.Thread{}
+	for _, pgThread := range pgThreads {
+		// Conversion is possible as both types have the same fields
+		threads = append(threads, FormatPgThread(GetThreadDetailsRow(pgThread)))
+	}
+	return threads
+}
+
+// FormatPgThreadRevision Formats a database.ThreadRevision into a models.ThreadRevision
+func FormatPgThreadRevision(pgRevision ThreadRevision) models.ThreadRevision {
+	return models.ThreadRevision{
+		RevisionNumber: pgRevision.RevisionNumber,
+		Title:          pgRevision.Title,
+		Body:           pgRevision.Body,
+		Tags:           pgRevision.Tags,
+		Editor:         pgRevision.Editor,
+		EditedTime:     pgRevision.EditedTime.Time,
+	}
+}
+
+// FormatPgThreadRevisions Formats a slice of database.ThreadRevision into a slice of models.ThreadRevision
+func FormatPgThreadRevisions(pgRevisions []ThreadRevision) []models.ThreadRevision {
+	revisions := []models.ThreadRevision{}
+	for _, pgRevision := range pgRevisions {
+		revisions = append(revisions, FormatPgThreadRevision(pgRevision))
+	}
+	return revisions
+}
+
+// FormatPgCommentRevision Formats a database.CommentRevision into a models.CommentRevision
+func FormatPgCommentRevision(pgRevision CommentRevision) models.CommentRevision {
+	return models.CommentRevision{
+		RevisionNumber: pgRevision.RevisionNumber,
+		Body:           pgRevision.Body,
+		Editor:         pgRevision.Editor,
+		EditedTime:     pgRevision.EditedTime.Time,
+	}
+}
+
+// FormatPgCommentRevisions Formats a slice of database.CommentRevision into a slice of models.CommentRevision
+func FormatPgCommentRevisions(pgRevisions []CommentRevision) []models.CommentRevision {
+	revisions := []models.CommentRevision{}
+	for _, pgRevision := range pgRevisions {
+		revisions = append(revisions, FormatPgCommentRevision(pgRevision))
+	}
+	return revisions
+}
+
+// FormatPgInviteCode Formats a database.InviteCode into a models.InviteCode
+func FormatPgInviteCode(pgInviteCode InviteCode) models.InviteCode {
+	inviteCode := models.InviteCode{
+		Code:        pgInviteCode.Code,
+		Creator:     pgInviteCode.Creator,
+		MaxUses:     pgInviteCode.MaxUses,
+		NumUses:     pgInviteCode.NumUses,
+		CreatedTime: pgInviteCode.CreatedTime.Time,
+	}
+	if pgInviteCode.ExpiresTime.Valid {
+		inviteCode.ExpiresTime = &pgInviteCode.ExpiresTime.Time
+	}
+	return inviteCode
+}
+
+// FormatPgInviteCodes Formats a slice of database.InviteCode into a slice of models.InviteCode
+func FormatPgInviteCodes(pgInviteCodes []InviteCode) []models.InviteCode {
+	inviteCodes := []models.InviteCode{}
+	for _, pgInviteCode := range pgInviteCodes {
+		inviteCodes = append(inviteCodes, FormatPgInviteCode(pgInviteCode))
+	}
+	return inviteCodes
+}
+
+// FormatPgBookmarks Formats a slice of database.GetBookmarksRow into a slice of models.Bookmark
+func FormatPgBookmarks(pgBookmarks []GetBookmarksRow) []models.Bookmark {
+	bookmarks := []models.Bookmark{}
+	for _, pgBookmark := range pgBookmarks {
+		bookmark := models.Bookmark{
+			ID:          FormatPgUuid(pgBookmark.ID),
+			ThreadID:    FormatPgUuid(pgBookmark.ThreadID),
+			ThreadTitle: pgBookmark.ThreadTitle,
+			Creator:     pgBookmark.Creator,
+			Folder:      pgBookmark.Folder,
+			Note:        pgBookmark.Note,
+			CreatedTime: pgBookmark.CreatedTime.Time,
+		}
+		if pgBookmark.CommentID.Valid {
+			commentId := FormatPgUuid(pgBookmark.CommentID)
+			bookmark.CommentID = &commentId
+			bookmark.CommentBody = &pgBookmark.CommentBody.String
+		}
+		bookmarks = append(bookmarks, bookmark)
+	}
+	return bookmarks
+}
+
+// FormatPgNotifications Formats a slice of database.GetNotificationsRow into a slice of models.Notification
+func FormatPgNotifications(pgNotifications []GetNotificationsRow) []models.Notification {
+	notifications := []models.Notification{}
+	for _, pgNotification := range pgNotifications {
+		notification := models.Notification{
+			ID:          FormatPgUuid(pgNotification.ID),
+			Type:        pgNotification.Type,
+			ThreadID:    FormatPgUuid(pgNotification.ThreadID),
+			ThreadTitle: pgNotification.ThreadTitle,
+			IsRead:      pgNotification.IsRead,
+			CreatedTime: pgNotification.CreatedTime.Time,
+		}
+		if pgNotification.CommentID.Valid {
+			commentId := FormatPgUuid(pgNotification.CommentID)
+			notification.CommentID = &commentId
+		}
+		if pgNotification.Actor.Valid {
+			notification.Actor = &pgNotification.Actor.String
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications
+}
+
+// FormatPgDraft Formats a database.GetDraftRow into a models.Draft
+func FormatPgDraft(pgDraft GetDraftRow) models.Draft {
+	return models.Draft{
+		Context:     pgDraft.Context,
+		Title:       pgDraft.Title,
+		Body:        pgDraft.Body,
+		Tags:        pgDraft.Tags,
+		CreatedTime: pgDraft.CreatedTime.Time,
+		UpdatedTime: pgDraft.UpdatedTime.Time,
+	}
+}
+
+// FormatPgDrafts Formats a slice of database.GetDraftsRow into a slice of models.Draft
+func FormatPgDrafts(pgDrafts []GetDraftsRow) []models.Draft {
+	drafts := []models.Draft{}
+	for _, pgDraft := range pgDrafts {
+		// Conversion is possible as both types have the same fields
+		drafts = append(drafts, FormatPgDraft(GetDraftRow(pgDraft)))
+	}
+	return drafts
+}
+
+// FormatPgScheduledThread Formats a database.ScheduledThread into a models.ScheduledThread
+func FormatPgScheduledThread(pgThread ScheduledThread) models.ScheduledThread {
+	scheduledThread := models.ScheduledThread{
+		ID:            FormatPgUuid(pgThread.ID),
+		Title:         pgThread.Title,
+		Body:          pgThread.Body,
+		Creator:       pgThread.Creator,
+		Tags:          pgThread.Tags,
+		AttachmentIds: []string{},
+		CategoryId:    FormatPgUuid(pgThread.CategoryID),
+		PublishAt:     pgThread.PublishTime.Time,
+		CreatedTime:   pgThread.CreatedTime.Time,
+	}
+	for _, pgAttachmentId := range pgThread.AttachmentIds {
+		scheduledThread.AttachmentIds = append(scheduledThread.AttachmentIds, FormatPgUuid(pgAttachmentId))
+	}
+	if pgThread.Poll != nil {
+		var poll models.CreatePollRequest
+		if json.Unmarshal(pgThread.Poll, &poll) == nil {
+			scheduledThread.Poll = &poll
+		}
+	}
+	return scheduledThread
+}
+
+// FormatPgScheduledThreads Formats a slice of database.ScheduledThread into a slice of models.ScheduledThread
+func FormatPgScheduledThreads(pgThreads []ScheduledThread) []models.ScheduledThread {
+	threads := []models.ScheduledThread{}
+	for _, pgThread := range pgThreads {
+		threads = append(threads, FormatPgScheduledThread(pgThread))
+	}
+	return threads
+}
+
+// FormatPgPoll Formats a database.GetPollRow and its slice of database.GetPollOptionsRow into a models.Poll
+// Voters are left out if the poll is anonymous.
+func FormatPgPoll(pgPoll GetPollRow, pgOptions []GetPollOptionsRow) models.Poll {
+	poll := models.Poll{
+		Options:          []models.PollOption{},
+		IsMultipleChoice: pgPoll.IsMultipleChoice,
+		IsAnonymous:      pgPoll.IsAnonymous,
+		IsClosed:         pgPoll.IsClosed,
+		NumVoters:        pgPoll.NumVoters,
+		MyVotes:          []int32{},
+	}
+	if pgPoll.CloseTime.Valid {
+		poll.CloseTime = &pgPoll.CloseTime.Time
+	}
+	for _, pgOption := range pgOptions {
+		option := models.PollOption{
+			Position: pgOption.Position,
+			Text:     pgOption.Text,
+			NumVotes: pgOption.NumVotes,
+			Voters:   []string{},
+		}
+		if !pgPoll.IsAnonymous {
+			option.Voters = pgOption.Voters
+		}
+		if pgOption.IsMyVote {
+			poll.MyVotes = append(poll.MyVotes, pgOption.Position)
+		}
+		poll.Options = append(poll.Options, option)
+	}
+	return poll
+}
+
+// FormatPgAttachment Formats a database.GetAttachmentsRow into a models.Attachment
+func FormatPgAttachment(pgAttachment GetAttachmentsRow) models.Attachment {
+	attachment := models.Attachment{
+		ID:           FormatPgUuid(pgAttachment.ID),
+		Filename:     pgAttachment.Filename,
+		ContentType:  pgAttachment.ContentType,
+		Size:         pgAttachment.Size,
+		HasThumbnail: pgAttachment.HasThumbnail,
+		CreatedTime:  pgAttachment.CreatedTime.Time,
+	}
+	if pgAttachment.Width.Valid && pgAttachment.Height.Valid {
+		attachment.Width = &pgAttachment.Width.Int32
+		attachment.Height = &pgAttachment.Height.Int32
+	}
+	return attachment
+}
+
+// FormatPgAttachments Formats a slice of database.GetAttachmentsRow into a slice of models.Attachment
+func FormatPgAttachments(pgAttachments []GetAttachmentsRow) []models.Attachment {
+	attachments := []models.Attachment{}
+	for _, pgAttachment := range pgAttachments {
+		attachments = append(attachments, FormatPgAttachment(pgAttachment))
+	}
+	return attachments
+}
+
+// FormatPgRelatedThreads Formats a slice of database.GetRelatedThreadsRow into a slice of models.RelatedThread
+func FormatPgRelatedThreads(pgThreads []GetRelatedThreadsRow) []models.RelatedThread {
+	threads := []models.RelatedThread{}
+	for _, pgThread := range pgThreads {
+		threads = append(threads, models.RelatedThread{
+			ID:          FormatPgUuid(pgThread.ID),
+			Title:       pgThread.Title,
+			Creator:     pgThread.Creator,
+			CreatedTime: pgThread.CreatedTime.Time,
+			NumComments: pgThread.NumComments,
+			Score:       pgThread.Score,
+			Tags:        pgThread.Tags,
+		})
+	}
+	return threads
+}
+
+// FormatPgSimilarThreads Formats a slice of database.GetSimilarThreadsRow into a slice of models.SimilarThread
+func FormatPgSimilarThreads(pgThreads []GetSimilarThreadsRow) []models.SimilarThread {
+	threads := []models.SimilarThread{}
+	for _, pgThread := range pgThreads {
+		threads = append(threads, models.SimilarThread{
+			ID:              FormatPgUuid(pgThread.ID),
+			Title:           pgThread.Title,
+			Creator:         pgThread.Creator,
+			CreatedTime:     pgThread.CreatedTime.Time,
+			NumComments:     pgThread.NumComments,
+			TitleSimilarity: pgThread.TitleSimilarity,
+			IsNearDuplicate: pgThread.IsNearDuplicate,
+		})
+	}
+	return threads
+}
+
+// FormatPgCategory Formats a database.Category into a models.Category without its subcategories
+func FormatPgCategory(pgCategory Category) models.Category {
+	category := models.Category{
+		ID:            FormatPgUuid(pgCategory.ID),
+		Name:          pgCategory.Name,
+		Slug:          pgCategory.Slug,
+		Description:   pgCategory.Description,
+		Position:      pgCategory.Position,
+		PostingRole:   pgCategory.PostingRole,
+		NumThreads:    pgCategory.NumThreads,
+		TotalThreads:  pgCategory.NumThreads,
+		CreatedTime:   pgCategory.CreatedTime.Time,
+		Subcategories: []models.Category{},
+	}
+	if pgCategory.ParentID.Valid {
+		parentId := FormatPgUuid(pgCategory.ParentID)
+		category.ParentId = &parentId
+	}
+	return category
+}
+
+// FormatPgCategories Formats a slice of database.Category into a tree of models.Category, keeping the order of the
+// slice among categories with the same parent. Returns the top-level categories.
+func FormatPgCategories(pgCategories []Category) []models.Category {
+	children := map[string][]models.Category{}
+	for _, pgCategory := range pgCategories {
+		category := FormatPgCategory(pgCategory)
+		parentId := ""
+		if category.ParentId != nil {
+			parentId = *category.ParentId
+		}
+		children[parentId] = append(children[parentId], category)
+	}
+	return buildCategoryTree(children, "")
+}
+
+// buildCategoryTree Returns the subcategories of the category with the given ID, with their own subcategories, and
+// adds the threads of the subcategories to their total number of threads.
+func buildCategoryTree(children map[string][]models.Category, parentId string) []models.Category {
+	categories := []models.Category{}
+	for _, category := range children[parentId] {
+		category.Subcategories = buildCategoryTree(children, category.ID)
+		for _, subcategory := range category.Subcategories {
+			category.TotalThreads += subcategory.TotalThreads
+		}
+		categories = append(categories, category)
+	}
+	return categories
+}
@@ -8,13 +8,160 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type Comment struct {
+type Attachment struct {
+	ID            pgtype.UUID        `json:"id"`
+	Uploader      string             `json:"uploader"`
+	Filename      string             `json:"filename"`
+	BlobHash      string             `json:"blob_hash"`
+	ThumbnailHash pgtype.Text        `json:"thumbnail_hash"`
+	Width         pgtype.Int4        `json:"width"`
+	Height        pgtype.Int4        `json:"height"`
+	ThreadID      pgtype.UUID        `json:"thread_id"`
+	CommentID     pgtype.UUID        `json:"comment_id"`
+	CreatedTime   pgtype.Timestamptz `json:"created_time"`
+}
+
+type Blob struct {
+	Hash         string             `json:"hash"`
+	Size         int32              `json:"size"`
+	ContentType  string             `json:"content_type"`
+	LastUsedTime pgtype.Timestamptz `json:"last_used_time"`
+}
+
+type Bookmark struct {
+	ID          pgtype.UUID        `json:"id"`
+	Username    string             `json:"username"`
+	ThreadID    pgtype.UUID        `json:"thread_id"`
+	CommentID   pgtype.UUID        `json:"comment_id"`
+	Folder      string             `json:"folder"`
+	Note        string             `json:"note"`
+	CreatedTime pgtype.Timestamptz `json:"created_time"`
+}
+
+type Category struct {
 	ID          pgtype.UUID        `json:"id"`
+	Name        string             `json:"name"`
+	Slug        string             `json:"slug"`
+	Description string             `json:"description"`
+	ParentID    pgtype.UUID        `json:"parent_id"`
+	Position    int32              `json:"position"`
+	PostingRole string             `json:"posting_role"`
+	NumThreads  int32              `json:"num_threads"`
+	CreatedTime pgtype.Timestamptz `json:"created_time"`
+}
+
+type Comment struct {
+	ID              pgtype.UUID        `json:"id"`
+	Body            string             `json:"body"`
+	BodyHtml        string             `json:"body_html"`
+	BodyHtmlVersion int32              `json:"body_html_version"`
+	Creator         string             `json:"creator"`
+	ThreadID        pgtype.UUID        `json:"thread_id"`
+	CreatedTime     pgtype.Timestamptz `json:"created_time"`
+	UpdatedTime     pgtype.Timestamptz `json:"updated_time"`
+	NumRevisions    int32              `json:"num_revisions"`
+	DeletedTime     pgtype.Timestamptz `json:"deleted_time"`
+	DeletedBy       pgtype.Text        `json:"deleted_by"`
+	Score           int32              `json:"score"`
+}
+
+type CommentMention struct {
+	CommentID   pgtype.UUID        `json:"comment_id"`
+	Username    string             `json:"username"`
+	IsCurrent   bool               `json:"is_current"`
+	CreatedTime pgtype.Timestamptz `json:"created_time"`
+}
+
+type CommentRevision struct {
+	CommentID      pgtype.UUID        `json:"comment_id"`
+	RevisionNumber int32              `json:"revision_number"`
+	Body           string             `json:"body"`
+	Editor         string             `json:"editor"`
+	EditedTime     pgtype.Timestamptz `json:"edited_time"`
+}
+
+type CommentVote struct {
+	CommentID   pgtype.UUID        `json:"comment_id"`
+	Username    string             `json:"username"`
+	Value       int16              `json:"value"`
+	CreatedTime pgtype.Timestamptz `json:"created_time"`
+	UpdatedTime pgtype.Timestamptz `json:"updated_time"`
+}
+
+type Draft struct {
+	Username    string             `json:"username"`
+	Context     string             `json:"context"`
+	Title       string             `json:"title"`
 	Body        string             `json:"body"`
+	Tags        []string           `json:"tags"`
+	CreatedTime pgtype.Timestamptz `json:"created_time"`
+	UpdatedTime pgtype.Timestamptz `json:"updated_time"`
+}
+
+type EmailVerification struct {
+	TokenHash   string             `json:"token_hash"`
+	Username    string             `json:"username"`
+	ExpiresTime pgtype.Timestamptz `json:"expires_time"`
+}
+
+type InviteCode struct {
+	Code        string             `json:"code"`
 	Creator     string             `json:"creator"`
+	MaxUses     int32              `json:"max_uses"`
+	NumUses     int32              `json:"num_uses"`
+	ExpiresTime pgtype.Timestamptz `json:"expires_time"`
+	CreatedTime pgtype.Timestamptz `json:"created_time"`
+}
+
+type InviteCodeUse struct {
+	Code     string             `json:"code"`
+	Username string             `json:"username"`
+	UsedTime pgtype.Timestamptz `json:"used_time"`
+}
+
+type Notification struct {
+	ID          pgtype.UUID        `json:"id"`
+	Username    string             `json:"username"`
+	Type        string             `json:"type"`
 	ThreadID    pgtype.UUID        `json:"thread_id"`
+	CommentID   pgtype.UUID        `json:"comment_id"`
+	Actor       pgtype.Text        `json:"actor"`
+	IsRead      bool               `json:"is_read"`
 	CreatedTime pgtype.Timestamptz `json:"created_time"`
-	UpdatedTime pgtype.Timestamptz `json:"updated_time"`
+}
+
+type Poll struct {
+	ThreadID         pgtype.UUID        `json:"thread_id"`
+	IsMultipleChoice bool               `json:"is_multiple_choice"`
+	IsAnonymous      bool               `json:"is_anonymous"`
+	CloseTime        pgtype.Timestamptz `json:"close_time"`
+	CreatedTime      pgtype.Timestamptz `json:"created_time"`
+}
+
+type PollOption struct {
+	ThreadID pgtype.UUID `json:"thread_id"`
+	Position int32       `json:"position"`
+	Text     string      `json:"text"`
+}
+
+type PollVote struct {
+	ThreadID    pgtype.UUID        `json:"thread_id"`
+	Position    int32              `json:"position"`
+	Username    string             `json:"username"`
+	CreatedTime pgtype.Timestamptz `json:"created_time"`
+}
+
+type ScheduledThread struct {
+	ID            pgtype.UUID        `json:"id"`
+	Title         string             `json:"title"`
+	Body          string             `json:"body"`
+	Creator       string             `json:"creator"`
+	Tags          []string           `json:"tags"`
+	Poll          []byte             `json:"poll"`
+	AttachmentIds []pgtype.UUID      `json:"attachment_ids"`
+	CategoryID    pgtype.UUID        `json:"category_id"`
+	PublishTime   pgtype.Timestamptz `json:"publish_time"`
+	CreatedTime   pgtype.Timestamptz `json:"created_time"`
 }
 
 type Tag struct {
@@ -22,13 +169,69 @@ type Tag struct {
 }
 
 type Thread struct {
-	ID          pgtype.UUID        `json:"id"`
-	Title       string             `json:"title"`
-	Body        string             `json:"body"`
-	Creator     string             `json:"creator"`
+	ID              pgtype.UUID        `json:"id"`
+	Title           string             `json:"title"`
+	Body            string             `json:"body"`
+	BodyHtml        string             `json:"body_html"`
+	BodyHtmlVersion int32              `json:"body_html_version"`
+	BodySimhash     pgtype.Int8        `json:"body_simhash"`
+	Creator         string             `json:"creator"`
+	CreatedTime     pgtype.Timestamptz `json:"created_time"`
+	UpdatedTime     pgtype.Timestamptz `json:"updated_time"`
+	NumComments     int32              `json:"num_comments"`
+	NumRevisions    int32              `json:"num_revisions"`
+	DeletedTime     pgtype.Timestamptz `json:"deleted_time"`
+	DeletedBy       pgtype.Text        `json:"deleted_by"`
+	IsLocked        bool               `json:"is_locked"`
+	LockReason      pgtype.Text        `json:"lock_reason"`
+	LockedTime      pgtype.Timestamptz `json:"locked_time"`
+	LockedBy        pgtype.Text        `json:"locked_by"`
+	Score           int32              `json:"score"`
+	HotScore        float64            `json:"hot_score"`
+	TrendingScore   float64            `json:"trending_score"`
+	ViewCount       int32              `json:"view_count"`
+	CategoryID      pgtype.UUID        `json:"category_id"`
+}
+
+type ThreadMention struct {
+	ThreadID    pgtype.UUID        `json:"thread_id"`
+	Username    string             `json:"username"`
+	IsCurrent   bool               `json:"is_current"`
+	CreatedTime pgtype.Timestamptz `json:"created_time"`
+}
+
+type ThreadPin struct {
+	ThreadID       pgtype.UUID        `json:"thread_id"`
+	TagName        string             `json:"tag_name"`
+	IsAnnouncement bool               `json:"is_announcement"`
+	PinnedBy       pgtype.Text        `json:"pinned_by"`
+	PinnedTime     pgtype.Timestamptz `json:"pinned_time"`
+	ExpiresTime    pgtype.Timestamptz `json:"expires_time"`
+}
+
+type ThreadRedirect struct {
+	ThreadID       pgtype.UUID        `json:"thread_id"`
+	TargetThreadID pgtype.UUID        `json:"target_thread_id"`
+	Title          string             `json:"title"`
+	CreatedBy      pgtype.Text        `json:"created_by"`
+	CreatedTime    pgtype.Timestamptz `json:"created_time"`
+}
+
+type ThreadRevision struct {
+	ThreadID       pgtype.UUID        `json:"thread_id"`
+	RevisionNumber int32              `json:"revision_number"`
+	Title          string             `json:"title"`
+	Body           string             `json:"body"`
+	Tags           []string           `json:"tags"`
+	Editor         string             `json:"editor"`
+	EditedTime     pgtype.Timestamptz `json:"edited_time"`
+}
+
+type ThreadSubscription struct {
+	ThreadID    pgtype.UUID        `json:"thread_id"`
+	Username    string             `json:"username"`
+	IsMuted     bool               `json:"is_muted"`
 	CreatedTime pgtype.Timestamptz `json:"created_time"`
-	UpdatedTime pgtype.Timestamptz `json:"updated_time"`
-	NumComments int32              `json:"num_comments"`
 }
 
 type ThreadTag struct {
@@ -36,7 +239,37 @@ type ThreadTag struct {
 	TagName  string      `json:"tag_name"`
 }
 
+type ThreadView struct {
+	ThreadID   pgtype.UUID        `json:"thread_id"`
+	Viewer     string             `json:"viewer"`
+	ViewedTime pgtype.Timestamptz `json:"viewed_time"`
+}
+
+type ThreadVote struct {
+	ThreadID    pgtype.UUID        `json:"thread_id"`
+	Username    string             `json:"username"`
+	Value       int16              `json:"value"`
+	CreatedTime pgtype.Timestamptz `json:"created_time"`
+	UpdatedTime pgtype.Timestamptz `json:"updated_time"`
+}
+
 type User struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username          string             `json:"username"`
+	CanonicalUsername string             `json:"canonical_username"`
+	UsernameSkeleton  string             `json:"username_skeleton"`
+	Password          string             `json:"password"`
+	Email             pgtype.Text        `json:"email"`
+	IsVerified        bool               `json:"is_verified"`
+	Role              string             `json:"role"`
+	CreatedTime       pgtype.Timestamptz `json:"created_time"`
+	LastRenamedTime   pgtype.Timestamptz `json:"last_renamed_time"`
+}
+
+type UsernameHistory struct {
+	ID                   pgtype.UUID        `json:"id"`
+	Username             string             `json:"username"`
+	OldUsername          string             `json:"old_username"`
+	OldCanonicalUsername string             `json:"old_canonical_username"`
+	OldUsernameSkeleton  string             `json:"old_username_skeleton"`
+	ChangedTime          pgtype.Timestamptz `json:"changed_time"`
 }
@@ -11,6 +11,22 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addInviteCodeUse = `-- name: AddInviteCodeUse :exec
+INSERT INTO invite_code_uses (code, username)
+VALUES ($1, $2)
+`
+
+type AddInviteCodeUseParams struct {
+	Code     string `json:"code"`
+	Username string `json:"username"`
+}
+
+// Records that a user registered with an invite code.
+func (q *Queries) AddInviteCodeUse(ctx context.Context, arg AddInviteCodeUseParams) error {
+	_, err := q.db.Exec(ctx, addInviteCodeUse, arg.Code, arg.Username)
+	return err
+}
+
 const addNewTags = `-- name: AddNewTags :exec
 INSERT INTO tags (name)
 SELECT new_tags FROM UNNEST($1::text[]) AS new_tags
@@ -27,6 +43,40 @@ func (q *Queries) AddNewTags(ctx context.Context, tagarray []string) error {
 	return err
 }
 
+const addPollOptions = `-- name: AddPollOptions :exec
+INSERT INTO poll_options (thread_id, position, text)
+SELECT $1, o.position, o.text
+FROM UNNEST($2::text[]) WITH ORDINALITY AS o(text, position)
+`
+
+type AddPollOptionsParams struct {
+	ThreadID pgtype.UUID `json:"thread_id"`
+	Options  []string    `json:"options"`
+}
+
+// Adds options to a poll, numbered from 1 in the given order.
+func (q *Queries) AddPollOptions(ctx context.Context, arg AddPollOptionsParams) error {
+	_, err := q.db.Exec(ctx, addPollOptions, arg.ThreadID, arg.Options)
+	return err
+}
+
+const addPollVotes = `-- name: AddPollVotes :exec
+INSERT INTO poll_votes (thread_id, position, username)
+SELECT $1, UNNEST($2::integer[]), $3::text
+`
+
+type AddPollVotesParams struct {
+	ThreadID  pgtype.UUID `json:"thread_id"`
+	Positions []int32     `json:"positions"`
+	Username  string      `json:"username"`
+}
+
+// Adds the votes of a user on a poll.
+func (q *Queries) AddPollVotes(ctx context.Context, arg AddPollVotesParams) error {
+	_, err := q.db.Exec(ctx, addPollVotes, arg.ThreadID, arg.Positions, arg.Username)
+	return err
+}
+
 const addThreadTags = `-- name: AddThreadTags :exec
 INSERT INTO thread_tags (thread_id, tag_name)
 SELECT $1 as thread_id,
@@ -45,9 +95,109 @@ func (q *Queries) AddThreadTags(ctx context.Context, arg AddThreadTagsParams) er
 	return err
 }
 
+const addUsernameHistory = `-- name: AddUsernameHistory :exec
+INSERT INTO username_history (username, old_username, old_canonical_username, old_username_skeleton)
+VALUES ($1, $2, $3, $4)
+`
+
+type AddUsernameHistoryParams struct {
+	Username             string `json:"username"`
+	OldUsername          string `json:"old_username"`
+	OldCanonicalUsername string `json:"old_canonical_username"`
+	OldUsernameSkeleton  string `json:"old_username_skeleton"`
+}
+
+// Records the previous username of a renamed user.
+func (q *Queries) AddUsernameHistory(ctx context.Context, arg AddUsernameHistoryParams) error {
+	_, err := q.db.Exec(ctx, addUsernameHistory,
+		arg.Username,
+		arg.OldUsername,
+		arg.OldCanonicalUsername,
+		arg.OldUsernameSkeleton,
+	)
+	return err
+}
+
+const attachToComment = `-- name: AttachToComment :execrows
+UPDATE attachments
+SET comment_id = $1
+WHERE id = ANY($2::uuid[])
+AND uploader = $3::text
+AND thread_id IS NULL
+AND comment_id IS NULL
+`
+
+type AttachToCommentParams struct {
+	CommentID     pgtype.UUID   `json:"comment_id"`
+	AttachmentIds []pgtype.UUID `json:"attachment_ids"`
+	Uploader      string        `json:"uploader"`
+}
+
+// Attaches attachments uploaded by a user to a comment, skipping any that are already attached.
+// Returns the number attached.
+func (q *Queries) AttachToComment(ctx context.Context, arg AttachToCommentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, attachToComment, arg.CommentID, arg.AttachmentIds, arg.Uploader)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const attachToThread = `-- name: AttachToThread :execrows
+UPDATE attachments
+SET thread_id = $1
+WHERE id = ANY($2::uuid[])
+AND uploader = $3::text
+AND thread_id IS NULL
+AND comment_id IS NULL
+`
+
+type AttachToThreadParams struct {
+	ThreadID      pgtype.UUID   `json:"thread_id"`
+	AttachmentIds []pgtype.UUID `json:"attachment_ids"`
+	Uploader      string        `json:"uploader"`
+}
+
+// Attaches attachments uploaded by a user to a thread, skipping any that are already attached.
+// Returns the number attached.
+func (q *Queries) AttachToThread(ctx context.Context, arg AttachToThreadParams) (int64, error) {
+	result, err := q.db.Exec(ctx, attachToThread, arg.ThreadID, arg.AttachmentIds, arg.Uploader)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const autoLockInactiveThreads = `-- name: AutoLockInactiveThreads :execrows
+UPDATE threads t
+SET is_locked = TRUE, lock_reason = $1::text, locked_time = NOW(), locked_by = NULL
+WHERE NOT t.is_locked
+AND t.deleted_time IS NULL
+AND GREATEST(t.created_time, (
+    SELECT MAX(c.created_time)
+    FROM comments c
+    WHERE c.thread_id = t.id
+    AND c.deleted_time IS NULL
+)) < NOW() - MAKE_INTERVAL(days => $2::integer)
+`
+
+type AutoLockInactiveThreadsParams struct {
+	LockReason   string `json:"lock_reason"`
+	InactiveDays int32  `json:"inactive_days"`
+}
+
+// Locks threads without new comments for more than the given number of days.
+func (q *Queries) AutoLockInactiveThreads(ctx context.Context, arg AutoLockInactiveThreadsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, autoLockInactiveThreads, arg.LockReason, arg.InactiveDays)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const checkCommentCreator = `-- name: CheckCommentCreator :one
 SELECT EXISTS
-    (SELECT 1 FROM comments WHERE id = $1 AND creator = $2)
+    (SELECT 1 FROM comments WHERE id = $1 AND creator = $2 AND deleted_time IS NULL)
 AS is_comment_creator
 `
 
@@ -56,7 +206,7 @@ type CheckCommentCreatorParams struct {
 	Creator string      `json:"creator"`
 }
 
-// Checks if a user is the creator of a comment.
+// Checks if a user is the creator of a comment that has not been deleted.
 func (q *Queries) CheckCommentCreator(ctx context.Context, arg CheckCommentCreatorParams) (bool, error) {
 	row := q.db.QueryRow(ctx, checkCommentCreator, arg.ID, arg.Creator)
 	var is_comment_creator bool
@@ -64,9 +214,55 @@ func (q *Queries) CheckCommentCreator(ctx context.Context, arg CheckCommentCreat
 	return is_comment_creator, err
 }
 
+const checkEmailExists = `-- name: CheckEmailExists :one
+SELECT EXISTS
+    (SELECT 1 FROM users WHERE LOWER(email) = LOWER($1))
+AS is_existing_email
+`
+
+// Returns 1 if a user with the given email exists.
+func (q *Queries) CheckEmailExists(ctx context.Context, lower string) (bool, error) {
+	row := q.db.QueryRow(ctx, checkEmailExists, lower)
+	var is_existing_email bool
+	err := row.Scan(&is_existing_email)
+	return is_existing_email, err
+}
+
+const checkRepost = `-- name: CheckRepost :one
+SELECT EXISTS (
+    SELECT 1 FROM threads t
+    WHERE t.creator = $1::text
+    AND LOWER(REGEXP_REPLACE(TRIM(t.title), '\s+', ' ', 'g')) = LOWER(REGEXP_REPLACE(TRIM($2::text), '\s+', ' ', 'g'))
+    AND REGEXP_REPLACE(TRIM(t.body), '\s+', ' ', 'g') = REGEXP_REPLACE(TRIM($3::text), '\s+', ' ', 'g')
+    AND t.created_time > NOW() - MAKE_INTERVAL(hours => $4::integer)
+    AND t.deleted_time IS NULL
+) AS is_repost
+`
+
+type CheckRepostParams struct {
+	Creator     string `json:"creator"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	WindowHours int32  `json:"window_hours"`
+}
+
+// Returns 1 if the user has created a thread with the same title and body within the last given number of hours.
+// Titles are compared case-insensitively, and whitespace is ignored at the ends and collapsed elsewhere.
+func (q *Queries) CheckRepost(ctx context.Context, arg CheckRepostParams) (bool, error) {
+	row := q.db.QueryRow(ctx, checkRepost,
+		arg.Creator,
+		arg.Title,
+		arg.Body,
+		arg.WindowHours,
+	)
+	var is_repost bool
+	err := row.Scan(&is_repost)
+	return is_repost, err
+}
+
 const checkThreadCreator = `-- name: CheckThreadCreator :one
 SELECT EXISTS
-    (SELECT 1 FROM threads WHERE id = $1 AND creator = $2)
+    (SELECT 1 FROM threads WHERE id = $1 AND creator = $2 AND deleted_time IS NULL)
 AS is_thread_creator
 `
 
@@ -75,7 +271,7 @@ type CheckThreadCreatorParams struct {
 	Creator string      `json:"creator"`
 }
 
-// Checks if a user is the creator of a thread.
+// Checks if a user is the creator of a thread that has not been deleted.
 func (q *Queries) CheckThreadCreator(ctx context.Context, arg CheckThreadCreatorParams) (bool, error) {
 	row := q.db.QueryRow(ctx, checkThreadCreator, arg.ID, arg.Creator)
 	var is_thread_creator bool
@@ -83,63 +279,464 @@ func (q *Queries) CheckThreadCreator(ctx context.Context, arg CheckThreadCreator
 	return is_thread_creator, err
 }
 
+const checkThreadExists = `-- name: CheckThreadExists :one
+SELECT EXISTS
+    (SELECT 1 FROM threads WHERE id = $1 AND deleted_time IS NULL)
+AS is_existing_thread
+`
+
+// Checks if a thread exists and has not been deleted.
+func (q *Queries) CheckThreadExists(ctx context.Context, id pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, checkThreadExists, id)
+	var is_existing_thread bool
+	err := row.Scan(&is_existing_thread)
+	return is_existing_thread, err
+}
+
 const checkUserExists = `-- name: CheckUserExists :one
 SELECT EXISTS
-    (SELECT 1 FROM users WHERE LOWER(username) = LOWER($1))
+    (SELECT 1 FROM users WHERE canonical_username = $1)
 AS is_existing_user
 `
 
-// Returns 1 if the user with the given username exists.
-func (q *Queries) CheckUserExists(ctx context.Context, lower string) (bool, error) {
-	row := q.db.QueryRow(ctx, checkUserExists, lower)
+// Returns 1 if the user with the given canonical username exists.
+func (q *Queries) CheckUserExists(ctx context.Context, canonicalUsername string) (bool, error) {
+	row := q.db.QueryRow(ctx, checkUserExists, canonicalUsername)
 	var is_existing_user bool
 	err := row.Scan(&is_existing_user)
 	return is_existing_user, err
 }
 
+const checkUserVerified = `-- name: CheckUserVerified :one
+SELECT is_verified
+FROM users
+WHERE username = $1
+`
+
+// Returns whether the user has verified their email.
+func (q *Queries) CheckUserVerified(ctx context.Context, username string) (bool, error) {
+	row := q.db.QueryRow(ctx, checkUserVerified, username)
+	var is_verified bool
+	err := row.Scan(&is_verified)
+	return is_verified, err
+}
+
+const checkUsernameTaken = `-- name: CheckUsernameTaken :one
+SELECT (EXISTS
+    (SELECT 1 FROM users u
+     WHERE (u.canonical_username = $1 OR u.username_skeleton = $2)
+     AND u.username <> $3::text)
+OR EXISTS
+    (SELECT 1 FROM username_history h
+     WHERE (h.old_canonical_username = $1 OR h.old_username_skeleton = $2)
+     AND h.username <> $3::text
+     AND h.changed_time > NOW() - MAKE_INTERVAL(days => $4::int)))::boolean
+AS is_username_taken
+`
+
+type CheckUsernameTakenParams struct {
+	CanonicalUsername string `json:"canonical_username"`
+	UsernameSkeleton  string `json:"username_skeleton"`
+	Username          string `json:"username"`
+	HoldDays          int32  `json:"hold_days"`
+}
+
+// Returns 1 if a user other than the given user has the given canonical username or a look-alike username,
+// or had it within the last hold_days days.
+func (q *Queries) CheckUsernameTaken(ctx context.Context, arg CheckUsernameTakenParams) (bool, error) {
+	row := q.db.QueryRow(ctx, checkUsernameTaken,
+		arg.CanonicalUsername,
+		arg.UsernameSkeleton,
+		arg.Username,
+		arg.HoldDays,
+	)
+	var is_username_taken bool
+	err := row.Scan(&is_username_taken)
+	return is_username_taken, err
+}
+
+const countAttachableAttachments = `-- name: CountAttachableAttachments :one
+SELECT COUNT(*)::integer
+FROM attachments a
+WHERE a.id = ANY($1::uuid[])
+AND a.uploader = $2::text
+AND a.thread_id IS NULL
+AND a.comment_id IS NULL
+AND NOT EXISTS (SELECT 1 FROM scheduled_threads st WHERE a.id = ANY(st.attachment_ids))
+`
+
+type CountAttachableAttachmentsParams struct {
+	AttachmentIds []pgtype.UUID `json:"attachment_ids"`
+	Uploader      string        `json:"uploader"`
+}
+
+// Counts the given attachments that were uploaded by a user and can still be attached, which are those not attached
+// to a thread, a comment or a scheduled thread.
+func (q *Queries) CountAttachableAttachments(ctx context.Context, arg CountAttachableAttachmentsParams) (int32, error) {
+	row := q.db.QueryRow(ctx, countAttachableAttachments, arg.AttachmentIds, arg.Uploader)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const countMovableComments = `-- name: CountMovableComments :one
+SELECT COUNT(*)::integer
+FROM comments
+WHERE id = ANY($1::uuid[])
+AND thread_id = $2
+AND deleted_time IS NULL
+`
+
+type CountMovableCommentsParams struct {
+	CommentIds []pgtype.UUID `json:"comment_ids"`
+	ThreadID   pgtype.UUID   `json:"thread_id"`
+}
+
+// Returns the number of the given comments that are on the given thread and have not been deleted.
+func (q *Queries) CountMovableComments(ctx context.Context, arg CountMovableCommentsParams) (int32, error) {
+	row := q.db.QueryRow(ctx, countMovableComments, arg.CommentIds, arg.ThreadID)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const createAttachment = `-- name: CreateAttachment :one
+INSERT INTO attachments (uploader, filename, blob_hash, thumbnail_hash, width, height)
+VALUES ($1::text, $2::text, $3::text, $4::text,
+    $5::integer, $6::integer)
+RETURNING id, created_time
+`
+
+type CreateAttachmentParams struct {
+	Uploader      string      `json:"uploader"`
+	Filename      string      `json:"filename"`
+	BlobHash      string      `json:"blob_hash"`
+	ThumbnailHash pgtype.Text `json:"thumbnail_hash"`
+	Width         pgtype.Int4 `json:"width"`
+	Height        pgtype.Int4 `json:"height"`
+}
+
+type CreateAttachmentRow struct {
+	ID          pgtype.UUID        `json:"id"`
+	CreatedTime pgtype.Timestamptz `json:"created_time"`
+}
+
+// Creates an attachment that is not attached to a thread or comment yet. Returns the ID and creation time.
+func (q *Queries) CreateAttachment(ctx context.Context, arg CreateAttachmentParams) (CreateAttachmentRow, error) {
+	row := q.db.QueryRow(ctx, createAttachment,
+		arg.Uploader,
+		arg.Filename,
+		arg.BlobHash,
+		arg.ThumbnailHash,
+		arg.Width,
+		arg.Height,
+	)
+	var i CreateAttachmentRow
+	err := row.Scan(&i.ID, &i.CreatedTime)
+	return i, err
+}
+
+const createBlob = `-- name: CreateBlob :exec
+INSERT INTO blobs (hash, size, content_type)
+VALUES ($1::text, $2::integer, $3::text)
+ON CONFLICT (hash) DO UPDATE
+SET last_used_time = NOW()
+`
+
+type CreateBlobParams struct {
+	Hash        string `json:"hash"`
+	Size        int32  `json:"size"`
+	ContentType string `json:"content_type"`
+}
+
+// Adds a file to the blob store index, or marks it as used again if it is already stored.
+func (q *Queries) CreateBlob(ctx context.Context, arg CreateBlobParams) error {
+	_, err := q.db.Exec(ctx, createBlob, arg.Hash, arg.Size, arg.ContentType)
+	return err
+}
+
+const createCategory = `-- name: CreateCategory :one
+INSERT INTO categories (name, slug, description, parent_id, position, posting_role)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, name, slug, description, parent_id, position, posting_role, num_threads, created_time
+`
+
+type CreateCategoryParams struct {
+	Name        string      `json:"name"`
+	Slug        string      `json:"slug"`
+	Description string      `json:"description"`
+	ParentID    pgtype.UUID `json:"parent_id"`
+	Position    int32       `json:"position"`
+	PostingRole string      `json:"posting_role"`
+}
+
+// Creates a new category. Returns the details of the created category.
+func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error) {
+	row := q.db.QueryRow(ctx, createCategory,
+		arg.Name,
+		arg.Slug,
+		arg.Description,
+		arg.ParentID,
+		arg.Position,
+		arg.PostingRole,
+	)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Slug,
+		&i.Description,
+		&i.ParentID,
+		&i.Position,
+		&i.PostingRole,
+		&i.NumThreads,
+		&i.CreatedTime,
+	)
+	return i, err
+}
+
 const createComment = `-- name: CreateComment :one
-INSERT INTO comments (body, creator, thread_id)
-VALUES ($1, $2, $3)
-RETURNING id, body, creator, thread_id, created_time, updated_time
+INSERT INTO comments (body, body_html, body_html_version, creator, thread_id)
+SELECT $1::text, $2::text, $3::integer, $4::text, t.id
+FROM threads t
+WHERE t.id = $5
+AND t.deleted_time IS NULL
+RETURNING id, body, body_html, body_html_version, creator, thread_id, created_time, updated_time, num_revisions,
+    deleted_time, deleted_by, score
 `
 
 type CreateCommentParams struct {
-	Body     string      `json:"body"`
-	Creator  string      `json:"creator"`
-	ThreadID pgtype.UUID `json:"thread_id"`
+	Body            string      `json:"body"`
+	BodyHtml        string      `json:"body_html"`
+	BodyHtmlVersion int32       `json:"body_html_version"`
+	Creator         string      `json:"creator"`
+	ThreadID        pgtype.UUID `json:"thread_id"`
 }
 
 // Creates a new comment with the given body, creator, and thread_id. Returns the details of the created comment.
+// No comment is created if the thread does not exist or has been deleted.
 func (q *Queries) CreateComment(ctx context.Context, arg CreateCommentParams) (Comment, error) {
-	row := q.db.QueryRow(ctx, createComment, arg.Body, arg.Creator, arg.ThreadID)
+	row := q.db.QueryRow(ctx, createComment,
+		arg.Body,
+		arg.BodyHtml,
+		arg.BodyHtmlVersion,
+		arg.Creator,
+		arg.ThreadID,
+	)
 	var i Comment
 	err := row.Scan(
 		&i.ID,
 		&i.Body,
+		&i.BodyHtml,
+		&i.BodyHtmlVersion,
 		&i.Creator,
 		&i.ThreadID,
 		&i.CreatedTime,
 		&i.UpdatedTime,
+		&i.NumRevisions,
+		&i.DeletedTime,
+		&i.DeletedBy,
+		&i.Score,
+	)
+	return i, err
+}
+
+const createCommentNotifications = `-- name: CreateCommentNotifications :execrows
+INSERT INTO notifications (username, type, thread_id, comment_id, actor)
+SELECT ts.username, 'comment', c.thread_id, c.id, c.creator
+FROM comments c
+JOIN thread_subscriptions ts ON ts.thread_id = c.thread_id
+WHERE c.id = $1
+AND c.deleted_time IS NULL
+AND NOT ts.is_muted
+AND ts.username <> c.creator
+AND NOT EXISTS (
+    SELECT 1 FROM comment_mentions cm
+    WHERE cm.comment_id = c.id
+    AND cm.username = ts.username
+)
+`
+
+// Notifies the users subscribed to the thread of a new comment, except for the creator of the comment and users
+// mentioned in it.
+// Returns the number of users notified.
+// Users mentioned in the comment are notified of the mention instead
+func (q *Queries) CreateCommentNotifications(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, createCommentNotifications, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createCommentRevision = `-- name: CreateCommentRevision :execrows
+INSERT INTO comment_revisions (comment_id, revision_number, body, editor)
+SELECT c.id, c.num_revisions + 1, c.body, $1::text
+FROM comments c
+WHERE c.id = $2
+AND c.body <> $3::text
+`
+
+type CreateCommentRevisionParams struct {
+	Editor string      `json:"editor"`
+	ID     pgtype.UUID `json:"id"`
+	Body   string      `json:"body"`
+}
+
+// Saves the current body of a comment as a revision before it is edited.
+// Nothing is saved if the new body is the same as the current one.
+func (q *Queries) CreateCommentRevision(ctx context.Context, arg CreateCommentRevisionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createCommentRevision, arg.Editor, arg.ID, arg.Body)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createEmailVerification = `-- name: CreateEmailVerification :exec
+INSERT INTO email_verifications (token_hash, username, expires_time)
+VALUES ($1, $2, NOW() + INTERVAL '24 hours')
+`
+
+type CreateEmailVerificationParams struct {
+	TokenHash string `json:"token_hash"`
+	Username  string `json:"username"`
+}
+
+// Creates a new email verification token for a user, valid for 24 hours.
+func (q *Queries) CreateEmailVerification(ctx context.Context, arg CreateEmailVerificationParams) error {
+	_, err := q.db.Exec(ctx, createEmailVerification, arg.TokenHash, arg.Username)
+	return err
+}
+
+const createInviteCode = `-- name: CreateInviteCode :one
+INSERT INTO invite_codes (code, creator, max_uses, expires_time)
+VALUES ($1, $2, $3, $4)
+RETURNING code, creator, max_uses, num_uses, expires_time, created_time
+`
+
+type CreateInviteCodeParams struct {
+	Code        string             `json:"code"`
+	Creator     string             `json:"creator"`
+	MaxUses     int32              `json:"max_uses"`
+	ExpiresTime pgtype.Timestamptz `json:"expires_time"`
+}
+
+// Creates a new invite code. Returns the details of the created invite code.
+func (q *Queries) CreateInviteCode(ctx context.Context, arg CreateInviteCodeParams) (InviteCode, error) {
+	row := q.db.QueryRow(ctx, createInviteCode,
+		arg.Code,
+		arg.Creator,
+		arg.MaxUses,
+		arg.ExpiresTime,
+	)
+	var i InviteCode
+	err := row.Scan(
+		&i.Code,
+		&i.Creator,
+		&i.MaxUses,
+		&i.NumUses,
+		&i.ExpiresTime,
+		&i.CreatedTime,
+	)
+	return i, err
+}
+
+const createScheduledThread = `-- name: CreateScheduledThread :one
+INSERT INTO scheduled_threads (title, body, creator, tags, poll, attachment_ids, category_id, publish_time)
+VALUES ($1::text, $2::text, $3::text, $4::text[], $5::jsonb, $6::uuid[],
+    $7::uuid, $8::timestamptz)
+RETURNING id, title, body, creator, tags, poll, attachment_ids, category_id, publish_time, created_time
+`
+
+type CreateScheduledThreadParams struct {
+	Title         string             `json:"title"`
+	Body          string             `json:"body"`
+	Creator       string             `json:"creator"`
+	Tagarray      []string           `json:"tagarray"`
+	Poll          []byte             `json:"poll"`
+	AttachmentIds []pgtype.UUID      `json:"attachment_ids"`
+	CategoryID    pgtype.UUID        `json:"category_id"`
+	PublishTime   pgtype.Timestamptz `json:"publish_time"`
+}
+
+// Schedules a thread to be published at the given time. Returns the scheduled thread.
+func (q *Queries) CreateScheduledThread(ctx context.Context, arg CreateScheduledThreadParams) (ScheduledThread, error) {
+	row := q.db.QueryRow(ctx, createScheduledThread,
+		arg.Title,
+		arg.Body,
+		arg.Creator,
+		arg.Tagarray,
+		arg.Poll,
+		arg.AttachmentIds,
+		arg.CategoryID,
+		arg.PublishTime,
+	)
+	var i ScheduledThread
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Body,
+		&i.Creator,
+		&i.Tags,
+		&i.Poll,
+		&i.AttachmentIds,
+		&i.CategoryID,
+		&i.PublishTime,
+		&i.CreatedTime,
 	)
 	return i, err
 }
 
 const createThread = `-- name: CreateThread :one
-INSERT INTO threads (title, body, creator)
-VALUES ($1, $2, $3)
-RETURNING id, title, body, creator, created_time, updated_time, num_comments
+INSERT INTO threads (title, body, creator, body_html, body_html_version, body_simhash, category_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, title, body, creator, created_time, updated_time, num_comments, num_revisions, deleted_time, deleted_by,
+    is_locked, lock_reason, locked_time, locked_by, score, view_count, category_id
 `
 
 type CreateThreadParams struct {
-	Title   string `json:"title"`
-	Body    string `json:"body"`
-	Creator string `json:"creator"`
+	Title           string      `json:"title"`
+	Body            string      `json:"body"`
+	Creator         string      `json:"creator"`
+	BodyHtml        string      `json:"body_html"`
+	BodyHtmlVersion int32       `json:"body_html_version"`
+	BodySimhash     pgtype.Int8 `json:"body_simhash"`
+	CategoryID      pgtype.UUID `json:"category_id"`
+}
+
+type CreateThreadRow struct {
+	ID           pgtype.UUID        `json:"id"`
+	Title        string             `json:"title"`
+	Body         string             `json:"body"`
+	Creator      string             `json:"creator"`
+	CreatedTime  pgtype.Timestamptz `json:"created_time"`
+	UpdatedTime  pgtype.Timestamptz `json:"updated_time"`
+	NumComments  int32              `json:"num_comments"`
+	NumRevisions int32              `json:"num_revisions"`
+	DeletedTime  pgtype.Timestamptz `json:"deleted_time"`
+	DeletedBy    pgtype.Text        `json:"deleted_by"`
+	IsLocked     bool               `json:"is_locked"`
+	LockReason   pgtype.Text        `json:"lock_reason"`
+	LockedTime   pgtype.Timestamptz `json:"locked_time"`
+	LockedBy     pgtype.Text        `json:"locked_by"`
+	Score        int32              `json:"score"`
+	ViewCount    int32              `json:"view_count"`
+	CategoryID   pgtype.UUID        `json:"category_id"`
 }
 
-// Creates a new thread with the given title, body, and creator. Returns the details of the created thread.
-func (q *Queries) CreateThread(ctx context.Context, arg CreateThreadParams) (Thread, error) {
-	row := q.db.QueryRow(ctx, createThread, arg.Title, arg.Body, arg.Creator)
-	var i Thread
+// Creates a new thread with the given title, body, creator and category. Returns the details of the created thread.
+func (q *Queries) CreateThread(ctx context.Context, arg CreateThreadParams) (CreateThreadRow, error) {
+	row := q.db.QueryRow(ctx, createThread,
+		arg.Title,
+		arg.Body,
+		arg.Creator,
+		arg.BodyHtml,
+		arg.BodyHtmlVersion,
+		arg.BodySimhash,
+		arg.CategoryID,
+	)
+	var i CreateThreadRow
 	err := row.Scan(
 		&i.ID,
 		&i.Title,
@@ -148,30 +745,139 @@ func (q *Queries) CreateThread(ctx context.Context, arg CreateThreadParams) (Thr
 		&i.CreatedTime,
 		&i.UpdatedTime,
 		&i.NumComments,
+		&i.NumRevisions,
+		&i.DeletedTime,
+		&i.DeletedBy,
+		&i.IsLocked,
+		&i.LockReason,
+		&i.LockedTime,
+		&i.LockedBy,
+		&i.Score,
+		&i.ViewCount,
+		&i.CategoryID,
 	)
 	return i, err
 }
 
+const createThreadRedirect = `-- name: CreateThreadRedirect :exec
+WITH updated_redirects AS (
+    UPDATE thread_redirects
+    SET target_thread_id = $2::uuid
+    WHERE target_thread_id = $1::uuid
+)
+INSERT INTO thread_redirects (thread_id, target_thread_id, title, created_by)
+VALUES ($1::uuid, $2::uuid, $3::text, $4::text)
+`
+
+type CreateThreadRedirectParams struct {
+	ThreadID       pgtype.UUID `json:"thread_id"`
+	TargetThreadID pgtype.UUID `json:"target_thread_id"`
+	Title          string      `json:"title"`
+	CreatedBy      string      `json:"created_by"`
+}
+
+// Leaves a redirect from a thread that is merged into another thread, and updates redirects to the merged thread to
+// point to the thread it is merged into.
+func (q *Queries) CreateThreadRedirect(ctx context.Context, arg CreateThreadRedirectParams) error {
+	_, err := q.db.Exec(ctx, createThreadRedirect,
+		arg.ThreadID,
+		arg.TargetThreadID,
+		arg.Title,
+		arg.CreatedBy,
+	)
+	return err
+}
+
+const createThreadRevision = `-- name: CreateThreadRevision :execrows
+INSERT INTO thread_revisions (thread_id, revision_number, title, body, tags, editor)
+SELECT t.id, t.num_revisions + 1, t.title, t.body,
+    ARRAY(SELECT tt.tag_name FROM thread_tags tt WHERE tt.thread_id = t.id ORDER BY tt.tag_name),
+    $1::text
+FROM threads t
+WHERE t.id = $2
+AND (
+    t.title <> $3::text
+    OR t.body <> $4::text
+    OR ARRAY(SELECT tt.tag_name FROM thread_tags tt WHERE tt.thread_id = t.id ORDER BY tt.tag_name)
+        <> ARRAY(SELECT DISTINCT new_tag FROM UNNEST($5::text[]) AS new_tag ORDER BY new_tag)
+)
+`
+
+type CreateThreadRevisionParams struct {
+	Editor   string      `json:"editor"`
+	ID       pgtype.UUID `json:"id"`
+	Title    string      `json:"title"`
+	Body     string      `json:"body"`
+	Tagarray []string    `json:"tagarray"`
+}
+
+// Saves the current title, body and tags of a thread as a revision before it is edited.
+// Nothing is saved if the new title, body and tags are the same as the current ones.
+func (q *Queries) CreateThreadRevision(ctx context.Context, arg CreateThreadRevisionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createThreadRevision,
+		arg.Editor,
+		arg.ID,
+		arg.Title,
+		arg.Body,
+		arg.Tagarray,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const createUser = `-- name: CreateUser :exec
-INSERT INTO users (username, password)
-VALUES ($1, $2)
+INSERT INTO users (username, canonical_username, username_skeleton, password, email, is_verified)
+VALUES ($1, $2, $3, $4, $5, $6)
 `
 
 type CreateUserParams struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username          string      `json:"username"`
+	CanonicalUsername string      `json:"canonical_username"`
+	UsernameSkeleton  string      `json:"username_skeleton"`
+	Password          string      `json:"password"`
+	Email             pgtype.Text `json:"email"`
+	IsVerified        bool        `json:"is_verified"`
 }
 
-// Creates a new user with the given username and password.
+// Creates a new user with the given username, password and email.
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) error {
-	_, err := q.db.Exec(ctx, createUser, arg.Username, arg.Password)
+	_, err := q.db.Exec(ctx, createUser,
+		arg.Username,
+		arg.CanonicalUsername,
+		arg.UsernameSkeleton,
+		arg.Password,
+		arg.Email,
+		arg.IsVerified,
+	)
 	return err
 }
 
+const deleteCategory = `-- name: DeleteCategory :execrows
+DELETE FROM categories c
+WHERE c.id = $1
+AND NOT EXISTS (SELECT 1 FROM categories sc WHERE sc.parent_id = c.id)
+AND NOT EXISTS (SELECT 1 FROM threads t WHERE t.category_id = c.id)
+AND NOT EXISTS (SELECT 1 FROM scheduled_threads st WHERE st.category_id = c.id)
+`
+
+// Deletes the category with the given id, unless it has subcategories or threads, including deleted and scheduled
+// threads.
+func (q *Queries) DeleteCategory(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteCategory, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteComment = `-- name: DeleteComment :exec
-DELETE FROM comments
+UPDATE comments
+SET deleted_time = NOW(), deleted_by = creator
 WHERE id = $1
 AND creator = $2
+AND deleted_time IS NULL
 `
 
 type DeleteCommentParams struct {
@@ -179,194 +885,354 @@ type DeleteCommentParams struct {
 	Creator string      `json:"creator"`
 }
 
-// Deletes the comment with the given id.
+// Soft deletes the comment with the given id. The comment is purged once it has been deleted for longer than the
+// retention period.
 func (q *Queries) DeleteComment(ctx context.Context, arg DeleteCommentParams) error {
 	_, err := q.db.Exec(ctx, deleteComment, arg.ID, arg.Creator)
 	return err
 }
 
-const deleteThread = `-- name: DeleteThread :exec
-DELETE FROM threads
-WHERE id = $1
-AND creator = $2
+const deleteCommentBookmark = `-- name: DeleteCommentBookmark :execrows
+DELETE FROM bookmarks
+WHERE comment_id = $1
+AND username = $2::text
 `
 
-type DeleteThreadParams struct {
-	ID      pgtype.UUID `json:"id"`
-	Creator string      `json:"creator"`
+type DeleteCommentBookmarkParams struct {
+	CommentID pgtype.UUID `json:"comment_id"`
+	Username  string      `json:"username"`
 }
 
-// Deletes the thread with the given id.
-func (q *Queries) DeleteThread(ctx context.Context, arg DeleteThreadParams) error {
-	_, err := q.db.Exec(ctx, deleteThread, arg.ID, arg.Creator)
-	return err
+// Removes the bookmark of a user on a comment.
+func (q *Queries) DeleteCommentBookmark(ctx context.Context, arg DeleteCommentBookmarkParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteCommentBookmark, arg.CommentID, arg.Username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const deleteThreadTags = `-- name: DeleteThreadTags :exec
-DELETE FROM thread_tags
-WHERE thread_id = $1
+const deleteCommentVote = `-- name: DeleteCommentVote :exec
+DELETE FROM comment_votes
+WHERE comment_id = $1
+AND username = $2
 `
 
-// Deletes all tags of the thread with the given id.
-func (q *Queries) DeleteThreadTags(ctx context.Context, threadID pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, deleteThreadTags, threadID)
+type DeleteCommentVoteParams struct {
+	CommentID pgtype.UUID `json:"comment_id"`
+	Username  string      `json:"username"`
+}
+
+// Removes the vote of a user on a comment.
+func (q *Queries) DeleteCommentVote(ctx context.Context, arg DeleteCommentVoteParams) error {
+	_, err := q.db.Exec(ctx, deleteCommentVote, arg.CommentID, arg.Username)
 	return err
 }
 
-const deleteUnusedTags = `-- name: DeleteUnusedTags :exec
-DELETE FROM tags
-WHERE name NOT IN (
-    SELECT DISTINCT tag_name
-    FROM thread_tags
-)
+const deleteDraft = `-- name: DeleteDraft :execrows
+DELETE FROM drafts
+WHERE username = $1::text
+AND context = $2::text
 `
 
-// Deletes tags that are not associated with any threads.
-func (q *Queries) DeleteUnusedTags(ctx context.Context) error {
-	_, err := q.db.Exec(ctx, deleteUnusedTags)
+type DeleteDraftParams struct {
+	Username string `json:"username"`
+	Context  string `json:"context"`
+}
+
+// Deletes the draft of a user for the given context.
+func (q *Queries) DeleteDraft(ctx context.Context, arg DeleteDraftParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteDraft, arg.Username, arg.Context)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteEmailVerifications = `-- name: DeleteEmailVerifications :exec
+DELETE FROM email_verifications
+WHERE username = $1
+`
+
+// Deletes all email verification tokens of a user.
+func (q *Queries) DeleteEmailVerifications(ctx context.Context, username string) error {
+	_, err := q.db.Exec(ctx, deleteEmailVerifications, username)
 	return err
 }
 
-const getCommentCount = `-- name: GetCommentCount :one
-SELECT COUNT(*) AS total_items
-FROM comments
+const deleteExpiredDrafts = `-- name: DeleteExpiredDrafts :execrows
+DELETE FROM drafts
+WHERE updated_time < NOW() - MAKE_INTERVAL(days => $1::integer)
+`
+
+// Deletes drafts that have not been updated for longer than the expiry period. Returns the number deleted.
+func (q *Queries) DeleteExpiredDrafts(ctx context.Context, expiryDays int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredDrafts, expiryDays)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExpiredPins = `-- name: DeleteExpiredPins :execrows
+DELETE FROM thread_pins
+WHERE expires_time <= NOW()
+`
+
+// Deletes pins that have expired.
+func (q *Queries) DeleteExpiredPins(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredPins)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExpiredThreadViews = `-- name: DeleteExpiredThreadViews :execrows
+DELETE FROM thread_views
+WHERE viewed_time <= NOW() - MAKE_INTERVAL(mins => $1::integer)
+`
+
+// Deletes recorded views that are older than the time window, as they no longer affect which views are counted.
+func (q *Queries) DeleteExpiredThreadViews(ctx context.Context, windowMinutes int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredThreadViews, windowMinutes)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteInviteCode = `-- name: DeleteInviteCode :execrows
+DELETE FROM invite_codes
+WHERE code = $1
+`
+
+// Deletes the invite code with the given code.
+func (q *Queries) DeleteInviteCode(ctx context.Context, code string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteInviteCode, code)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteMergedThread = `-- name: DeleteMergedThread :exec
+DELETE FROM threads
+WHERE id = $1
+`
+
+// Permanently deletes a thread that has been merged into another thread.
+func (q *Queries) DeleteMergedThread(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteMergedThread, id)
+	return err
+}
+
+const deleteMovedComment = `-- name: DeleteMovedComment :exec
+DELETE FROM comments
+WHERE id = $1
+`
+
+// Permanently deletes a comment that has been turned into a thread.
+func (q *Queries) DeleteMovedComment(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteMovedComment, id)
+	return err
+}
+
+const deleteOldNotifications = `-- name: DeleteOldNotifications :execrows
+DELETE FROM notifications
+WHERE is_read
+AND created_time < NOW() - MAKE_INTERVAL(days => $1::integer)
+`
+
+// Permanently deletes read notifications that are older than the retention period. Returns the number deleted.
+func (q *Queries) DeleteOldNotifications(ctx context.Context, retentionDays int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteOldNotifications, retentionDays)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteOrphanedAttachments = `-- name: DeleteOrphanedAttachments :execrows
+DELETE FROM attachments a
+WHERE a.thread_id IS NULL
+AND a.comment_id IS NULL
+AND a.created_time < NOW() - MAKE_INTERVAL(hours => $1::integer)
+AND NOT EXISTS (SELECT 1 FROM scheduled_threads st WHERE a.id = ANY(st.attachment_ids))
+`
+
+// Deletes attachments that were not attached to a thread, a comment or a scheduled thread within the time limit.
+// Returns the number deleted.
+func (q *Queries) DeleteOrphanedAttachments(ctx context.Context, orphanHours int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteOrphanedAttachments, orphanHours)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deletePollOptions = `-- name: DeletePollOptions :exec
+DELETE FROM poll_options
 WHERE thread_id = $1
 `
 
-// Counts the total number of comments for a thread.
-func (q *Queries) GetCommentCount(ctx context.Context, threadID pgtype.UUID) (int64, error) {
-	row := q.db.QueryRow(ctx, getCommentCount, threadID)
-	var total_items int64
-	err := row.Scan(&total_items)
-	return total_items, err
+// Removes the options of a poll. Fails if any of the options have been voted for.
+func (q *Queries) DeletePollOptions(ctx context.Context, threadID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deletePollOptions, threadID)
+	return err
 }
 
-const getComments = `-- name: GetComments :many
-SELECT id, body, creator, thread_id, created_time, updated_time
-FROM comments
+const deletePollVotes = `-- name: DeletePollVotes :execrows
+DELETE FROM poll_votes
 WHERE thread_id = $1
-ORDER BY
-    CASE WHEN $4::text = 'created_time_asc' THEN created_time END ASC,
-    CASE WHEN $4::text = 'created_time_desc' THEN created_time END DESC
-LIMIT $2
-OFFSET $3
+AND username = $2
 `
 
-type GetCommentsParams struct {
-	ThreadID  pgtype.UUID `json:"thread_id"`
-	Limit     int32       `json:"limit"`
-	Offset    int32       `json:"offset"`
-	Sortorder string      `json:"sortorder"`
+type DeletePollVotesParams struct {
+	ThreadID pgtype.UUID `json:"thread_id"`
+	Username string      `json:"username"`
 }
 
-// Get comments for a thread.
-// Sort order should be one of 'created_time_asc', 'created_time_desc'.
-func (q *Queries) GetComments(ctx context.Context, arg GetCommentsParams) ([]Comment, error) {
-	rows, err := q.db.Query(ctx, getComments,
-		arg.ThreadID,
-		arg.Limit,
-		arg.Offset,
-		arg.Sortorder,
-	)
+// Removes the votes of a user on a poll.
+func (q *Queries) DeletePollVotes(ctx context.Context, arg DeletePollVotesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deletePollVotes, arg.ThreadID, arg.Username)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	items := []Comment{}
-	for rows.Next() {
-		var i Comment
-		if err := rows.Scan(
-			&i.ID,
-			&i.Body,
-			&i.Creator,
-			&i.ThreadID,
-			&i.CreatedTime,
-			&i.UpdatedTime,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
+		return 0, err
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+	return result.RowsAffected(), nil
+}
+
+const deleteScheduledThread = `-- name: DeleteScheduledThread :execrows
+DELETE FROM scheduled_threads
+WHERE id = $1
+AND creator = $2::text
+`
+
+type DeleteScheduledThreadParams struct {
+	ID      pgtype.UUID `json:"id"`
+	Creator string      `json:"creator"`
+}
+
+// Cancels a thread scheduled by a user.
+func (q *Queries) DeleteScheduledThread(ctx context.Context, arg DeleteScheduledThreadParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteScheduledThread, arg.ID, arg.Creator)
+	if err != nil {
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected(), nil
 }
 
-const getPasswordHash = `-- name: GetPasswordHash :one
-SELECT username, password
-FROM users
-WHERE LOWER(username) = LOWER($1)
+const deleteThread = `-- name: DeleteThread :exec
+UPDATE threads
+SET deleted_time = NOW(), deleted_by = creator
+WHERE id = $1
+AND creator = $2
+AND deleted_time IS NULL
 `
 
-// Returns a username and their password hash.
-func (q *Queries) GetPasswordHash(ctx context.Context, lower string) (User, error) {
-	row := q.db.QueryRow(ctx, getPasswordHash, lower)
-	var i User
-	err := row.Scan(&i.Username, &i.Password)
-	return i, err
+type DeleteThreadParams struct {
+	ID      pgtype.UUID `json:"id"`
+	Creator string      `json:"creator"`
 }
 
-const getThreadDetails = `-- name: GetThreadDetails :one
-SELECT t.id, t.title, t.body, t.creator, t.created_time, t.updated_time, t.num_comments,
-    CASE
-    WHEN COUNT(tt.tag_name) > 0 THEN ARRAY_AGG(tt.tag_name ORDER BY tt.tag_name)
-        ELSE '{}'::text[]
-    END AS tags
-FROM threads t
-LEFT JOIN thread_tags tt ON t.id = tt.thread_id
-WHERE t.id = $1
-GROUP BY t.id
+// Soft deletes the thread with the given id. The thread is purged once it has been deleted for longer than the
+// retention period.
+func (q *Queries) DeleteThread(ctx context.Context, arg DeleteThreadParams) error {
+	_, err := q.db.Exec(ctx, deleteThread, arg.ID, arg.Creator)
+	return err
+}
+
+const deleteThreadBookmark = `-- name: DeleteThreadBookmark :execrows
+DELETE FROM bookmarks
+WHERE thread_id = $1
+AND username = $2::text
 `
 
-type GetThreadDetailsRow struct {
-	ID          pgtype.UUID        `json:"id"`
-	Title       string             `json:"title"`
-	Body        string             `json:"body"`
-	Creator     string             `json:"creator"`
-	CreatedTime pgtype.Timestamptz `json:"created_time"`
-	UpdatedTime pgtype.Timestamptz `json:"updated_time"`
-	NumComments int32              `json:"num_comments"`
-	Tags        []string           `json:"tags"`
+type DeleteThreadBookmarkParams struct {
+	ThreadID pgtype.UUID `json:"thread_id"`
+	Username string      `json:"username"`
 }
 
-// Returns the details of the thread with the given id, as well as the tags of the thread as an array.
-func (q *Queries) GetThreadDetails(ctx context.Context, id pgtype.UUID) (GetThreadDetailsRow, error) {
-	row := q.db.QueryRow(ctx, getThreadDetails, id)
-	var i GetThreadDetailsRow
-	err := row.Scan(
-		&i.ID,
-		&i.Title,
-		&i.Body,
-		&i.Creator,
-		&i.CreatedTime,
-		&i.UpdatedTime,
-		&i.NumComments,
-		&i.Tags,
-	)
-	return i, err
+// Removes the bookmark of a user on a thread.
+func (q *Queries) DeleteThreadBookmark(ctx context.Context, arg DeleteThreadBookmarkParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteThreadBookmark, arg.ThreadID, arg.Username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const getThreadTags = `-- name: GetThreadTags :many
-SELECT tag_name
-FROM thread_tags
+const deleteThreadSubscription = `-- name: DeleteThreadSubscription :execrows
+DELETE FROM thread_subscriptions
 WHERE thread_id = $1
+AND username = $2::text
 `
 
-// Returns the tags of the thread with the given id.
-func (q *Queries) GetThreadTags(ctx context.Context, threadID pgtype.UUID) ([]string, error) {
-	rows, err := q.db.Query(ctx, getThreadTags, threadID)
+type DeleteThreadSubscriptionParams struct {
+	ThreadID pgtype.UUID `json:"thread_id"`
+	Username string      `json:"username"`
+}
+
+// Removes the subscription of a user to a thread, including a mute.
+// The user is subscribed again automatically if they comment on the thread.
+func (q *Queries) DeleteThreadSubscription(ctx context.Context, arg DeleteThreadSubscriptionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteThreadSubscription, arg.ThreadID, arg.Username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteThreadTags = `-- name: DeleteThreadTags :exec
+DELETE FROM thread_tags
+WHERE thread_id = $1
+`
+
+// Deletes all tags of the thread with the given id.
+func (q *Queries) DeleteThreadTags(ctx context.Context, threadID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteThreadTags, threadID)
+	return err
+}
+
+const deleteThreadVote = `-- name: DeleteThreadVote :exec
+DELETE FROM thread_votes
+WHERE thread_id = $1
+AND username = $2
+`
+
+type DeleteThreadVoteParams struct {
+	ThreadID pgtype.UUID `json:"thread_id"`
+	Username string      `json:"username"`
+}
+
+// Removes the vote of a user on a thread.
+func (q *Queries) DeleteThreadVote(ctx context.Context, arg DeleteThreadVoteParams) error {
+	_, err := q.db.Exec(ctx, deleteThreadVote, arg.ThreadID, arg.Username)
+	return err
+}
+
+const deleteUnusedBlobs = `-- name: DeleteUnusedBlobs :many
+DELETE FROM blobs b
+WHERE b.last_used_time < NOW() - MAKE_INTERVAL(hours => $1::integer)
+AND NOT EXISTS (SELECT 1 FROM attachments a WHERE a.blob_hash = b.hash OR a.thumbnail_hash = b.hash)
+RETURNING b.hash
+`
+
+// Deletes blobs that are not used by any attachment and have not been uploaded again within the grace period, so that
+// blobs of uploads in progress are kept. Returns the hashes of the deleted blobs.
+func (q *Queries) DeleteUnusedBlobs(ctx context.Context, graceHours int32) ([]string, error) {
+	rows, err := q.db.Query(ctx, deleteUnusedBlobs, graceHours)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	items := []string{}
 	for rows.Next() {
-		var tag_name string
-		if err := rows.Scan(&tag_name); err != nil {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
 			return nil, err
 		}
-		items = append(items, tag_name)
+		items = append(items, hash)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -374,52 +1240,89 @@ func (q *Queries) GetThreadTags(ctx context.Context, threadID pgtype.UUID) ([]st
 	return items, nil
 }
 
-const getThreads = `-- name: GetThreads :many
-SELECT t.id, t.title, t.body, t.creator, t.created_time, t.updated_time, t.num_comments,
+const deleteUnusedTags = `-- name: DeleteUnusedTags :exec
+DELETE FROM tags
+WHERE name NOT IN (
+    SELECT DISTINCT tag_name
+    FROM thread_tags
+)
+`
+
+// Deletes tags that are not associated with any threads.
+func (q *Queries) DeleteUnusedTags(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, deleteUnusedTags)
+	return err
+}
+
+const getAnnouncements = `-- name: GetAnnouncements :many
+SELECT t.id, t.title, t.body, t.creator, t.created_time, t.updated_time, t.num_comments, t.num_revisions,
+    t.is_locked, t.lock_reason, t.score, t.view_count, t.body_html, t.body_html_version, t.category_id,
     CASE
     WHEN COUNT(tt.tag_name) > 0 THEN ARRAY_AGG(tt.tag_name ORDER BY tt.tag_name)
         ELSE '{}'::text[]
-    END AS tags
+    END AS tags,
+    TRUE AS is_pinned,
+    TRUE AS is_announcement,
+    COALESCE((
+        SELECT tv.value FROM thread_votes tv
+        WHERE tv.thread_id = t.id
+        AND tv.username = $1::text
+    ), 0)::integer AS my_vote,
+    EXISTS (
+        SELECT 1 FROM bookmarks b
+        WHERE b.thread_id = t.id
+        AND b.username = $1::text
+    ) AS is_bookmarked,
+    EXISTS (
+        SELECT 1 FROM thread_subscriptions ts
+        WHERE ts.thread_id = t.id
+        AND ts.username = $1::text
+        AND NOT ts.is_muted
+    ) AS is_subscribed
 FROM threads t
+JOIN thread_pins tp ON t.id = tp.thread_id
 LEFT JOIN thread_tags tt ON t.id = tt.thread_id
-GROUP BY t.id
-ORDER BY
-    CASE WHEN $3::text = 'created_time_asc' THEN created_time END ASC,
-    CASE WHEN $3::text = 'created_time_desc' THEN created_time END DESC,
-    CASE WHEN $3::text = 'num_comments_asc' THEN num_comments END ASC,
-    CASE WHEN $3::text = 'num_comments_desc' THEN num_comments END DESC
-LIMIT $1
-OFFSET $2
+WHERE tp.is_announcement
+AND (tp.expires_time IS NULL OR tp.expires_time > NOW())
+AND t.deleted_time IS NULL
+GROUP BY t.id, tp.pinned_time
+ORDER BY tp.pinned_time DESC
 `
 
-type GetThreadsParams struct {
-	Limit     int32  `json:"limit"`
-	Offset    int32  `json:"offset"`
-	Sortorder string `json:"sortorder"`
-}
-
-type GetThreadsRow struct {
-	ID          pgtype.UUID        `json:"id"`
-	Title       string             `json:"title"`
-	Body        string             `json:"body"`
-	Creator     string             `json:"creator"`
-	CreatedTime pgtype.Timestamptz `json:"created_time"`
-	UpdatedTime pgtype.Timestamptz `json:"updated_time"`
-	NumComments int32              `json:"num_comments"`
-	Tags        []string           `json:"tags"`
+type GetAnnouncementsRow struct {
+	ID              pgtype.UUID        `json:"id"`
+	Title           string             `json:"title"`
+	Body            string             `json:"body"`
+	Creator         string             `json:"creator"`
+	CreatedTime     pgtype.Timestamptz `json:"created_time"`
+	UpdatedTime     pgtype.Timestamptz `json:"updated_time"`
+	NumComments     int32              `json:"num_comments"`
+	NumRevisions    int32              `json:"num_revisions"`
+	IsLocked        bool               `json:"is_locked"`
+	LockReason      pgtype.Text        `json:"lock_reason"`
+	Score           int32              `json:"score"`
+	ViewCount       int32              `json:"view_count"`
+	BodyHtml        string             `json:"body_html"`
+	BodyHtmlVersion int32              `json:"body_html_version"`
+	CategoryID      pgtype.UUID        `json:"category_id"`
+	Tags            []string           `json:"tags"`
+	IsPinned        bool               `json:"is_pinned"`
+	IsAnnouncement  bool               `json:"is_announcement"`
+	MyVote          int32              `json:"my_vote"`
+	IsBookmarked    bool               `json:"is_bookmarked"`
+	IsSubscribed    bool               `json:"is_subscribed"`
 }
 
-// Returns the details of all threads.
-// Sort order should be one of 'created_time_asc', 'created_time_desc', 'num_comments_asc', 'num_comments_desc'.
-func (q *Queries) GetThreads(ctx context.Context, arg GetThreadsParams) ([]GetThreadsRow, error) {
-	rows, err := q.db.Query(ctx, getThreads, arg.Limit, arg.Offset, arg.Sortorder)
+// Returns the active announcements, latest first.
+func (q *Queries) GetAnnouncements(ctx context.Context, viewer string) ([]GetAnnouncementsRow, error) {
+	rows, err := q.db.Query(ctx, getAnnouncements, viewer)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []GetThreadsRow{}
+	items := []GetAnnouncementsRow{}
 	for rows.Next() {
-		var i GetThreadsRow
+		var i GetAnnouncementsRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Title,
@@ -428,7 +1331,20 @@ func (q *Queries) GetThreads(ctx context.Context, arg GetThreadsParams) ([]GetTh
 			&i.CreatedTime,
 			&i.UpdatedTime,
 			&i.NumComments,
+			&i.NumRevisions,
+			&i.IsLocked,
+			&i.LockReason,
+			&i.Score,
+			&i.ViewCount,
+			&i.BodyHtml,
+			&i.BodyHtmlVersion,
+			&i.CategoryID,
 			&i.Tags,
+			&i.IsPinned,
+			&i.IsAnnouncement,
+			&i.MyVote,
+			&i.IsBookmarked,
+			&i.IsSubscribed,
 		); err != nil {
 			return nil, err
 		}
@@ -440,166 +1356,3476 @@ func (q *Queries) GetThreads(ctx context.Context, arg GetThreadsParams) ([]GetTh
 	return items, nil
 }
 
-const getThreadsByCriteria = `-- name: GetThreadsByCriteria :many
-SELECT t.id, t.title, t.body, t.creator, t.created_time, t.updated_time, t.num_comments,
-    -- Concatenate all the tags of the thread into an array.
-    CASE
-       WHEN COUNT(tt.tag_name) > 0 THEN ARRAY_AGG(tt.tag_name ORDER BY tt.tag_name)
-       ELSE '{}'::text[]
-    END AS tags
-FROM threads t
-LEFT JOIN thread_tags tt ON t.id = tt.thread_id
-WHERE
-    -- Handle the case where the keyword is empty (NULL).
-    CASE
-        WHEN LENGTH($3::text) > 0 THEN TO_TSVECTOR('simple', t.title || ' ' || t.body) @@ TO_TSQUERY
-('simple', $3::text)
-        ELSE TRUE
-    END
-AND
-    -- Handle the case where the tag array is empty.
-    CASE
-        WHEN ARRAY_LENGTH($4::text[], 1) > 0 THEN t.id IN (
-            SELECT tt.thread_id
-            FROM thread_tags tt
-            WHERE tt.tag_name = ANY($4::text[])
-            GROUP BY tt.thread_id
-            HAVING COUNT(DISTINCT tt.tag_name) = ARRAY_LENGTH($4::text[], 1)
-        )
-        ELSE TRUE
-    END
-GROUP BY t.id
-ORDER BY
-    CASE WHEN $5::text = 'created_time_asc' THEN created_time END ASC,
-    CASE WHEN $5::text = 'created_time_desc' THEN created_time END DESC,
-    CASE WHEN $5::text = 'num_comments_asc' THEN num_comments END ASC,
-    CASE WHEN $5::text = 'num_comments_desc' THEN num_comments END DESC
+const getAttachment = `-- name: GetAttachment :one
+SELECT a.id, a.filename, a.blob_hash, b.content_type, b.size, a.thumbnail_hash,
+    tb.content_type AS thumbnail_content_type, tb.size AS thumbnail_size
+FROM attachments a
+JOIN blobs b ON b.hash = a.blob_hash
+LEFT JOIN blobs tb ON tb.hash = a.thumbnail_hash
+LEFT JOIN threads t ON t.id = a.thread_id
+LEFT JOIN comments c ON c.id = a.comment_id
+WHERE a.id = $1
+AND t.deleted_time IS NULL
+AND c.deleted_time IS NULL
+`
+
+type GetAttachmentRow struct {
+	ID                   pgtype.UUID `json:"id"`
+	Filename             string      `json:"filename"`
+	BlobHash             string      `json:"blob_hash"`
+	ContentType          string      `json:"content_type"`
+	Size                 int32       `json:"size"`
+	ThumbnailHash        pgtype.Text `json:"thumbnail_hash"`
+	ThumbnailContentType pgtype.Text `json:"thumbnail_content_type"`
+	ThumbnailSize        pgtype.Int4 `json:"thumbnail_size"`
+}
+
+// Gets an attachment with the hashes of its files. Attachments of deleted threads and comments are not returned.
+func (q *Queries) GetAttachment(ctx context.Context, id pgtype.UUID) (GetAttachmentRow, error) {
+	row := q.db.QueryRow(ctx, getAttachment, id)
+	var i GetAttachmentRow
+	err := row.Scan(
+		&i.ID,
+		&i.Filename,
+		&i.BlobHash,
+		&i.ContentType,
+		&i.Size,
+		&i.ThumbnailHash,
+		&i.ThumbnailContentType,
+		&i.ThumbnailSize,
+	)
+	return i, err
+}
+
+const getAttachments = `-- name: GetAttachments :many
+SELECT a.id, a.filename, b.content_type, b.size, a.width, a.height,
+    (a.thumbnail_hash IS NOT NULL)::boolean AS has_thumbnail, a.thread_id, a.comment_id, a.created_time
+FROM attachments a
+JOIN blobs b ON b.hash = a.blob_hash
+WHERE a.thread_id = $1
+OR a.comment_id = ANY($2::uuid[])
+ORDER BY a.created_time, a.id
+`
+
+type GetAttachmentsParams struct {
+	ThreadID   pgtype.UUID   `json:"thread_id"`
+	CommentIds []pgtype.UUID `json:"comment_ids"`
+}
+
+type GetAttachmentsRow struct {
+	ID           pgtype.UUID        `json:"id"`
+	Filename     string             `json:"filename"`
+	ContentType  string             `json:"content_type"`
+	Size         int32              `json:"size"`
+	Width        pgtype.Int4        `json:"width"`
+	Height       pgtype.Int4        `json:"height"`
+	HasThumbnail bool               `json:"has_thumbnail"`
+	ThreadID     pgtype.UUID        `json:"thread_id"`
+	CommentID    pgtype.UUID        `json:"comment_id"`
+	CreatedTime  pgtype.Timestamptz `json:"created_time"`
+}
+
+// Get the attachments of a thread and of the given comments, in the order they were uploaded.
+func (q *Queries) GetAttachments(ctx context.Context, arg GetAttachmentsParams) ([]GetAttachmentsRow, error) {
+	rows, err := q.db.Query(ctx, getAttachments, arg.ThreadID, arg.CommentIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetAttachmentsRow{}
+	for rows.Next() {
+		var i GetAttachmentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Filename,
+			&i.ContentType,
+			&i.Size,
+			&i.Width,
+			&i.Height,
+			&i.HasThumbnail,
+			&i.ThreadID,
+			&i.CommentID,
+			&i.CreatedTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBookmarkCount = `-- name: GetBookmarkCount :one
+SELECT COUNT(*) AS total_items
+FROM bookmarks b
+LEFT JOIN comments c ON b.comment_id = c.id
+JOIN threads t ON t.id = COALESCE(b.thread_id, c.thread_id)
+WHERE b.username = $1::text
+AND (LENGTH($2::text) = 0 OR b.folder = $2::text)
+AND t.deleted_time IS NULL
+AND c.deleted_time IS NULL
+`
+
+type GetBookmarkCountParams struct {
+	Username string `json:"username"`
+	Folder   string `json:"folder"`
+}
+
+// Counts the bookmarks of a user that would be returned by GetBookmarks.
+func (q *Queries) GetBookmarkCount(ctx context.Context, arg GetBookmarkCountParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getBookmarkCount, arg.Username, arg.Folder)
+	var total_items int64
+	err := row.Scan(&total_items)
+	return total_items, err
+}
+
+const getBookmarkFolders = `-- name: GetBookmarkFolders :many
+SELECT DISTINCT folder
+FROM bookmarks
+WHERE username = $1
+AND folder <> ''
+ORDER BY folder
+`
+
+// Get the names of the folders that a user has bookmarks in.
+func (q *Queries) GetBookmarkFolders(ctx context.Context, username string) ([]string, error) {
+	rows, err := q.db.Query(ctx, getBookmarkFolders, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var folder string
+		if err := rows.Scan(&folder); err != nil {
+			return nil, err
+		}
+		items = append(items, folder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBookmarks = `-- name: GetBookmarks :many
+SELECT b.id, b.folder, b.note, b.created_time,
+    t.id AS thread_id, t.title AS thread_title,
+    c.id AS comment_id, c.body AS comment_body,
+    COALESCE(c.creator, t.creator)::text AS creator
+FROM bookmarks b
+LEFT JOIN comments c ON b.comment_id = c.id
+JOIN threads t ON t.id = COALESCE(b.thread_id, c.thread_id)
+WHERE b.username = $3::text
+AND (LENGTH($4::text) = 0 OR b.folder = $4::text)
+AND t.deleted_time IS NULL
+AND c.deleted_time IS NULL
+ORDER BY b.created_time DESC
 LIMIT $1
 OFFSET $2
 `
 
-type GetThreadsByCriteriaParams struct {
-	Limit     int32    `json:"limit"`
-	Offset    int32    `json:"offset"`
-	Keywords  string   `json:"keywords"`
-	Tagarray  []string `json:"tagarray"`
-	Sortorder string   `json:"sortorder"`
+type GetBookmarksParams struct {
+	Limit    int32  `json:"limit"`
+	Offset   int32  `json:"offset"`
+	Username string `json:"username"`
+	Folder   string `json:"folder"`
+}
+
+type GetBookmarksRow struct {
+	ID          pgtype.UUID        `json:"id"`
+	Folder      string             `json:"folder"`
+	Note        string             `json:"note"`
+	CreatedTime pgtype.Timestamptz `json:"created_time"`
+	ThreadID    pgtype.UUID        `json:"thread_id"`
+	ThreadTitle string             `json:"thread_title"`
+	CommentID   pgtype.UUID        `json:"comment_id"`
+	CommentBody pgtype.Text        `json:"comment_body"`
+	Creator     string             `json:"creator"`
+}
+
+// Get the bookmarks of a user, latest first, together with the thread or comment they point to.
+// If a folder is provided, only bookmarks in that folder are returned.
+// Bookmarks of deleted threads and comments are not returned, but are kept in case they are restored.
+func (q *Queries) GetBookmarks(ctx context.Context, arg GetBookmarksParams) ([]GetBookmarksRow, error) {
+	rows, err := q.db.Query(ctx, getBookmarks,
+		arg.Limit,
+		arg.Offset,
+		arg.Username,
+		arg.Folder,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetBookmarksRow{}
+	for rows.Next() {
+		var i GetBookmarksRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Folder,
+			&i.Note,
+			&i.CreatedTime,
+			&i.ThreadID,
+			&i.ThreadTitle,
+			&i.CommentID,
+			&i.CommentBody,
+			&i.Creator,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCategories = `-- name: GetCategories :many
+SELECT id, name, slug, description, parent_id, position, posting_role, num_threads, created_time
+FROM categories
+ORDER BY position, name
+`
+
+// Returns all categories, ordered by their position and then by name.
+func (q *Queries) GetCategories(ctx context.Context) ([]Category, error) {
+	rows, err := q.db.Query(ctx, getCategories)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Category{}
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Slug,
+			&i.Description,
+			&i.ParentID,
+			&i.Position,
+			&i.PostingRole,
+			&i.NumThreads,
+			&i.CreatedTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCategory = `-- name: GetCategory :one
+SELECT id, name, slug, description, parent_id, position, posting_role, num_threads, created_time
+FROM categories
+WHERE id = $1
+`
+
+// Returns the category with the given id.
+func (q *Queries) GetCategory(ctx context.Context, id pgtype.UUID) (Category, error) {
+	row := q.db.QueryRow(ctx, getCategory, id)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Slug,
+		&i.Description,
+		&i.ParentID,
+		&i.Position,
+		&i.PostingRole,
+		&i.NumThreads,
+		&i.CreatedTime,
+	)
+	return i, err
+}
+
+const getComment = `-- name: GetComment :one
+SELECT c.id, c.body, c.body_html, c.body_html_version, c.creator, c.thread_id, c.created_time, c.updated_time,
+    c.num_revisions, c.deleted_time, c.deleted_by, c.score
+FROM comments c
+JOIN threads t ON c.thread_id = t.id
+WHERE c.id = $1
+AND c.deleted_time IS NULL
+AND t.deleted_time IS NULL
+`
+
+// Returns the comment with the given id. Deleted comments and comments of deleted threads are not returned.
+func (q *Queries) GetComment(ctx context.Context, id pgtype.UUID) (Comment, error) {
+	row := q.db.QueryRow(ctx, getComment, id)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.Body,
+		&i.BodyHtml,
+		&i.BodyHtmlVersion,
+		&i.Creator,
+		&i.ThreadID,
+		&i.CreatedTime,
+		&i.UpdatedTime,
+		&i.NumRevisions,
+		&i.DeletedTime,
+		&i.DeletedBy,
+		&i.Score,
+	)
+	return i, err
+}
+
+const getCommentCount = `-- name: GetCommentCount :one
+SELECT COUNT(*) AS total_items
+FROM comments c
+JOIN threads t ON c.thread_id = t.id
+WHERE c.thread_id = $1
+AND c.deleted_time IS NULL
+AND t.deleted_time IS NULL
+`
+
+// Counts the total number of comments for a thread that have not been deleted.
+func (q *Queries) GetCommentCount(ctx context.Context, threadID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, getCommentCount, threadID)
+	var total_items int64
+	err := row.Scan(&total_items)
+	return total_items, err
+}
+
+const getCommentCountByCreator = `-- name: GetCommentCountByCreator :one
+SELECT COUNT(*) AS total_items
+FROM comments c
+JOIN threads t ON c.thread_id = t.id
+WHERE c.creator = (SELECT u.username FROM users u WHERE u.canonical_username = $1)
+AND c.deleted_time IS NULL
+AND t.deleted_time IS NULL
+`
+
+// Counts the total number of comments created by a user that have not been deleted.
+func (q *Queries) GetCommentCountByCreator(ctx context.Context, canonicalUsername string) (int64, error) {
+	row := q.db.QueryRow(ctx, getCommentCountByCreator, canonicalUsername)
+	var total_items int64
+	err := row.Scan(&total_items)
+	return total_items, err
+}
+
+const getCommentRevision = `-- name: GetCommentRevision :one
+SELECT comment_id, revision_number, body, editor, edited_time
+FROM comment_revisions
+WHERE comment_id = $1
+AND revision_number = $2
+`
+
+type GetCommentRevisionParams struct {
+	CommentID      pgtype.UUID `json:"comment_id"`
+	RevisionNumber int32       `json:"revision_number"`
+}
+
+// Returns a single revision of the comment with the given id.
+func (q *Queries) GetCommentRevision(ctx context.Context, arg GetCommentRevisionParams) (CommentRevision, error) {
+	row := q.db.QueryRow(ctx, getCommentRevision, arg.CommentID, arg.RevisionNumber)
+	var i CommentRevision
+	err := row.Scan(
+		&i.CommentID,
+		&i.RevisionNumber,
+		&i.Body,
+		&i.Editor,
+		&i.EditedTime,
+	)
+	return i, err
+}
+
+const getCommentRevisions = `-- name: GetCommentRevisions :many
+SELECT comment_id, revision_number, body, editor, edited_time
+FROM comment_revisions
+WHERE comment_id = $1
+ORDER BY revision_number ASC
+`
+
+// Returns the revisions of the comment with the given id, oldest first.
+func (q *Queries) GetCommentRevisions(ctx context.Context, commentID pgtype.UUID) ([]CommentRevision, error) {
+	rows, err := q.db.Query(ctx, getCommentRevisions, commentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CommentRevision{}
+	for rows.Next() {
+		var i CommentRevision
+		if err := rows.Scan(
+			&i.CommentID,
+			&i.RevisionNumber,
+			&i.Body,
+			&i.Editor,
+			&i.EditedTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCommentScore = `-- name: GetCommentScore :one
+SELECT score
+FROM comments
+WHERE id = $1
+AND deleted_time IS NULL
+`
+
+// Returns the score of the comment with the given id.
+func (q *Queries) GetCommentScore(ctx context.Context, id pgtype.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, getCommentScore, id)
+	var score int32
+	err := row.Scan(&score)
+	return score, err
+}
+
+const getComments = `-- name: GetComments :many
+SELECT c.id, c.body, c.body_html, c.body_html_version, c.creator, c.thread_id, c.created_time, c.updated_time, c.num_revisions, c.deleted_time, c.deleted_by, c.score,
+    -- The vote of the user viewing the comment, 0 if they have not voted or are not logged in.
+    COALESCE((
+        SELECT cv.value FROM comment_votes cv
+        WHERE cv.comment_id = c.id
+        AND cv.username = $4::text
+    ), 0)::integer AS my_vote
+FROM comments c
+JOIN threads t ON c.thread_id = t.id
+WHERE c.thread_id = $1
+AND c.deleted_time IS NULL
+AND t.deleted_time IS NULL
+ORDER BY
+    CASE WHEN $5::text = 'created_time_asc' THEN c.created_time END ASC,
+    CASE WHEN $5::text = 'created_time_desc' THEN c.created_time END DESC,
+    CASE WHEN $5::text = 'score_desc' THEN c.score END DESC
+LIMIT $2
+OFFSET $3
+`
+
+type GetCommentsParams struct {
+	ThreadID  pgtype.UUID `json:"thread_id"`
+	Limit     int32       `json:"limit"`
+	Offset    int32       `json:"offset"`
+	Viewer    string      `json:"viewer"`
+	Sortorder string      `json:"sortorder"`
+}
+
+type GetCommentsRow struct {
+	Comment Comment `json:"comment"`
+	MyVote  int32   `json:"my_vote"`
+}
+
+// Get comments for a thread. Deleted comments and comments of deleted threads are not returned.
+// Sort order should be one of 'created_time_asc', 'created_time_desc', 'score_desc'.
+func (q *Queries) GetComments(ctx context.Context, arg GetCommentsParams) ([]GetCommentsRow, error) {
+	rows, err := q.db.Query(ctx, getComments,
+		arg.ThreadID,
+		arg.Limit,
+		arg.Offset,
+		arg.Viewer,
+		arg.Sortorder,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetCommentsRow{}
+	for rows.Next() {
+		var i GetCommentsRow
+		if err := rows.Scan(
+			&i.Comment.ID,
+			&i.Comment.Body,
+			&i.Comment.BodyHtml,
+			&i.Comment.BodyHtmlVersion,
+			&i.Comment.Creator,
+			&i.Comment.ThreadID,
+			&i.Comment.CreatedTime,
+			&i.Comment.UpdatedTime,
+			&i.Comment.NumRevisions,
+			&i.Comment.DeletedTime,
+			&i.Comment.DeletedBy,
+			&i.Comment.Score,
+			&i.MyVote,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCommentsByCreator = `-- name: GetCommentsByCreator :many
+SELECT c.id, c.body, c.body_html, c.body_html_version, c.creator, c.thread_id, c.created_time, c.updated_time,
+    c.num_revisions, c.score,
+    COALESCE((
+        SELECT cv.value FROM comment_votes cv
+        WHERE cv.comment_id = c.id
+        AND cv.username = $3::text
+    ), 0)::integer AS my_vote,
+    t.title AS thread_title
+FROM comments c
+JOIN threads t ON c.thread_id = t.id
+WHERE c.creator = (SELECT u.username FROM users u WHERE u.canonical_username = $4::text)
+AND c.deleted_time IS NULL
+AND t.deleted_time IS NULL
+ORDER BY
+    CASE WHEN $5::text = 'created_time_asc' THEN c.created_time END ASC,
+    CASE WHEN $5::text = 'created_time_desc' THEN c.created_time END DESC,
+    CASE WHEN $5::text = 'score_desc' THEN c.score END DESC
+LIMIT $1
+OFFSET $2
+`
+
+type GetCommentsByCreatorParams struct {
+	Limit     int32  `json:"limit"`
+	Offset    int32  `json:"offset"`
+	Viewer    string `json:"viewer"`
+	Creator   string `json:"creator"`
+	Sortorder string `json:"sortorder"`
+}
+
+type GetCommentsByCreatorRow struct {
+	ID              pgtype.UUID        `json:"id"`
+	Body            string             `json:"body"`
+	BodyHtml        string             `json:"body_html"`
+	BodyHtmlVersion int32              `json:"body_html_version"`
+	Creator         string             `json:"creator"`
+	ThreadID        pgtype.UUID        `json:"thread_id"`
+	CreatedTime     pgtype.Timestamptz `json:"created_time"`
+	UpdatedTime     pgtype.Timestamptz `json:"updated_time"`
+	NumRevisions    int32              `json:"num_revisions"`
+	Score           int32              `json:"score"`
+	MyVote          int32              `json:"my_vote"`
+	ThreadTitle     string             `json:"thread_title"`
+}
+
+// Get comments created by a user, together with the title of the thread they belong to.
+// Deleted comments and comments of deleted threads are not returned.
+// Sort order should be one of 'created_time_asc', 'created_time_desc', 'score_desc'.
+func (q *Queries) GetCommentsByCreator(ctx context.Context, arg GetCommentsByCreatorParams) ([]GetCommentsByCreatorRow, error) {
+	rows, err := q.db.Query(ctx, getCommentsByCreator,
+		arg.Limit,
+		arg.Offset,
+		arg.Viewer,
+		arg.Creator,
+		arg.Sortorder,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetCommentsByCreatorRow{}
+	for rows.Next() {
+		var i GetCommentsByCreatorRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Body,
+			&i.BodyHtml,
+			&i.BodyHtmlVersion,
+			&i.Creator,
+			&i.ThreadID,
+			&i.CreatedTime,
+			&i.UpdatedTime,
+			&i.NumRevisions,
+			&i.Score,
+			&i.MyVote,
+			&i.ThreadTitle,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeletedComment = `-- name: GetDeletedComment :one
+SELECT id, creator, deleted_time, deleted_by
+FROM comments
+WHERE id = $1
+AND deleted_time IS NOT NULL
+`
+
+type GetDeletedCommentRow struct {
+	ID          pgtype.UUID        `json:"id"`
+	Creator     string             `json:"creator"`
+	DeletedTime pgtype.Timestamptz `json:"deleted_time"`
+	DeletedBy   pgtype.Text        `json:"deleted_by"`
+}
+
+// Returns the creator and deletion details of the deleted comment with the given id.
+func (q *Queries) GetDeletedComment(ctx context.Context, id pgtype.UUID) (GetDeletedCommentRow, error) {
+	row := q.db.QueryRow(ctx, getDeletedComment, id)
+	var i GetDeletedCommentRow
+	err := row.Scan(
+		&i.ID,
+		&i.Creator,
+		&i.DeletedTime,
+		&i.DeletedBy,
+	)
+	return i, err
+}
+
+const getDeletedThread = `-- name: GetDeletedThread :one
+SELECT id, creator, deleted_time, deleted_by
+FROM threads
+WHERE id = $1
+AND deleted_time IS NOT NULL
+`
+
+type GetDeletedThreadRow struct {
+	ID          pgtype.UUID        `json:"id"`
+	Creator     string             `json:"creator"`
+	DeletedTime pgtype.Timestamptz `json:"deleted_time"`
+	DeletedBy   pgtype.Text        `json:"deleted_by"`
+}
+
+// Returns the creator and deletion details of the deleted thread with the given id.
+func (q *Queries) GetDeletedThread(ctx context.Context, id pgtype.UUID) (GetDeletedThreadRow, error) {
+	row := q.db.QueryRow(ctx, getDeletedThread, id)
+	var i GetDeletedThreadRow
+	err := row.Scan(
+		&i.ID,
+		&i.Creator,
+		&i.DeletedTime,
+		&i.DeletedBy,
+	)
+	return i, err
+}
+
+const getDraft = `-- name: GetDraft :one
+SELECT context, title, body, tags, created_time, updated_time
+FROM drafts
+WHERE username = $1::text
+AND context = $2::text
+`
+
+type GetDraftParams struct {
+	Username string `json:"username"`
+	Context  string `json:"context"`
+}
+
+type GetDraftRow struct {
+	Context     string             `json:"context"`
+	Title       string             `json:"title"`
+	Body        string             `json:"body"`
+	Tags        []string           `json:"tags"`
+	CreatedTime pgtype.Timestamptz `json:"created_time"`
+	UpdatedTime pgtype.Timestamptz `json:"updated_time"`
+}
+
+// Get the draft of a user for the given context.
+func (q *Queries) GetDraft(ctx context.Context, arg GetDraftParams) (GetDraftRow, error) {
+	row := q.db.QueryRow(ctx, getDraft, arg.Username, arg.Context)
+	var i GetDraftRow
+	err := row.Scan(
+		&i.Context,
+		&i.Title,
+		&i.Body,
+		&i.Tags,
+		&i.CreatedTime,
+		&i.UpdatedTime,
+	)
+	return i, err
+}
+
+const getDrafts = `-- name: GetDrafts :many
+SELECT context, title, body, tags, created_time, updated_time
+FROM drafts
+WHERE username = $1
+ORDER BY updated_time DESC
+`
+
+type GetDraftsRow struct {
+	Context     string             `json:"context"`
+	Title       string             `json:"title"`
+	Body        string             `json:"body"`
+	Tags        []string           `json:"tags"`
+	CreatedTime pgtype.Timestamptz `json:"created_time"`
+	UpdatedTime pgtype.Timestamptz `json:"updated_time"`
+}
+
+// Get the drafts of a user, most recently updated first.
+func (q *Queries) GetDrafts(ctx context.Context, username string) ([]GetDraftsRow, error) {
+	rows, err := q.db.Query(ctx, getDrafts, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetDraftsRow{}
+	for rows.Next() {
+		var i GetDraftsRow
+		if err := rows.Scan(
+			&i.Context,
+			&i.Title,
+			&i.Body,
+			&i.Tags,
+			&i.CreatedTime,
+			&i.UpdatedTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDueScheduledThreads = `-- name: GetDueScheduledThreads :many
+SELECT id, title, body, creator, tags, poll, attachment_ids, category_id, publish_time, created_time
+FROM scheduled_threads
+WHERE publish_time <= NOW()
+ORDER BY publish_time
+LIMIT $1
+`
+
+// Get scheduled threads whose publish time has passed, earliest first.
+func (q *Queries) GetDueScheduledThreads(ctx context.Context, limit int32) ([]ScheduledThread, error) {
+	rows, err := q.db.Query(ctx, getDueScheduledThreads, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ScheduledThread{}
+	for rows.Next() {
+		var i ScheduledThread
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Body,
+			&i.Creator,
+			&i.Tags,
+			&i.Poll,
+			&i.AttachmentIds,
+			&i.CategoryID,
+			&i.PublishTime,
+			&i.CreatedTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getInviteCodes = `-- name: GetInviteCodes :many
+SELECT code, creator, max_uses, num_uses, expires_time, created_time
+FROM invite_codes
+ORDER BY created_time DESC
+`
+
+// Returns all invite codes, latest first.
+func (q *Queries) GetInviteCodes(ctx context.Context) ([]InviteCode, error) {
+	rows, err := q.db.Query(ctx, getInviteCodes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InviteCode{}
+	for rows.Next() {
+		var i InviteCode
+		if err := rows.Scan(
+			&i.Code,
+			&i.Creator,
+			&i.MaxUses,
+			&i.NumUses,
+			&i.ExpiresTime,
+			&i.CreatedTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMentionedUsers = `-- name: GetMentionedUsers :many
+SELECT u.canonical_username AS mentioned_username, u.username
+FROM users u
+WHERE u.canonical_username = ANY($1::text[])
+UNION ALL
+SELECT renamed.old_canonical_username AS mentioned_username, renamed.username
+FROM (
+    SELECT DISTINCT ON (h.old_canonical_username) h.old_canonical_username, h.username
+    FROM username_history h
+    WHERE h.old_canonical_username = ANY($1::text[])
+    AND NOT EXISTS (SELECT 1 FROM users u WHERE u.canonical_username = h.old_canonical_username)
+    ORDER BY h.old_canonical_username, h.changed_time DESC
+) renamed
+`
+
+type GetMentionedUsersRow struct {
+	MentionedUsername string `json:"mentioned_username"`
+	Username          string `json:"username"`
+}
+
+// Returns the current usernames of the users with the given canonical usernames. Canonical usernames no user has
+// are resolved to the user who most recently had them, as in GetRenamedUsername.
+func (q *Queries) GetMentionedUsers(ctx context.Context, canonicalUsernames []string) ([]GetMentionedUsersRow, error) {
+	rows, err := q.db.Query(ctx, getMentionedUsers, canonicalUsernames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetMentionedUsersRow{}
+	for rows.Next() {
+		var i GetMentionedUsersRow
+		if err := rows.Scan(&i.MentionedUsername, &i.Username); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNotificationCount = `-- name: GetNotificationCount :one
+SELECT COUNT(*) AS total_items
+FROM notifications n
+JOIN threads t ON n.thread_id = t.id
+LEFT JOIN comments c ON n.comment_id = c.id
+WHERE n.username = $1::text
+AND (NOT $2::boolean OR NOT n.is_read)
+AND t.deleted_time IS NULL
+AND c.deleted_time IS NULL
+`
+
+type GetNotificationCountParams struct {
+	Username   string `json:"username"`
+	UnreadOnly bool   `json:"unread_only"`
+}
+
+// Counts the notifications of a user that would be returned by GetNotifications.
+func (q *Queries) GetNotificationCount(ctx context.Context, arg GetNotificationCountParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getNotificationCount, arg.Username, arg.UnreadOnly)
+	var total_items int64
+	err := row.Scan(&total_items)
+	return total_items, err
+}
+
+const getNotifications = `-- name: GetNotifications :many
+SELECT n.id, n.type, n.thread_id, t.title AS thread_title, n.comment_id, n.actor, n.is_read, n.created_time
+FROM notifications n
+JOIN threads t ON n.thread_id = t.id
+LEFT JOIN comments c ON n.comment_id = c.id
+WHERE n.username = $3::text
+AND (NOT $4::boolean OR NOT n.is_read)
+AND t.deleted_time IS NULL
+AND c.deleted_time IS NULL
+ORDER BY n.created_time DESC
+LIMIT $1
+OFFSET $2
+`
+
+type GetNotificationsParams struct {
+	Limit      int32  `json:"limit"`
+	Offset     int32  `json:"offset"`
+	Username   string `json:"username"`
+	UnreadOnly bool   `json:"unread_only"`
+}
+
+type GetNotificationsRow struct {
+	ID          pgtype.UUID        `json:"id"`
+	Type        string             `json:"type"`
+	ThreadID    pgtype.UUID        `json:"thread_id"`
+	ThreadTitle string             `json:"thread_title"`
+	CommentID   pgtype.UUID        `json:"comment_id"`
+	Actor       pgtype.Text        `json:"actor"`
+	IsRead      bool               `json:"is_read"`
+	CreatedTime pgtype.Timestamptz `json:"created_time"`
+}
+
+// Get the notifications of a user, latest first, together with the title of the thread.
+// If unread_only is true, only unread notifications are returned.
+// Notifications about deleted threads and comments are not returned.
+func (q *Queries) GetNotifications(ctx context.Context, arg GetNotificationsParams) ([]GetNotificationsRow, error) {
+	rows, err := q.db.Query(ctx, getNotifications,
+		arg.Limit,
+		arg.Offset,
+		arg.Username,
+		arg.UnreadOnly,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetNotificationsRow{}
+	for rows.Next() {
+		var i GetNotificationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.ThreadID,
+			&i.ThreadTitle,
+			&i.CommentID,
+			&i.Actor,
+			&i.IsRead,
+			&i.CreatedTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOldUsernameSkeletons = `-- name: GetOldUsernameSkeletons :many
+SELECT id, old_username, old_username_skeleton
+FROM username_history
+WHERE id > $2::uuid
+ORDER BY id
+LIMIT $1
+`
+
+type GetOldUsernameSkeletonsParams struct {
+	Limit   int32       `json:"limit"`
+	AfterID pgtype.UUID `json:"after_id"`
+}
+
+type GetOldUsernameSkeletonsRow struct {
+	ID                  pgtype.UUID `json:"id"`
+	OldUsername         string      `json:"old_username"`
+	OldUsernameSkeleton string      `json:"old_username_skeleton"`
+}
+
+// Returns the previous usernames and their skeletons ordered by ID, starting after the given ID.
+func (q *Queries) GetOldUsernameSkeletons(ctx context.Context, arg GetOldUsernameSkeletonsParams) ([]GetOldUsernameSkeletonsRow, error) {
+	rows, err := q.db.Query(ctx, getOldUsernameSkeletons, arg.Limit, arg.AfterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetOldUsernameSkeletonsRow{}
+	for rows.Next() {
+		var i GetOldUsernameSkeletonsRow
+		if err := rows.Scan(&i.ID, &i.OldUsername, &i.OldUsernameSkeleton); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPasswordHash = `-- name: GetPasswordHash :one
+SELECT username, password, is_verified
+FROM users
+WHERE canonical_username = $1
+`
+
+type GetPasswordHashRow struct {
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	IsVerified bool   `json:"is_verified"`
+}
+
+// Returns a username, their password hash and whether their email has been verified.
+func (q *Queries) GetPasswordHash(ctx context.Context, canonicalUsername string) (GetPasswordHashRow, error) {
+	row := q.db.QueryRow(ctx, getPasswordHash, canonicalUsername)
+	var i GetPasswordHashRow
+	err := row.Scan(&i.Username, &i.Password, &i.IsVerified)
+	return i, err
+}
+
+const getPoll = `-- name: GetPoll :one
+SELECT p.is_multiple_choice, p.is_anonymous, p.close_time,
+    (p.close_time IS NOT NULL AND p.close_time <= NOW())::boolean AS is_closed,
+    (SELECT COUNT(DISTINCT pv.username) FROM poll_votes pv WHERE pv.thread_id = p.thread_id)::integer AS num_voters
+FROM polls p
+JOIN threads t ON t.id = p.thread_id
+WHERE p.thread_id = $1
+AND t.deleted_time IS NULL
+`
+
+type GetPollRow struct {
+	IsMultipleChoice bool               `json:"is_multiple_choice"`
+	IsAnonymous      bool               `json:"is_anonymous"`
+	CloseTime        pgtype.Timestamptz `json:"close_time"`
+	IsClosed         bool               `json:"is_closed"`
+	NumVoters        int32              `json:"num_voters"`
+}
+
+// Gets the poll of a thread.
+func (q *Queries) GetPoll(ctx context.Context, threadID pgtype.UUID) (GetPollRow, error) {
+	row := q.db.QueryRow(ctx, getPoll, threadID)
+	var i GetPollRow
+	err := row.Scan(
+		&i.IsMultipleChoice,
+		&i.IsAnonymous,
+		&i.CloseTime,
+		&i.IsClosed,
+		&i.NumVoters,
+	)
+	return i, err
+}
+
+const getPollForUpdate = `-- name: GetPollForUpdate :one
+SELECT p.is_multiple_choice,
+    (p.close_time IS NOT NULL AND p.close_time <= NOW())::boolean AS is_closed,
+    (SELECT COUNT(*) FROM poll_options po WHERE po.thread_id = p.thread_id)::integer AS num_options,
+    EXISTS (SELECT 1 FROM poll_votes pv WHERE pv.thread_id = p.thread_id) AS has_votes
+FROM polls p
+JOIN threads t ON t.id = p.thread_id
+WHERE p.thread_id = $1
+AND t.deleted_time IS NULL
+FOR UPDATE OF p
+`
+
+type GetPollForUpdateRow struct {
+	IsMultipleChoice bool  `json:"is_multiple_choice"`
+	IsClosed         bool  `json:"is_closed"`
+	NumOptions       int32 `json:"num_options"`
+	HasVotes         bool  `json:"has_votes"`
+}
+
+// Gets the poll of a thread, locking it until the end of the transaction so that votes and changes to the poll
+// are applied one at a time.
+func (q *Queries) GetPollForUpdate(ctx context.Context, threadID pgtype.UUID) (GetPollForUpdateRow, error) {
+	row := q.db.QueryRow(ctx, getPollForUpdate, threadID)
+	var i GetPollForUpdateRow
+	err := row.Scan(
+		&i.IsMultipleChoice,
+		&i.IsClosed,
+		&i.NumOptions,
+		&i.HasVotes,
+	)
+	return i, err
+}
+
+const getPollOptions = `-- name: GetPollOptions :many
+SELECT po.position, po.text,
+    COUNT(pv.username)::integer AS num_votes,
+    COALESCE(ARRAY_AGG(pv.username ORDER BY pv.created_time, pv.username)
+        FILTER (WHERE pv.username IS NOT NULL), '{}')::text[] AS voters,
+    -- Whether the user viewing the poll voted for the option, false if they are not logged in.
+    COALESCE(BOOL_OR(pv.username = $1::text), FALSE)::boolean AS is_my_vote
+FROM poll_options po
+LEFT JOIN poll_votes pv ON pv.thread_id = po.thread_id AND pv.position = po.position
+WHERE po.thread_id = $2
+GROUP BY po.thread_id, po.position
+ORDER BY po.position
+`
+
+type GetPollOptionsParams struct {
+	Viewer   string      `json:"viewer"`
+	ThreadID pgtype.UUID `json:"thread_id"`
+}
+
+type GetPollOptionsRow struct {
+	Position int32    `json:"position"`
+	Text     string   `json:"text"`
+	NumVotes int32    `json:"num_votes"`
+	Voters   []string `json:"voters"`
+	IsMyVote bool     `json:"is_my_vote"`
+}
+
+// Gets the options of a poll with their votes. Voters are listed in the order they voted.
+func (q *Queries) GetPollOptions(ctx context.Context, arg GetPollOptionsParams) ([]GetPollOptionsRow, error) {
+	rows, err := q.db.Query(ctx, getPollOptions, arg.Viewer, arg.ThreadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetPollOptionsRow{}
+	for rows.Next() {
+		var i GetPollOptionsRow
+		if err := rows.Scan(
+			&i.Position,
+			&i.Text,
+			&i.NumVotes,
+			&i.Voters,
+			&i.IsMyVote,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRelatedThreads = `-- name: GetRelatedThreads :many
+WITH source AS (
+    SELECT t.id,
+        TO_TSQUERY('simple', REPLACE(PLAINTO_TSQUERY('simple', t.title)::text, ' & ', ' | ')) AS query
+    FROM threads t
+    WHERE t.id = $2
+    AND t.deleted_time IS NULL
+),
+candidates AS (
+    SELECT t.id,
+        (
+            SELECT COUNT(*)
+            FROM thread_tags tt
+            JOIN thread_tags stt ON stt.tag_name = tt.tag_name AND stt.thread_id = s.id
+            WHERE tt.thread_id = t.id
+        ) AS shared_tags,
+        TS_RANK(TO_TSVECTOR('simple', t.title || ' ' || t.body), s.query) AS text_rank
+    FROM threads t, source s
+    WHERE t.id <> s.id
+    AND t.deleted_time IS NULL
+)
+SELECT t.id, t.title, t.creator, t.created_time, t.num_comments, t.score,
+    ARRAY(
+        SELECT tt.tag_name
+        FROM thread_tags tt
+        WHERE tt.thread_id = t.id
+        ORDER BY tt.tag_name
+    )::text[] AS tags
+FROM candidates c
+JOIN threads t ON t.id = c.id
+WHERE c.shared_tags > 0
+OR c.text_rank > 0
+ORDER BY c.shared_tags + c.text_rank * 10 DESC, t.score DESC, t.created_time DESC
+LIMIT $1::integer
+`
+
+type GetRelatedThreadsParams struct {
+	MaxThreads int32       `json:"max_threads"`
+	ThreadID   pgtype.UUID `json:"thread_id"`
+}
+
+type GetRelatedThreadsRow struct {
+	ID          pgtype.UUID        `json:"id"`
+	Title       string             `json:"title"`
+	Creator     string             `json:"creator"`
+	CreatedTime pgtype.Timestamptz `json:"created_time"`
+	NumComments int32              `json:"num_comments"`
+	Score       int32              `json:"score"`
+	Tags        []string           `json:"tags"`
+}
+
+// Returns the threads most related to the given thread, ranked by the number of tags they share with it and by the
+// full-text similarity of their titles and bodies to its title. Any word of the title can match, and a shared tag
+// counts as much as a text rank of 0.1. Deleted threads and the thread itself are not returned.
+func (q *Queries) GetRelatedThreads(ctx context.Context, arg GetRelatedThreadsParams) ([]GetRelatedThreadsRow, error) {
+	rows, err := q.db.Query(ctx, getRelatedThreads, arg.MaxThreads, arg.ThreadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetRelatedThreadsRow{}
+	for rows.Next() {
+		var i GetRelatedThreadsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Creator,
+			&i.CreatedTime,
+			&i.NumComments,
+			&i.Score,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRenamedUsername = `-- name: GetRenamedUsername :one
+SELECT username
+FROM username_history
+WHERE old_canonical_username = $1
+ORDER BY changed_time DESC
+LIMIT 1
+`
+
+// Returns the current username of the user who most recently had the given canonical username.
+func (q *Queries) GetRenamedUsername(ctx context.Context, oldCanonicalUsername string) (string, error) {
+	row := q.db.QueryRow(ctx, getRenamedUsername, oldCanonicalUsername)
+	var username string
+	err := row.Scan(&username)
+	return username, err
+}
+
+const getScheduledThreadPoll = `-- name: GetScheduledThreadPoll :one
+SELECT poll
+FROM scheduled_threads
+WHERE id = $1
+AND creator = $2::text
+`
+
+type GetScheduledThreadPollParams struct {
+	ID      pgtype.UUID `json:"id"`
+	Creator string      `json:"creator"`
+}
+
+// Get the poll of a thread scheduled by a user, as JSON.
+func (q *Queries) GetScheduledThreadPoll(ctx context.Context, arg GetScheduledThreadPollParams) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getScheduledThreadPoll, arg.ID, arg.Creator)
+	var poll []byte
+	err := row.Scan(&poll)
+	return poll, err
+}
+
+const getScheduledThreads = `-- name: GetScheduledThreads :many
+SELECT id, title, body, creator, tags, poll, attachment_ids, category_id, publish_time, created_time
+FROM scheduled_threads
+WHERE creator = $1
+ORDER BY publish_time
+`
+
+// Get the threads scheduled by a user, earliest publish time first.
+func (q *Queries) GetScheduledThreads(ctx context.Context, creator string) ([]ScheduledThread, error) {
+	rows, err := q.db.Query(ctx, getScheduledThreads, creator)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ScheduledThread{}
+	for rows.Next() {
+		var i ScheduledThread
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Body,
+			&i.Creator,
+			&i.Tags,
+			&i.Poll,
+			&i.AttachmentIds,
+			&i.CategoryID,
+			&i.PublishTime,
+			&i.CreatedTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSimilarThreads = `-- name: GetSimilarThreads :many
+WITH candidates AS (
+    SELECT t.id
+    FROM threads t
+    WHERE t.title % $1::text
+    AND t.deleted_time IS NULL
+    UNION
+    SELECT t.id
+    FROM threads t
+    WHERE LENGTH(REPLACE((t.body_simhash # $2::bigint)::bit(64)::text, '0', '')) <= $3::integer
+    AND t.deleted_time IS NULL
+)
+SELECT t.id, t.title, t.creator, t.created_time, t.num_comments,
+    SIMILARITY(t.title, $1::text)::real AS title_similarity,
+    COALESCE(
+        LENGTH(REPLACE((t.body_simhash # $2::bigint)::bit(64)::text, '0', '')) <= $3::integer,
+        FALSE
+    )::boolean AS is_near_duplicate
+FROM candidates c
+JOIN threads t ON t.id = c.id
+ORDER BY is_near_duplicate DESC, title_similarity DESC, t.created_time DESC
+LIMIT $4::integer
+`
+
+type GetSimilarThreadsParams struct {
+	Title       string `json:"title"`
+	BodySimhash int64  `json:"body_simhash"`
+	MaxDistance int32  `json:"max_distance"`
+	MaxThreads  int32  `json:"max_threads"`
+}
+
+type GetSimilarThreadsRow struct {
+	ID              pgtype.UUID        `json:"id"`
+	Title           string             `json:"title"`
+	Creator         string             `json:"creator"`
+	CreatedTime     pgtype.Timestamptz `json:"created_time"`
+	NumComments     int32              `json:"num_comments"`
+	TitleSimilarity float32            `json:"title_similarity"`
+	IsNearDuplicate bool               `json:"is_near_duplicate"`
+}
+
+// Returns threads that are likely duplicates of a new thread with the given title and body fingerprint. Threads are
+// returned if their titles are similar, with a trigram similarity of at least pg_trgm.similarity_threshold (0.3 by
+// default), or if their body fingerprints differ in at most the given number of bits. Near-duplicate bodies are
+// returned first, then the most similar titles. Deleted threads are not returned.
+func (q *Queries) GetSimilarThreads(ctx context.Context, arg GetSimilarThreadsParams) ([]GetSimilarThreadsRow, error) {
+	rows, err := q.db.Query(ctx, getSimilarThreads,
+		arg.Title,
+		arg.BodySimhash,
+		arg.MaxDistance,
+		arg.MaxThreads,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSimilarThreadsRow{}
+	for rows.Next() {
+		var i GetSimilarThreadsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Creator,
+			&i.CreatedTime,
+			&i.NumComments,
+			&i.TitleSimilarity,
+			&i.IsNearDuplicate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStaleCommentBodies = `-- name: GetStaleCommentBodies :many
+SELECT id, body
+FROM comments
+WHERE body_html_version <> $2::integer
+LIMIT $1
+`
+
+type GetStaleCommentBodiesParams struct {
+	Limit           int32 `json:"limit"`
+	BodyHtmlVersion int32 `json:"body_html_version"`
+}
+
+type GetStaleCommentBodiesRow struct {
+	ID   pgtype.UUID `json:"id"`
+	Body string      `json:"body"`
+}
+
+// Get comments whose cached HTML was rendered by a different version of the renderer, including deleted comments.
+func (q *Queries) GetStaleCommentBodies(ctx context.Context, arg GetStaleCommentBodiesParams) ([]GetStaleCommentBodiesRow, error) {
+	rows, err := q.db.Query(ctx, getStaleCommentBodies, arg.Limit, arg.BodyHtmlVersion)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetStaleCommentBodiesRow{}
+	for rows.Next() {
+		var i GetStaleCommentBodiesRow
+		if err := rows.Scan(&i.ID, &i.Body); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStaleThreadBodies = `-- name: GetStaleThreadBodies :many
+SELECT id, body
+FROM threads
+WHERE body_html_version <> $2::integer
+LIMIT $1
+`
+
+type GetStaleThreadBodiesParams struct {
+	Limit           int32 `json:"limit"`
+	BodyHtmlVersion int32 `json:"body_html_version"`
+}
+
+type GetStaleThreadBodiesRow struct {
+	ID   pgtype.UUID `json:"id"`
+	Body string      `json:"body"`
+}
+
+// Get threads whose cached HTML was rendered by a different version of the renderer, including deleted threads.
+func (q *Queries) GetStaleThreadBodies(ctx context.Context, arg GetStaleThreadBodiesParams) ([]GetStaleThreadBodiesRow, error) {
+	rows, err := q.db.Query(ctx, getStaleThreadBodies, arg.Limit, arg.BodyHtmlVersion)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetStaleThreadBodiesRow{}
+	for rows.Next() {
+		var i GetStaleThreadBodiesRow
+		if err := rows.Scan(&i.ID, &i.Body); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getThreadDetails = `-- name: GetThreadDetails :one
+SELECT t.id, t.title, t.body, t.creator, t.created_time, t.updated_time, t.num_comments, t.num_revisions,
+    t.is_locked, t.lock_reason, t.score, t.view_count, t.body_html, t.body_html_version, t.category_id,
+    CASE
+    WHEN COUNT(tt.tag_name) > 0 THEN ARRAY_AGG(tt.tag_name ORDER BY tt.tag_name)
+        ELSE '{}'::text[]
+    END AS tags,
+    EXISTS (
+        SELECT 1 FROM thread_pins tp
+        WHERE tp.thread_id = t.id
+        AND tp.tag_name = ''
+        AND (tp.expires_time IS NULL OR tp.expires_time > NOW())
+    ) AS is_pinned,
+    EXISTS (
+        SELECT 1 FROM thread_pins tp
+        WHERE tp.thread_id = t.id
+        AND tp.is_announcement
+        AND (tp.expires_time IS NULL OR tp.expires_time > NOW())
+    ) AS is_announcement,
+    -- The vote of the user viewing the thread, 0 if they have not voted or are not logged in.
+    COALESCE((
+        SELECT tv.value FROM thread_votes tv
+        WHERE tv.thread_id = t.id
+        AND tv.username = $2::text
+    ), 0)::integer AS my_vote,
+    EXISTS (
+        SELECT 1 FROM bookmarks b
+        WHERE b.thread_id = t.id
+        AND b.username = $2::text
+    ) AS is_bookmarked,
+    EXISTS (
+        SELECT 1 FROM thread_subscriptions ts
+        WHERE ts.thread_id = t.id
+        AND ts.username = $2::text
+        AND NOT ts.is_muted
+    ) AS is_subscribed
+FROM threads t
+LEFT JOIN thread_tags tt ON t.id = tt.thread_id
+WHERE t.id = $1
+AND t.deleted_time IS NULL
+GROUP BY t.id
+`
+
+type GetThreadDetailsParams struct {
+	ID     pgtype.UUID `json:"id"`
+	Viewer string      `json:"viewer"`
+}
+
+type GetThreadDetailsRow struct {
+	ID              pgtype.UUID        `json:"id"`
+	Title           string             `json:"title"`
+	Body            string             `json:"body"`
+	Creator         string             `json:"creator"`
+	CreatedTime     pgtype.Timestamptz `json:"created_time"`
+	UpdatedTime     pgtype.Timestamptz `json:"updated_time"`
+	NumComments     int32              `json:"num_comments"`
+	NumRevisions    int32              `json:"num_revisions"`
+	IsLocked        bool               `json:"is_locked"`
+	LockReason      pgtype.Text        `json:"lock_reason"`
+	Score           int32              `json:"score"`
+	ViewCount       int32              `json:"view_count"`
+	BodyHtml        string             `json:"body_html"`
+	BodyHtmlVersion int32              `json:"body_html_version"`
+	CategoryID      pgtype.UUID        `json:"category_id"`
+	Tags            []string           `json:"tags"`
+	IsPinned        bool               `json:"is_pinned"`
+	IsAnnouncement  bool               `json:"is_announcement"`
+	MyVote          int32              `json:"my_vote"`
+	IsBookmarked    bool               `json:"is_bookmarked"`
+	IsSubscribed    bool               `json:"is_subscribed"`
+}
+
+// Returns the details of the thread with the given id, as well as the tags of the thread as an array.
+// The thread is pinned if it is pinned everywhere. Deleted threads are not returned.
+func (q *Queries) GetThreadDetails(ctx context.Context, arg GetThreadDetailsParams) (GetThreadDetailsRow, error) {
+	row := q.db.QueryRow(ctx, getThreadDetails, arg.ID, arg.Viewer)
+	var i GetThreadDetailsRow
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Body,
+		&i.Creator,
+		&i.CreatedTime,
+		&i.UpdatedTime,
+		&i.NumComments,
+		&i.NumRevisions,
+		&i.IsLocked,
+		&i.LockReason,
+		&i.Score,
+		&i.ViewCount,
+		&i.BodyHtml,
+		&i.BodyHtmlVersion,
+		&i.CategoryID,
+		&i.Tags,
+		&i.IsPinned,
+		&i.IsAnnouncement,
+		&i.MyVote,
+		&i.IsBookmarked,
+		&i.IsSubscribed,
+	)
+	return i, err
+}
+
+const getThreadLockDetails = `-- name: GetThreadLockDetails :one
+SELECT id, creator, is_locked, locked_by
+FROM threads
+WHERE id = $1
+AND deleted_time IS NULL
+`
+
+type GetThreadLockDetailsRow struct {
+	ID       pgtype.UUID `json:"id"`
+	Creator  string      `json:"creator"`
+	IsLocked bool        `json:"is_locked"`
+	LockedBy pgtype.Text `json:"locked_by"`
+}
+
+// Returns the creator and lock details of the thread with the given id, if it has not been deleted.
+func (q *Queries) GetThreadLockDetails(ctx context.Context, id pgtype.UUID) (GetThreadLockDetailsRow, error) {
+	row := q.db.QueryRow(ctx, getThreadLockDetails, id)
+	var i GetThreadLockDetailsRow
+	err := row.Scan(
+		&i.ID,
+		&i.Creator,
+		&i.IsLocked,
+		&i.LockedBy,
+	)
+	return i, err
+}
+
+const getThreadRedirect = `-- name: GetThreadRedirect :one
+SELECT target_thread_id
+FROM thread_redirects
+WHERE thread_id = $1
+`
+
+// Returns the id of the thread that the thread with the given id was merged into.
+func (q *Queries) GetThreadRedirect(ctx context.Context, threadID pgtype.UUID) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, getThreadRedirect, threadID)
+	var target_thread_id pgtype.UUID
+	err := row.Scan(&target_thread_id)
+	return target_thread_id, err
+}
+
+const getThreadRevision = `-- name: GetThreadRevision :one
+SELECT thread_id, revision_number, title, body, tags, editor, edited_time
+FROM thread_revisions
+WHERE thread_id = $1
+AND revision_number = $2
+`
+
+type GetThreadRevisionParams struct {
+	ThreadID       pgtype.UUID `json:"thread_id"`
+	RevisionNumber int32       `json:"revision_number"`
+}
+
+// Returns a single revision of the thread with the given id.
+func (q *Queries) GetThreadRevision(ctx context.Context, arg GetThreadRevisionParams) (ThreadRevision, error) {
+	row := q.db.QueryRow(ctx, getThreadRevision, arg.ThreadID, arg.RevisionNumber)
+	var i ThreadRevision
+	err := row.Scan(
+		&i.ThreadID,
+		&i.RevisionNumber,
+		&i.Title,
+		&i.Body,
+		&i.Tags,
+		&i.Editor,
+		&i.EditedTime,
+	)
+	return i, err
+}
+
+const getThreadRevisions = `-- name: GetThreadRevisions :many
+SELECT thread_id, revision_number, title, body, tags, editor, edited_time
+FROM thread_revisions
+WHERE thread_id = $1
+ORDER BY revision_number ASC
+`
+
+// Returns the revisions of the thread with the given id, oldest first.
+func (q *Queries) GetThreadRevisions(ctx context.Context, threadID pgtype.UUID) ([]ThreadRevision, error) {
+	rows, err := q.db.Query(ctx, getThreadRevisions, threadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ThreadRevision{}
+	for rows.Next() {
+		var i ThreadRevision
+		if err := rows.Scan(
+			&i.ThreadID,
+			&i.RevisionNumber,
+			&i.Title,
+			&i.Body,
+			&i.Tags,
+			&i.Editor,
+			&i.EditedTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getThreadScore = `-- name: GetThreadScore :one
+SELECT score
+FROM threads
+WHERE id = $1
+AND deleted_time IS NULL
+`
+
+// Returns the score of the thread with the given id.
+func (q *Queries) GetThreadScore(ctx context.Context, id pgtype.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, getThreadScore, id)
+	var score int32
+	err := row.Scan(&score)
+	return score, err
+}
+
+const getThreadTags = `-- name: GetThreadTags :many
+SELECT tag_name
+FROM thread_tags
+WHERE thread_id = $1
+`
+
+// Returns the tags of the thread with the given id.
+func (q *Queries) GetThreadTags(ctx context.Context, threadID pgtype.UUID) ([]string, error) {
+	rows, err := q.db.Query(ctx, getThreadTags, threadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var tag_name string
+		if err := rows.Scan(&tag_name); err != nil {
+			return nil, err
+		}
+		items = append(items, tag_name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getThreads = `-- name: GetThreads :many
+SELECT t.id, t.title, t.body, t.creator, t.created_time, t.updated_time, t.num_comments, t.num_revisions,
+    t.is_locked, t.lock_reason, t.score, t.view_count, t.body_html, t.body_html_version, t.category_id,
+    CASE
+    WHEN COUNT(tt.tag_name) > 0 THEN ARRAY_AGG(tt.tag_name ORDER BY tt.tag_name)
+        ELSE '{}'::text[]
+    END AS tags,
+    EXISTS (
+        SELECT 1 FROM thread_pins tp
+        WHERE tp.thread_id = t.id
+        AND tp.tag_name = ''
+        AND (tp.expires_time IS NULL OR tp.expires_time > NOW())
+    ) AS is_pinned,
+    EXISTS (
+        SELECT 1 FROM thread_pins tp
+        WHERE tp.thread_id = t.id
+        AND tp.is_announcement
+        AND (tp.expires_time IS NULL OR tp.expires_time > NOW())
+    ) AS is_announcement,
+    -- The vote of the user viewing the thread, 0 if they have not voted or are not logged in.
+    COALESCE((
+        SELECT tv.value FROM thread_votes tv
+        WHERE tv.thread_id = t.id
+        AND tv.username = $3::text
+    ), 0)::integer AS my_vote,
+    EXISTS (
+        SELECT 1 FROM bookmarks b
+        WHERE b.thread_id = t.id
+        AND b.username = $3::text
+    ) AS is_bookmarked,
+    EXISTS (
+        SELECT 1 FROM thread_subscriptions ts
+        WHERE ts.thread_id = t.id
+        AND ts.username = $3::text
+        AND NOT ts.is_muted
+    ) AS is_subscribed
+FROM threads t
+LEFT JOIN thread_tags tt ON t.id = tt.thread_id
+WHERE t.deleted_time IS NULL
+GROUP BY t.id
+ORDER BY
+    is_announcement DESC,
+    is_pinned DESC,
+    CASE WHEN $4::text = 'created_time_asc' THEN created_time END ASC,
+    CASE WHEN $4::text = 'created_time_desc' THEN created_time END DESC,
+    CASE WHEN $4::text = 'num_comments_asc' THEN num_comments END ASC,
+    CASE WHEN $4::text = 'num_comments_desc' THEN num_comments END DESC,
+    CASE WHEN $4::text = 'score_desc' THEN score END DESC,
+    CASE WHEN $4::text = 'hot_desc' THEN hot_score END DESC,
+    CASE WHEN $4::text = 'trending_desc' THEN trending_score END DESC,
+    CASE WHEN $4::text = 'views_desc' THEN view_count END DESC
+LIMIT $1
+OFFSET $2
+`
+
+type GetThreadsParams struct {
+	Limit     int32  `json:"limit"`
+	Offset    int32  `json:"offset"`
+	Viewer    string `json:"viewer"`
+	Sortorder string `json:"sortorder"`
+}
+
+type GetThreadsRow struct {
+	ID              pgtype.UUID        `json:"id"`
+	Title           string             `json:"title"`
+	Body            string             `json:"body"`
+	Creator         string             `json:"creator"`
+	CreatedTime     pgtype.Timestamptz `json:"created_time"`
+	UpdatedTime     pgtype.Timestamptz `json:"updated_time"`
+	NumComments     int32              `json:"num_comments"`
+	NumRevisions    int32              `json:"num_revisions"`
+	IsLocked        bool               `json:"is_locked"`
+	LockReason      pgtype.Text        `json:"lock_reason"`
+	Score           int32              `json:"score"`
+	ViewCount       int32              `json:"view_count"`
+	BodyHtml        string             `json:"body_html"`
+	BodyHtmlVersion int32              `json:"body_html_version"`
+	CategoryID      pgtype.UUID        `json:"category_id"`
+	Tags            []string           `json:"tags"`
+	IsPinned        bool               `json:"is_pinned"`
+	IsAnnouncement  bool               `json:"is_announcement"`
+	MyVote          int32              `json:"my_vote"`
+	IsBookmarked    bool               `json:"is_bookmarked"`
+	IsSubscribed    bool               `json:"is_subscribed"`
+}
+
+// Returns the details of all threads that have not been deleted, with announcements and pinned threads first.
+// Sort order should be one of 'created_time_asc', 'created_time_desc', 'num_comments_asc', 'num_comments_desc',
+// 'score_desc', 'hot_desc', 'trending_desc', 'views_desc'.
+func (q *Queries) GetThreads(ctx context.Context, arg GetThreadsParams) ([]GetThreadsRow, error) {
+	rows, err := q.db.Query(ctx, getThreads,
+		arg.Limit,
+		arg.Offset,
+		arg.Viewer,
+		arg.Sortorder,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetThreadsRow{}
+	for rows.Next() {
+		var i GetThreadsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Body,
+			&i.Creator,
+			&i.CreatedTime,
+			&i.UpdatedTime,
+			&i.NumComments,
+			&i.NumRevisions,
+			&i.IsLocked,
+			&i.LockReason,
+			&i.Score,
+			&i.ViewCount,
+			&i.BodyHtml,
+			&i.BodyHtmlVersion,
+			&i.CategoryID,
+			&i.Tags,
+			&i.IsPinned,
+			&i.IsAnnouncement,
+			&i.MyVote,
+			&i.IsBookmarked,
+			&i.IsSubscribed,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getThreadsByCriteria = `-- name: GetThreadsByCriteria :many
+WITH RECURSIVE subcategories AS (
+    -- The category with the given slug and all of its subcategories.
+    SELECT c.id FROM categories c WHERE c.slug = $7::text
+    UNION
+    SELECT c.id FROM categories c JOIN subcategories s ON c.parent_id = s.id
+)
+SELECT t.id, t.title, t.body, t.creator, t.created_time, t.updated_time, t.num_comments, t.num_revisions,
+    t.is_locked, t.lock_reason, t.score, t.view_count, t.body_html, t.body_html_version, t.category_id,
+    -- Concatenate all the tags of the thread into an array.
+    CASE
+       WHEN COUNT(tt.tag_name) > 0 THEN ARRAY_AGG(tt.tag_name ORDER BY tt.tag_name)
+       ELSE '{}'::text[]
+    END AS tags,
+    -- Threads pinned everywhere or for one of the searched tags are pinned.
+    EXISTS (
+        SELECT 1 FROM thread_pins tp
+        WHERE tp.thread_id = t.id
+        AND (tp.tag_name = '' OR tp.tag_name = ANY($3::text[]))
+        AND (tp.expires_time IS NULL OR tp.expires_time > NOW())
+    ) AS is_pinned,
+    EXISTS (
+        SELECT 1 FROM thread_pins tp
+        WHERE tp.thread_id = t.id
+        AND tp.is_announcement
+        AND (tp.expires_time IS NULL OR tp.expires_time > NOW())
+    ) AS is_announcement,
+    -- The vote of the user viewing the thread, 0 if they have not voted or are not logged in.
+    COALESCE((
+        SELECT tv.value FROM thread_votes tv
+        WHERE tv.thread_id = t.id
+        AND tv.username = $4::text
+    ), 0)::integer AS my_vote,
+    EXISTS (
+        SELECT 1 FROM bookmarks b
+        WHERE b.thread_id = t.id
+        AND b.username = $4::text
+    ) AS is_bookmarked,
+    EXISTS (
+        SELECT 1 FROM thread_subscriptions ts
+        WHERE ts.thread_id = t.id
+        AND ts.username = $4::text
+        AND NOT ts.is_muted
+    ) AS is_subscribed
+FROM threads t
+LEFT JOIN thread_tags tt ON t.id = tt.thread_id
+WHERE
+    t.deleted_time IS NULL
+AND
+    -- Handle the case where the keyword is empty (NULL).
+    CASE
+        WHEN LENGTH($5::text) > 0 THEN TO_TSVECTOR('simple', t.title || ' ' || t.body) @@ TO_TSQUERY
+('simple', $5::text)
+        ELSE TRUE
+    END
+AND
+    -- Handle the case where the tag array is empty.
+    CASE
+        WHEN ARRAY_LENGTH($3::text[], 1) > 0 THEN t.id IN (
+            SELECT tt.thread_id
+            FROM thread_tags tt
+            WHERE tt.tag_name = ANY($3::text[])
+            GROUP BY tt.thread_id
+            HAVING COUNT(DISTINCT tt.tag_name) = ARRAY_LENGTH($3::text[], 1)
+        )
+        ELSE TRUE
+    END
+AND
+    -- Handle the case where the creator is empty.
+    CASE
+        WHEN LENGTH($6::text) > 0 THEN t.creator = (
+            SELECT u.username FROM users u WHERE u.canonical_username = $6::text
+        )
+        ELSE TRUE
+    END
+AND
+    -- Handle the case where the category is empty, otherwise include the threads of its subcategories.
+    CASE
+        WHEN LENGTH($7::text) > 0 THEN t.category_id IN (SELECT s.id FROM subcategories s)
+        ELSE TRUE
+    END
+GROUP BY t.id
+ORDER BY
+    -- Announcements and pinned threads are always returned first.
+    is_announcement DESC,
+    is_pinned DESC,
+    CASE WHEN $8::text = 'created_time_asc' THEN created_time END ASC,
+    CASE WHEN $8::text = 'created_time_desc' THEN created_time END DESC,
+    CASE WHEN $8::text = 'num_comments_asc' THEN num_comments END ASC,
+    CASE WHEN $8::text = 'num_comments_desc' THEN num_comments END DESC,
+    CASE WHEN $8::text = 'score_desc' THEN score END DESC,
+    CASE WHEN $8::text = 'hot_desc' THEN hot_score END DESC,
+    CASE WHEN $8::text = 'trending_desc' THEN trending_score END DESC,
+    CASE WHEN $8::text = 'views_desc' THEN view_count END DESC
+LIMIT $1
+OFFSET $2
+`
+
+type GetThreadsByCriteriaParams struct {
+	Limit     int32    `json:"limit"`
+	Offset    int32    `json:"offset"`
+	Tagarray  []string `json:"tagarray"`
+	Viewer    string   `json:"viewer"`
+	Keywords  string   `json:"keywords"`
+	Creator   string   `json:"creator"`
+	Category  string   `json:"category"`
+	Sortorder string   `json:"sortorder"`
+}
+
+type GetThreadsByCriteriaRow struct {
+	ID              pgtype.UUID        `json:"id"`
+	Title           string             `json:"title"`
+	Body            string             `json:"body"`
+	Creator         string             `json:"creator"`
+	CreatedTime     pgtype.Timestamptz `json:"created_time"`
+	UpdatedTime     pgtype.Timestamptz `json:"updated_time"`
+	NumComments     int32              `json:"num_comments"`
+	NumRevisions    int32              `json:"num_revisions"`
+	IsLocked        bool               `json:"is_locked"`
+	LockReason      pgtype.Text        `json:"lock_reason"`
+	Score           int32              `json:"score"`
+	ViewCount       int32              `json:"view_count"`
+	BodyHtml        string             `json:"body_html"`
+	BodyHtmlVersion int32              `json:"body_html_version"`
+	CategoryID      pgtype.UUID        `json:"category_id"`
+	Tags            []string           `json:"tags"`
+	IsPinned        bool               `json:"is_pinned"`
+	IsAnnouncement  bool               `json:"is_announcement"`
+	MyVote          int32              `json:"my_vote"`
+	IsBookmarked    bool               `json:"is_bookmarked"`
+	IsSubscribed    bool               `json:"is_subscribed"`
+}
+
+// Returns the threads that match the keywords, tags and creator.
+// If the keyword is provided, only threads that match all the keywords will be returned.
+// If the tags are provided, only threads that match all the tags will be returned.
+// If the canonical username of a creator is provided, only threads created by that user will be returned.
+// If the slug of a category is provided, only threads in that category or its subcategories will be returned.
+// Announcements and pinned threads are returned first, regardless of the sort order. Deleted threads are not returned.
+func (q *Queries) GetThreadsByCriteria(ctx context.Context, arg GetThreadsByCriteriaParams) ([]GetThreadsByCriteriaRow, error) {
+	rows, err := q.db.Query(ctx, getThreadsByCriteria,
+		arg.Limit,
+		arg.Offset,
+		arg.Tagarray,
+		arg.Viewer,
+		arg.Keywords,
+		arg.Creator,
+		arg.Category,
+		arg.Sortorder,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetThreadsByCriteriaRow{}
+	for rows.Next() {
+		var i GetThreadsByCriteriaRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Body,
+			&i.Creator,
+			&i.CreatedTime,
+			&i.UpdatedTime,
+			&i.NumComments,
+			&i.NumRevisions,
+			&i.IsLocked,
+			&i.LockReason,
+			&i.Score,
+			&i.ViewCount,
+			&i.BodyHtml,
+			&i.BodyHtmlVersion,
+			&i.CategoryID,
+			&i.Tags,
+			&i.IsPinned,
+			&i.IsAnnouncement,
+			&i.MyVote,
+			&i.IsBookmarked,
+			&i.IsSubscribed,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getThreadsByCriteriaCount = `-- name: GetThreadsByCriteriaCount :one
+WITH RECURSIVE subcategories AS (
+    -- The category with the given slug and all of its subcategories.
+    SELECT c.id FROM categories c WHERE c.slug = $4::text
+    UNION
+    SELECT c.id FROM categories c JOIN subcategories s ON c.parent_id = s.id
+)
+SELECT COUNT(*) AS total_items
+FROM threads t
+WHERE
+    t.deleted_time IS NULL
+  AND
+    CASE
+        WHEN LENGTH($1::text) > 0 THEN TO_TSVECTOR('simple', t.title || ' ' || t.body) @@ TO_TSQUERY('simple', $1::text)
+        ELSE TRUE
+    END
+  AND
+    CASE
+        WHEN ARRAY_LENGTH($2::text[], 1) > 0 THEN t.id IN (
+          SELECT tt.thread_id
+          FROM thread_tags tt
+          WHERE tt.tag_name = ANY($2::text[])
+          GROUP BY tt.thread_id
+          HAVING COUNT(DISTINCT tt.tag_name) = ARRAY_LENGTH($2::text[], 1)
+        )
+        ELSE TRUE
+    END
+  AND
+    CASE
+        WHEN LENGTH($3::text) > 0 THEN t.creator = (
+            SELECT u.username FROM users u WHERE u.canonical_username = $3::text
+        )
+        ELSE TRUE
+    END
+  AND
+    CASE
+        WHEN LENGTH($4::text) > 0 THEN t.category_id IN (SELECT s.id FROM subcategories s)
+        ELSE TRUE
+    END
+`
+
+type GetThreadsByCriteriaCountParams struct {
+	Keywords string   `json:"keywords"`
+	Tagarray []string `json:"tagarray"`
+	Creator  string   `json:"creator"`
+	Category string   `json:"category"`
+}
+
+// Counts the total number of threads that match the keywords, tags, creator and category.
+func (q *Queries) GetThreadsByCriteriaCount(ctx context.Context, arg GetThreadsByCriteriaCountParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getThreadsByCriteriaCount,
+		arg.Keywords,
+		arg.Tagarray,
+		arg.Creator,
+		arg.Category,
+	)
+	var total_items int64
+	err := row.Scan(&total_items)
+	return total_items, err
+}
+
+const getThreadsWithPolls = `-- name: GetThreadsWithPolls :many
+SELECT thread_id
+FROM polls
+WHERE thread_id = ANY($1::uuid[])
+`
+
+// Returns the ids of the threads with polls out of the given threads.
+func (q *Queries) GetThreadsWithPolls(ctx context.Context, threadIds []pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, getThreadsWithPolls, threadIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var thread_id pgtype.UUID
+		if err := rows.Scan(&thread_id); err != nil {
+			return nil, err
+		}
+		items = append(items, thread_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnfingerprintedThreads = `-- name: GetUnfingerprintedThreads :many
+SELECT id, body
+FROM threads
+WHERE body_simhash IS NULL
+LIMIT $1
+`
+
+type GetUnfingerprintedThreadsRow struct {
+	ID   pgtype.UUID `json:"id"`
+	Body string      `json:"body"`
+}
+
+// Returns threads whose bodies have not been fingerprinted yet.
+func (q *Queries) GetUnfingerprintedThreads(ctx context.Context, limit int32) ([]GetUnfingerprintedThreadsRow, error) {
+	rows, err := q.db.Query(ctx, getUnfingerprintedThreads, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetUnfingerprintedThreadsRow{}
+	for rows.Next() {
+		var i GetUnfingerprintedThreadsRow
+		if err := rows.Scan(&i.ID, &i.Body); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserEmail = `-- name: GetUserEmail :one
+SELECT email, is_verified
+FROM users
+WHERE username = $1
+`
+
+type GetUserEmailRow struct {
+	Email      pgtype.Text `json:"email"`
+	IsVerified bool        `json:"is_verified"`
+}
+
+// Returns the email of a user and whether it has been verified.
+func (q *Queries) GetUserEmail(ctx context.Context, username string) (GetUserEmailRow, error) {
+	row := q.db.QueryRow(ctx, getUserEmail, username)
+	var i GetUserEmailRow
+	err := row.Scan(&i.Email, &i.IsVerified)
+	return i, err
+}
+
+const getUserProfile = `-- name: GetUserProfile :one
+SELECT u.username,
+    (SELECT COUNT(*) FROM threads t WHERE t.creator = u.username AND t.deleted_time IS NULL) AS num_threads,
+    (SELECT COUNT(*) FROM comments c JOIN threads t ON c.thread_id = t.id
+        WHERE c.creator = u.username AND c.deleted_time IS NULL AND t.deleted_time IS NULL) AS num_comments
+FROM users u
+WHERE u.canonical_username = $1
+`
+
+type GetUserProfileRow struct {
+	Username    string `json:"username"`
+	NumThreads  int64  `json:"num_threads"`
+	NumComments int64  `json:"num_comments"`
+}
+
+// Returns the username of a user and the number of threads and comments they have created that have not been deleted.
+func (q *Queries) GetUserProfile(ctx context.Context, canonicalUsername string) (GetUserProfileRow, error) {
+	row := q.db.QueryRow(ctx, getUserProfile, canonicalUsername)
+	var i GetUserProfileRow
+	err := row.Scan(&i.Username, &i.NumThreads, &i.NumComments)
+	return i, err
+}
+
+const getUserRole = `-- name: GetUserRole :one
+SELECT role
+FROM users
+WHERE username = $1
+`
+
+// Returns the role of the user.
+func (q *Queries) GetUserRole(ctx context.Context, username string) (string, error) {
+	row := q.db.QueryRow(ctx, getUserRole, username)
+	var role string
+	err := row.Scan(&role)
+	return role, err
+}
+
+const getUsernameDetails = `-- name: GetUsernameDetails :one
+SELECT canonical_username, username_skeleton, last_renamed_time
+FROM users
+WHERE username = $1
+`
+
+type GetUsernameDetailsRow struct {
+	CanonicalUsername string             `json:"canonical_username"`
+	UsernameSkeleton  string             `json:"username_skeleton"`
+	LastRenamedTime   pgtype.Timestamptz `json:"last_renamed_time"`
+}
+
+// Returns the current canonical username, username skeleton and the last time the user was renamed.
+func (q *Queries) GetUsernameDetails(ctx context.Context, username string) (GetUsernameDetailsRow, error) {
+	row := q.db.QueryRow(ctx, getUsernameDetails, username)
+	var i GetUsernameDetailsRow
+	err := row.Scan(&i.CanonicalUsername, &i.UsernameSkeleton, &i.LastRenamedTime)
+	return i, err
+}
+
+const getUsernameSkeletons = `-- name: GetUsernameSkeletons :many
+SELECT username, username_skeleton
+FROM users
+WHERE username > $2::text
+ORDER BY username
+LIMIT $1
+`
+
+type GetUsernameSkeletonsParams struct {
+	Limit         int32  `json:"limit"`
+	AfterUsername string `json:"after_username"`
+}
+
+type GetUsernameSkeletonsRow struct {
+	Username         string `json:"username"`
+	UsernameSkeleton string `json:"username_skeleton"`
+}
+
+// Returns the usernames and username skeletons of users ordered by username, starting after the given username.
+func (q *Queries) GetUsernameSkeletons(ctx context.Context, arg GetUsernameSkeletonsParams) ([]GetUsernameSkeletonsRow, error) {
+	rows, err := q.db.Query(ctx, getUsernameSkeletons, arg.Limit, arg.AfterUsername)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetUsernameSkeletonsRow{}
+	for rows.Next() {
+		var i GetUsernameSkeletonsRow
+		if err := rows.Scan(&i.Username, &i.UsernameSkeleton); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isCategoryDescendant = `-- name: IsCategoryDescendant :one
+WITH RECURSIVE ancestors AS (
+    SELECT c.id, c.parent_id FROM categories c WHERE c.id = $2::uuid
+    UNION
+    SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+)
+SELECT EXISTS (
+    SELECT 1 FROM ancestors a WHERE a.id = $1::uuid
+) AS is_descendant
+`
+
+type IsCategoryDescendantParams struct {
+	AncestorID pgtype.UUID `json:"ancestor_id"`
+	CategoryID pgtype.UUID `json:"category_id"`
+}
+
+// Returns 1 if the category with the given id is the given ancestor or one of its subcategories.
+func (q *Queries) IsCategoryDescendant(ctx context.Context, arg IsCategoryDescendantParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isCategoryDescendant, arg.AncestorID, arg.CategoryID)
+	var is_descendant bool
+	err := row.Scan(&is_descendant)
+	return is_descendant, err
+}
+
+const lockThread = `-- name: LockThread :exec
+UPDATE threads
+SET is_locked = TRUE, lock_reason = NULLIF($1::text, ''), locked_time = NOW(), locked_by = $2::text
+WHERE id = $3
+`
+
+type LockThreadParams struct {
+	LockReason string      `json:"lock_reason"`
+	LockedBy   string      `json:"locked_by"`
+	ID         pgtype.UUID `json:"id"`
+}
+
+// Locks the thread with the given id so that no new comments can be added. An empty reason is stored as NULL.
+func (q *Queries) LockThread(ctx context.Context, arg LockThreadParams) error {
+	_, err := q.db.Exec(ctx, lockThread, arg.LockReason, arg.LockedBy, arg.ID)
+	return err
+}
+
+const lockThreadsForMove = `-- name: LockThreadsForMove :many
+SELECT id, title
+FROM threads
+WHERE id = ANY($1::uuid[])
+AND deleted_time IS NULL
+ORDER BY id
+FOR UPDATE
+`
+
+type LockThreadsForMoveRow struct {
+	ID    pgtype.UUID `json:"id"`
+	Title string      `json:"title"`
+}
+
+// Locks the given threads until the end of the transaction so that they do not change while comments are moved
+// between them. Threads are locked in order of their ids to avoid deadlocks. Deleted threads are not returned.
+func (q *Queries) LockThreadsForMove(ctx context.Context, threadIds []pgtype.UUID) ([]LockThreadsForMoveRow, error) {
+	rows, err := q.db.Query(ctx, lockThreadsForMove, threadIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LockThreadsForMoveRow{}
+	for rows.Next() {
+		var i LockThreadsForMoveRow
+		if err := rows.Scan(&i.ID, &i.Title); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markAllNotificationsRead = `-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications
+SET is_read = TRUE
+WHERE username = $1
+AND NOT is_read
+`
+
+// Marks all notifications of a user as read.
+func (q *Queries) MarkAllNotificationsRead(ctx context.Context, username string) (int64, error) {
+	result, err := q.db.Exec(ctx, markAllNotificationsRead, username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const markNotificationRead = `-- name: MarkNotificationRead :execrows
+UPDATE notifications
+SET is_read = TRUE
+WHERE id = $1
+AND username = $2::text
+`
+
+type MarkNotificationReadParams struct {
+	ID       pgtype.UUID `json:"id"`
+	Username string      `json:"username"`
+}
+
+// Marks a notification of a user as read.
+func (q *Queries) MarkNotificationRead(ctx context.Context, arg MarkNotificationReadParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markNotificationRead, arg.ID, arg.Username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const moveCommentToThread = `-- name: MoveCommentToThread :one
+WITH first_comment AS (
+    SELECT c.id, c.body, c.body_html, c.body_html_version, c.creator, c.created_time, c.updated_time
+    FROM comments c
+    WHERE c.id = ANY($1::uuid[])
+    AND c.thread_id = $2::uuid
+    AND c.deleted_time IS NULL
+    ORDER BY c.created_time, c.id
+    LIMIT 1
+),
+new_thread AS (
+    INSERT INTO threads (title, body, body_html, body_html_version, creator, category_id, created_time, updated_time)
+    SELECT $3::text, fc.body, fc.body_html, fc.body_html_version, fc.creator,
+        (SELECT t.category_id FROM threads t WHERE t.id = $2::uuid), fc.created_time, fc.updated_time
+    FROM first_comment fc
+    RETURNING id
+),
+moved_votes AS (
+    INSERT INTO thread_votes (thread_id, username, value, created_time, updated_time)
+    SELECT nt.id, cv.username, cv.value, cv.created_time, cv.updated_time
+    FROM comment_votes cv, first_comment fc, new_thread nt
+    WHERE cv.comment_id = fc.id
+),
+moved_revisions AS (
+    INSERT INTO thread_revisions (thread_id, revision_number, title, body, editor, edited_time)
+    SELECT nt.id, cr.revision_number, $3::text, cr.body, cr.editor, cr.edited_time
+    FROM comment_revisions cr, first_comment fc, new_thread nt
+    WHERE cr.comment_id = fc.id
+),
+moved_attachments AS (
+    UPDATE attachments a
+    SET thread_id = nt.id, comment_id = NULL
+    FROM first_comment fc, new_thread nt
+    WHERE a.comment_id = fc.id
+),
+moved_mentions AS (
+    INSERT INTO thread_mentions (thread_id, username, is_current, created_time)
+    SELECT nt.id, cm.username, cm.is_current, cm.created_time
+    FROM comment_mentions cm, first_comment fc, new_thread nt
+    WHERE cm.comment_id = fc.id
+),
+moved_bookmarks AS (
+    UPDATE bookmarks b
+    SET thread_id = nt.id, comment_id = NULL
+    FROM first_comment fc, new_thread nt
+    WHERE b.comment_id = fc.id
+),
+moved_notifications AS (
+    UPDATE notifications n
+    SET thread_id = nt.id, comment_id = NULL
+    FROM first_comment fc, new_thread nt
+    WHERE n.comment_id = fc.id
+)
+SELECT nt.id AS thread_id, fc.id AS comment_id
+FROM new_thread nt, first_comment fc
+`
+
+type MoveCommentToThreadParams struct {
+	CommentIds []pgtype.UUID `json:"comment_ids"`
+	ThreadID   pgtype.UUID   `json:"thread_id"`
+	Title      string        `json:"title"`
+}
+
+type MoveCommentToThreadRow struct {
+	ThreadID  pgtype.UUID `json:"thread_id"`
+	CommentID pgtype.UUID `json:"comment_id"`
+}
+
+// Turns the earliest of the given comments into a new thread with the given title when comments are split out of a
+// thread, together with its votes, revisions, attachments, mentions, bookmarks and notifications. The thread keeps the
+// creator and creation time of the comment. The comment itself is left to be deleted with DeleteMovedComment.
+// Returns the ids of the thread and of the comment.
+func (q *Queries) MoveCommentToThread(ctx context.Context, arg MoveCommentToThreadParams) (MoveCommentToThreadRow, error) {
+	row := q.db.QueryRow(ctx, moveCommentToThread, arg.CommentIds, arg.ThreadID, arg.Title)
+	var i MoveCommentToThreadRow
+	err := row.Scan(&i.ThreadID, &i.CommentID)
+	return i, err
+}
+
+const moveComments = `-- name: MoveComments :one
+WITH moved_comments AS (
+    UPDATE comments c
+    SET thread_id = $1::uuid
+    WHERE c.thread_id = $2::uuid
+    AND ($3::uuid[] IS NULL OR c.id = ANY($3::uuid[]))
+    RETURNING c.id, c.creator
+),
+moved_notifications AS (
+    UPDATE notifications n
+    SET thread_id = $1::uuid
+    FROM moved_comments mc
+    WHERE n.comment_id = mc.id
+),
+new_subscriptions AS (
+    INSERT INTO thread_subscriptions (thread_id, username)
+    SELECT DISTINCT $1::uuid, mc.creator
+    FROM moved_comments mc
+    ON CONFLICT (thread_id, username) DO NOTHING
+)
+SELECT COUNT(*)::integer FROM moved_comments
+`
+
+type MoveCommentsParams struct {
+	TargetThreadID pgtype.UUID   `json:"target_thread_id"`
+	ThreadID       pgtype.UUID   `json:"thread_id"`
+	CommentIds     []pgtype.UUID `json:"comment_ids"`
+}
+
+// Moves comments of a thread to another thread, or all of its comments if no comment ids are given. Notifications
+// about the comments are moved with them and their creators are subscribed to the other thread unless they have
+// muted it. The number of comments of both threads is updated by the comment trigger.
+// Returns the number of comments moved.
+func (q *Queries) MoveComments(ctx context.Context, arg MoveCommentsParams) (int32, error) {
+	row := q.db.QueryRow(ctx, moveComments, arg.TargetThreadID, arg.ThreadID, arg.CommentIds)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const movePoll = `-- name: MovePoll :exec
+WITH moved_poll AS (
+    INSERT INTO polls (thread_id, is_multiple_choice, is_anonymous, close_time, created_time)
+    SELECT $2::uuid, p.is_multiple_choice, p.is_anonymous, p.close_time, p.created_time
+    FROM polls p
+    WHERE p.thread_id = $1::uuid
+    RETURNING thread_id
+),
+moved_options AS (
+    INSERT INTO poll_options (thread_id, position, text)
+    SELECT mp.thread_id, po.position, po.text
+    FROM poll_options po
+    CROSS JOIN moved_poll mp
+    WHERE po.thread_id = $1::uuid
+)
+INSERT INTO poll_votes (thread_id, position, username, created_time)
+SELECT mp.thread_id, pv.position, pv.username, pv.created_time
+FROM poll_votes pv
+CROSS JOIN moved_poll mp
+WHERE pv.thread_id = $1::uuid
+`
+
+type MovePollParams struct {
+	ThreadID       pgtype.UUID `json:"thread_id"`
+	TargetThreadID pgtype.UUID `json:"target_thread_id"`
+}
+
+// Moves the poll of a thread, with its options and votes, to the thread it is merged into, which must not have a poll.
+// Does nothing if the thread has no poll. The poll of the thread is deleted together with the thread.
+func (q *Queries) MovePoll(ctx context.Context, arg MovePollParams) error {
+	_, err := q.db.Exec(ctx, movePoll, arg.ThreadID, arg.TargetThreadID)
+	return err
+}
+
+const moveThreadFollowers = `-- name: MoveThreadFollowers :exec
+WITH moved_subscriptions AS (
+    INSERT INTO thread_subscriptions (thread_id, username, is_muted, created_time)
+    SELECT $1::uuid, ts.username, ts.is_muted, ts.created_time
+    FROM thread_subscriptions ts
+    WHERE ts.thread_id = $3::uuid
+    ON CONFLICT (thread_id, username) DO NOTHING
+),
+moved_bookmarks AS (
+    INSERT INTO bookmarks (username, thread_id, folder, note, created_time)
+    SELECT b.username, $1::uuid, b.folder, b.note, b.created_time
+    FROM bookmarks b
+    WHERE b.thread_id = $3::uuid
+    ON CONFLICT DO NOTHING
+)
+UPDATE notifications
+SET thread_id = $1::uuid, comment_id = COALESCE(comment_id, $2::uuid)
+WHERE thread_id = $3::uuid
+`
+
+type MoveThreadFollowersParams struct {
+	TargetThreadID pgtype.UUID `json:"target_thread_id"`
+	CommentID      pgtype.UUID `json:"comment_id"`
+	ThreadID       pgtype.UUID `json:"thread_id"`
+}
+
+// Moves the subscriptions, bookmarks and notifications of a thread to the thread it is merged into. Users who are
+// subscribed to or have bookmarked both threads keep their subscription or bookmark of the thread merged into.
+// Notifications about the opening post of the merged thread are moved to the comment it was turned into.
+func (q *Queries) MoveThreadFollowers(ctx context.Context, arg MoveThreadFollowersParams) error {
+	_, err := q.db.Exec(ctx, moveThreadFollowers, arg.TargetThreadID, arg.CommentID, arg.ThreadID)
+	return err
+}
+
+const moveThreadPostToComment = `-- name: MoveThreadPostToComment :one
+WITH new_comment AS (
+    INSERT INTO comments (body, body_html, body_html_version, creator, thread_id, created_time, updated_time)
+    SELECT t.body, t.body_html, t.body_html_version, t.creator, $1::uuid, t.created_time, t.updated_time
+    FROM threads t
+    WHERE t.id = $2::uuid
+    RETURNING id
+),
+moved_votes AS (
+    INSERT INTO comment_votes (comment_id, username, value, created_time, updated_time)
+    SELECT nc.id, tv.username, tv.value, tv.created_time, tv.updated_time
+    FROM thread_votes tv, new_comment nc
+    WHERE tv.thread_id = $2::uuid
+),
+moved_revisions AS (
+    INSERT INTO comment_revisions (comment_id, revision_number, body, editor, edited_time)
+    SELECT nc.id, tr.revision_number, tr.body, tr.editor, tr.edited_time
+    FROM thread_revisions tr, new_comment nc
+    WHERE tr.thread_id = $2::uuid
+),
+moved_attachments AS (
+    UPDATE attachments a
+    SET thread_id = NULL, comment_id = nc.id
+    FROM new_comment nc
+    WHERE a.thread_id = $2::uuid
+),
+moved_mentions AS (
+    INSERT INTO comment_mentions (comment_id, username, is_current, created_time)
+    SELECT nc.id, tm.username, tm.is_current, tm.created_time
+    FROM thread_mentions tm, new_comment nc
+    WHERE tm.thread_id = $2::uuid
+)
+SELECT id FROM new_comment
+`
+
+type MoveThreadPostToCommentParams struct {
+	TargetThreadID pgtype.UUID `json:"target_thread_id"`
+	ThreadID       pgtype.UUID `json:"thread_id"`
+}
+
+// Turns the opening post of a thread into a comment on the thread it is merged into, together with its votes,
+// revisions, attachments and mentions. The comment keeps the creator and creation time of the opening post.
+// Returns the id of the comment.
+func (q *Queries) MoveThreadPostToComment(ctx context.Context, arg MoveThreadPostToCommentParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, moveThreadPostToComment, arg.TargetThreadID, arg.ThreadID)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const moveThreadTagsAndPins = `-- name: MoveThreadTagsAndPins :exec
+WITH moved_tags AS (
+    INSERT INTO thread_tags (thread_id, tag_name)
+    SELECT $1::uuid, tt.tag_name
+    FROM thread_tags tt
+    WHERE tt.thread_id = $2::uuid
+    ON CONFLICT (thread_id, tag_name) DO NOTHING
+)
+INSERT INTO thread_pins (thread_id, tag_name, is_announcement, pinned_by, pinned_time, expires_time)
+SELECT $1::uuid, tp.tag_name, tp.is_announcement, tp.pinned_by, tp.pinned_time, tp.expires_time
+FROM thread_pins tp
+WHERE tp.thread_id = $2::uuid
+ON CONFLICT (thread_id, tag_name) DO NOTHING
+`
+
+type MoveThreadTagsAndPinsParams struct {
+	TargetThreadID pgtype.UUID `json:"target_thread_id"`
+	ThreadID       pgtype.UUID `json:"thread_id"`
+}
+
+// Adds the tags and pins of a thread to the thread it is merged into. If both threads are pinned for the same tag,
+// the pin of the thread merged into is kept.
+func (q *Queries) MoveThreadTagsAndPins(ctx context.Context, arg MoveThreadTagsAndPinsParams) error {
+	_, err := q.db.Exec(ctx, moveThreadTagsAndPins, arg.TargetThreadID, arg.ThreadID)
+	return err
+}
+
+const moveThreadToCategory = `-- name: MoveThreadToCategory :one
+UPDATE threads
+SET category_id = $1::uuid
+WHERE id = $2::uuid
+AND deleted_time IS NULL
+RETURNING id
+`
+
+type MoveThreadToCategoryParams struct {
+	CategoryID pgtype.UUID `json:"category_id"`
+	ID         pgtype.UUID `json:"id"`
+}
+
+// Moves a thread to another category. Deleted threads are not moved. Returns the id of the thread.
+func (q *Queries) MoveThreadToCategory(ctx context.Context, arg MoveThreadToCategoryParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, moveThreadToCategory, arg.CategoryID, arg.ID)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const pinThread = `-- name: PinThread :exec
+INSERT INTO thread_pins (thread_id, tag_name, is_announcement, pinned_by, expires_time)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (thread_id, tag_name) DO UPDATE
+SET is_announcement = EXCLUDED.is_announcement,
+    pinned_by = EXCLUDED.pinned_by,
+    pinned_time = NOW(),
+    expires_time = EXCLUDED.expires_time
+`
+
+type PinThreadParams struct {
+	ThreadID       pgtype.UUID        `json:"thread_id"`
+	TagName        string             `json:"tag_name"`
+	IsAnnouncement bool               `json:"is_announcement"`
+	PinnedBy       pgtype.Text        `json:"pinned_by"`
+	ExpiresTime    pgtype.Timestamptz `json:"expires_time"`
+}
+
+// Pins a thread everywhere, or only for the given tag. Pinning a thread again replaces the previous pin.
+func (q *Queries) PinThread(ctx context.Context, arg PinThreadParams) error {
+	_, err := q.db.Exec(ctx, pinThread,
+		arg.ThreadID,
+		arg.TagName,
+		arg.IsAnnouncement,
+		arg.PinnedBy,
+		arg.ExpiresTime,
+	)
+	return err
+}
+
+const publishScheduledThread = `-- name: PublishScheduledThread :one
+WITH due_thread AS (
+    DELETE FROM scheduled_threads st
+    WHERE st.id = $1
+    AND st.publish_time <= NOW()
+    RETURNING st.id, st.title, st.body, st.creator, st.category_id, st.publish_time
+)
+INSERT INTO threads (id, title, body, body_html, body_html_version, body_simhash, creator, category_id, created_time,
+    updated_time)
+SELECT dt.id, dt.title, dt.body, $2::text, $3::integer, $4::bigint, dt.creator,
+    dt.category_id, dt.publish_time, dt.publish_time
+FROM due_thread dt
+RETURNING threads.id
+`
+
+type PublishScheduledThreadParams struct {
+	ID              pgtype.UUID `json:"id"`
+	BodyHtml        string      `json:"body_html"`
+	BodyHtmlVersion int32       `json:"body_html_version"`
+	BodySimhash     int64       `json:"body_simhash"`
+}
+
+// Publishes a scheduled thread whose publish time has passed, keeping its ID.
+// The thread is created at its publish time, which is not in the future. Returns the ID of the thread.
+func (q *Queries) PublishScheduledThread(ctx context.Context, arg PublishScheduledThreadParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, publishScheduledThread,
+		arg.ID,
+		arg.BodyHtml,
+		arg.BodyHtmlVersion,
+		arg.BodySimhash,
+	)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const purgeDeletedComments = `-- name: PurgeDeletedComments :execrows
+DELETE FROM comments
+WHERE deleted_time < NOW() - MAKE_INTERVAL(days => $1::integer)
+`
+
+// Permanently deletes comments that were deleted more than the given number of days ago.
+func (q *Queries) PurgeDeletedComments(ctx context.Context, retentionDays int32) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedComments, retentionDays)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeDeletedThreads = `-- name: PurgeDeletedThreads :execrows
+DELETE FROM threads
+WHERE deleted_time < NOW() - MAKE_INTERVAL(days => $1::integer)
+`
+
+// Permanently deletes threads that were deleted more than the given number of days ago.
+func (q *Queries) PurgeDeletedThreads(ctx context.Context, retentionDays int32) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedThreads, retentionDays)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const recordThreadViews = `-- name: RecordThreadViews :execrows
+WITH counted_views AS (
+    INSERT INTO thread_views (thread_id, viewer, viewed_time)
+    SELECT v.thread_id, v.viewer, v.viewed_time
+    FROM (
+        -- Arrays of the same length are unnested in parallel.
+        SELECT UNNEST($1::uuid[]) AS thread_id,
+            UNNEST($2::text[]) AS viewer,
+            UNNEST($3::timestamptz[]) AS viewed_time
+    ) v
+    JOIN threads t ON t.id = v.thread_id
+    WHERE t.deleted_time IS NULL
+    ON CONFLICT (thread_id, viewer) DO UPDATE
+    SET viewed_time = EXCLUDED.viewed_time
+    WHERE thread_views.viewed_time <= EXCLUDED.viewed_time - MAKE_INTERVAL(mins => $4::integer)
+    RETURNING thread_id
+)
+UPDATE threads t
+SET view_count = t.view_count + cv.num_views
+FROM (
+    SELECT thread_id, COUNT(*) AS num_views
+    FROM counted_views
+    GROUP BY thread_id
+) cv
+WHERE t.id = cv.thread_id
+`
+
+type RecordThreadViewsParams struct {
+	ThreadIds     []pgtype.UUID        `json:"thread_ids"`
+	Viewers       []string             `json:"viewers"`
+	ViewedTimes   []pgtype.Timestamptz `json:"viewed_times"`
+	WindowMinutes int32                `json:"window_minutes"`
+}
+
+// Records a batch of thread views and adds them to the view counts of the threads. A view is only counted if the
+// viewer has not viewed the thread within the last window_minutes minutes. Each viewer must appear at most once per
+// thread in the batch. Returns the number of threads whose view count changed.
+func (q *Queries) RecordThreadViews(ctx context.Context, arg RecordThreadViewsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, recordThreadViews,
+		arg.ThreadIds,
+		arg.Viewers,
+		arg.ViewedTimes,
+		arg.WindowMinutes,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const redeemInviteCode = `-- name: RedeemInviteCode :one
+UPDATE invite_codes
+SET num_uses = num_uses + 1
+WHERE code = $1
+AND num_uses < max_uses
+AND (expires_time IS NULL OR expires_time > NOW())
+RETURNING code
+`
+
+// Uses up one use of an invite code if it is still valid. Returns the code if successful.
+func (q *Queries) RedeemInviteCode(ctx context.Context, code string) (string, error) {
+	row := q.db.QueryRow(ctx, redeemInviteCode, code)
+	err := row.Scan(&code)
+	return code, err
+}
+
+const refreshThreadRankings = `-- name: RefreshThreadRankings :execrows
+UPDATE threads t
+SET hot_score = r.hot_score, trending_score = r.trending_score
+FROM (
+    SELECT a.id,
+        CASE
+            WHEN a.last_activity_time > NOW() - INTERVAL '7 days' THEN
+                (GREATEST(a.score + a.num_comments, 0) + 1)::double precision /
+                POWER(EXTRACT(EPOCH FROM NOW() - a.last_activity_time) / 3600 + 2, 1.5)
+            ELSE 0
+        END AS hot_score,
+        (a.num_recent_comments + a.recent_votes)::double precision AS trending_score
+    FROM (
+        SELECT t.id, t.score, t.num_comments,
+            GREATEST(t.created_time, MAX(c.created_time)) AS last_activity_time,
+            COUNT(c.id) FILTER (
+                WHERE c.created_time > NOW() - MAKE_INTERVAL(hours => $1::integer)
+            ) AS num_recent_comments,
+            COALESCE((
+                SELECT SUM(tv.value) FROM thread_votes tv
+                WHERE tv.thread_id = t.id
+                AND tv.updated_time > NOW() - MAKE_INTERVAL(hours => $1::integer)
+            ), 0) AS recent_votes
+        FROM threads t
+        LEFT JOIN comments c ON c.thread_id = t.id AND c.deleted_time IS NULL
+        WHERE t.deleted_time IS NULL
+        GROUP BY t.id
+    ) a
+) r
+WHERE t.id = r.id
+AND (t.hot_score <> r.hot_score OR t.trending_score <> r.trending_score)
+`
+
+// Recomputes the ranking scores of all threads that have not been deleted. Returns the number of threads updated.
+// The hot score decays with the time since the last comment, so threads without activity for a week are no longer hot.
+// The trending score counts the comments and net votes within the last trending_hours hours.
+func (q *Queries) RefreshThreadRankings(ctx context.Context, trendingHours int32) (int64, error) {
+	result, err := q.db.Exec(ctx, refreshThreadRankings, trendingHours)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const renameUser = `-- name: RenameUser :exec
+UPDATE users
+SET username = $1::text,
+    canonical_username = $2::text,
+    username_skeleton = $3::text,
+    last_renamed_time = NOW()
+WHERE username = $4::text
+`
+
+type RenameUserParams struct {
+	NewUsername       string `json:"new_username"`
+	CanonicalUsername string `json:"canonical_username"`
+	UsernameSkeleton  string `json:"username_skeleton"`
+	OldUsername       string `json:"old_username"`
+}
+
+// Renames a user. References to the old username in other tables are updated by ON UPDATE CASCADE.
+func (q *Queries) RenameUser(ctx context.Context, arg RenameUserParams) error {
+	_, err := q.db.Exec(ctx, renameUser,
+		arg.NewUsername,
+		arg.CanonicalUsername,
+		arg.UsernameSkeleton,
+		arg.OldUsername,
+	)
+	return err
+}
+
+const rescheduleThread = `-- name: RescheduleThread :one
+UPDATE scheduled_threads
+SET publish_time = $2::timestamptz
+WHERE id = $1
+AND creator = $3::text
+RETURNING id, title, body, creator, tags, poll, attachment_ids, category_id, publish_time, created_time
+`
+
+type RescheduleThreadParams struct {
+	ID          pgtype.UUID        `json:"id"`
+	PublishTime pgtype.Timestamptz `json:"publish_time"`
+	Creator     string             `json:"creator"`
+}
+
+// Changes the publish time of a thread scheduled by a user. Returns the scheduled thread.
+func (q *Queries) RescheduleThread(ctx context.Context, arg RescheduleThreadParams) (ScheduledThread, error) {
+	row := q.db.QueryRow(ctx, rescheduleThread, arg.ID, arg.PublishTime, arg.Creator)
+	var i ScheduledThread
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Body,
+		&i.Creator,
+		&i.Tags,
+		&i.Poll,
+		&i.AttachmentIds,
+		&i.CategoryID,
+		&i.PublishTime,
+		&i.CreatedTime,
+	)
+	return i, err
+}
+
+const restoreComment = `-- name: RestoreComment :exec
+UPDATE comments
+SET deleted_time = NULL, deleted_by = NULL
+WHERE id = $1
+`
+
+// Restores the deleted comment with the given id.
+func (q *Queries) RestoreComment(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, restoreComment, id)
+	return err
 }
 
-type GetThreadsByCriteriaRow struct {
-	ID          pgtype.UUID        `json:"id"`
+const restoreThread = `-- name: RestoreThread :exec
+UPDATE threads
+SET deleted_time = NULL, deleted_by = NULL
+WHERE id = $1
+`
+
+// Restores the deleted thread with the given id.
+func (q *Queries) RestoreThread(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, restoreThread, id)
+	return err
+}
+
+const saveDraft = `-- name: SaveDraft :one
+INSERT INTO drafts (username, context, title, body, tags)
+VALUES ($1::text, $2::text, $3::text, $4::text, $5::text[])
+ON CONFLICT (username, context) DO UPDATE
+SET title = EXCLUDED.title, body = EXCLUDED.body, tags = EXCLUDED.tags, updated_time = NOW()
+RETURNING context, title, body, tags, created_time, updated_time
+`
+
+type SaveDraftParams struct {
+	Username string   `json:"username"`
+	Context  string   `json:"context"`
+	Title    string   `json:"title"`
+	Body     string   `json:"body"`
+	Tagarray []string `json:"tagarray"`
+}
+
+type SaveDraftRow struct {
+	Context     string             `json:"context"`
 	Title       string             `json:"title"`
 	Body        string             `json:"body"`
-	Creator     string             `json:"creator"`
+	Tags        []string           `json:"tags"`
 	CreatedTime pgtype.Timestamptz `json:"created_time"`
 	UpdatedTime pgtype.Timestamptz `json:"updated_time"`
-	NumComments int32              `json:"num_comments"`
-	Tags        []string           `json:"tags"`
 }
 
-// Returns the threads that match the keywords and tags.
-// If the keyword is provided, only threads that match all the keywords will be returned.
-// If the tags are provided, only threads that match all the tags will be returned.
-func (q *Queries) GetThreadsByCriteria(ctx context.Context, arg GetThreadsByCriteriaParams) ([]GetThreadsByCriteriaRow, error) {
-	rows, err := q.db.Query(ctx, getThreadsByCriteria,
-		arg.Limit,
-		arg.Offset,
-		arg.Keywords,
+// Saves a draft of a user, replacing any previous draft for the same context. Returns the saved draft.
+func (q *Queries) SaveDraft(ctx context.Context, arg SaveDraftParams) (SaveDraftRow, error) {
+	row := q.db.QueryRow(ctx, saveDraft,
+		arg.Username,
+		arg.Context,
+		arg.Title,
+		arg.Body,
 		arg.Tagarray,
-		arg.Sortorder,
+	)
+	var i SaveDraftRow
+	err := row.Scan(
+		&i.Context,
+		&i.Title,
+		&i.Body,
+		&i.Tags,
+		&i.CreatedTime,
+		&i.UpdatedTime,
+	)
+	return i, err
+}
+
+const setCommentBodyHtml = `-- name: SetCommentBodyHtml :exec
+UPDATE comments
+SET body_html = $1::text, body_html_version = $2::integer
+WHERE id = $3
+AND body = $4::text
+`
+
+type SetCommentBodyHtmlParams struct {
+	BodyHtml        string      `json:"body_html"`
+	BodyHtmlVersion int32       `json:"body_html_version"`
+	ID              pgtype.UUID `json:"id"`
+	Body            string      `json:"body"`
+}
+
+// Caches the HTML of the body of a comment, unless the body has changed since it was rendered.
+func (q *Queries) SetCommentBodyHtml(ctx context.Context, arg SetCommentBodyHtmlParams) error {
+	_, err := q.db.Exec(ctx, setCommentBodyHtml,
+		arg.BodyHtml,
+		arg.BodyHtmlVersion,
+		arg.ID,
+		arg.Body,
+	)
+	return err
+}
+
+const setCommentBookmark = `-- name: SetCommentBookmark :execrows
+INSERT INTO bookmarks (username, comment_id, folder, note)
+SELECT $1::text, c.id, $2::text, $3::text
+FROM comments c
+JOIN threads t ON c.thread_id = t.id
+WHERE c.id = $4
+AND c.deleted_time IS NULL
+AND t.deleted_time IS NULL
+ON CONFLICT (username, comment_id) WHERE comment_id IS NOT NULL DO UPDATE
+SET folder = EXCLUDED.folder, note = EXCLUDED.note
+`
+
+type SetCommentBookmarkParams struct {
+	Username  string      `json:"username"`
+	Folder    string      `json:"folder"`
+	Note      string      `json:"note"`
+	CommentID pgtype.UUID `json:"comment_id"`
+}
+
+// Bookmarks a comment that has not been deleted for a user, or updates the folder and note of an existing bookmark.
+func (q *Queries) SetCommentBookmark(ctx context.Context, arg SetCommentBookmarkParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setCommentBookmark,
+		arg.Username,
+		arg.Folder,
+		arg.Note,
+		arg.CommentID,
 	)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	defer rows.Close()
-	items := []GetThreadsByCriteriaRow{}
-	for rows.Next() {
-		var i GetThreadsByCriteriaRow
-		if err := rows.Scan(
-			&i.ID,
-			&i.Title,
-			&i.Body,
-			&i.Creator,
-			&i.CreatedTime,
-			&i.UpdatedTime,
-			&i.NumComments,
-			&i.Tags,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
+	return result.RowsAffected(), nil
+}
+
+const setCommentMentions = `-- name: SetCommentMentions :execrows
+WITH mentioned_users AS (
+    SELECT u.username
+    FROM users u
+    JOIN comments c ON c.id = $1
+    WHERE (u.canonical_username = ANY($2::text[])
+        OR u.username IN (
+            SELECT DISTINCT ON (h.old_canonical_username) h.username
+            FROM username_history h
+            WHERE h.old_canonical_username = ANY($2::text[])
+            AND NOT EXISTS (SELECT 1 FROM users ou WHERE ou.canonical_username = h.old_canonical_username)
+            ORDER BY h.old_canonical_username, h.changed_time DESC
+        ))
+    AND u.username <> c.creator
+),
+updated_mentions AS (
+    UPDATE comment_mentions cm
+    SET is_current = cm.username IN (SELECT mu.username FROM mentioned_users mu)
+    WHERE cm.comment_id = $1
+),
+new_mentions AS (
+    INSERT INTO comment_mentions (comment_id, username)
+    SELECT $1, mu.username
+    FROM mentioned_users mu
+    WHERE NOT EXISTS (
+        SELECT 1 FROM comment_mentions cm
+        WHERE cm.comment_id = $1
+        AND cm.username = mu.username
+    )
+    ON CONFLICT (comment_id, username) DO NOTHING
+    RETURNING username
+)
+INSERT INTO notifications (username, type, thread_id, comment_id, actor)
+SELECT nm.username, 'mention', c.thread_id, c.id, c.creator
+FROM new_mentions nm
+JOIN comments c ON c.id = $1
+`
+
+type SetCommentMentionsParams struct {
+	CommentID          pgtype.UUID `json:"comment_id"`
+	CanonicalUsernames []string    `json:"canonical_usernames"`
+}
+
+// Records the users mentioned in the current version of a comment in the same way as SetThreadMentions.
+// Returns the number of users notified.
+func (q *Queries) SetCommentMentions(ctx context.Context, arg SetCommentMentionsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setCommentMentions, arg.CommentID, arg.CanonicalUsernames)
+	if err != nil {
+		return 0, err
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+	return result.RowsAffected(), nil
+}
+
+const setCommentVote = `-- name: SetCommentVote :one
+INSERT INTO comment_votes (comment_id, username, value)
+SELECT c.id, $1::text, $2::smallint
+FROM comments c
+WHERE c.id = $3
+AND c.deleted_time IS NULL
+ON CONFLICT (comment_id, username) DO UPDATE
+SET value = EXCLUDED.value, updated_time = NOW()
+RETURNING value
+`
+
+type SetCommentVoteParams struct {
+	Username  string      `json:"username"`
+	Value     int16       `json:"value"`
+	CommentID pgtype.UUID `json:"comment_id"`
+}
+
+// Sets the vote of a user on a comment that has not been deleted, replacing their previous vote.
+func (q *Queries) SetCommentVote(ctx context.Context, arg SetCommentVoteParams) (int16, error) {
+	row := q.db.QueryRow(ctx, setCommentVote, arg.Username, arg.Value, arg.CommentID)
+	var value int16
+	err := row.Scan(&value)
+	return value, err
+}
+
+const setOldUsernameSkeleton = `-- name: SetOldUsernameSkeleton :exec
+UPDATE username_history
+SET old_username_skeleton = $1::text
+WHERE id = $2::uuid
+`
+
+type SetOldUsernameSkeletonParams struct {
+	OldUsernameSkeleton string      `json:"old_username_skeleton"`
+	ID                  pgtype.UUID `json:"id"`
+}
+
+// Sets the username skeleton of a previous username.
+func (q *Queries) SetOldUsernameSkeleton(ctx context.Context, arg SetOldUsernameSkeletonParams) error {
+	_, err := q.db.Exec(ctx, setOldUsernameSkeleton, arg.OldUsernameSkeleton, arg.ID)
+	return err
+}
+
+const setPoll = `-- name: SetPoll :exec
+INSERT INTO polls (thread_id, is_multiple_choice, is_anonymous, close_time)
+VALUES ($1, $2::boolean, $3::boolean, $4::timestamptz)
+ON CONFLICT (thread_id) DO UPDATE
+SET is_multiple_choice = EXCLUDED.is_multiple_choice,
+    is_anonymous = EXCLUDED.is_anonymous,
+    close_time = EXCLUDED.close_time
+`
+
+type SetPollParams struct {
+	ThreadID         pgtype.UUID        `json:"thread_id"`
+	IsMultipleChoice bool               `json:"is_multiple_choice"`
+	IsAnonymous      bool               `json:"is_anonymous"`
+	CloseTime        pgtype.Timestamptz `json:"close_time"`
+}
+
+// Creates the poll of a thread, or replaces its settings if it already has one.
+func (q *Queries) SetPoll(ctx context.Context, arg SetPollParams) error {
+	_, err := q.db.Exec(ctx, setPoll,
+		arg.ThreadID,
+		arg.IsMultipleChoice,
+		arg.IsAnonymous,
+		arg.CloseTime,
+	)
+	return err
+}
+
+const setThreadBodyHtml = `-- name: SetThreadBodyHtml :exec
+UPDATE threads
+SET body_html = $1::text, body_html_version = $2::integer
+WHERE id = $3
+AND body = $4::text
+`
+
+type SetThreadBodyHtmlParams struct {
+	BodyHtml        string      `json:"body_html"`
+	BodyHtmlVersion int32       `json:"body_html_version"`
+	ID              pgtype.UUID `json:"id"`
+	Body            string      `json:"body"`
+}
+
+// Caches the HTML of the body of a thread, unless the body has changed since it was rendered.
+func (q *Queries) SetThreadBodyHtml(ctx context.Context, arg SetThreadBodyHtmlParams) error {
+	_, err := q.db.Exec(ctx, setThreadBodyHtml,
+		arg.BodyHtml,
+		arg.BodyHtmlVersion,
+		arg.ID,
+		arg.Body,
+	)
+	return err
+}
+
+const setThreadBookmark = `-- name: SetThreadBookmark :execrows
+INSERT INTO bookmarks (username, thread_id, folder, note)
+SELECT $1::text, t.id, $2::text, $3::text
+FROM threads t
+WHERE t.id = $4
+AND t.deleted_time IS NULL
+ON CONFLICT (username, thread_id) WHERE thread_id IS NOT NULL DO UPDATE
+SET folder = EXCLUDED.folder, note = EXCLUDED.note
+`
+
+type SetThreadBookmarkParams struct {
+	Username string      `json:"username"`
+	Folder   string      `json:"folder"`
+	Note     string      `json:"note"`
+	ThreadID pgtype.UUID `json:"thread_id"`
+}
+
+// Bookmarks a thread that has not been deleted for a user, or updates the folder and note of an existing bookmark.
+func (q *Queries) SetThreadBookmark(ctx context.Context, arg SetThreadBookmarkParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setThreadBookmark,
+		arg.Username,
+		arg.Folder,
+		arg.Note,
+		arg.ThreadID,
+	)
+	if err != nil {
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected(), nil
 }
 
-const getThreadsByCriteriaCount = `-- name: GetThreadsByCriteriaCount :one
-SELECT COUNT(*) AS total_items
+const setThreadFingerprint = `-- name: SetThreadFingerprint :exec
+UPDATE threads
+SET body_simhash = $1::bigint
+WHERE id = $2
+AND body = $3::text
+`
+
+type SetThreadFingerprintParams struct {
+	BodySimhash int64       `json:"body_simhash"`
+	ID          pgtype.UUID `json:"id"`
+	Body        string      `json:"body"`
+}
+
+// Sets the fingerprint of the body of a thread, unless the body has been changed since it was read.
+func (q *Queries) SetThreadFingerprint(ctx context.Context, arg SetThreadFingerprintParams) error {
+	_, err := q.db.Exec(ctx, setThreadFingerprint, arg.BodySimhash, arg.ID, arg.Body)
+	return err
+}
+
+const setThreadMentions = `-- name: SetThreadMentions :execrows
+WITH mentioned_users AS (
+    SELECT u.username
+    FROM users u
+    JOIN threads t ON t.id = $1
+    WHERE (u.canonical_username = ANY($2::text[])
+        OR u.username IN (
+            SELECT DISTINCT ON (h.old_canonical_username) h.username
+            FROM username_history h
+            WHERE h.old_canonical_username = ANY($2::text[])
+            AND NOT EXISTS (SELECT 1 FROM users ou WHERE ou.canonical_username = h.old_canonical_username)
+            ORDER BY h.old_canonical_username, h.changed_time DESC
+        ))
+    AND u.username <> t.creator
+),
+updated_mentions AS (
+    UPDATE thread_mentions tm
+    SET is_current = tm.username IN (SELECT mu.username FROM mentioned_users mu)
+    WHERE tm.thread_id = $1
+),
+new_mentions AS (
+    INSERT INTO thread_mentions (thread_id, username)
+    SELECT $1, mu.username
+    FROM mentioned_users mu
+    WHERE NOT EXISTS (
+        SELECT 1 FROM thread_mentions tm
+        WHERE tm.thread_id = $1
+        AND tm.username = mu.username
+    )
+    ON CONFLICT (thread_id, username) DO NOTHING
+    RETURNING username
+)
+INSERT INTO notifications (username, type, thread_id, actor)
+SELECT nm.username, 'mention', t.id, t.creator
+FROM new_mentions nm
+JOIN threads t ON t.id = $1
+`
+
+type SetThreadMentionsParams struct {
+	ThreadID           pgtype.UUID `json:"thread_id"`
+	CanonicalUsernames []string    `json:"canonical_usernames"`
+}
+
+// Records the users mentioned in the current version of a thread, out of the given canonical usernames, leaving out
+// users that do not exist and the creator of the thread. Usernames of renamed users are resolved as in
+// GetMentionedUsers. Users no longer mentioned are kept but are no longer current.
+// Users mentioned in the thread for the first time are notified. Returns the number of users notified.
+func (q *Queries) SetThreadMentions(ctx context.Context, arg SetThreadMentionsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setThreadMentions, arg.ThreadID, arg.CanonicalUsernames)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setThreadSubscription = `-- name: SetThreadSubscription :execrows
+INSERT INTO thread_subscriptions (thread_id, username, is_muted)
+SELECT t.id, $1::text, $2::boolean
 FROM threads t
-WHERE
-    CASE
-        WHEN LENGTH($1::text) > 0 THEN TO_TSVECTOR('simple', t.title || ' ' || t.body) @@ TO_TSQUERY('simple', $1::text)
-        ELSE TRUE
-    END
-  AND
-    CASE
-        WHEN ARRAY_LENGTH($2::text[], 1) > 0 THEN t.id IN (
-          SELECT tt.thread_id
-          FROM thread_tags tt
-          WHERE tt.tag_name = ANY($2::text[])
-          GROUP BY tt.thread_id
-          HAVING COUNT(DISTINCT tt.tag_name) = ARRAY_LENGTH($2::text[], 1)
-        )
-        ELSE TRUE
-    END
+WHERE t.id = $3
+AND t.deleted_time IS NULL
+ON CONFLICT (thread_id, username) DO UPDATE
+SET is_muted = EXCLUDED.is_muted
 `
 
-type GetThreadsByCriteriaCountParams struct {
-	Keywords string   `json:"keywords"`
-	Tagarray []string `json:"tagarray"`
+type SetThreadSubscriptionParams struct {
+	Username string      `json:"username"`
+	IsMuted  bool        `json:"is_muted"`
+	ThreadID pgtype.UUID `json:"thread_id"`
 }
 
-// Counts the total number of threads that match the keywords and tags.
-func (q *Queries) GetThreadsByCriteriaCount(ctx context.Context, arg GetThreadsByCriteriaCountParams) (int64, error) {
-	row := q.db.QueryRow(ctx, getThreadsByCriteriaCount, arg.Keywords, arg.Tagarray)
-	var total_items int64
-	err := row.Scan(&total_items)
-	return total_items, err
+// Subscribes a user to a thread that has not been deleted, or mutes the thread for the user.
+func (q *Queries) SetThreadSubscription(ctx context.Context, arg SetThreadSubscriptionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setThreadSubscription, arg.Username, arg.IsMuted, arg.ThreadID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setThreadVote = `-- name: SetThreadVote :one
+INSERT INTO thread_votes (thread_id, username, value)
+SELECT t.id, $1::text, $2::smallint
+FROM threads t
+WHERE t.id = $3
+AND t.deleted_time IS NULL
+ON CONFLICT (thread_id, username) DO UPDATE
+SET value = EXCLUDED.value, updated_time = NOW()
+RETURNING value
+`
+
+type SetThreadVoteParams struct {
+	Username string      `json:"username"`
+	Value    int16       `json:"value"`
+	ThreadID pgtype.UUID `json:"thread_id"`
+}
+
+// Sets the vote of a user on a thread that has not been deleted, replacing their previous vote.
+func (q *Queries) SetThreadVote(ctx context.Context, arg SetThreadVoteParams) (int16, error) {
+	row := q.db.QueryRow(ctx, setThreadVote, arg.Username, arg.Value, arg.ThreadID)
+	var value int16
+	err := row.Scan(&value)
+	return value, err
+}
+
+const setUsernameSkeleton = `-- name: SetUsernameSkeleton :exec
+UPDATE users
+SET username_skeleton = $1::text
+WHERE username = $2::text
+`
+
+type SetUsernameSkeletonParams struct {
+	UsernameSkeleton string `json:"username_skeleton"`
+	Username         string `json:"username"`
+}
+
+// Sets the username skeleton of a user.
+func (q *Queries) SetUsernameSkeleton(ctx context.Context, arg SetUsernameSkeletonParams) error {
+	_, err := q.db.Exec(ctx, setUsernameSkeleton, arg.UsernameSkeleton, arg.Username)
+	return err
+}
+
+const unlockThread = `-- name: UnlockThread :exec
+UPDATE threads
+SET is_locked = FALSE, lock_reason = NULL, locked_time = NULL, locked_by = NULL
+WHERE id = $1
+`
+
+// Unlocks the thread with the given id.
+func (q *Queries) UnlockThread(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, unlockThread, id)
+	return err
+}
+
+const unpinThread = `-- name: UnpinThread :execrows
+DELETE FROM thread_pins
+WHERE thread_id = $1
+AND tag_name = $2
+`
+
+type UnpinThreadParams struct {
+	ThreadID pgtype.UUID `json:"thread_id"`
+	TagName  string      `json:"tag_name"`
+}
+
+// Unpins a thread everywhere, or only for the given tag.
+func (q *Queries) UnpinThread(ctx context.Context, arg UnpinThreadParams) (int64, error) {
+	result, err := q.db.Exec(ctx, unpinThread, arg.ThreadID, arg.TagName)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateCategory = `-- name: UpdateCategory :one
+UPDATE categories
+SET name = $2,
+    slug = $3,
+    description = $4,
+    parent_id = $5,
+    position = $6,
+    posting_role = $7
+WHERE id = $1
+RETURNING id, name, slug, description, parent_id, position, posting_role, num_threads, created_time
+`
+
+type UpdateCategoryParams struct {
+	ID          pgtype.UUID `json:"id"`
+	Name        string      `json:"name"`
+	Slug        string      `json:"slug"`
+	Description string      `json:"description"`
+	ParentID    pgtype.UUID `json:"parent_id"`
+	Position    int32       `json:"position"`
+	PostingRole string      `json:"posting_role"`
+}
+
+// Updates the category with the given id. Returns the details of the updated category.
+func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error) {
+	row := q.db.QueryRow(ctx, updateCategory,
+		arg.ID,
+		arg.Name,
+		arg.Slug,
+		arg.Description,
+		arg.ParentID,
+		arg.Position,
+		arg.PostingRole,
+	)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Slug,
+		&i.Description,
+		&i.ParentID,
+		&i.Position,
+		&i.PostingRole,
+		&i.NumThreads,
+		&i.CreatedTime,
+	)
+	return i, err
 }
 
 const updateComment = `-- name: UpdateComment :exec
 UPDATE comments
-SET body = $1, updated_time = NOW()
+SET body = $1, body_html = $4, body_html_version = $5, updated_time = NOW()
 WHERE id = $2
 AND creator = $3
 `
 
 type UpdateCommentParams struct {
-	Body    string      `json:"body"`
-	ID      pgtype.UUID `json:"id"`
-	Creator string      `json:"creator"`
+	Body            string      `json:"body"`
+	ID              pgtype.UUID `json:"id"`
+	Creator         string      `json:"creator"`
+	BodyHtml        string      `json:"body_html"`
+	BodyHtmlVersion int32       `json:"body_html_version"`
 }
 
 // Updates the comment with the given id.
 func (q *Queries) UpdateComment(ctx context.Context, arg UpdateCommentParams) error {
-	_, err := q.db.Exec(ctx, updateComment, arg.Body, arg.ID, arg.Creator)
+	_, err := q.db.Exec(ctx, updateComment,
+		arg.Body,
+		arg.ID,
+		arg.Creator,
+		arg.BodyHtml,
+		arg.BodyHtmlVersion,
+	)
 	return err
 }
 
 const updateThread = `-- name: UpdateThread :exec
 UPDATE threads
-SET title = $1, body = $2, updated_time = NOW()
+SET title = $1, body = $2, body_html = $5, body_html_version = $6, body_simhash = $7, updated_time = NOW()
 WHERE id = $3
 AND creator = $4
 `
 
 type UpdateThreadParams struct {
-	Title   string      `json:"title"`
-	Body    string      `json:"body"`
-	ID      pgtype.UUID `json:"id"`
-	Creator string      `json:"creator"`
+	Title           string      `json:"title"`
+	Body            string      `json:"body"`
+	ID              pgtype.UUID `json:"id"`
+	Creator         string      `json:"creator"`
+	BodyHtml        string      `json:"body_html"`
+	BodyHtmlVersion int32       `json:"body_html_version"`
+	BodySimhash     pgtype.Int8 `json:"body_simhash"`
 }
 
 // Updates the thread with the given id.
@@ -609,6 +4835,28 @@ func (q *Queries) UpdateThread(ctx context.Context, arg UpdateThreadParams) erro
 		arg.Body,
 		arg.ID,
 		arg.Creator,
+		arg.BodyHtml,
+		arg.BodyHtmlVersion,
+		arg.BodySimhash,
 	)
 	return err
 }
+
+const verifyUserEmail = `-- name: VerifyUserEmail :one
+UPDATE users
+SET is_verified = TRUE
+WHERE username = (
+    SELECT username
+    FROM email_verifications
+    WHERE token_hash = $1
+    AND expires_time > NOW())
+RETURNING username
+`
+
+// Marks the user who owns the given unexpired verification token as verified. Returns the username.
+func (q *Queries) VerifyUserEmail(ctx context.Context, tokenHash string) (string, error) {
+	row := q.db.QueryRow(ctx, verifyUserEmail, tokenHash)
+	var username string
+	err := row.Scan(&username)
+	return username, err
+}
@@ -0,0 +1,25 @@
+package database
+
+import (
+	"backend/internal/utils"
+	"context"
+)
+
+// RenderBody Renders a thread or comment body as sanitised HTML, linking the mentions of users that exist, including
+// mentions of the previous usernames of renamed users.
+func RenderBody(ctx context.Context, queries *Queries, body string) (string, error) {
+	mentionedUsers := map[string]string{}
+
+	mentions := utils.ExtractAllMentions(body)
+	if len(mentions) > 0 {
+		users, err := queries.GetMentionedUsers(ctx, mentions)
+		if err != nil {
+			return "", err
+		}
+		for _, user := range users {
+			mentionedUsers[user.MentionedUsername] = user.Username
+		}
+	}
+
+	return utils.RenderMarkdown(body, mentionedUsers), nil
+}
@@ -0,0 +1,80 @@
+package attachments
+
+import (
+	"backend/internal/database"
+	"backend/internal/storage"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+// storeBlob Records a file in the blob index and stores it in the blob store. Returns the hash of the file.
+// The file is recorded first, so that the cleanup job does not delete a file that was just stored again.
+func storeBlob(ctx context.Context, qtx *database.Queries, data []byte, contentType string) (string, error) {
+	hash := storage.BlobKey(data)
+
+	err := qtx.CreateBlob(ctx, database.CreateBlobParams{
+		Hash:        hash,
+		Size:        int32(len(data)),
+		ContentType: contentType,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hash, storage.Blobs.Put(ctx, hash, data, contentType)
+}
+
+// serveBlob Writes a file from the blob store to the response. Only images are shown inline, other files are
+// downloaded. The file is never run as a page of the site, even if the browser thinks it is HTML.
+func serveBlob(ctx context.Context, w http.ResponseWriter, handler string, hash string, contentType string,
+	size int32, filename string) {
+	blob, err := storage.Blobs.Get(ctx, hash)
+
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			utils.Log(handler, "File "+hash+" not found in blob store", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Attachment not found"))
+			if err != nil {
+				utils.Log(handler, "Unable to write response", err)
+			}
+		} else {
+			utils.Log(handler, "Unable to get file "+hash+" from blob store", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log(handler, "Unable to write response", err)
+			}
+		}
+		return
+	}
+	defer blob.Close()
+
+	disposition := "attachment"
+	if utils.IsInlineAttachmentType(contentType) {
+		disposition = "inline"
+	}
+	formattedDisposition := mime.FormatMediaType(disposition, map[string]string{"filename": filename})
+	if formattedDisposition != "" {
+		disposition = formattedDisposition
+	}
+
+	// Files are stored by the hash of their contents, so they never change
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(int(size)))
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=86400, immutable")
+
+	_, err = io.Copy(w, blob)
+
+	if err != nil {
+		utils.Log(handler, "Unable to write response", err)
+	}
+}
@@ -0,0 +1,86 @@
+package attachments
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// GetAttachment godoc
+// @Summary Handles attachment download requests
+// @Description Retrieves the file of an attachment. Images are shown inline, other files are downloaded.
+// @Description Attachments of deleted threads and comments are not found.
+// @Tags attachment
+// @Produce octet-stream
+// @Param id path string true "Attachment UUID"
+// @Success 200 {file} file "File"
+// @Failure 404 "Attachment not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /attachment/{id} [get]
+func GetAttachment(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetAttachment", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetAttachment", "Unable to write response", err)
+		}
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+
+	var pgAttachmentId pgtype.UUID
+	err := pgAttachmentId.Scan(id)
+
+	if err != nil {
+		utils.Log("GetAttachment", "Unable to scan attachmentId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetAttachment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	attachment, err := queries.GetAttachment(ctx, pgAttachmentId)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("GetAttachment", "Attachment "+id+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Attachment not found"))
+			if err != nil {
+				utils.Log("GetAttachment", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("GetAttachment", "Unable to get attachment "+id, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("GetAttachment", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	serveBlob(ctx, w, "GetAttachment", attachment.BlobHash, attachment.ContentType, attachment.Size,
+		attachment.Filename)
+
+	utils.Log("GetAttachment", "Attachment: "+id+" retrieved", nil)
+
+	return
+}
@@ -0,0 +1,102 @@
+package attachments
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// GetAttachmentThumbnail godoc
+// @Summary Handles attachment thumbnail requests
+// @Description Retrieves the thumbnail of an image attachment, which fits in 320 by 320 pixels. Images that are
+// @Description already small enough are their own thumbnails. Other files have no thumbnail.
+// @Tags attachment
+// @Produce png
+// @Produce jpeg
+// @Param id path string true "Attachment UUID"
+// @Success 200 {file} file "Thumbnail"
+// @Failure 404 "Attachment not found"
+// @Failure 404 "Thumbnail not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /attachment/{id}/thumbnail [get]
+func GetAttachmentThumbnail(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetAttachmentThumbnail", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetAttachmentThumbnail", "Unable to write response", err)
+		}
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+
+	var pgAttachmentId pgtype.UUID
+	err := pgAttachmentId.Scan(id)
+
+	if err != nil {
+		utils.Log("GetAttachmentThumbnail", "Unable to scan attachmentId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetAttachmentThumbnail", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	attachment, err := queries.GetAttachment(ctx, pgAttachmentId)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("GetAttachmentThumbnail", "Attachment "+id+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Attachment not found"))
+			if err != nil {
+				utils.Log("GetAttachmentThumbnail", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("GetAttachmentThumbnail", "Unable to get attachment "+id, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("GetAttachmentThumbnail", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	switch {
+	case attachment.ThumbnailHash.Valid:
+		serveBlob(ctx, w, "GetAttachmentThumbnail", attachment.ThumbnailHash.String,
+			attachment.ThumbnailContentType.String, attachment.ThumbnailSize.Int32, "thumbnail-"+attachment.Filename)
+	case utils.IsImageType(attachment.ContentType):
+		serveBlob(ctx, w, "GetAttachmentThumbnail", attachment.BlobHash, attachment.ContentType, attachment.Size,
+			attachment.Filename)
+	default:
+		utils.Log("GetAttachmentThumbnail", "Attachment "+id+" has no thumbnail", errors.New("no thumbnail"))
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("Thumbnail not found"))
+		if err != nil {
+			utils.Log("GetAttachmentThumbnail", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetAttachmentThumbnail", "Thumbnail of attachment: "+id+" retrieved", nil)
+
+	return
+}
@@ -0,0 +1,100 @@
+package attachments
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ParseAttachmentIds Returns the distinct attachment IDs as UUIDs, and false if any of them is not a UUID or there
+// are more than utils.MaxAttachments of them.
+func ParseAttachmentIds(attachmentIds []string) ([]pgtype.UUID, bool) {
+	pgAttachmentIds := []pgtype.UUID{}
+	seenIds := map[[16]byte]bool{}
+
+	for _, attachmentId := range attachmentIds {
+		var pgAttachmentId pgtype.UUID
+		err := pgAttachmentId.Scan(attachmentId)
+		if err != nil {
+			return nil, false
+		}
+
+		if !seenIds[pgAttachmentId.Bytes] {
+			seenIds[pgAttachmentId.Bytes] = true
+			pgAttachmentIds = append(pgAttachmentIds, pgAttachmentId)
+		}
+	}
+
+	return pgAttachmentIds, len(pgAttachmentIds) <= utils.MaxAttachments
+}
+
+// CanAttach Returns true if all the attachments were uploaded by the user and are not attached to anything yet.
+func CanAttach(ctx context.Context, queries *database.Queries, pgAttachmentIds []pgtype.UUID,
+	username string) (bool, error) {
+	if len(pgAttachmentIds) == 0 {
+		return true, nil
+	}
+
+	numAttachable, err := queries.CountAttachableAttachments(ctx, database.CountAttachableAttachmentsParams{
+		AttachmentIds: pgAttachmentIds,
+		Uploader:      username,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return int(numAttachable) == len(pgAttachmentIds), nil
+}
+
+// GetThreadAttachments Returns the attachments of the thread, not including those of its comments.
+func GetThreadAttachments(ctx context.Context, queries *database.Queries, pgThreadId pgtype.UUID) (
+	[]models.Attachment, error) {
+	pgAttachments, err := queries.GetAttachments(ctx, database.GetAttachmentsParams{
+		ThreadID:   pgThreadId,
+		CommentIds: []pgtype.UUID{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return database.FormatPgAttachments(pgAttachments), nil
+}
+
+// AddCommentAttachments Fills in the attachments of the comments.
+func AddCommentAttachments(ctx context.Context, queries *database.Queries, comments []models.Comment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	pgCommentIds := []pgtype.UUID{}
+	for _, comment := range comments {
+		var pgCommentId pgtype.UUID
+		err := pgCommentId.Scan(comment.ID)
+		if err != nil {
+			return err
+		}
+		pgCommentIds = append(pgCommentIds, pgCommentId)
+	}
+
+	pgAttachments, err := queries.GetAttachments(ctx, database.GetAttachmentsParams{
+		CommentIds: pgCommentIds,
+	})
+	if err != nil {
+		return err
+	}
+
+	attachmentsByComment := map[string][]models.Attachment{}
+	for _, pgAttachment := range pgAttachments {
+		commentId := database.FormatPgUuid(pgAttachment.CommentID)
+		attachmentsByComment[commentId] = append(attachmentsByComment[commentId],
+			database.FormatPgAttachment(pgAttachment))
+	}
+
+	for i := range comments {
+		comments[i].Attachments = attachmentsByComment[comments[i].ID]
+	}
+
+	return nil
+}
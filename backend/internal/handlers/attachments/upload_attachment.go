@@ -0,0 +1,288 @@
+package attachments
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// UploadAttachment godoc
+// @Summary Handles attachment upload requests
+// @Description Uploads a file to attach to a thread or comment by passing its ID in attachment_ids when creating it.
+// @Description The type of the file is detected from its contents. PNG, JPEG and GIF images, PDF documents and
+// @Description plain text files are accepted. The metadata of images is stripped and thumbnails are made of large
+// @Description images. Attachments that are not attached within ATTACHMENT_ORPHAN_HOURS are deleted.
+// @Tags attachment
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "File to upload"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.Attachment
+// @Failure 400 "Invalid data"
+// @Failure 400 "Invalid image"
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "Email not verified"
+// @Failure 405 "Method not allowed"
+// @Failure 413 "File too large"
+// @Failure 415 "Unsupported file type"
+// @Failure 500 "Internal server error"
+// @Router /attachment [post]
+func UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	// Only POST
+	if r.Method != http.MethodPost {
+		utils.Log("UploadAttachment", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("UploadAttachment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("UploadAttachment", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("UploadAttachment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get the file from the request, leaving some room for the rest of the multipart form
+	maxSize := utils.AttachmentMaxSize()
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+(1<<20))
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			utils.Log("UploadAttachment", "File too large", err)
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			_, err := w.Write([]byte("File too large"))
+			if err != nil {
+				utils.Log("UploadAttachment", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("UploadAttachment", "Unable to get file from form", err)
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("Invalid data"))
+			if err != nil {
+				utils.Log("UploadAttachment", "Unable to write response", err)
+			}
+		}
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil || len(data) == 0 {
+		utils.Log("UploadAttachment", "Unable to read file", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("UploadAttachment", "Unable to write response", err)
+		}
+		return
+	}
+
+	if int64(len(data)) > maxSize {
+		utils.Log("UploadAttachment", "File too large", errors.New("file too large"))
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		_, err := w.Write([]byte("File too large"))
+		if err != nil {
+			utils.Log("UploadAttachment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Detect the type of the file from its contents, the type sent by the client is not trusted
+	contentType, isAllowed := utils.SniffAttachmentType(data)
+	if !isAllowed {
+		utils.Log("UploadAttachment", "Unsupported file type: "+contentType, errors.New("unsupported file type"))
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		_, err := w.Write([]byte("Unsupported file type"))
+		if err != nil {
+			utils.Log("UploadAttachment", "Unable to write response", err)
+		}
+		return
+	}
+
+	attachment := models.Attachment{
+		Filename:    utils.NormalizeFilename(header.Filename),
+		ContentType: contentType,
+	}
+
+	var thumbnail []byte
+	var thumbnailType string
+	var pgWidth, pgHeight pgtype.Int4
+
+	// Strip the metadata of images and make their thumbnails
+	if utils.IsImageType(contentType) {
+		processedImage, err := utils.ProcessImage(data, contentType)
+		if err != nil {
+			utils.Log("UploadAttachment", "Unable to process image", err)
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("Invalid image"))
+			if err != nil {
+				utils.Log("UploadAttachment", "Unable to write response", err)
+			}
+			return
+		}
+
+		data = processedImage.Data
+		thumbnail = processedImage.Thumbnail
+		thumbnailType = processedImage.ThumbnailType
+		pgWidth = pgtype.Int4{Int32: int32(processedImage.Width), Valid: true}
+		pgHeight = pgtype.Int4{Int32: int32(processedImage.Height), Valid: true}
+		attachment.Width = &pgWidth.Int32
+		attachment.Height = &pgHeight.Int32
+		attachment.HasThumbnail = thumbnail != nil
+	}
+
+	attachment.Size = int32(len(data))
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Check if user has verified their email
+	isVerified, err := queries.CheckUserVerified(ctx, verifiedUsername)
+
+	if err != nil || !isVerified {
+		utils.Log("UploadAttachment", "User has not verified their email: "+verifiedUsername, err)
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("Email not verified"))
+		if err != nil {
+			utils.Log("UploadAttachment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Begin a new transaction, so that the cleanup job does not delete the blobs while they are stored
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		utils.Log("UploadAttachment", "Unable to begin transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UploadAttachment", "Unable to write response", err)
+		}
+		return
+	}
+
+	var hasCommitted = false
+
+	defer func(tx pgx.Tx, ctx context.Context) {
+		if hasCommitted {
+			return
+		}
+		err := tx.Rollback(ctx)
+		if err != nil {
+			utils.Log("UploadAttachment", "Unable to rollback transaction", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("UploadAttachment", "Unable to write response", err)
+			}
+		}
+	}(tx, ctx)
+
+	qtx := queries.WithTx(tx)
+
+	// Store the file and its thumbnail under the hashes of their contents
+	blobHash, err := storeBlob(ctx, qtx, data, contentType)
+	if err != nil {
+		utils.Log("UploadAttachment", "Unable to store file", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UploadAttachment", "Unable to write response", err)
+		}
+		return
+	}
+
+	var pgThumbnailHash pgtype.Text
+	if thumbnail != nil {
+		thumbnailHash, err := storeBlob(ctx, qtx, thumbnail, thumbnailType)
+		if err != nil {
+			utils.Log("UploadAttachment", "Unable to store thumbnail", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("UploadAttachment", "Unable to write response", err)
+			}
+			return
+		}
+		pgThumbnailHash = pgtype.Text{String: thumbnailHash, Valid: true}
+	}
+
+	pgAttachment, err := qtx.CreateAttachment(ctx, database.CreateAttachmentParams{
+		Uploader:      verifiedUsername,
+		Filename:      attachment.Filename,
+		BlobHash:      blobHash,
+		ThumbnailHash: pgThumbnailHash,
+		Width:         pgWidth,
+		Height:        pgHeight,
+	})
+
+	if err != nil {
+		utils.Log("UploadAttachment", "Unable to create attachment", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UploadAttachment", "Unable to write response", err)
+		}
+		return
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		utils.Log("UploadAttachment", "Unable to commit transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UploadAttachment", "Unable to write response", err)
+		}
+		return
+	}
+
+	hasCommitted = true
+
+	attachment.ID = database.FormatPgUuid(pgAttachment.ID)
+	attachment.CreatedTime = pgAttachment.CreatedTime.Time
+
+	// Return attachment as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(attachment)
+
+	if jsonErr != nil {
+		utils.Log("UploadAttachment", "Unable to encode attachment as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UploadAttachment", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("UploadAttachment", "Attachment: "+attachment.ID+" ("+strconv.Itoa(int(attachment.Size))+
+		" bytes) uploaded by: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,137 @@
+package bookmarks
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgtype"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// BookmarkComment godoc
+// @Summary Handles comment bookmark requests
+// @Description Bookmarks the comment with the given ID for the user, or updates the folder and note of the bookmark
+// @Tags bookmark
+// @Accept json
+// @Param id path string true "Comment ID"
+// @Param data body models.BookmarkRequest false "Bookmark data"
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 400 "Invalid data"
+// @Failure 401 "Invalid JWT token"
+// @Failure 404 "Comment not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /comment/{id}/bookmark [put]
+func BookmarkComment(w http.ResponseWriter, r *http.Request) {
+	// Only PUT
+	if r.Method != http.MethodPut {
+		utils.Log("BookmarkComment", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("BookmarkComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request, the body is optional
+	commentId := mux.Vars(r)["id"]
+	var bookmarkRequest models.BookmarkRequest
+	err := json.NewDecoder(r.Body).Decode(&bookmarkRequest)
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		utils.Log("BookmarkComment", "Unable to decode JSON", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("BookmarkComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	folder := strings.TrimSpace(bookmarkRequest.Folder)
+	note := strings.TrimSpace(bookmarkRequest.Note)
+
+	if utf8.RuneCountInString(folder) > 64 || utf8.RuneCountInString(note) > 500 {
+		utils.Log("BookmarkComment", "Folder or note too long", errors.New("invalid input"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("BookmarkComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("BookmarkComment", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("BookmarkComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Create comment UUID for pg
+	var pgCommentId pgtype.UUID
+
+	err = pgCommentId.Scan(commentId)
+	if err != nil {
+		utils.Log("BookmarkComment", "Unable to scan commentId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("BookmarkComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	numRows, err := queries.SetCommentBookmark(ctx, database.SetCommentBookmarkParams{
+		CommentID: pgCommentId,
+		Username:  verifiedUsername,
+		Folder:    folder,
+		Note:      note,
+	})
+
+	if err != nil {
+		utils.Log("BookmarkComment", "Unable to bookmark comment "+commentId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("BookmarkComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	if numRows == 0 {
+		utils.Log("BookmarkComment", "Comment "+commentId+" not found", errors.New("comment not found"))
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("Comment not found"))
+		if err != nil {
+			utils.Log("BookmarkComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("BookmarkComment", "Comment "+commentId+" bookmarked by: "+verifiedUsername, nil)
+
+	return
+}
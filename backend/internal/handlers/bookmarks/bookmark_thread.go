@@ -0,0 +1,137 @@
+package bookmarks
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgtype"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// BookmarkThread godoc
+// @Summary Handles thread bookmark requests
+// @Description Bookmarks the thread with the given ID for the user, or updates the folder and note of the bookmark
+// @Tags bookmark
+// @Accept json
+// @Param id path string true "Thread ID"
+// @Param data body models.BookmarkRequest false "Bookmark data"
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 400 "Invalid data"
+// @Failure 401 "Invalid JWT token"
+// @Failure 404 "Thread not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/bookmark [put]
+func BookmarkThread(w http.ResponseWriter, r *http.Request) {
+	// Only PUT
+	if r.Method != http.MethodPut {
+		utils.Log("BookmarkThread", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("BookmarkThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request, the body is optional
+	threadId := mux.Vars(r)["id"]
+	var bookmarkRequest models.BookmarkRequest
+	err := json.NewDecoder(r.Body).Decode(&bookmarkRequest)
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		utils.Log("BookmarkThread", "Unable to decode JSON", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("BookmarkThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	folder := strings.TrimSpace(bookmarkRequest.Folder)
+	note := strings.TrimSpace(bookmarkRequest.Note)
+
+	if utf8.RuneCountInString(folder) > 64 || utf8.RuneCountInString(note) > 500 {
+		utils.Log("BookmarkThread", "Folder or note too long", errors.New("invalid input"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("BookmarkThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("BookmarkThread", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("BookmarkThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Create thread UUID for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("BookmarkThread", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("BookmarkThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	numRows, err := queries.SetThreadBookmark(ctx, database.SetThreadBookmarkParams{
+		ThreadID: pgThreadId,
+		Username: verifiedUsername,
+		Folder:   folder,
+		Note:     note,
+	})
+
+	if err != nil {
+		utils.Log("BookmarkThread", "Unable to bookmark thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("BookmarkThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	if numRows == 0 {
+		utils.Log("BookmarkThread", "Thread "+threadId+" not found", errors.New("thread not found"))
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("Thread not found"))
+		if err != nil {
+			utils.Log("BookmarkThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("BookmarkThread", "Thread "+threadId+" bookmarked by: "+verifiedUsername, nil)
+
+	return
+}
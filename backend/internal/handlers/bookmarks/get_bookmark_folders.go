@@ -0,0 +1,88 @@
+package bookmarks
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// GetBookmarkFolders godoc
+// @Summary Handles bookmark folder retrieval requests
+// @Description Retrieves the names of the folders the user has bookmarks in, in alphabetical order
+// @Tags bookmark
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} string
+// @Failure 401 "Invalid JWT token"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /bookmark/folders [get]
+func GetBookmarkFolders(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetBookmarkFolders", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetBookmarkFolders", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("GetBookmarkFolders", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("GetBookmarkFolders", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	folders, err := queries.GetBookmarkFolders(ctx, verifiedUsername)
+
+	if err != nil {
+		utils.Log("GetBookmarkFolders", "Unable to get bookmark folders of "+verifiedUsername, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetBookmarkFolders", "Unable to write response", err)
+		}
+		return
+	}
+
+	if folders == nil {
+		folders = []string{}
+	}
+
+	// Return folders as JSON array
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(folders)
+
+	if jsonErr != nil {
+		utils.Log("GetBookmarkFolders", "Unable to encode folders as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetBookmarkFolders", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetBookmarkFolders", "Bookmark folders retrieved for: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,126 @@
+package bookmarks
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GetBookmarks godoc
+// @Summary Handles bookmark retrieval requests
+// @Description Retrieves the bookmarks of the user, latest first, together with the thread or comment they point to
+// @Tags bookmark
+// @Produce json
+// @Param folder query string false "Only return bookmarks in this folder"
+// @Param p query string false "Page number, default '1'"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.GetBookmarksResponse
+// @Failure 401 "Invalid JWT token"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /bookmark [get]
+func GetBookmarks(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetBookmarks", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetBookmarks", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Number of bookmarks per page
+	pageSize := 10
+
+	// Get details from request
+	folder := strings.TrimSpace(r.FormValue("folder"))
+	page := r.FormValue("p")
+
+	// Check page number
+	pageNumber, err := strconv.Atoi(page)
+	offset := 0
+	if err == nil && pageNumber > 1 {
+		offset = (pageNumber - 1) * pageSize
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("GetBookmarks", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("GetBookmarks", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	pgBookmarks, err := queries.GetBookmarks(ctx, database.GetBookmarksParams{
+		Username: verifiedUsername,
+		Folder:   folder,
+		Limit:    int32(pageSize),
+		Offset:   int32(offset),
+	})
+
+	if err != nil {
+		utils.Log("GetBookmarks", "Unable to get bookmarks of "+verifiedUsername, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetBookmarks", "Unable to write response", err)
+		}
+		return
+	}
+
+	count, err := queries.GetBookmarkCount(ctx, database.GetBookmarkCountParams{
+		Username: verifiedUsername,
+		Folder:   folder,
+	})
+
+	if err != nil {
+		utils.Log("GetBookmarks", "Unable to get bookmark count of "+verifiedUsername, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetBookmarks", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return bookmarks as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(models.GetBookmarksResponse{
+		Bookmarks: database.FormatPgBookmarks(pgBookmarks),
+		Count:     int32(count),
+	})
+
+	if jsonErr != nil {
+		utils.Log("GetBookmarks", "Unable to encode bookmarks as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetBookmarks", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetBookmarks", "Bookmarks retrieved for: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,102 @@
+package bookmarks
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// UnbookmarkComment godoc
+// @Summary Handles comment bookmark removal requests
+// @Description Removes the bookmark of the user on the comment with the given ID
+// @Tags bookmark
+// @Param id path string true "Comment ID"
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 401 "Invalid JWT token"
+// @Failure 404 "Bookmark not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /comment/{id}/bookmark [delete]
+func UnbookmarkComment(w http.ResponseWriter, r *http.Request) {
+	// Only DELETE
+	if r.Method != http.MethodDelete {
+		utils.Log("UnbookmarkComment", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("UnbookmarkComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	commentId := mux.Vars(r)["id"]
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("UnbookmarkComment", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("UnbookmarkComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Create comment UUID for pg
+	var pgCommentId pgtype.UUID
+
+	err = pgCommentId.Scan(commentId)
+	if err != nil {
+		utils.Log("UnbookmarkComment", "Unable to scan commentId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UnbookmarkComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	numRows, err := queries.DeleteCommentBookmark(ctx, database.DeleteCommentBookmarkParams{
+		CommentID: pgCommentId,
+		Username:  verifiedUsername,
+	})
+
+	if err != nil {
+		utils.Log("UnbookmarkComment", "Unable to remove bookmark on comment "+commentId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UnbookmarkComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	if numRows == 0 {
+		utils.Log("UnbookmarkComment", "Bookmark on comment "+commentId+" not found", errors.New("bookmark not found"))
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("Bookmark not found"))
+		if err != nil {
+			utils.Log("UnbookmarkComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("UnbookmarkComment", "Bookmark on comment "+commentId+" removed by: "+verifiedUsername, nil)
+
+	return
+}
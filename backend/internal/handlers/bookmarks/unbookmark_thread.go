@@ -0,0 +1,102 @@
+package bookmarks
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// UnbookmarkThread godoc
+// @Summary Handles thread bookmark removal requests
+// @Description Removes the bookmark of the user on the thread with the given ID
+// @Tags bookmark
+// @Param id path string true "Thread ID"
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 401 "Invalid JWT token"
+// @Failure 404 "Bookmark not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/bookmark [delete]
+func UnbookmarkThread(w http.ResponseWriter, r *http.Request) {
+	// Only DELETE
+	if r.Method != http.MethodDelete {
+		utils.Log("UnbookmarkThread", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("UnbookmarkThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	threadId := mux.Vars(r)["id"]
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("UnbookmarkThread", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("UnbookmarkThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Create thread UUID for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("UnbookmarkThread", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UnbookmarkThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	numRows, err := queries.DeleteThreadBookmark(ctx, database.DeleteThreadBookmarkParams{
+		ThreadID: pgThreadId,
+		Username: verifiedUsername,
+	})
+
+	if err != nil {
+		utils.Log("UnbookmarkThread", "Unable to remove bookmark on thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UnbookmarkThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	if numRows == 0 {
+		utils.Log("UnbookmarkThread", "Bookmark on thread "+threadId+" not found", errors.New("bookmark not found"))
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("Bookmark not found"))
+		if err != nil {
+			utils.Log("UnbookmarkThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("UnbookmarkThread", "Bookmark on thread "+threadId+" removed by: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,33 @@
+package categories
+
+import (
+	"backend/internal/models"
+	"backend/internal/utils"
+	"github.com/jackc/pgx/v5/pgtype"
+	"strings"
+)
+
+// normalizeCategoryRequest Trims the fields of a category request and defaults its posting role to user.
+// Returns the parsed parent ID, which is not valid for top-level categories, and whether the request is valid.
+func normalizeCategoryRequest(categoryRequest *models.CategoryRequest) (pgtype.UUID, bool) {
+	categoryRequest.Name = strings.TrimSpace(categoryRequest.Name)
+	categoryRequest.Slug = strings.TrimSpace(categoryRequest.Slug)
+	categoryRequest.Description = strings.TrimSpace(categoryRequest.Description)
+	if categoryRequest.PostingRole == "" {
+		categoryRequest.PostingRole = utils.RoleUser
+	}
+
+	var pgParentId pgtype.UUID
+	if categoryRequest.ParentId != nil {
+		err := pgParentId.Scan(*categoryRequest.ParentId)
+		if err != nil {
+			return pgParentId, false
+		}
+	}
+
+	isValid := len(categoryRequest.Name) > 0 && len(categoryRequest.Name) <= 64 &&
+		len(categoryRequest.Slug) <= 64 && utils.CATEGORY_SLUG_PATTERN.MatchString(categoryRequest.Slug) &&
+		len(categoryRequest.Description) <= 500 && utils.IsValidRole(categoryRequest.PostingRole)
+
+	return pgParentId, isValid
+}
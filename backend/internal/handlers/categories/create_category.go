@@ -0,0 +1,175 @@
+package categories
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"net/http"
+)
+
+// CreateCategory godoc
+// @Summary Handles category creation requests
+// @Description Creates a new category, as a subcategory of the given parent category if one is given.
+// @Description The slug must be unique and may only contain lowercase letters and digits separated by single hyphens.
+// @Description Only users with at least the posting role can create threads in the category. Only available to admins.
+// @Tags category
+// @Accept json
+// @Produce json
+// @Param data body models.CategoryRequest true "Category data"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.Category
+// @Failure 400 "Invalid data"
+// @Failure 400 "Invalid parent category"
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "No permission to create categories"
+// @Failure 405 "Method not allowed"
+// @Failure 409 "Slug already in use"
+// @Failure 500 "Internal server error"
+// @Router /category/create [post]
+func CreateCategory(w http.ResponseWriter, r *http.Request) {
+	// Only POST
+	if r.Method != http.MethodPost {
+		utils.Log("CreateCategory", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("CreateCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	var categoryCreate models.CategoryRequest
+	err := json.NewDecoder(r.Body).Decode(&categoryCreate)
+	if err != nil {
+		utils.Log("CreateCategory", "Unable to decode JSON", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("CreateCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Check if fields are valid
+	pgParentId, isValid := normalizeCategoryRequest(&categoryCreate)
+	if !isValid {
+		utils.Log("CreateCategory", "Invalid inputs", errors.New("invalid input"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("CreateCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("CreateCategory", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("CreateCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Check if user is an admin
+	role, err := queries.GetUserRole(ctx, verifiedUsername)
+
+	if err != nil || role != "admin" {
+		utils.Log("CreateCategory", "User is not an admin: "+verifiedUsername, err)
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("No permission to create categories"))
+		if err != nil {
+			utils.Log("CreateCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Check if the parent category exists
+	if pgParentId.Valid {
+		_, err = queries.GetCategory(ctx, pgParentId)
+
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				utils.Log("CreateCategory", "Parent category not found: "+*categoryCreate.ParentId, err)
+				w.WriteHeader(http.StatusBadRequest)
+				_, err := w.Write([]byte("Invalid parent category"))
+				if err != nil {
+					utils.Log("CreateCategory", "Unable to write response", err)
+				}
+			} else {
+				utils.Log("CreateCategory", "Unable to get parent category "+*categoryCreate.ParentId, err)
+				w.WriteHeader(http.StatusInternalServerError)
+				_, err := w.Write([]byte("Internal server error"))
+				if err != nil {
+					utils.Log("CreateCategory", "Unable to write response", err)
+				}
+			}
+			return
+		}
+	}
+
+	pgCategory, err := queries.CreateCategory(ctx, database.CreateCategoryParams{
+		Name:        categoryCreate.Name,
+		Slug:        categoryCreate.Slug,
+		Description: categoryCreate.Description,
+		ParentID:    pgParentId,
+		Position:    categoryCreate.Position,
+		PostingRole: categoryCreate.PostingRole,
+	})
+
+	if err != nil {
+		// 23505 is a unique violation, which happens if another category has the same slug
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			utils.Log("CreateCategory", "Slug already in use: "+categoryCreate.Slug, err)
+			w.WriteHeader(http.StatusConflict)
+			_, err := w.Write([]byte("Slug already in use"))
+			if err != nil {
+				utils.Log("CreateCategory", "Unable to write response", err)
+			}
+			return
+		}
+		utils.Log("CreateCategory", "Unable to create category", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("CreateCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return category as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(database.FormatPgCategory(pgCategory))
+
+	if jsonErr != nil {
+		utils.Log("CreateCategory", "Unable to encode category as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("CreateCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("CreateCategory", "Category "+categoryCreate.Slug+" created by: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,146 @@
+package categories
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// DeleteCategory godoc
+// @Summary Handles category deletion requests
+// @Description Deletes the category with the given ID. Categories with subcategories or threads, including deleted
+// @Description and scheduled threads, cannot be deleted, and neither can the General category. Only available to admins.
+// @Tags category
+// @Param id path string true "Category ID"
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 400 "Invalid category"
+// @Failure 400 "Cannot delete the default category"
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "No permission to delete categories"
+// @Failure 404 "Category not found"
+// @Failure 405 "Method not allowed"
+// @Failure 409 "Category not empty"
+// @Failure 500 "Internal server error"
+// @Router /category/{id} [delete]
+func DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	// Only DELETE
+	if r.Method != http.MethodDelete {
+		utils.Log("DeleteCategory", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("DeleteCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	categoryId := mux.Vars(r)["id"]
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("DeleteCategory", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("DeleteCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Create category UUID for pg
+	var pgCategoryId pgtype.UUID
+
+	err = pgCategoryId.Scan(categoryId)
+	if err != nil {
+		utils.Log("DeleteCategory", "Invalid category "+categoryId, err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid category"))
+		if err != nil {
+			utils.Log("DeleteCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Check if user is an admin
+	role, err := queries.GetUserRole(ctx, verifiedUsername)
+
+	if err != nil || role != "admin" {
+		utils.Log("DeleteCategory", "User is not an admin: "+verifiedUsername, err)
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("No permission to delete categories"))
+		if err != nil {
+			utils.Log("DeleteCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Threads without a category are created in the default category, so it must always exist
+	if database.FormatPgUuid(pgCategoryId) == utils.DefaultCategoryId {
+		utils.Log("DeleteCategory", "Cannot delete the default category", errors.New("default category"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Cannot delete the default category"))
+		if err != nil {
+			utils.Log("DeleteCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	rowsDeleted, err := queries.DeleteCategory(ctx, pgCategoryId)
+
+	if err != nil {
+		utils.Log("DeleteCategory", "Unable to delete category "+categoryId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("DeleteCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Nothing is deleted if the category does not exist or is not empty
+	if rowsDeleted == 0 {
+		_, err = queries.GetCategory(ctx, pgCategoryId)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("DeleteCategory", "Category "+categoryId+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Category not found"))
+			if err != nil {
+				utils.Log("DeleteCategory", "Unable to write response", err)
+			}
+		} else if err != nil {
+			utils.Log("DeleteCategory", "Unable to get category "+categoryId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("DeleteCategory", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("DeleteCategory", "Category "+categoryId+" is not empty", errors.New("category not empty"))
+			w.WriteHeader(http.StatusConflict)
+			_, err := w.Write([]byte("Category not empty"))
+			if err != nil {
+				utils.Log("DeleteCategory", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	utils.Log("DeleteCategory", "Category "+categoryId+" deleted by "+verifiedUsername, nil)
+}
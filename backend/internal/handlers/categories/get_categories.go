@@ -0,0 +1,69 @@
+package categories
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// GetCategories godoc
+// @Summary Handles category retrieval requests
+// @Description Retrieves all categories as a tree of top-level categories and their subcategories, ordered by their
+// @Description position and then by name. Each category has the number of threads in it and in its subcategories.
+// @Tags category
+// @Produce json
+// @Success 200 {array} models.Category
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /category [get]
+func GetCategories(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetCategories", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetCategories", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	pgCategories, err := queries.GetCategories(ctx)
+
+	if err != nil {
+		utils.Log("GetCategories", "Unable to get categories", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetCategories", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return categories as JSON array
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(database.FormatPgCategories(pgCategories))
+
+	if jsonErr != nil {
+		utils.Log("GetCategories", "Unable to encode categories as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetCategories", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetCategories", "Categories retrieved", nil)
+
+	return
+}
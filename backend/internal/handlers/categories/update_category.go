@@ -0,0 +1,227 @@
+package categories
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// UpdateCategory godoc
+// @Summary Handles category update requests
+// @Description Updates the category with the given ID. The category can be moved under another parent category, but
+// @Description not under itself or one of its subcategories. Only available to admins.
+// @Tags category
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Param data body models.CategoryRequest true "Category data"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.Category
+// @Failure 400 "Invalid data"
+// @Failure 400 "Invalid parent category"
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "No permission to update categories"
+// @Failure 404 "Category not found"
+// @Failure 405 "Method not allowed"
+// @Failure 409 "Slug already in use"
+// @Failure 500 "Internal server error"
+// @Router /category/{id} [put]
+func UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	// Only PUT
+	if r.Method != http.MethodPut {
+		utils.Log("UpdateCategory", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("UpdateCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	categoryId := mux.Vars(r)["id"]
+	var categoryUpdate models.CategoryRequest
+	err := json.NewDecoder(r.Body).Decode(&categoryUpdate)
+	if err != nil {
+		utils.Log("UpdateCategory", "Unable to decode JSON", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("UpdateCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Check if fields are valid
+	pgParentId, isValid := normalizeCategoryRequest(&categoryUpdate)
+	if !isValid {
+		utils.Log("UpdateCategory", "Invalid inputs", errors.New("invalid input"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("UpdateCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("UpdateCategory", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("UpdateCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Create category UUID for pg
+	var pgCategoryId pgtype.UUID
+
+	err = pgCategoryId.Scan(categoryId)
+	if err != nil {
+		utils.Log("UpdateCategory", "Unable to scan categoryId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UpdateCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Check if user is an admin
+	role, err := queries.GetUserRole(ctx, verifiedUsername)
+
+	if err != nil || role != "admin" {
+		utils.Log("UpdateCategory", "User is not an admin: "+verifiedUsername, err)
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("No permission to update categories"))
+		if err != nil {
+			utils.Log("UpdateCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	// The parent category must exist, and must not be the category itself or one of its subcategories
+	if pgParentId.Valid {
+		_, err = queries.GetCategory(ctx, pgParentId)
+
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				utils.Log("UpdateCategory", "Parent category not found: "+*categoryUpdate.ParentId, err)
+				w.WriteHeader(http.StatusBadRequest)
+				_, err := w.Write([]byte("Invalid parent category"))
+				if err != nil {
+					utils.Log("UpdateCategory", "Unable to write response", err)
+				}
+			} else {
+				utils.Log("UpdateCategory", "Unable to get parent category "+*categoryUpdate.ParentId, err)
+				w.WriteHeader(http.StatusInternalServerError)
+				_, err := w.Write([]byte("Internal server error"))
+				if err != nil {
+					utils.Log("UpdateCategory", "Unable to write response", err)
+				}
+			}
+			return
+		}
+
+		isDescendant, err := queries.IsCategoryDescendant(ctx, database.IsCategoryDescendantParams{
+			CategoryID: pgParentId,
+			AncestorID: pgCategoryId,
+		})
+
+		if err != nil {
+			utils.Log("UpdateCategory", "Unable to check parent category "+*categoryUpdate.ParentId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("UpdateCategory", "Unable to write response", err)
+			}
+			return
+		}
+
+		if isDescendant {
+			utils.Log("UpdateCategory", "Category "+categoryId+" cannot be moved under "+*categoryUpdate.ParentId,
+				errors.New("category cycle"))
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("Invalid parent category"))
+			if err != nil {
+				utils.Log("UpdateCategory", "Unable to write response", err)
+			}
+			return
+		}
+	}
+
+	pgCategory, err := queries.UpdateCategory(ctx, database.UpdateCategoryParams{
+		ID:          pgCategoryId,
+		Name:        categoryUpdate.Name,
+		Slug:        categoryUpdate.Slug,
+		Description: categoryUpdate.Description,
+		ParentID:    pgParentId,
+		Position:    categoryUpdate.Position,
+		PostingRole: categoryUpdate.PostingRole,
+	})
+
+	if err != nil {
+		// 23505 is a unique violation, which happens if another category has the same slug
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			utils.Log("UpdateCategory", "Slug already in use: "+categoryUpdate.Slug, err)
+			w.WriteHeader(http.StatusConflict)
+			_, err := w.Write([]byte("Slug already in use"))
+			if err != nil {
+				utils.Log("UpdateCategory", "Unable to write response", err)
+			}
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("UpdateCategory", "Category "+categoryId+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Category not found"))
+			if err != nil {
+				utils.Log("UpdateCategory", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("UpdateCategory", "Unable to update category "+categoryId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("UpdateCategory", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	// Return category as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(database.FormatPgCategory(pgCategory))
+
+	if jsonErr != nil {
+		utils.Log("UpdateCategory", "Unable to encode category as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UpdateCategory", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("UpdateCategory", "Category "+categoryId+" updated by: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,84 @@
+package challenges
+
+import (
+	"backend/internal/models"
+	"backend/internal/utils"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// GetChallenge godoc
+// @Summary Handles proof-of-work challenge requests
+// @Description Issues a proof-of-work challenge for the given purpose.
+// @Description The challenge and solution are sent in the X-Pow-Challenge and X-Pow-Solution headers of the protected request.
+// @Tags challenge
+// @Produce json
+// @Param purpose query string true "What the challenge will be used for" Enums(register, thread)
+// @Success 200 {object} models.PowChallenge
+// @Failure 400 "Invalid purpose"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /challenge [get]
+func GetChallenge(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetChallenge", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetChallenge", "Unable to write response", err)
+		}
+		return
+	}
+
+	purpose := r.URL.Query().Get("purpose")
+
+	if purpose != utils.PowRegister && purpose != utils.PowThread {
+		utils.Log("GetChallenge", "Invalid purpose: "+purpose, errors.New("invalid purpose"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid purpose"))
+		if err != nil {
+			utils.Log("GetChallenge", "Unable to write response", err)
+		}
+		return
+	}
+
+	response := models.PowChallenge{Required: utils.IsPowRequired(purpose)}
+
+	if response.Required {
+		challenge, difficulty, expiresTime, err := utils.CreatePowChallenge(purpose)
+
+		if err != nil {
+			utils.Log("GetChallenge", "Unable to create challenge", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("GetChallenge", "Unable to write response", err)
+			}
+			return
+		}
+
+		response.Challenge = challenge
+		response.Difficulty = difficulty
+		response.ExpiresTime = expiresTime
+	}
+
+	// Return challenge as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(response)
+
+	if jsonErr != nil {
+		utils.Log("GetChallenge", "Unable to encode challenge as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetChallenge", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetChallenge", "Challenge issued for: "+purpose, nil)
+
+	return
+}
@@ -2,11 +2,13 @@ package comments
 
 import (
 	"backend/internal/database"
+	"backend/internal/handlers/attachments"
 	"backend/internal/models"
 	"backend/internal/utils"
 	"context"
 	"encoding/json"
 	"errors"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"net/http"
 	"strings"
@@ -14,7 +16,8 @@ import (
 
 // CreateComment godoc
 // @Summary Handles comment creation requests
-// @Description Creates a new comment for the given thread
+// @Description Creates a new comment for the given thread, with up to 10 attachments uploaded by the user that are
+// @Description not attached to anything yet
 // @Tags comment
 // @Accept json
 // @Produce json
@@ -22,8 +25,12 @@ import (
 // @Security ApiKeyAuth
 // @Success 200 {object} models.Comment
 // @Failure 400 "Invalid data"
+// @Failure 400 "Invalid attachments"
 // @Failure 401 "Invalid JWT token"
+// @Failure 403 "Email not verified"
+// @Failure 404 "Thread not found"
 // @Failure 405 "Method not allowed"
+// @Failure 423 "Thread is locked"
 // @Failure 500 "Internal server error"
 // @Router /comment/create [post]
 func CreateComment(w http.ResponseWriter, r *http.Request) {
@@ -67,6 +74,17 @@ func CreateComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	pgAttachmentIds, isValid := attachments.ParseAttachmentIds(createCommentRequest.AttachmentIds)
+	if !isValid {
+		utils.Log("CreateComment", "Invalid attachments", errors.New("invalid attachments"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid attachments"))
+		if err != nil {
+			utils.Log("CreateComment", "Unable to write response", err)
+		}
+		return
+	}
+
 	// Get and verify JWT token from request header
 	token := r.Header.Get("Authorization")[7:]
 
@@ -88,6 +106,32 @@ func CreateComment(w http.ResponseWriter, r *http.Request) {
 	defer database.CloseConnection(conn)
 	queries := database.New(conn)
 
+	// Check if user has verified their email
+	isVerified, err := queries.CheckUserVerified(ctx, verifiedUsername)
+
+	if err != nil || !isVerified {
+		utils.Log("CreateComment", "User has not verified their email: "+verifiedUsername, err)
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("Email not verified"))
+		if err != nil {
+			utils.Log("CreateComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Check if the attachments can be attached to the comment
+	canAttach, err := attachments.CanAttach(ctx, queries, pgAttachmentIds, verifiedUsername)
+
+	if err != nil || !canAttach {
+		utils.Log("CreateComment", "Invalid attachments", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid attachments"))
+		if err != nil {
+			utils.Log("CreateComment", "Unable to write response", err)
+		}
+		return
+	}
+
 	// Format threadId as pgtype.UUID for query
 	var pgThreadId pgtype.UUID
 	err = pgThreadId.Scan(threadId)
@@ -101,17 +145,138 @@ func CreateComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check if the thread is locked, only moderators can comment on locked threads
+	lockDetails, err := queries.GetThreadLockDetails(ctx, pgThreadId)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("CreateComment", "Thread "+threadId+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Thread not found"))
+			if err != nil {
+				utils.Log("CreateComment", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("CreateComment", "Unable to get thread "+threadId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("CreateComment", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	if lockDetails.IsLocked {
+		role, err := queries.GetUserRole(ctx, verifiedUsername)
+
+		if err != nil || !utils.IsModerator(role) {
+			utils.Log("CreateComment", "Thread "+threadId+" is locked", err)
+			w.WriteHeader(http.StatusLocked)
+			_, err := w.Write([]byte("Thread is locked"))
+			if err != nil {
+				utils.Log("CreateComment", "Unable to write response", err)
+			}
+			return
+		}
+	}
+
+	// Render the body, linking the mentions of existing users
+	bodyHtml, err := database.RenderBody(ctx, queries, body)
+
+	if err != nil {
+		utils.Log("CreateComment", "Unable to render body", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("CreateComment", "Unable to write response", err)
+		}
+		return
+	}
+
 	// Create the comment
 	params := database.CreateCommentParams{
-		Body:     body,
-		Creator:  verifiedUsername,
-		ThreadID: pgThreadId,
+		Body:            body,
+		BodyHtml:        bodyHtml,
+		BodyHtmlVersion: utils.MarkdownVersion,
+		Creator:         verifiedUsername,
+		ThreadID:        pgThreadId,
+	}
+
+	// Begin a new transaction, so that the comment is only created together with its attachments
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		utils.Log("CreateComment", "Unable to begin transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("CreateComment", "Unable to write response", err)
+		}
+		return
 	}
 
-	pgComment, err := queries.CreateComment(ctx, params)
+	var hasCommitted = false
+
+	defer func(tx pgx.Tx, ctx context.Context) {
+		if hasCommitted {
+			return
+		}
+		err := tx.Rollback(ctx)
+		if err != nil {
+			utils.Log("CreateComment", "Unable to rollback transaction", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("CreateComment", "Unable to write response", err)
+			}
+		}
+	}(tx, ctx)
+
+	qtx := queries.WithTx(tx)
+
+	pgComment, err := qtx.CreateComment(ctx, params)
 
 	if err != nil {
-		utils.Log("CreateComment", "Unable to create comment", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("CreateComment", "Thread "+threadId+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Thread not found"))
+			if err != nil {
+				utils.Log("CreateComment", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("CreateComment", "Unable to create comment", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("CreateComment", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	// Attach the attachments to the comment
+	if len(pgAttachmentIds) > 0 {
+		_, err = qtx.AttachToComment(ctx, database.AttachToCommentParams{
+			CommentID:     pgComment.ID,
+			AttachmentIds: pgAttachmentIds,
+			Uploader:      verifiedUsername,
+		})
+		if err != nil {
+			utils.Log("CreateComment", "Unable to attach attachments", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("CreateComment", "Unable to write response", err)
+			}
+			return
+		}
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		utils.Log("CreateComment", "Unable to commit transaction", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		_, err := w.Write([]byte("Internal server error"))
 		if err != nil {
@@ -120,8 +285,40 @@ func CreateComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	hasCommitted = true
+
 	comment := database.FormatPgComment(pgComment)
 
+	if len(pgAttachmentIds) > 0 {
+		createdComments := []models.Comment{comment}
+		err = attachments.AddCommentAttachments(ctx, queries, createdComments)
+		if err != nil {
+			utils.Log("CreateComment", "Unable to get attachments", err)
+		}
+		comment = createdComments[0]
+	}
+
+	// Record the users mentioned and notify them, before subscribers are notified so that they are not notified twice
+	_, err = queries.SetCommentMentions(ctx, database.SetCommentMentionsParams{
+		CommentID:          pgComment.ID,
+		CanonicalUsernames: utils.ExtractMentions(body),
+	})
+	if err != nil {
+		utils.Log("CreateComment", "Unable to record mentions", err)
+	}
+
+	// Notify subscribers of the thread in the background
+	utils.QueueCommentNotification(comment.ID)
+
+	// The draft is no longer needed once it has been posted
+	_, err = queries.DeleteDraft(ctx, database.DeleteDraftParams{
+		Username: verifiedUsername,
+		Context:  utils.ReplyDraftContext(threadId),
+	})
+	if err != nil {
+		utils.Log("CreateComment", "Unable to delete draft", err)
+	}
+
 	// Return comment as JSON object
 	w.Header().Set("Content-Type", "application/json")
 	jsonErr := json.NewEncoder(w).Encode(comment)
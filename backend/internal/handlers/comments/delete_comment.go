@@ -12,7 +12,7 @@ import (
 
 // DeleteComment godoc
 // @Summary Handles comment deletion requests
-// @Description Deletes a comment
+// @Description Deletes a comment. The comment can be restored until it is purged.
 // @Tags comment
 // @Param id path string true "Comment UUID"
 // @Security ApiKeyAuth
@@ -0,0 +1,135 @@
+package comments
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// DeleteCommentVote godoc
+// @Summary Handles comment vote removal requests
+// @Description Removes the vote of the user on the comment with the given ID
+// @Tags comment
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.VoteResponse
+// @Failure 401 "Invalid JWT token"
+// @Failure 404 "Comment not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /comment/{id}/vote [delete]
+func DeleteCommentVote(w http.ResponseWriter, r *http.Request) {
+	// Only DELETE
+	if r.Method != http.MethodDelete {
+		utils.Log("DeleteCommentVote", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("DeleteCommentVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	commentId := mux.Vars(r)["id"]
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("DeleteCommentVote", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("DeleteCommentVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Create comment UUID for pg
+	var pgCommentId pgtype.UUID
+
+	err = pgCommentId.Scan(commentId)
+	if err != nil {
+		utils.Log("DeleteCommentVote", "Unable to scan commentId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("DeleteCommentVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Remove the vote, the score is updated by a trigger
+	err = queries.DeleteCommentVote(ctx, database.DeleteCommentVoteParams{
+		CommentID: pgCommentId,
+		Username:  verifiedUsername,
+	})
+
+	if err != nil {
+		utils.Log("DeleteCommentVote", "Unable to remove vote on comment "+commentId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("DeleteCommentVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	score, err := queries.GetCommentScore(ctx, pgCommentId)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("DeleteCommentVote", "Comment "+commentId+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Comment not found"))
+			if err != nil {
+				utils.Log("DeleteCommentVote", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("DeleteCommentVote", "Unable to get score of comment "+commentId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("DeleteCommentVote", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	// Return the new score as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(models.VoteResponse{
+		Score:  score,
+		MyVote: 0,
+	})
+
+	if jsonErr != nil {
+		utils.Log("DeleteCommentVote", "Unable to encode vote as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("DeleteCommentVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("DeleteCommentVote", "Vote on comment "+commentId+" removed by: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,174 @@
+package comments
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"strconv"
+)
+
+// GetCommentRevisionDiff godoc
+// @Summary Handles comment revision comparison requests
+// @Description Compares two versions of the comment with the given ID.
+// @Description Versions are numbered from 1, where num_revisions + 1 is the current version of the comment.
+// @Description By default, the current version is compared with the version before it.
+// @Tags comment
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Param from query int false "Version to compare from, default 'to - 1'"
+// @Param to query int false "Version to compare to, default the current version"
+// @Success 200 {object} models.RevisionDiff
+// @Failure 400 "Invalid data"
+// @Failure 404 "Comment not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /comment/{id}/revisions/diff [get]
+func GetCommentRevisionDiff(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetCommentRevisionDiff", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetCommentRevisionDiff", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	id := mux.Vars(r)["id"]
+	params := r.URL.Query()
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	var pgCommentId pgtype.UUID
+	err := pgCommentId.Scan(id)
+
+	if err != nil {
+		utils.Log("GetCommentRevisionDiff", "Unable to scan commentId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetCommentRevisionDiff", "Unable to write response", err)
+		}
+		return
+	}
+
+	pgComment, err := queries.GetComment(ctx, pgCommentId)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("GetCommentRevisionDiff", "Comment "+id+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Comment not found"))
+			if err != nil {
+				utils.Log("GetCommentRevisionDiff", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("GetCommentRevisionDiff", "Unable to get comment "+id, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("GetCommentRevisionDiff", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	// Check the versions to compare
+	currentVersion := pgComment.NumRevisions + 1
+	to := int(currentVersion)
+	if params.Get("to") != "" {
+		to, err = strconv.Atoi(params.Get("to"))
+	}
+	from := max(to-1, 1)
+	if err == nil && params.Get("from") != "" {
+		from, err = strconv.Atoi(params.Get("from"))
+	}
+
+	if err != nil || from < 1 || to < 1 || from > int(currentVersion) || to > int(currentVersion) {
+		utils.Log("GetCommentRevisionDiff", "Invalid versions", errors.New("invalid versions"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("GetCommentRevisionDiff", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get the versions to compare
+	fromVersion, err := getCommentVersion(ctx, queries, pgComment, int32(from))
+
+	if err != nil {
+		utils.Log("GetCommentRevisionDiff", "Unable to get version "+strconv.Itoa(from)+" of comment "+id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetCommentRevisionDiff", "Unable to write response", err)
+		}
+		return
+	}
+
+	toVersion, err := getCommentVersion(ctx, queries, pgComment, int32(to))
+
+	if err != nil {
+		utils.Log("GetCommentRevisionDiff", "Unable to get version "+strconv.Itoa(to)+" of comment "+id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetCommentRevisionDiff", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return differences as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(models.RevisionDiff{
+		From: int32(from),
+		To:   int32(to),
+		Body: utils.DiffLines(fromVersion.Body, toVersion.Body),
+	})
+
+	if jsonErr != nil {
+		utils.Log("GetCommentRevisionDiff", "Unable to encode differences as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetCommentRevisionDiff", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetCommentRevisionDiff", "Versions "+strconv.Itoa(from)+" and "+strconv.Itoa(to)+
+		" of comment "+id+" compared", nil)
+
+	return
+}
+
+// getCommentVersion Returns the given version of a comment, which is either a stored revision or the current comment.
+func getCommentVersion(ctx context.Context, queries *database.Queries, pgComment database.Comment,
+	versionNumber int32) (database.CommentRevision, error) {
+	if versionNumber > pgComment.NumRevisions {
+		return database.CommentRevision{
+			CommentID:      pgComment.ID,
+			RevisionNumber: versionNumber,
+			Body:           pgComment.Body,
+		}, nil
+	}
+
+	return queries.GetCommentRevision(ctx, database.GetCommentRevisionParams{
+		CommentID:      pgComment.ID,
+		RevisionNumber: versionNumber,
+	})
+}
@@ -0,0 +1,112 @@
+package comments
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// GetCommentRevisions godoc
+// @Summary Handles comment revision history requests
+// @Description Retrieves the previous versions of the comment with the given ID, oldest first.
+// @Description Revision n is the version of the comment before its n-th edit, with the user who made the edit.
+// @Tags comment
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Success 200 {array} models.CommentRevision
+// @Failure 404 "Comment not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /comment/{id}/revisions [get]
+func GetCommentRevisions(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetCommentRevisions", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetCommentRevisions", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request url
+	id := mux.Vars(r)["id"]
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	var pgCommentId pgtype.UUID
+	err := pgCommentId.Scan(id)
+
+	if err != nil {
+		utils.Log("GetCommentRevisions", "Unable to scan commentId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetCommentRevisions", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Check that the comment exists
+	_, err = queries.GetComment(ctx, pgCommentId)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("GetCommentRevisions", "Comment "+id+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Comment not found"))
+			if err != nil {
+				utils.Log("GetCommentRevisions", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("GetCommentRevisions", "Unable to get comment "+id, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("GetCommentRevisions", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	pgRevisions, err := queries.GetCommentRevisions(ctx, pgCommentId)
+
+	if err != nil {
+		utils.Log("GetCommentRevisions", "Unable to get revisions of comment "+id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetCommentRevisions", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return revisions as JSON array
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(database.FormatPgCommentRevisions(pgRevisions))
+
+	if jsonErr != nil {
+		utils.Log("GetCommentRevisions", "Unable to encode revisions as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetCommentRevisions", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetCommentRevisions", "Revisions of comment "+id+" retrieved", nil)
+
+	return
+}
@@ -2,6 +2,7 @@ package comments
 
 import (
 	"backend/internal/database"
+	"backend/internal/handlers/attachments"
 	"backend/internal/models"
 	"backend/internal/utils"
 	"context"
@@ -18,7 +19,7 @@ import (
 // @Description Retrieves comments for the given thread
 // @Tags comment
 // @Param thread_id path string true "Thread UUID"
-// @Param order query string false "Sorting order, default 'created_time_asc'" Enums(created_time_asc, created_time_desc)
+// @Param order query string false "Sorting order, default 'created_time_asc'" Enums(created_time_asc, created_time_desc, score_desc)
 // @Param p query string false "Page number, default '1'"
 // @Success 200 {object} models.GetCommentResponse
 // @Failure 405 "Method not allowed"
@@ -44,7 +45,7 @@ func GetComments(w http.ResponseWriter, r *http.Request) {
 	page := r.FormValue("p")
 
 	// Available sorting orders
-	availableSortOrders := []string{"created_time_asc", "created_time_desc"}
+	availableSortOrders := []string{"created_time_asc", "created_time_desc", "score_desc"}
 
 	// Check sort order
 	if order == "" || !slices.Contains(availableSortOrders, order) {
@@ -80,6 +81,7 @@ func GetComments(w http.ResponseWriter, r *http.Request) {
 	// Get the comments
 	pgComments, err := queries.GetComments(ctx, database.GetCommentsParams{
 		ThreadID:  pgThreadId,
+		Viewer:    utils.GetRequestUsername(r),
 		Sortorder: order,
 		Offset:    int32(offset),
 		Limit:     int32(pageSize),
@@ -111,6 +113,18 @@ func GetComments(w http.ResponseWriter, r *http.Request) {
 	response.Comments = database.FormatPgComments(pgComments)
 	response.Count = int32(commentsCount)
 
+	err = attachments.AddCommentAttachments(ctx, queries, response.Comments)
+
+	if err != nil {
+		utils.Log("GetComments", "Unable to get attachments of comments", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetComments", "Unable to write response", err)
+		}
+		return
+	}
+
 	// Return comments as JSON object
 	w.Header().Set("Content-Type", "application/json")
 	jsonErr := json.NewEncoder(w).Encode(response)
@@ -0,0 +1,143 @@
+package comments
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// RestoreComment godoc
+// @Summary Handles comment restore requests
+// @Description Restores the deleted comment with the given ID.
+// @Description The creator can restore a comment they deleted within the restore window, and moderators can restore
+// @Description any deleted comment until it is purged.
+// @Description Comments of a deleted thread stay hidden until the thread is restored.
+// @Tags comment
+// @Param id path string true "Comment ID"
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "No permission to restore comment"
+// @Failure 404 "Deleted comment not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /comment/{id}/restore [post]
+func RestoreComment(w http.ResponseWriter, r *http.Request) {
+	// Only POST
+	if r.Method != http.MethodPost {
+		utils.Log("RestoreComment", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("RestoreComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	commentId := mux.Vars(r)["id"]
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("RestoreComment", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("RestoreComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Create comment UUID for pg
+	var pgCommentId pgtype.UUID
+
+	err = pgCommentId.Scan(commentId)
+	if err != nil {
+		utils.Log("RestoreComment", "Unable to scan commentId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("RestoreComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	deletedComment, err := queries.GetDeletedComment(ctx, pgCommentId)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("RestoreComment", "Deleted comment "+commentId+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Deleted comment not found"))
+			if err != nil {
+				utils.Log("RestoreComment", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("RestoreComment", "Unable to get deleted comment "+commentId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("RestoreComment", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	role, err := queries.GetUserRole(ctx, verifiedUsername)
+
+	if err != nil {
+		utils.Log("RestoreComment", "Unable to get role of user "+verifiedUsername, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("RestoreComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Creators can only restore comments they deleted themselves, within the restore window
+	isCreatorRestore := deletedComment.Creator == verifiedUsername &&
+		deletedComment.DeletedBy.String == verifiedUsername &&
+		utils.CanAuthorRestore(deletedComment.DeletedTime.Time)
+
+	if !isCreatorRestore && !utils.IsModerator(role) {
+		utils.Log("RestoreComment", "User "+verifiedUsername+" cannot restore comment "+commentId,
+			errors.New("no permission"))
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("No permission to restore comment"))
+		if err != nil {
+			utils.Log("RestoreComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	err = queries.RestoreComment(ctx, pgCommentId)
+
+	if err != nil {
+		utils.Log("RestoreComment", "Unable to restore comment", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("RestoreComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("RestoreComment", "Comment restored: "+commentId+" by: "+verifiedUsername, nil)
+
+	return
+}
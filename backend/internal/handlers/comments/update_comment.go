@@ -7,6 +7,7 @@ import (
 	"context"
 	"encoding/json"
 	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"net/http"
 	"strings"
@@ -86,6 +87,51 @@ func UpdateComment(w http.ResponseWriter, r *http.Request) {
 	defer database.CloseConnection(conn)
 	queries := database.New(conn)
 
+	// Render the body, linking the mentions of existing users
+	bodyHtml, err := database.RenderBody(ctx, queries, body)
+
+	if err != nil {
+		utils.Log("UpdateComment", "Unable to render body", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UpdateComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Begin a new transaction
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		utils.Log("UpdateComment", "Unable to begin transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UpdateComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	var hasCommitted = false
+
+	defer func(tx pgx.Tx, ctx context.Context) {
+		if hasCommitted {
+			return
+		}
+		err := tx.Rollback(ctx)
+		if err != nil {
+			utils.Log("UpdateComment", "Unable to rollback transaction", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("UpdateComment", "Unable to write response", err)
+			}
+		}
+	}(tx, ctx)
+
+	qtx := queries.WithTx(tx)
+
 	// Create comment UUID for pg
 	var pgCommentId pgtype.UUID
 
@@ -101,7 +147,7 @@ func UpdateComment(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if the user is the creator of the comment
-	isCreator, err := queries.CheckCommentCreator(ctx, database.CheckCommentCreatorParams{
+	isCreator, err := qtx.CheckCommentCreator(ctx, database.CheckCommentCreatorParams{
 		Creator: verifiedUsername,
 		ID:      pgCommentId})
 
@@ -115,11 +161,30 @@ func UpdateComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Save the current version of the comment as a revision
+	_, err = qtx.CreateCommentRevision(ctx, database.CreateCommentRevisionParams{
+		ID:     pgCommentId,
+		Body:   body,
+		Editor: verifiedUsername,
+	})
+
+	if err != nil {
+		utils.Log("UpdateComment", "Unable to save comment revision", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UpdateComment", "Unable to write response", err)
+		}
+		return
+	}
+
 	// Update the comment
-	err = queries.UpdateComment(ctx, database.UpdateCommentParams{
-		Body:    body,
-		Creator: verifiedUsername,
-		ID:      pgCommentId,
+	err = qtx.UpdateComment(ctx, database.UpdateCommentParams{
+		Body:            body,
+		BodyHtml:        bodyHtml,
+		BodyHtmlVersion: utils.MarkdownVersion,
+		Creator:         verifiedUsername,
+		ID:              pgCommentId,
 	})
 
 	if err != nil {
@@ -132,6 +197,37 @@ func UpdateComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = tx.Commit(ctx)
+	if err != nil {
+		utils.Log("UpdateComment", "Unable to commit transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UpdateComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	hasCommitted = true
+
+	// Record the users mentioned and notify those mentioned for the first time, once the comment has been saved
+	_, err = queries.SetCommentMentions(ctx, database.SetCommentMentionsParams{
+		CommentID:          pgCommentId,
+		CanonicalUsernames: utils.ExtractMentions(body),
+	})
+	if err != nil {
+		utils.Log("UpdateComment", "Unable to record mentions", err)
+	}
+
+	// The draft is no longer needed once it has been posted
+	_, err = queries.DeleteDraft(ctx, database.DeleteDraftParams{
+		Username: verifiedUsername,
+		Context:  utils.EditCommentDraftContext(commentId),
+	})
+	if err != nil {
+		utils.Log("UpdateComment", "Unable to delete draft", err)
+	}
+
 	utils.Log("UpdateComment", "Comment "+commentId+" updated by "+verifiedUsername, nil)
 
 	return
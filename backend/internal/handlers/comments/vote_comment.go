@@ -0,0 +1,165 @@
+package comments
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// VoteComment godoc
+// @Summary Handles comment vote requests
+// @Description Upvotes or downvotes the comment with the given ID, replacing any previous vote of the user
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Param data body models.VoteRequest true "Vote data"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.VoteResponse
+// @Failure 400 "Invalid data"
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "Email not verified"
+// @Failure 404 "Comment not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /comment/{id}/vote [put]
+func VoteComment(w http.ResponseWriter, r *http.Request) {
+	// Only PUT
+	if r.Method != http.MethodPut {
+		utils.Log("VoteComment", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("VoteComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	commentId := mux.Vars(r)["id"]
+	var vote models.VoteRequest
+	err := json.NewDecoder(r.Body).Decode(&vote)
+
+	if err != nil || (vote.Value != 1 && vote.Value != -1) {
+		utils.Log("VoteComment", "Invalid vote", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("VoteComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("VoteComment", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("VoteComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Check if user has verified their email
+	isVerified, err := queries.CheckUserVerified(ctx, verifiedUsername)
+
+	if err != nil || !isVerified {
+		utils.Log("VoteComment", "User has not verified their email: "+verifiedUsername, err)
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("Email not verified"))
+		if err != nil {
+			utils.Log("VoteComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Create comment UUID for pg
+	var pgCommentId pgtype.UUID
+
+	err = pgCommentId.Scan(commentId)
+	if err != nil {
+		utils.Log("VoteComment", "Unable to scan commentId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("VoteComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Set the vote, the score is updated by a trigger
+	myVote, err := queries.SetCommentVote(ctx, database.SetCommentVoteParams{
+		CommentID: pgCommentId,
+		Username:  verifiedUsername,
+		Value:     int16(vote.Value),
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("VoteComment", "Comment "+commentId+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Comment not found"))
+			if err != nil {
+				utils.Log("VoteComment", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("VoteComment", "Unable to vote on comment "+commentId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("VoteComment", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	score, err := queries.GetCommentScore(ctx, pgCommentId)
+
+	if err != nil {
+		utils.Log("VoteComment", "Unable to get score of comment "+commentId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("VoteComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return the new score as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(models.VoteResponse{
+		Score:  score,
+		MyVote: int32(myVote),
+	})
+
+	if jsonErr != nil {
+		utils.Log("VoteComment", "Unable to encode vote as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("VoteComment", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("VoteComment", "Comment "+commentId+" voted on by: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,88 @@
+package drafts
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strings"
+)
+
+// DeleteDraft godoc
+// @Summary Handles draft deletion requests
+// @Description Deletes the draft of the user for the given context
+// @Tags draft
+// @Param context path string true "Draft context"
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 401 "Invalid JWT token"
+// @Failure 404 "Draft not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /draft/{context} [delete]
+func DeleteDraft(w http.ResponseWriter, r *http.Request) {
+	// Only DELETE
+	if r.Method != http.MethodDelete {
+		utils.Log("DeleteDraft", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("DeleteDraft", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	draftContext := strings.ToLower(mux.Vars(r)["context"])
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("DeleteDraft", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("DeleteDraft", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	numRows, err := queries.DeleteDraft(ctx, database.DeleteDraftParams{
+		Username: verifiedUsername,
+		Context:  draftContext,
+	})
+
+	if err != nil {
+		utils.Log("DeleteDraft", "Unable to delete draft "+draftContext, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("DeleteDraft", "Unable to write response", err)
+		}
+		return
+	}
+
+	if numRows == 0 {
+		utils.Log("DeleteDraft", "Draft "+draftContext+" not found", errors.New("draft not found"))
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("Draft not found"))
+		if err != nil {
+			utils.Log("DeleteDraft", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("DeleteDraft", "Draft "+draftContext+" deleted by: "+verifiedUsername, nil)
+
+	return
+}
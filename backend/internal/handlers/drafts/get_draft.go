@@ -0,0 +1,104 @@
+package drafts
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"strings"
+)
+
+// GetDraft godoc
+// @Summary Handles draft retrieval requests
+// @Description Retrieves the draft of the user for the given context
+// @Tags draft
+// @Produce json
+// @Param context path string true "Draft context"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.Draft
+// @Failure 401 "Invalid JWT token"
+// @Failure 404 "Draft not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /draft/{context} [get]
+func GetDraft(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetDraft", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetDraft", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	draftContext := strings.ToLower(mux.Vars(r)["context"])
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("GetDraft", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("GetDraft", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	pgDraft, err := queries.GetDraft(ctx, database.GetDraftParams{
+		Username: verifiedUsername,
+		Context:  draftContext,
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("GetDraft", "Draft "+draftContext+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Draft not found"))
+			if err != nil {
+				utils.Log("GetDraft", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("GetDraft", "Unable to get draft "+draftContext, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("GetDraft", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	// Return draft as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(database.FormatPgDraft(pgDraft))
+
+	if jsonErr != nil {
+		utils.Log("GetDraft", "Unable to encode draft as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetDraft", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetDraft", "Draft "+draftContext+" retrieved for: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,84 @@
+package drafts
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// GetDrafts godoc
+// @Summary Handles draft list requests
+// @Description Retrieves the drafts of the user, most recently updated first
+// @Tags draft
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.Draft
+// @Failure 401 "Invalid JWT token"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /draft [get]
+func GetDrafts(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetDrafts", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetDrafts", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("GetDrafts", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("GetDrafts", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	pgDrafts, err := queries.GetDrafts(ctx, verifiedUsername)
+
+	if err != nil {
+		utils.Log("GetDrafts", "Unable to get drafts of "+verifiedUsername, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetDrafts", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return drafts as JSON array
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(database.FormatPgDrafts(pgDrafts))
+
+	if jsonErr != nil {
+		utils.Log("GetDrafts", "Unable to encode drafts as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetDrafts", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetDrafts", "Drafts retrieved for: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,141 @@
+package drafts
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// SaveDraft godoc
+// @Summary Handles draft save requests
+// @Description Saves a draft for the given context, replacing any previous draft for the same context.
+// @Description The context is one of 'new_thread', 'reply:<thread id>', 'edit_thread:<thread id>' or 'edit_comment:<comment id>'.
+// @Description Drafts are deleted when the thread or comment is posted, or after not being updated for some time.
+// @Tags draft
+// @Accept json
+// @Produce json
+// @Param context path string true "Draft context"
+// @Param data body models.SaveDraftRequest true "Draft data"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.Draft
+// @Failure 400 "Invalid data"
+// @Failure 401 "Invalid JWT token"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /draft/{context} [put]
+func SaveDraft(w http.ResponseWriter, r *http.Request) {
+	// Only PUT
+	if r.Method != http.MethodPut {
+		utils.Log("SaveDraft", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("SaveDraft", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	draftContext := strings.ToLower(mux.Vars(r)["context"])
+	var draftSave models.SaveDraftRequest
+	err := json.NewDecoder(r.Body).Decode(&draftSave)
+
+	if err != nil {
+		utils.Log("SaveDraft", "Unable to decode JSON", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("SaveDraft", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Only drafts of threads have a title and tags
+	title := ""
+	tags := []string{}
+	if draftContext == utils.DraftNewThread || strings.HasPrefix(draftContext, "edit_thread:") {
+		title = draftSave.Title
+		for _, tag := range draftSave.Tags {
+			trimmedTag := strings.TrimSpace(tag)
+			if len(trimmedTag) > 0 && len(trimmedTag) <= 30 &&
+				regexp.MustCompile(`^[a-zA-Z0-9-]+$`).MatchString(trimmedTag) {
+				tags = append(tags, trimmedTag)
+			}
+		}
+	}
+	body := draftSave.Body
+
+	// Check if fields are valid, drafts may be incomplete but not longer than what can be posted
+	if !utils.IsValidDraftContext(draftContext) || len(tags) > 3 || len(title) > 100 || len(body) > 3000 {
+		utils.Log("SaveDraft", "Invalid inputs", errors.New("invalid input"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("SaveDraft", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("SaveDraft", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("SaveDraft", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	pgDraft, err := queries.SaveDraft(ctx, database.SaveDraftParams{
+		Username: verifiedUsername,
+		Context:  draftContext,
+		Title:    title,
+		Body:     body,
+		Tagarray: tags,
+	})
+
+	if err != nil {
+		utils.Log("SaveDraft", "Unable to save draft "+draftContext, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("SaveDraft", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return draft as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(database.FormatPgDraft(database.GetDraftRow(pgDraft)))
+
+	if jsonErr != nil {
+		utils.Log("SaveDraft", "Unable to encode draft as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("SaveDraft", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("SaveDraft", "Draft "+draftContext+" saved by: "+verifiedUsername, nil)
+
+	return
+}
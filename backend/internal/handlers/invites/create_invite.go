@@ -0,0 +1,155 @@
+package invites
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"time"
+)
+
+// CreateInvite godoc
+// @Summary Handles invite code creation requests
+// @Description Creates a new invite code. Only available to admins.
+// @Tags invite
+// @Accept json
+// @Produce json
+// @Param data body models.CreateInviteRequest true "Invite code data"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.InviteCode
+// @Failure 400 "Invalid data"
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "No permission to create invite codes"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /invite/create [post]
+func CreateInvite(w http.ResponseWriter, r *http.Request) {
+	// Only POST
+	if r.Method != http.MethodPost {
+		utils.Log("CreateInvite", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("CreateInvite", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	var inviteCreate models.CreateInviteRequest
+	err := json.NewDecoder(r.Body).Decode(&inviteCreate)
+	if err != nil {
+		utils.Log("CreateInvite", "Unable to decode JSON", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("CreateInvite", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Default to a single use invite code
+	maxUses := inviteCreate.MaxUses
+	if maxUses == 0 {
+		maxUses = 1
+	}
+
+	// Check if fields are valid
+	if maxUses < 0 || (inviteCreate.ExpiresTime != nil && inviteCreate.ExpiresTime.Before(time.Now())) {
+		utils.Log("CreateInvite", "Invalid inputs", errors.New("invalid input"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("CreateInvite", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("CreateInvite", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("CreateInvite", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Check if user is an admin
+	role, err := queries.GetUserRole(ctx, verifiedUsername)
+
+	if err != nil || role != "admin" {
+		utils.Log("CreateInvite", "User is not an admin: "+verifiedUsername, err)
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("No permission to create invite codes"))
+		if err != nil {
+			utils.Log("CreateInvite", "Unable to write response", err)
+		}
+		return
+	}
+
+	code, err := utils.GenerateToken(8)
+
+	if err != nil {
+		utils.Log("CreateInvite", "Unable to generate invite code", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("CreateInvite", "Unable to write response", err)
+		}
+		return
+	}
+
+	var pgExpiresTime pgtype.Timestamptz
+	if inviteCreate.ExpiresTime != nil {
+		pgExpiresTime = pgtype.Timestamptz{Time: *inviteCreate.ExpiresTime, Valid: true}
+	}
+
+	pgInviteCode, err := queries.CreateInviteCode(ctx, database.CreateInviteCodeParams{
+		Code:        code,
+		Creator:     verifiedUsername,
+		MaxUses:     maxUses,
+		ExpiresTime: pgExpiresTime})
+
+	if err != nil {
+		utils.Log("CreateInvite", "Unable to create invite code", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("CreateInvite", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return invite code as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(database.FormatPgInviteCode(pgInviteCode))
+
+	if jsonErr != nil {
+		utils.Log("CreateInvite", "Unable to encode invite code as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("CreateInvite", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("CreateInvite", "Invite code created by: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,98 @@
+package invites
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"net/http"
+)
+
+// DeleteInvite godoc
+// @Summary Handles invite code deletion requests
+// @Description Revokes the given invite code. Only available to admins.
+// @Tags invite
+// @Param code path string true "Invite code"
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "No permission to delete invite codes"
+// @Failure 404 "Invite code not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /invite/{code} [delete]
+func DeleteInvite(w http.ResponseWriter, r *http.Request) {
+	// Only DELETE
+	if r.Method != http.MethodDelete {
+		utils.Log("DeleteInvite", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("DeleteInvite", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	code := mux.Vars(r)["code"]
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("DeleteInvite", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("DeleteInvite", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Check if user is an admin
+	role, err := queries.GetUserRole(ctx, verifiedUsername)
+
+	if err != nil || role != "admin" {
+		utils.Log("DeleteInvite", "User is not an admin: "+verifiedUsername, err)
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("No permission to delete invite codes"))
+		if err != nil {
+			utils.Log("DeleteInvite", "Unable to write response", err)
+		}
+		return
+	}
+
+	rowsDeleted, err := queries.DeleteInviteCode(ctx, code)
+
+	if err != nil {
+		utils.Log("DeleteInvite", "Unable to delete invite code", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("DeleteInvite", "Unable to write response", err)
+		}
+		return
+	}
+
+	if rowsDeleted == 0 {
+		utils.Log("DeleteInvite", "Invite code not found: "+code, errors.New("invite code not found"))
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("Invite code not found"))
+		if err != nil {
+			utils.Log("DeleteInvite", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("DeleteInvite", "Invite code "+code+" deleted by "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,98 @@
+package invites
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// GetInvites godoc
+// @Summary Handles invite code retrieval requests
+// @Description Retrieves all invite codes, latest first. Only available to admins.
+// @Tags invite
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.InviteCode
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "No permission to view invite codes"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /invite [get]
+func GetInvites(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetInvites", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetInvites", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("GetInvites", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("GetInvites", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Check if user is an admin
+	role, err := queries.GetUserRole(ctx, verifiedUsername)
+
+	if err != nil || role != "admin" {
+		utils.Log("GetInvites", "User is not an admin: "+verifiedUsername, err)
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("No permission to view invite codes"))
+		if err != nil {
+			utils.Log("GetInvites", "Unable to write response", err)
+		}
+		return
+	}
+
+	pgInviteCodes, err := queries.GetInviteCodes(ctx)
+
+	if err != nil {
+		utils.Log("GetInvites", "Unable to get invite codes", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetInvites", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return invite codes as JSON array
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(database.FormatPgInviteCodes(pgInviteCodes))
+
+	if jsonErr != nil {
+		utils.Log("GetInvites", "Unable to encode invite codes as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetInvites", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetInvites", "Invite codes retrieved by: "+verifiedUsername, nil)
+
+	return
+}
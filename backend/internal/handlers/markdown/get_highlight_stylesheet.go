@@ -0,0 +1,59 @@
+package markdown
+
+import (
+	"backend/internal/utils"
+	"errors"
+	"net/http"
+)
+
+// GetHighlightStylesheet godoc
+// @Summary Handles highlighted code stylesheet requests
+// @Description Retrieves the stylesheet of a theme for the highlighted code blocks in rendered bodies.
+// @Description Highlighted code is marked with CSS classes only, so any theme can be used.
+// @Tags render
+// @Produce text/css
+// @Param theme query string false "Name of the theme, e.g. github, monokai or dracula" default(github)
+// @Success 200 {string} string "Stylesheet"
+// @Failure 404 "Theme not found"
+// @Failure 405 "Method not allowed"
+// @Router /render/stylesheet [get]
+func GetHighlightStylesheet(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetHighlightStylesheet", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetHighlightStylesheet", "Unable to write response", err)
+		}
+		return
+	}
+
+	theme := r.URL.Query().Get("theme")
+	if theme == "" {
+		theme = "github"
+	}
+
+	stylesheet, isFound := utils.HighlightStylesheet(theme)
+
+	if !isFound {
+		utils.Log("GetHighlightStylesheet", "Theme "+theme+" not found", errors.New("theme not found"))
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("Theme not found"))
+		if err != nil {
+			utils.Log("GetHighlightStylesheet", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return stylesheet as CSS
+	w.Header().Set("Content-Type", "text/css")
+	_, err := w.Write([]byte(stylesheet))
+
+	if err != nil {
+		utils.Log("GetHighlightStylesheet", "Unable to write response", err)
+		return
+	}
+
+	utils.Log("GetHighlightStylesheet", "Stylesheet of theme "+theme+" retrieved", nil)
+}
@@ -0,0 +1,90 @@
+package markdown
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// RenderMarkdown godoc
+// @Summary Handles body preview requests
+// @Description Renders a thread or comment body as CommonMark and sanitises the HTML, in the same way as the
+// @Description body_html of threads and comments. Only mentions of existing users are rendered as links.
+// @Tags render
+// @Accept json
+// @Produce json
+// @Param data body models.RenderRequest true "Body to render"
+// @Success 200 {object} models.RenderResponse
+// @Failure 400 "Invalid data"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /render [post]
+func RenderMarkdown(w http.ResponseWriter, r *http.Request) {
+	// Only POST
+	if r.Method != http.MethodPost {
+		utils.Log("RenderMarkdown", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("RenderMarkdown", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	var renderRequest models.RenderRequest
+	err := json.NewDecoder(r.Body).Decode(&renderRequest)
+
+	// Bodies are limited to the same length as thread and comment bodies
+	if err != nil || len(renderRequest.Body) > 3000 {
+		utils.Log("RenderMarkdown", "Invalid body", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("RenderMarkdown", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	bodyHtml, err := database.RenderBody(ctx, queries, renderRequest.Body)
+
+	if err != nil {
+		utils.Log("RenderMarkdown", "Unable to render body", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("RenderMarkdown", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return rendered HTML as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(models.RenderResponse{
+		BodyHtml: bodyHtml,
+	})
+
+	if jsonErr != nil {
+		utils.Log("RenderMarkdown", "Unable to encode rendered body as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("RenderMarkdown", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("RenderMarkdown", "Body rendered", nil)
+
+	return
+}
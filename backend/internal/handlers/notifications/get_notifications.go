@@ -0,0 +1,125 @@
+package notifications
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// GetNotifications godoc
+// @Summary Handles notification retrieval requests
+// @Description Retrieves the notifications of the user, latest first, together with the title of their thread
+// @Tags notification
+// @Produce json
+// @Param unread query bool false "Only return unread notifications"
+// @Param p query string false "Page number, default '1'"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.GetNotificationsResponse
+// @Failure 401 "Invalid JWT token"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /notification [get]
+func GetNotifications(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetNotifications", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetNotifications", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Number of notifications per page
+	pageSize := 10
+
+	// Get details from request
+	unreadOnly := r.FormValue("unread") == "true"
+	page := r.FormValue("p")
+
+	// Check page number
+	pageNumber, err := strconv.Atoi(page)
+	offset := 0
+	if err == nil && pageNumber > 1 {
+		offset = (pageNumber - 1) * pageSize
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("GetNotifications", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("GetNotifications", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	pgNotifications, err := queries.GetNotifications(ctx, database.GetNotificationsParams{
+		Username:   verifiedUsername,
+		UnreadOnly: unreadOnly,
+		Limit:      int32(pageSize),
+		Offset:     int32(offset),
+	})
+
+	if err != nil {
+		utils.Log("GetNotifications", "Unable to get notifications of "+verifiedUsername, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetNotifications", "Unable to write response", err)
+		}
+		return
+	}
+
+	count, err := queries.GetNotificationCount(ctx, database.GetNotificationCountParams{
+		Username:   verifiedUsername,
+		UnreadOnly: unreadOnly,
+	})
+
+	if err != nil {
+		utils.Log("GetNotifications", "Unable to get notification count of "+verifiedUsername, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetNotifications", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return notifications as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(models.GetNotificationsResponse{
+		Notifications: database.FormatPgNotifications(pgNotifications),
+		Count:         int32(count),
+	})
+
+	if jsonErr != nil {
+		utils.Log("GetNotifications", "Unable to encode notifications as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetNotifications", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetNotifications", "Notifications retrieved for: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,90 @@
+package notifications
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// GetUnreadNotificationCount godoc
+// @Summary Handles unread notification count requests
+// @Description Retrieves the number of unread notifications of the user
+// @Tags notification
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.UnreadNotificationsResponse
+// @Failure 401 "Invalid JWT token"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /notification/unread [get]
+func GetUnreadNotificationCount(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetUnreadNotificationCount", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetUnreadNotificationCount", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("GetUnreadNotificationCount", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("GetUnreadNotificationCount", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	count, err := queries.GetNotificationCount(ctx, database.GetNotificationCountParams{
+		Username:   verifiedUsername,
+		UnreadOnly: true,
+	})
+
+	if err != nil {
+		utils.Log("GetUnreadNotificationCount", "Unable to get unread notification count of "+verifiedUsername, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetUnreadNotificationCount", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return count as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(models.UnreadNotificationsResponse{
+		Count: int32(count),
+	})
+
+	if jsonErr != nil {
+		utils.Log("GetUnreadNotificationCount", "Unable to encode count as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetUnreadNotificationCount", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetUnreadNotificationCount", "Unread notification count retrieved for: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,68 @@
+package notifications
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"net/http"
+)
+
+// MarkAllNotificationsRead godoc
+// @Summary Handles requests to mark all notifications as read
+// @Description Marks all notifications of the user as read
+// @Tags notification
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 401 "Invalid JWT token"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /notification/read [post]
+func MarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	// Only POST
+	if r.Method != http.MethodPost {
+		utils.Log("MarkAllNotificationsRead", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("MarkAllNotificationsRead", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("MarkAllNotificationsRead", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("MarkAllNotificationsRead", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	_, err = queries.MarkAllNotificationsRead(ctx, verifiedUsername)
+
+	if err != nil {
+		utils.Log("MarkAllNotificationsRead", "Unable to mark notifications of "+verifiedUsername+" as read", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MarkAllNotificationsRead", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("MarkAllNotificationsRead", "All notifications read by: "+verifiedUsername, nil)
+
+	return
+}
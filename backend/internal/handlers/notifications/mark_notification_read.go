@@ -0,0 +1,104 @@
+package notifications
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// MarkNotificationRead godoc
+// @Summary Handles notification read requests
+// @Description Marks the notification with the given ID as read
+// @Tags notification
+// @Param id path string true "Notification ID"
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 401 "Invalid JWT token"
+// @Failure 404 "Notification not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /notification/{id}/read [post]
+func MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	// Only POST
+	if r.Method != http.MethodPost {
+		utils.Log("MarkNotificationRead", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("MarkNotificationRead", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	notificationId := mux.Vars(r)["id"]
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("MarkNotificationRead", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("MarkNotificationRead", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Create notification UUID for pg
+	var pgNotificationId pgtype.UUID
+
+	err = pgNotificationId.Scan(notificationId)
+	if err != nil {
+		utils.Log("MarkNotificationRead", "Unable to scan notificationId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MarkNotificationRead", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Only notifications of the user can be marked as read
+	numRows, err := queries.MarkNotificationRead(ctx, database.MarkNotificationReadParams{
+		ID:       pgNotificationId,
+		Username: verifiedUsername,
+	})
+
+	if err != nil {
+		utils.Log("MarkNotificationRead", "Unable to mark notification "+notificationId+" as read", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MarkNotificationRead", "Unable to write response", err)
+		}
+		return
+	}
+
+	if numRows == 0 {
+		utils.Log("MarkNotificationRead", "Notification "+notificationId+" not found",
+			errors.New("notification not found"))
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("Notification not found"))
+		if err != nil {
+			utils.Log("MarkNotificationRead", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("MarkNotificationRead", "Notification "+notificationId+" read by: "+verifiedUsername, nil)
+
+	return
+}
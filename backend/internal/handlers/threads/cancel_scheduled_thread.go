@@ -0,0 +1,104 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// CancelScheduledThread godoc
+// @Summary Handles scheduled thread cancellation requests
+// @Description Cancels the scheduled thread with the given ID, so that it is never published
+// @Tags thread
+// @Param id path string true "Scheduled thread ID"
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 401 "Invalid JWT token"
+// @Failure 404 "Scheduled thread not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/scheduled/{id} [delete]
+func CancelScheduledThread(w http.ResponseWriter, r *http.Request) {
+	// Only DELETE
+	if r.Method != http.MethodDelete {
+		utils.Log("CancelScheduledThread", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("CancelScheduledThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	threadId := mux.Vars(r)["id"]
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("CancelScheduledThread", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("CancelScheduledThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Create thread UUID for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("CancelScheduledThread", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("CancelScheduledThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Only the creator of a scheduled thread can cancel it
+	numRows, err := queries.DeleteScheduledThread(ctx, database.DeleteScheduledThreadParams{
+		ID:      pgThreadId,
+		Creator: verifiedUsername,
+	})
+
+	if err != nil {
+		utils.Log("CancelScheduledThread", "Unable to cancel scheduled thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("CancelScheduledThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	if numRows == 0 {
+		utils.Log("CancelScheduledThread", "Scheduled thread "+threadId+" not found",
+			errors.New("scheduled thread not found"))
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("Scheduled thread not found"))
+		if err != nil {
+			utils.Log("CancelScheduledThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("CancelScheduledThread", "Scheduled thread "+threadId+" cancelled by: "+verifiedUsername, nil)
+
+	return
+}
@@ -2,28 +2,48 @@ package threads
 
 import (
 	"backend/internal/database"
+	"backend/internal/handlers/attachments"
 	"backend/internal/models"
 	"backend/internal/utils"
 	"context"
 	"encoding/json"
 	"errors"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"net/http"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // CreateThread godoc
 // @Summary Handles thread creation requests
-// @Description Creates a new thread
+// @Description Creates a new thread. If publish_at is in the future, the thread is scheduled to be published at that
+// @Description time instead, and the scheduled thread is returned with status 202. Only its creator can see it until then.
+// @Description A poll with 2 to 10 options can optionally be attached to the thread, as well as up to 10
+// @Description attachments uploaded by the user that are not attached to anything yet.
+// @Description If REPOST_WINDOW_HOURS is set, a thread with the same title and body as a thread the user posted within
+// @Description that many hours is rejected.
+// @Description The thread is created in the given category, or in the General category if none is given. Categories
+// @Description can restrict posting to moderators or admins.
 // @Tags thread
 // @Accept json
 // @Produce json
 // @Param data body models.CreateThreadRequest true "Thread data"
+// @Param X-Pow-Challenge header string false "Proof-of-work challenge from /challenge, if required"
+// @Param X-Pow-Solution header string false "Proof-of-work solution, if required"
 // @Security ApiKeyAuth
 // @Success 200 {object} models.Thread
+// @Success 202 {object} models.ScheduledThread
 // @Failure 400 "Invalid data"
+// @Failure 400 "Invalid poll"
+// @Failure 400 "Invalid attachments"
+// @Failure 400 "Invalid category"
+// @Failure 400 "Invalid proof of work"
 // @Failure 401 "Invalid JWT token"
+// @Failure 403 "Email not verified"
+// @Failure 403 "No permission to post in category"
+// @Failure 409 "Duplicate thread"
 // @Failure 405 "Method not allowed"
 // @Failure 500 "Internal server error"
 // @Router /thread/create [post]
@@ -74,6 +94,49 @@ func CreateThread(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check if poll is valid, it must stay open for some time after the thread is published
+	if threadCreate.Poll != nil && (!utils.NormalizePoll(threadCreate.Poll) ||
+		(threadCreate.PublishAt != nil && threadCreate.Poll.CloseTime != nil &&
+			!threadCreate.Poll.CloseTime.After(*threadCreate.PublishAt))) {
+		utils.Log("CreateThread", "Invalid poll", errors.New("invalid poll"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid poll"))
+		if err != nil {
+			utils.Log("CreateThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	pgAttachmentIds, isValid := attachments.ParseAttachmentIds(threadCreate.AttachmentIds)
+	if !isValid {
+		utils.Log("CreateThread", "Invalid attachments", errors.New("invalid attachments"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid attachments"))
+		if err != nil {
+			utils.Log("CreateThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Threads without a category are created in the General category
+	categoryId := utils.DefaultCategoryId
+	if threadCreate.CategoryId != nil {
+		categoryId = *threadCreate.CategoryId
+	}
+
+	var pgCategoryId pgtype.UUID
+	err = pgCategoryId.Scan(categoryId)
+
+	if err != nil {
+		utils.Log("CreateThread", "Invalid category "+categoryId, err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid category"))
+		if err != nil {
+			utils.Log("CreateThread", "Unable to write response", err)
+		}
+		return
+	}
+
 	// Get and verify JWT token from request header
 	token := r.Header.Get("Authorization")[7:]
 	verifiedUsername, err := utils.VerifyJWT(token)
@@ -88,12 +151,198 @@ func CreateThread(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check proof-of-work solution
+	if utils.IsPowRequired(utils.PowThread) {
+		err := utils.VerifyPowSolution(utils.PowThread, r.Header.Get("X-Pow-Challenge"), r.Header.Get("X-Pow-Solution"))
+		if err != nil {
+			utils.Log("CreateThread", "Invalid proof of work", err)
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("Invalid proof of work"))
+			if err != nil {
+				utils.Log("CreateThread", "Unable to write response", err)
+			}
+			return
+		}
+	}
+
 	// Connect to database
 	ctx := context.Background()
 	conn := database.GetConnection()
 	defer database.CloseConnection(conn)
 	queries := database.New(conn)
 
+	// Check if user has verified their email
+	isVerified, err := queries.CheckUserVerified(ctx, verifiedUsername)
+
+	if err != nil || !isVerified {
+		utils.Log("CreateThread", "User has not verified their email: "+verifiedUsername, err)
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("Email not verified"))
+		if err != nil {
+			utils.Log("CreateThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Check if the attachments can be attached to the thread
+	canAttach, err := attachments.CanAttach(ctx, queries, pgAttachmentIds, verifiedUsername)
+
+	if err != nil || !canAttach {
+		utils.Log("CreateThread", "Invalid attachments", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid attachments"))
+		if err != nil {
+			utils.Log("CreateThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Check if the category exists and the user is allowed to post in it
+	category, err := queries.GetCategory(ctx, pgCategoryId)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("CreateThread", "Category "+categoryId+" not found", err)
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("Invalid category"))
+			if err != nil {
+				utils.Log("CreateThread", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("CreateThread", "Unable to get category "+categoryId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("CreateThread", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	role, err := queries.GetUserRole(ctx, verifiedUsername)
+
+	if err != nil || !utils.HasRole(role, category.PostingRole) {
+		utils.Log("CreateThread", "User "+verifiedUsername+" cannot post in category "+categoryId, err)
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("No permission to post in category"))
+		if err != nil {
+			utils.Log("CreateThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Reject the thread if the user posted the same thread recently, if reposts are not allowed
+	if utils.REPOST_WINDOW_HOURS > 0 {
+		isRepost, err := queries.CheckRepost(ctx, database.CheckRepostParams{
+			Creator:     verifiedUsername,
+			Title:       title,
+			Body:        body,
+			WindowHours: int32(utils.REPOST_WINDOW_HOURS),
+		})
+
+		if err != nil {
+			utils.Log("CreateThread", "Unable to check for reposts", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("CreateThread", "Unable to write response", err)
+			}
+			return
+		}
+
+		if isRepost {
+			utils.Log("CreateThread", "Thread is a repost by: "+verifiedUsername, errors.New("duplicate thread"))
+			w.WriteHeader(http.StatusConflict)
+			_, err := w.Write([]byte("Duplicate thread"))
+			if err != nil {
+				utils.Log("CreateThread", "Unable to write response", err)
+			}
+			return
+		}
+	}
+
+	// Threads to be published in the future are scheduled and created by the scheduler job
+	if threadCreate.PublishAt != nil && threadCreate.PublishAt.After(time.Now()) {
+		if tags == nil {
+			tags = []string{}
+		}
+
+		// The poll is kept as JSON and attached when the thread is published
+		var pollJson []byte
+		if threadCreate.Poll != nil {
+			pollJson, err = json.Marshal(threadCreate.Poll)
+			if err != nil {
+				utils.Log("CreateThread", "Unable to encode poll as JSON", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				_, err := w.Write([]byte("Internal server error"))
+				if err != nil {
+					utils.Log("CreateThread", "Unable to write response", err)
+				}
+				return
+			}
+		}
+
+		// Attachments are attached when the thread is published
+		pgScheduledThread, err := queries.CreateScheduledThread(ctx, database.CreateScheduledThreadParams{
+			Title:         title,
+			Body:          body,
+			Creator:       verifiedUsername,
+			Tagarray:      tags,
+			Poll:          pollJson,
+			AttachmentIds: pgAttachmentIds,
+			CategoryID:    pgCategoryId,
+			PublishTime:   pgtype.Timestamptz{Time: *threadCreate.PublishAt, Valid: true},
+		})
+
+		if err != nil {
+			utils.Log("CreateThread", "Unable to schedule thread", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("CreateThread", "Unable to write response", err)
+			}
+			return
+		}
+
+		// The draft is no longer needed once it has been scheduled
+		_, err = queries.DeleteDraft(ctx, database.DeleteDraftParams{
+			Username: verifiedUsername,
+			Context:  utils.DraftNewThread,
+		})
+		if err != nil {
+			utils.Log("CreateThread", "Unable to delete draft", err)
+		}
+
+		scheduledThread := database.FormatPgScheduledThread(pgScheduledThread)
+
+		// Return scheduled thread as JSON object
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		jsonErr := json.NewEncoder(w).Encode(scheduledThread)
+
+		if jsonErr != nil {
+			utils.Log("CreateThread", "Unable to encode scheduled thread as JSON", jsonErr)
+			return
+		}
+
+		utils.Log("CreateThread", "Thread: "+scheduledThread.ID+" scheduled by: "+verifiedUsername, nil)
+
+		return
+	}
+
+	// Render the body, linking the mentions of existing users
+	bodyHtml, err := database.RenderBody(ctx, queries, body)
+
+	if err != nil {
+		utils.Log("CreateThread", "Unable to render body", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("CreateThread", "Unable to write response", err)
+		}
+		return
+	}
+
 	// Begin a new transaction
 	tx, err := conn.Begin(ctx)
 	if err != nil {
@@ -127,9 +376,13 @@ func CreateThread(w http.ResponseWriter, r *http.Request) {
 
 	// Create the thread
 	thread, err := qtx.CreateThread(ctx, database.CreateThreadParams{
-		Creator: verifiedUsername,
-		Title:   title,
-		Body:    body})
+		Creator:         verifiedUsername,
+		Title:           title,
+		Body:            body,
+		BodyHtml:        bodyHtml,
+		BodyHtmlVersion: utils.MarkdownVersion,
+		BodySimhash:     pgtype.Int8{Int64: utils.SimHash(body), Valid: true},
+		CategoryID:      pgCategoryId})
 
 	if err != nil {
 		utils.Log("CreateThread", "Unable to create thread", err)
@@ -169,6 +422,38 @@ func CreateThread(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Attach the poll to the thread
+	if threadCreate.Poll != nil {
+		err = setPoll(ctx, qtx, pgThreadId, *threadCreate.Poll, true)
+		if err != nil {
+			utils.Log("CreateThread", "Unable to create poll", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("CreateThread", "Unable to write response", err)
+			}
+			return
+		}
+	}
+
+	// Attach the attachments to the thread
+	if len(pgAttachmentIds) > 0 {
+		_, err = qtx.AttachToThread(ctx, database.AttachToThreadParams{
+			ThreadID:      pgThreadId,
+			AttachmentIds: pgAttachmentIds,
+			Uploader:      verifiedUsername,
+		})
+		if err != nil {
+			utils.Log("CreateThread", "Unable to attach attachments", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("CreateThread", "Unable to write response", err)
+			}
+			return
+		}
+	}
+
 	err = tx.Commit(ctx)
 
 	if err != nil {
@@ -183,7 +468,31 @@ func CreateThread(w http.ResponseWriter, r *http.Request) {
 
 	hasCommitted = true
 
-	pgCreatedThread, err := queries.GetThreadDetails(ctx, pgThreadId)
+	// Record the users mentioned and notify them, once the thread has been saved
+	_, err = queries.SetThreadMentions(ctx, database.SetThreadMentionsParams{
+		ThreadID:           pgThreadId,
+		CanonicalUsernames: utils.ExtractMentions(body),
+	})
+	if err != nil {
+		utils.Log("CreateThread", "Unable to record mentions", err)
+	}
+
+	// The new thread may change the related threads of other threads
+	utils.InvalidateRelatedThreads()
+
+	// The draft is no longer needed once it has been posted
+	_, err = queries.DeleteDraft(ctx, database.DeleteDraftParams{
+		Username: verifiedUsername,
+		Context:  utils.DraftNewThread,
+	})
+	if err != nil {
+		utils.Log("CreateThread", "Unable to delete draft", err)
+	}
+
+	pgCreatedThread, err := queries.GetThreadDetails(ctx, database.GetThreadDetailsParams{
+		ID:     pgThreadId,
+		Viewer: verifiedUsername,
+	})
 
 	if err != nil {
 		utils.Log("CreateThread", "Unable to get thread details", err)
@@ -197,6 +506,30 @@ func CreateThread(w http.ResponseWriter, r *http.Request) {
 
 	createdThread := database.FormatPgThread(pgCreatedThread)
 
+	createdThread.Poll, err = getPoll(ctx, queries, pgThreadId, verifiedUsername)
+
+	if err != nil {
+		utils.Log("CreateThread", "Unable to get poll", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("CreateThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	createdThread.Attachments, err = attachments.GetThreadAttachments(ctx, queries, pgThreadId)
+
+	if err != nil {
+		utils.Log("CreateThread", "Unable to get attachments", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("CreateThread", "Unable to write response", err)
+		}
+		return
+	}
+
 	// Return thread as JSON object
 	w.Header().Set("Content-Type", "application/json")
 	jsonErr := json.NewEncoder(w).Encode(createdThread)
@@ -0,0 +1,200 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// DeletePollVote godoc
+// @Summary Handles poll vote removal requests
+// @Description Removes the votes of the user on the poll of the thread with the given ID
+// @Tags thread
+// @Produce json
+// @Param id path string true "Thread ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.Poll
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "Poll is closed"
+// @Failure 404 "Poll not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/poll/vote [delete]
+func DeletePollVote(w http.ResponseWriter, r *http.Request) {
+	// Only DELETE
+	if r.Method != http.MethodDelete {
+		utils.Log("DeletePollVote", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("DeletePollVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	threadId := mux.Vars(r)["id"]
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("DeletePollVote", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("DeletePollVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Create thread UUID for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("DeletePollVote", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("DeletePollVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Begin a new transaction
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		utils.Log("DeletePollVote", "Unable to begin transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("DeletePollVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	var hasCommitted = false
+
+	defer func(tx pgx.Tx, ctx context.Context) {
+		if hasCommitted {
+			return
+		}
+		err := tx.Rollback(ctx)
+		if err != nil {
+			utils.Log("DeletePollVote", "Unable to rollback transaction", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("DeletePollVote", "Unable to write response", err)
+			}
+		}
+	}(tx, ctx)
+
+	qtx := queries.WithTx(tx)
+
+	// Lock the poll so that votes of the user are changed one request at a time
+	pgPoll, err := qtx.GetPollForUpdate(ctx, pgThreadId)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("DeletePollVote", "Poll of thread "+threadId+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Poll not found"))
+			if err != nil {
+				utils.Log("DeletePollVote", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("DeletePollVote", "Unable to get poll of thread "+threadId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("DeletePollVote", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	if pgPoll.IsClosed {
+		utils.Log("DeletePollVote", "Poll of thread "+threadId+" is closed", errors.New("poll is closed"))
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("Poll is closed"))
+		if err != nil {
+			utils.Log("DeletePollVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Remove the votes of the user, closed polls keep their votes
+	_, err = qtx.DeletePollVotes(ctx, database.DeletePollVotesParams{
+		ThreadID: pgThreadId,
+		Username: verifiedUsername,
+	})
+
+	if err != nil {
+		utils.Log("DeletePollVote", "Unable to remove votes on poll of thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("DeletePollVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		utils.Log("DeletePollVote", "Unable to commit transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("DeletePollVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	hasCommitted = true
+
+	poll, err := getPoll(ctx, queries, pgThreadId, verifiedUsername)
+
+	if err != nil || poll == nil {
+		utils.Log("DeletePollVote", "Unable to get poll of thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("DeletePollVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return poll as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(poll)
+
+	if jsonErr != nil {
+		utils.Log("DeletePollVote", "Unable to encode poll as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("DeletePollVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("DeletePollVote", "Poll of thread "+threadId+" votes removed by: "+verifiedUsername, nil)
+
+	return
+}
@@ -12,7 +12,7 @@ import (
 
 // DeleteThread godoc
 // @Summary Handles thread deletion requests
-// @Description Deletes the thread with the given ID
+// @Description Deletes the thread with the given ID. The thread can be restored until it is purged.
 // @Tags thread
 // @Accept json
 // @Produce json
@@ -106,6 +106,9 @@ func DeleteThread(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The deleted thread must no longer be suggested as a related thread
+	utils.InvalidateRelatedThreads()
+
 	utils.Log("DeleteThread", "Thread deleted: "+threadId+" by: "+verifiedUsername, nil)
 
 	return
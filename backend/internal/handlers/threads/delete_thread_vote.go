@@ -0,0 +1,135 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// DeleteThreadVote godoc
+// @Summary Handles thread vote removal requests
+// @Description Removes the vote of the user on the thread with the given ID
+// @Tags thread
+// @Produce json
+// @Param id path string true "Thread ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.VoteResponse
+// @Failure 401 "Invalid JWT token"
+// @Failure 404 "Thread not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/vote [delete]
+func DeleteThreadVote(w http.ResponseWriter, r *http.Request) {
+	// Only DELETE
+	if r.Method != http.MethodDelete {
+		utils.Log("DeleteThreadVote", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("DeleteThreadVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	threadId := mux.Vars(r)["id"]
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("DeleteThreadVote", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("DeleteThreadVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Create thread UUID for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("DeleteThreadVote", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("DeleteThreadVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Remove the vote, the score is updated by a trigger
+	err = queries.DeleteThreadVote(ctx, database.DeleteThreadVoteParams{
+		ThreadID: pgThreadId,
+		Username: verifiedUsername,
+	})
+
+	if err != nil {
+		utils.Log("DeleteThreadVote", "Unable to remove vote on thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("DeleteThreadVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	score, err := queries.GetThreadScore(ctx, pgThreadId)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("DeleteThreadVote", "Thread "+threadId+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Thread not found"))
+			if err != nil {
+				utils.Log("DeleteThreadVote", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("DeleteThreadVote", "Unable to get score of thread "+threadId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("DeleteThreadVote", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	// Return the new score as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(models.VoteResponse{
+		Score:  score,
+		MyVote: 0,
+	})
+
+	if jsonErr != nil {
+		utils.Log("DeleteThreadVote", "Unable to encode vote as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("DeleteThreadVote", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("DeleteThreadVote", "Vote on thread "+threadId+" removed by: "+verifiedUsername, nil)
+
+	return
+}
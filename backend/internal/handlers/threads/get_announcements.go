@@ -0,0 +1,68 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// GetAnnouncements godoc
+// @Summary Handles announcement retrieval requests
+// @Description Retrieves the site-wide announcements that have not expired, latest first
+// @Tags thread
+// @Produce json
+// @Success 200 {array} models.Thread
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /announcement [get]
+func GetAnnouncements(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetAnnouncements", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetAnnouncements", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	pgAnnouncements, err := queries.GetAnnouncements(ctx, utils.GetRequestUsername(r))
+
+	if err != nil {
+		utils.Log("GetAnnouncements", "Unable to get announcements", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetAnnouncements", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return announcements as JSON array
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(database.FormatPgAnnouncements(pgAnnouncements))
+
+	if jsonErr != nil {
+		utils.Log("GetAnnouncements", "Unable to encode announcements as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetAnnouncements", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetAnnouncements", "Announcements retrieved", nil)
+
+	return
+}
@@ -0,0 +1,128 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// GetRelatedThreads godoc
+// @Summary Handles related thread requests
+// @Description Retrieves up to 5 threads related to the thread with the given ID, ranked by the number of tags they
+// @Description share with it and by the full-text similarity of their titles and bodies to its title.
+// @Description Results are cached, and the cache is cleared whenever the tags of a thread change.
+// @Tags thread
+// @Accept json
+// @Produce json
+// @Param id path string true "Thread ID"
+// @Success 200 {array} models.RelatedThread
+// @Success 301 "Thread was merged into another thread"
+// @Failure 404 "Thread not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/related [get]
+func GetRelatedThreads(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetRelatedThreads", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetRelatedThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request url
+	threadId := mux.Vars(r)["id"]
+
+	var pgThreadId pgtype.UUID
+	err := pgThreadId.Scan(threadId)
+
+	if err != nil {
+		utils.Log("GetRelatedThreads", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetRelatedThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Use the cached related threads if there are any
+	cacheKey := database.FormatPgUuid(pgThreadId)
+	relatedThreads, generation, isCached := utils.GetCachedRelatedThreads(cacheKey)
+
+	if !isCached {
+		// Connect to database
+		ctx := context.Background()
+		conn := database.GetConnection()
+		defer database.CloseConnection(conn)
+		queries := database.New(conn)
+
+		// Check that the thread exists, it may have been merged into another thread
+		_, err = queries.GetThreadLockDetails(ctx, pgThreadId)
+
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				if redirectToMergedThread(ctx, queries, w, r, threadId, pgThreadId) {
+					return
+				}
+				utils.Log("GetRelatedThreads", "Thread "+threadId+" not found", err)
+				w.WriteHeader(http.StatusNotFound)
+				_, err := w.Write([]byte("Thread not found"))
+				if err != nil {
+					utils.Log("GetRelatedThreads", "Unable to write response", err)
+				}
+			} else {
+				utils.Log("GetRelatedThreads", "Unable to get thread "+threadId, err)
+				w.WriteHeader(http.StatusInternalServerError)
+				_, err := w.Write([]byte("Internal server error"))
+				if err != nil {
+					utils.Log("GetRelatedThreads", "Unable to write response", err)
+				}
+			}
+			return
+		}
+
+		pgRelatedThreads, err := queries.GetRelatedThreads(ctx, database.GetRelatedThreadsParams{
+			ThreadID:   pgThreadId,
+			MaxThreads: utils.MaxRelatedThreads,
+		})
+
+		if err != nil {
+			utils.Log("GetRelatedThreads", "Unable to get related threads of thread "+threadId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("GetRelatedThreads", "Unable to write response", err)
+			}
+			return
+		}
+
+		relatedThreads = database.FormatPgRelatedThreads(pgRelatedThreads)
+		utils.CacheRelatedThreads(cacheKey, relatedThreads, generation)
+	}
+
+	// Return related threads as JSON array
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(relatedThreads)
+
+	if jsonErr != nil {
+		utils.Log("GetRelatedThreads", "Unable to encode related threads as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetRelatedThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetRelatedThreads", "Related threads of thread "+threadId+" retrieved", nil)
+}
@@ -0,0 +1,84 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// GetScheduledThreads godoc
+// @Summary Handles scheduled thread list requests
+// @Description Retrieves the threads scheduled by the user that have not been published yet, earliest first
+// @Tags thread
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.ScheduledThread
+// @Failure 401 "Invalid JWT token"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/scheduled [get]
+func GetScheduledThreads(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetScheduledThreads", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetScheduledThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("GetScheduledThreads", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("GetScheduledThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	pgThreads, err := queries.GetScheduledThreads(ctx, verifiedUsername)
+
+	if err != nil {
+		utils.Log("GetScheduledThreads", "Unable to get scheduled threads of "+verifiedUsername, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetScheduledThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return scheduled threads as JSON array
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(database.FormatPgScheduledThreads(pgThreads))
+
+	if jsonErr != nil {
+		utils.Log("GetScheduledThreads", "Unable to encode scheduled threads as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetScheduledThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetScheduledThreads", "Scheduled threads retrieved for: "+verifiedUsername, nil)
+
+	return
+}
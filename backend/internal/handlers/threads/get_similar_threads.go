@@ -0,0 +1,131 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// GetSimilarThreads godoc
+// @Summary Handles duplicate thread detection requests
+// @Description Retrieves up to 5 threads that are likely duplicates of a draft thread, so that the user can be warned
+// @Description before creating it. Threads are matched by the trigram similarity of their titles to the draft title,
+// @Description or by the SimHash fingerprints of their bodies if the draft body is given. Near-duplicate bodies are
+// @Description returned first.
+// @Tags thread
+// @Accept json
+// @Produce json
+// @Param data body models.SimilarThreadsRequest true "Draft thread"
+// @Security ApiKeyAuth
+// @Success 200 {array} models.SimilarThread
+// @Failure 400 "Invalid data"
+// @Failure 401 "Invalid JWT token"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/similar [post]
+func GetSimilarThreads(w http.ResponseWriter, r *http.Request) {
+	// Only POST
+	if r.Method != http.MethodPost {
+		utils.Log("GetSimilarThreads", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetSimilarThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	var similarRequest models.SimilarThreadsRequest
+	err := json.NewDecoder(r.Body).Decode(&similarRequest)
+
+	if err != nil {
+		utils.Log("GetSimilarThreads", "Unable to decode JSON", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("GetSimilarThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	title := strings.TrimSpace(similarRequest.Title)
+	body := strings.TrimSpace(similarRequest.Body)
+
+	// Check if fields are valid, the body is optional
+	if len(title) == 0 || len(title) > 100 || len(body) > 3000 {
+		utils.Log("GetSimilarThreads", "Invalid inputs", errors.New("invalid input"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("GetSimilarThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("GetSimilarThreads", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("GetSimilarThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Without a body, threads are only matched by title
+	maxDistance := int32(utils.NearDuplicateDistance)
+	if len(body) == 0 {
+		maxDistance = -1
+	}
+
+	pgThreads, err := queries.GetSimilarThreads(ctx, database.GetSimilarThreadsParams{
+		Title:       title,
+		BodySimhash: utils.SimHash(body),
+		MaxDistance: maxDistance,
+		MaxThreads:  utils.MaxSimilarThreads,
+	})
+
+	if err != nil {
+		utils.Log("GetSimilarThreads", "Unable to get similar threads", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetSimilarThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	threads := database.FormatPgSimilarThreads(pgThreads)
+
+	// Return similar threads as JSON array
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(threads)
+
+	if jsonErr != nil {
+		utils.Log("GetSimilarThreads", "Unable to encode similar threads as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetSimilarThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetSimilarThreads", "Similar threads retrieved for: "+verifiedUsername, nil)
+}
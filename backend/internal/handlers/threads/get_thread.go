@@ -2,6 +2,7 @@ package threads
 
 import (
 	"backend/internal/database"
+	"backend/internal/handlers/attachments"
 	"backend/internal/utils"
 	"context"
 	"encoding/json"
@@ -13,12 +14,16 @@ import (
 
 // GetThread godoc
 // @Summary Handles thread retrieval requests
-// @Description Retrieves the thread with the given ID
+// @Description Retrieves the thread with the given ID and records a view of the thread.
+// @Description Views are counted at most once per user or IP address within a time window.
+// @Description The results of the poll of the thread, if any, are included.
+// @Description If the thread was merged into another thread, redirects to that thread instead.
 // @Tags thread
 // @Accept json
 // @Produce json
 // @Param id path string true "Thread ID"
 // @Success 200 {object} models.Thread
+// @Success 301 "Thread was merged into another thread"
 // @Failure 404 "Thread not found"
 // @Failure 405 "Method not allowed"
 // @Failure 500 "Internal server error"
@@ -59,10 +64,18 @@ func GetThread(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create the thread
-	pgThread, err := queries.GetThreadDetails(ctx, pgThreadId)
+	viewer := utils.GetRequestUsername(r)
+	pgThread, err := queries.GetThreadDetails(ctx, database.GetThreadDetailsParams{
+		ID:     pgThreadId,
+		Viewer: viewer,
+	})
 
 	if err != nil {
 		if err.Error() == "no rows in result set" {
+			// The thread may have been merged into another thread
+			if redirectToMergedThread(ctx, queries, w, r, id, pgThreadId) {
+				return
+			}
 			utils.Log("GetThread", "Thread"+id+" not found", err)
 			w.WriteHeader(http.StatusNotFound)
 			_, err := w.Write([]byte("Thread not found"))
@@ -82,6 +95,33 @@ func GetThread(w http.ResponseWriter, r *http.Request) {
 
 	thread := database.FormatPgThread(pgThread)
 
+	thread.Poll, err = getPoll(ctx, queries, pgThreadId, viewer)
+
+	if err != nil {
+		utils.Log("GetThread", "Unable to get poll of thread "+id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	thread.Attachments, err = attachments.GetThreadAttachments(ctx, queries, pgThreadId)
+
+	if err != nil {
+		utils.Log("GetThread", "Unable to get attachments of thread "+id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Queue the view to be counted in the background
+	utils.RecordThreadView(r, id, viewer)
+
 	// Return thread as JSON object
 	w.Header().Set("Content-Type", "application/json")
 	jsonErr := json.NewEncoder(w).Encode(thread)
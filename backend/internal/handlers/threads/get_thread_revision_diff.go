@@ -0,0 +1,181 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"strconv"
+)
+
+// GetThreadRevisionDiff godoc
+// @Summary Handles thread revision comparison requests
+// @Description Compares two versions of the thread with the given ID.
+// @Description Versions are numbered from 1, where num_revisions + 1 is the current version of the thread.
+// @Description By default, the current version is compared with the version before it.
+// @Tags thread
+// @Produce json
+// @Param id path string true "Thread ID"
+// @Param from query int false "Version to compare from, default 'to - 1'"
+// @Param to query int false "Version to compare to, default the current version"
+// @Success 200 {object} models.RevisionDiff
+// @Failure 400 "Invalid data"
+// @Failure 404 "Thread not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/revisions/diff [get]
+func GetThreadRevisionDiff(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetThreadRevisionDiff", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetThreadRevisionDiff", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	id := mux.Vars(r)["id"]
+	params := r.URL.Query()
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	var pgThreadId pgtype.UUID
+	err := pgThreadId.Scan(id)
+
+	if err != nil {
+		utils.Log("GetThreadRevisionDiff", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetThreadRevisionDiff", "Unable to write response", err)
+		}
+		return
+	}
+
+	pgThread, err := queries.GetThreadDetails(ctx, database.GetThreadDetailsParams{ID: pgThreadId})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("GetThreadRevisionDiff", "Thread "+id+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Thread not found"))
+			if err != nil {
+				utils.Log("GetThreadRevisionDiff", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("GetThreadRevisionDiff", "Unable to get thread "+id, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("GetThreadRevisionDiff", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	// Check the versions to compare
+	currentVersion := pgThread.NumRevisions + 1
+	to := int(currentVersion)
+	if params.Get("to") != "" {
+		to, err = strconv.Atoi(params.Get("to"))
+	}
+	from := max(to-1, 1)
+	if err == nil && params.Get("from") != "" {
+		from, err = strconv.Atoi(params.Get("from"))
+	}
+
+	if err != nil || from < 1 || to < 1 || from > int(currentVersion) || to > int(currentVersion) {
+		utils.Log("GetThreadRevisionDiff", "Invalid versions", errors.New("invalid versions"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("GetThreadRevisionDiff", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get the versions to compare
+	fromVersion, err := getThreadVersion(ctx, queries, pgThread, int32(from))
+
+	if err != nil {
+		utils.Log("GetThreadRevisionDiff", "Unable to get version "+strconv.Itoa(from)+" of thread "+id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetThreadRevisionDiff", "Unable to write response", err)
+		}
+		return
+	}
+
+	toVersion, err := getThreadVersion(ctx, queries, pgThread, int32(to))
+
+	if err != nil {
+		utils.Log("GetThreadRevisionDiff", "Unable to get version "+strconv.Itoa(to)+" of thread "+id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetThreadRevisionDiff", "Unable to write response", err)
+		}
+		return
+	}
+
+	tagsAdded, tagsRemoved := utils.DiffTags(fromVersion.Tags, toVersion.Tags)
+
+	// Return differences as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(models.RevisionDiff{
+		From:        int32(from),
+		To:          int32(to),
+		Title:       utils.DiffWords(fromVersion.Title, toVersion.Title),
+		Body:        utils.DiffLines(fromVersion.Body, toVersion.Body),
+		TagsAdded:   tagsAdded,
+		TagsRemoved: tagsRemoved,
+	})
+
+	if jsonErr != nil {
+		utils.Log("GetThreadRevisionDiff", "Unable to encode differences as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetThreadRevisionDiff", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetThreadRevisionDiff", "Versions "+strconv.Itoa(from)+" and "+strconv.Itoa(to)+
+		" of thread "+id+" compared", nil)
+
+	return
+}
+
+// getThreadVersion Returns the given version of a thread, which is either a stored revision or the current thread.
+func getThreadVersion(ctx context.Context, queries *database.Queries, pgThread database.GetThreadDetailsRow,
+	versionNumber int32) (database.ThreadRevision, error) {
+	if versionNumber > pgThread.NumRevisions {
+		return database.ThreadRevision{
+			ThreadID:       pgThread.ID,
+			RevisionNumber: versionNumber,
+			Title:          pgThread.Title,
+			Body:           pgThread.Body,
+			Tags:           pgThread.Tags,
+		}, nil
+	}
+
+	return queries.GetThreadRevision(ctx, database.GetThreadRevisionParams{
+		ThreadID:       pgThread.ID,
+		RevisionNumber: versionNumber,
+	})
+}
@@ -0,0 +1,112 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// GetThreadRevisions godoc
+// @Summary Handles thread revision history requests
+// @Description Retrieves the previous versions of the thread with the given ID, oldest first.
+// @Description Revision n is the version of the thread before its n-th edit, with the user who made the edit.
+// @Tags thread
+// @Produce json
+// @Param id path string true "Thread ID"
+// @Success 200 {array} models.ThreadRevision
+// @Failure 404 "Thread not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/revisions [get]
+func GetThreadRevisions(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetThreadRevisions", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetThreadRevisions", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request url
+	id := mux.Vars(r)["id"]
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	var pgThreadId pgtype.UUID
+	err := pgThreadId.Scan(id)
+
+	if err != nil {
+		utils.Log("GetThreadRevisions", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetThreadRevisions", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Check that the thread exists
+	isExistingThread, err := queries.CheckThreadExists(ctx, pgThreadId)
+
+	if err != nil {
+		utils.Log("GetThreadRevisions", "Unable to check if thread exists: "+id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetThreadRevisions", "Unable to write response", err)
+		}
+		return
+	}
+
+	if !isExistingThread {
+		utils.Log("GetThreadRevisions", "Thread "+id+" not found", errors.New("thread not found"))
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("Thread not found"))
+		if err != nil {
+			utils.Log("GetThreadRevisions", "Unable to write response", err)
+		}
+		return
+	}
+
+	pgRevisions, err := queries.GetThreadRevisions(ctx, pgThreadId)
+
+	if err != nil {
+		utils.Log("GetThreadRevisions", "Unable to get revisions of thread "+id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetThreadRevisions", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return revisions as JSON array
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(database.FormatPgThreadRevisions(pgRevisions))
+
+	if jsonErr != nil {
+		utils.Log("GetThreadRevisions", "Unable to encode revisions as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetThreadRevisions", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetThreadRevisions", "Revisions of thread "+id+" retrieved", nil)
+
+	return
+}
@@ -0,0 +1,172 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// LockThread godoc
+// @Summary Handles thread lock requests
+// @Description Locks the thread with the given ID so that no new comments can be added, with an optional reason.
+// @Description The creator of the thread and moderators can lock a thread.
+// @Tags thread
+// @Accept json
+// @Param id path string true "Thread ID"
+// @Param data body models.LockThreadRequest false "Lock reason"
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 400 "Invalid data"
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "No permission to lock thread"
+// @Failure 404 "Thread not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/lock [post]
+func LockThread(w http.ResponseWriter, r *http.Request) {
+	// Only POST
+	if r.Method != http.MethodPost {
+		utils.Log("LockThread", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("LockThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request, the body is optional
+	threadId := mux.Vars(r)["id"]
+	var lockRequest models.LockThreadRequest
+	err := json.NewDecoder(r.Body).Decode(&lockRequest)
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		utils.Log("LockThread", "Unable to decode JSON", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("LockThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	reason := strings.TrimSpace(lockRequest.Reason)
+
+	if utf8.RuneCountInString(reason) > 200 {
+		utils.Log("LockThread", "Lock reason too long", errors.New("invalid input"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("LockThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("LockThread", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("LockThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Create thread UUID for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("LockThread", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("LockThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	lockDetails, err := queries.GetThreadLockDetails(ctx, pgThreadId)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("LockThread", "Thread "+threadId+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Thread not found"))
+			if err != nil {
+				utils.Log("LockThread", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("LockThread", "Unable to get thread "+threadId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("LockThread", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	role, err := queries.GetUserRole(ctx, verifiedUsername)
+
+	if err != nil {
+		utils.Log("LockThread", "Unable to get role of user "+verifiedUsername, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("LockThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	if !utils.CanChangeThreadLock(verifiedUsername, role, lockDetails.Creator, lockDetails.LockedBy.String) {
+		utils.Log("LockThread", "User "+verifiedUsername+" cannot lock thread "+threadId, errors.New("no permission"))
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("No permission to lock thread"))
+		if err != nil {
+			utils.Log("LockThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Lock the thread, replacing the reason if it is already locked
+	err = queries.LockThread(ctx, database.LockThreadParams{
+		ID:         pgThreadId,
+		LockReason: reason,
+		LockedBy:   verifiedUsername,
+	})
+
+	if err != nil {
+		utils.Log("LockThread", "Unable to lock thread", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("LockThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("LockThread", "Thread locked: "+threadId+" by: "+verifiedUsername, nil)
+
+	return
+}
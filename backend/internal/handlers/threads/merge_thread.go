@@ -0,0 +1,379 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// MergeThread godoc
+// @Summary Handles thread merge requests
+// @Description Merges the thread with the given ID into the target thread. The opening post of the thread becomes a
+// @Description comment on the target thread and all of its comments are moved there, ordered by their creation time.
+// @Description Subscriptions, bookmarks, notifications, tags, pins and the poll are moved to the target thread, and the
+// @Description thread is replaced by a redirect to it. Threads that both have a poll cannot be merged.
+// @Description Only moderators can merge threads.
+// @Tags thread
+// @Accept json
+// @Produce json
+// @Param id path string true "Thread ID"
+// @Param data body models.MergeThreadRequest true "Target thread"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.Thread
+// @Failure 400 "Invalid data"
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "No permission to merge threads"
+// @Failure 404 "Thread not found"
+// @Failure 405 "Method not allowed"
+// @Failure 409 "Both threads have polls"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/merge [post]
+func MergeThread(w http.ResponseWriter, r *http.Request) {
+	// Only POST
+	if r.Method != http.MethodPost {
+		utils.Log("MergeThread", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	threadId := mux.Vars(r)["id"]
+	var mergeRequest models.MergeThreadRequest
+	err := json.NewDecoder(r.Body).Decode(&mergeRequest)
+
+	if err != nil {
+		utils.Log("MergeThread", "Unable to decode JSON", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	targetThreadId := mergeRequest.TargetThreadId
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("MergeThread", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Create thread UUIDs for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("MergeThread", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// A thread cannot be merged into itself
+	var pgTargetThreadId pgtype.UUID
+
+	err = pgTargetThreadId.Scan(targetThreadId)
+	if err != nil || pgTargetThreadId == pgThreadId {
+		utils.Log("MergeThread", "Invalid target thread "+targetThreadId, err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Only moderators can merge threads
+	role, err := queries.GetUserRole(ctx, verifiedUsername)
+
+	if err != nil {
+		utils.Log("MergeThread", "Unable to get role of user "+verifiedUsername, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	if !utils.IsModerator(role) {
+		utils.Log("MergeThread", "User "+verifiedUsername+" cannot merge threads", errors.New("no permission"))
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("No permission to merge threads"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Begin a new transaction
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		utils.Log("MergeThread", "Unable to begin transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	var hasCommitted = false
+
+	defer func(tx pgx.Tx, ctx context.Context) {
+		if hasCommitted {
+			return
+		}
+		err := tx.Rollback(ctx)
+		if err != nil {
+			utils.Log("MergeThread", "Unable to rollback transaction", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("MergeThread", "Unable to write response", err)
+			}
+		}
+	}(tx, ctx)
+
+	qtx := queries.WithTx(tx)
+
+	// Lock both threads, they must exist and not be deleted
+	lockedThreads, err := qtx.LockThreadsForMove(ctx, []pgtype.UUID{pgThreadId, pgTargetThreadId})
+
+	if err != nil {
+		utils.Log("MergeThread", "Unable to lock threads "+threadId+" and "+targetThreadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	if len(lockedThreads) != 2 {
+		utils.Log("MergeThread", "Thread "+threadId+" or "+targetThreadId+" not found", pgx.ErrNoRows)
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("Thread not found"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	var title string
+	for _, lockedThread := range lockedThreads {
+		if lockedThread.ID == pgThreadId {
+			title = lockedThread.Title
+		}
+	}
+
+	// Only one poll can be kept, so threads that both have a poll are not merged
+	pollThreadIds, err := qtx.GetThreadsWithPolls(ctx, []pgtype.UUID{pgThreadId, pgTargetThreadId})
+
+	if err != nil {
+		utils.Log("MergeThread", "Unable to get polls of threads "+threadId+" and "+targetThreadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	if len(pollThreadIds) == 2 {
+		utils.Log("MergeThread", "Threads "+threadId+" and "+targetThreadId+" both have polls",
+			errors.New("both threads have polls"))
+		w.WriteHeader(http.StatusConflict)
+		_, err := w.Write([]byte("Both threads have polls"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Turn the opening post into a comment on the target thread
+	pgCommentId, err := qtx.MoveThreadPostToComment(ctx, database.MoveThreadPostToCommentParams{
+		TargetThreadID: pgTargetThreadId,
+		ThreadID:       pgThreadId,
+	})
+
+	if err != nil {
+		utils.Log("MergeThread", "Unable to move opening post of thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Move subscriptions, bookmarks and notifications before the comments, so that mutes are kept
+	err = qtx.MoveThreadFollowers(ctx, database.MoveThreadFollowersParams{
+		TargetThreadID: pgTargetThreadId,
+		CommentID:      pgCommentId,
+		ThreadID:       pgThreadId,
+	})
+
+	if err != nil {
+		utils.Log("MergeThread", "Unable to move followers of thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Move all comments, they keep their creation time so they are interleaved with the comments of the target thread
+	_, err = qtx.MoveComments(ctx, database.MoveCommentsParams{
+		TargetThreadID: pgTargetThreadId,
+		ThreadID:       pgThreadId,
+	})
+
+	if err != nil {
+		utils.Log("MergeThread", "Unable to move comments of thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Keep the tags, pins and poll of the thread, which would otherwise be deleted with it
+	err = qtx.MoveThreadTagsAndPins(ctx, database.MoveThreadTagsAndPinsParams{
+		TargetThreadID: pgTargetThreadId,
+		ThreadID:       pgThreadId,
+	})
+
+	if err != nil {
+		utils.Log("MergeThread", "Unable to move tags and pins of thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	err = qtx.MovePoll(ctx, database.MovePollParams{
+		TargetThreadID: pgTargetThreadId,
+		ThreadID:       pgThreadId,
+	})
+
+	if err != nil {
+		utils.Log("MergeThread", "Unable to move poll of thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Replace the thread with a redirect to the target thread
+	err = qtx.CreateThreadRedirect(ctx, database.CreateThreadRedirectParams{
+		ThreadID:       pgThreadId,
+		TargetThreadID: pgTargetThreadId,
+		Title:          title,
+		CreatedBy:      verifiedUsername,
+	})
+
+	if err != nil {
+		utils.Log("MergeThread", "Unable to create redirect of thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	err = qtx.DeleteMergedThread(ctx, pgThreadId)
+
+	if err != nil {
+		utils.Log("MergeThread", "Unable to delete thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		utils.Log("MergeThread", "Unable to commit transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	hasCommitted = true
+
+	// The merged thread no longer exists, and its comments may change the related threads of the target thread
+	utils.InvalidateRelatedThreads()
+
+	thread, err := getMovedThread(ctx, queries, pgTargetThreadId, verifiedUsername)
+
+	if err != nil {
+		utils.Log("MergeThread", "Unable to get thread "+targetThreadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return the target thread as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(thread)
+
+	if jsonErr != nil {
+		utils.Log("MergeThread", "Unable to encode thread as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MergeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("MergeThread", "Thread "+threadId+" merged into "+targetThreadId+" by: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,39 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/handlers/attachments"
+	"backend/internal/models"
+	"context"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// getMovedThread Returns the thread with the given ID, with its poll and attachments, after comments have been moved
+// to it by a merge or split, or after it has been moved to another category.
+func getMovedThread(ctx context.Context, queries *database.Queries, pgThreadId pgtype.UUID,
+	viewer string) (models.Thread, error) {
+	pgThread, err := queries.GetThreadDetails(ctx, database.GetThreadDetailsParams{
+		ID:     pgThreadId,
+		Viewer: viewer,
+	})
+
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	thread := database.FormatPgThread(pgThread)
+
+	thread.Poll, err = getPoll(ctx, queries, pgThreadId, viewer)
+
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	thread.Attachments, err = attachments.GetThreadAttachments(ctx, queries, pgThreadId)
+
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	return thread, nil
+}
@@ -0,0 +1,209 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// MoveThread godoc
+// @Summary Handles thread move requests
+// @Description Moves the thread with the given ID to another category. The posting role of the category does not
+// @Description apply, as only moderators can move threads.
+// @Tags thread
+// @Accept json
+// @Produce json
+// @Param id path string true "Thread ID"
+// @Param data body models.MoveThreadRequest true "Target category"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.Thread
+// @Failure 400 "Invalid data"
+// @Failure 400 "Invalid category"
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "No permission to move threads"
+// @Failure 404 "Thread not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/move [post]
+func MoveThread(w http.ResponseWriter, r *http.Request) {
+	// Only POST
+	if r.Method != http.MethodPost {
+		utils.Log("MoveThread", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("MoveThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	threadId := mux.Vars(r)["id"]
+	var moveRequest models.MoveThreadRequest
+	err := json.NewDecoder(r.Body).Decode(&moveRequest)
+
+	if err != nil {
+		utils.Log("MoveThread", "Unable to decode JSON", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("MoveThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	categoryId := moveRequest.CategoryId
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("MoveThread", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("MoveThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Create thread and category UUIDs for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("MoveThread", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MoveThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	var pgCategoryId pgtype.UUID
+
+	err = pgCategoryId.Scan(categoryId)
+	if err != nil {
+		utils.Log("MoveThread", "Invalid category "+categoryId, err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid category"))
+		if err != nil {
+			utils.Log("MoveThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Only moderators can move threads
+	role, err := queries.GetUserRole(ctx, verifiedUsername)
+
+	if err != nil {
+		utils.Log("MoveThread", "Unable to get role of user "+verifiedUsername, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MoveThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	if !utils.IsModerator(role) {
+		utils.Log("MoveThread", "User "+verifiedUsername+" cannot move threads", errors.New("no permission"))
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("No permission to move threads"))
+		if err != nil {
+			utils.Log("MoveThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Check if the category exists
+	_, err = queries.GetCategory(ctx, pgCategoryId)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("MoveThread", "Category "+categoryId+" not found", err)
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("Invalid category"))
+			if err != nil {
+				utils.Log("MoveThread", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("MoveThread", "Unable to get category "+categoryId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("MoveThread", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	_, err = queries.MoveThreadToCategory(ctx, database.MoveThreadToCategoryParams{
+		CategoryID: pgCategoryId,
+		ID:         pgThreadId,
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("MoveThread", "Thread "+threadId+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Thread not found"))
+			if err != nil {
+				utils.Log("MoveThread", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("MoveThread", "Unable to move thread "+threadId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("MoveThread", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	thread, err := getMovedThread(ctx, queries, pgThreadId, verifiedUsername)
+
+	if err != nil {
+		utils.Log("MoveThread", "Unable to get thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MoveThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return the moved thread as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(thread)
+
+	if jsonErr != nil {
+		utils.Log("MoveThread", "Unable to encode thread as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("MoveThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("MoveThread", "Thread "+threadId+" moved to category "+categoryId+" by: "+verifiedUsername, nil)
+
+	return
+}
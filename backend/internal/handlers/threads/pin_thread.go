@@ -0,0 +1,173 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgtype"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// PinThread godoc
+// @Summary Handles thread pin requests
+// @Description Pins the thread with the given ID so that it is listed before other threads. Only available to moderators.
+// @Description A thread can be pinned everywhere or only when searching for a tag, and can be made a site-wide announcement.
+// @Tags thread
+// @Accept json
+// @Param id path string true "Thread ID"
+// @Param data body models.PinThreadRequest false "Pin data"
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 400 "Invalid data"
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "No permission to pin thread"
+// @Failure 404 "Thread not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/pin [post]
+func PinThread(w http.ResponseWriter, r *http.Request) {
+	// Only POST
+	if r.Method != http.MethodPost {
+		utils.Log("PinThread", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("PinThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request, the body is optional
+	threadId := mux.Vars(r)["id"]
+	var pinRequest models.PinThreadRequest
+	err := json.NewDecoder(r.Body).Decode(&pinRequest)
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		utils.Log("PinThread", "Unable to decode JSON", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("PinThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	tag := strings.TrimSpace(pinRequest.Tag)
+
+	// Check if fields are valid
+	if (tag != "" && (len(tag) > 30 || !regexp.MustCompile(`^[a-zA-Z0-9-]+$`).MatchString(tag))) ||
+		(tag != "" && pinRequest.IsAnnouncement) ||
+		(pinRequest.ExpiresTime != nil && pinRequest.ExpiresTime.Before(time.Now())) {
+		utils.Log("PinThread", "Invalid inputs", errors.New("invalid input"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("PinThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("PinThread", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("PinThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Check if user is a moderator
+	role, err := queries.GetUserRole(ctx, verifiedUsername)
+
+	if err != nil || !utils.IsModerator(role) {
+		utils.Log("PinThread", "User is not a moderator: "+verifiedUsername, err)
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("No permission to pin thread"))
+		if err != nil {
+			utils.Log("PinThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Create thread UUID for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("PinThread", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("PinThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	isExistingThread, err := queries.CheckThreadExists(ctx, pgThreadId)
+
+	if err != nil {
+		utils.Log("PinThread", "Unable to check if thread exists: "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("PinThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	if !isExistingThread {
+		utils.Log("PinThread", "Thread "+threadId+" not found", errors.New("thread not found"))
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("Thread not found"))
+		if err != nil {
+			utils.Log("PinThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	var pgExpiresTime pgtype.Timestamptz
+	if pinRequest.ExpiresTime != nil {
+		pgExpiresTime = pgtype.Timestamptz{Time: *pinRequest.ExpiresTime, Valid: true}
+	}
+
+	err = queries.PinThread(ctx, database.PinThreadParams{
+		ThreadID:       pgThreadId,
+		TagName:        tag,
+		IsAnnouncement: pinRequest.IsAnnouncement,
+		PinnedBy:       pgtype.Text{String: verifiedUsername, Valid: true},
+		ExpiresTime:    pgExpiresTime,
+	})
+
+	if err != nil {
+		utils.Log("PinThread", "Unable to pin thread", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("PinThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("PinThread", "Thread pinned: "+threadId+" by: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,62 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// getPoll Returns the poll of the thread with its results as seen by the viewer, or nil if the thread has no poll.
+func getPoll(ctx context.Context, queries *database.Queries, pgThreadId pgtype.UUID, viewer string) (*models.Poll,
+	error) {
+	pgPoll, err := queries.GetPoll(ctx, pgThreadId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	pgOptions, err := queries.GetPollOptions(ctx, database.GetPollOptionsParams{
+		ThreadID: pgThreadId,
+		Viewer:   viewer,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	poll := database.FormatPgPoll(pgPoll, pgOptions)
+	return &poll, nil
+}
+
+// setPoll Creates or replaces the poll of the thread. The options are only replaced if replaceOptions is true.
+func setPoll(ctx context.Context, queries *database.Queries, pgThreadId pgtype.UUID, poll models.CreatePollRequest,
+	replaceOptions bool) error {
+	var pgCloseTime pgtype.Timestamptz
+	if poll.CloseTime != nil {
+		pgCloseTime = pgtype.Timestamptz{Time: *poll.CloseTime, Valid: true}
+	}
+
+	err := queries.SetPoll(ctx, database.SetPollParams{
+		ThreadID:         pgThreadId,
+		IsMultipleChoice: poll.IsMultipleChoice,
+		IsAnonymous:      poll.IsAnonymous,
+		CloseTime:        pgCloseTime,
+	})
+	if err != nil || !replaceOptions {
+		return err
+	}
+
+	err = queries.DeletePollOptions(ctx, pgThreadId)
+	if err != nil {
+		return err
+	}
+
+	return queries.AddPollOptions(ctx, database.AddPollOptionsParams{
+		ThreadID: pgThreadId,
+		Options:  poll.Options,
+	})
+}
@@ -0,0 +1,190 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"time"
+)
+
+// RescheduleThread godoc
+// @Summary Handles scheduled thread reschedule requests
+// @Description Changes when the scheduled thread with the given ID is published
+// @Tags thread
+// @Accept json
+// @Produce json
+// @Param id path string true "Scheduled thread ID"
+// @Param data body models.RescheduleThreadRequest true "Publish time"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.ScheduledThread
+// @Failure 400 "Invalid data"
+// @Failure 400 "Invalid poll"
+// @Failure 401 "Invalid JWT token"
+// @Failure 404 "Scheduled thread not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/scheduled/{id} [put]
+func RescheduleThread(w http.ResponseWriter, r *http.Request) {
+	// Only PUT
+	if r.Method != http.MethodPut {
+		utils.Log("RescheduleThread", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("RescheduleThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	threadId := mux.Vars(r)["id"]
+	var rescheduleRequest models.RescheduleThreadRequest
+	err := json.NewDecoder(r.Body).Decode(&rescheduleRequest)
+
+	// Threads can only be rescheduled to the future
+	if err != nil || !rescheduleRequest.PublishAt.After(time.Now()) {
+		utils.Log("RescheduleThread", "Invalid publish time", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("RescheduleThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("RescheduleThread", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("RescheduleThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Create thread UUID for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("RescheduleThread", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("RescheduleThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Only the creator of a scheduled thread can reschedule it
+	pollJson, err := queries.GetScheduledThreadPoll(ctx, database.GetScheduledThreadPollParams{
+		ID:      pgThreadId,
+		Creator: verifiedUsername,
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("RescheduleThread", "Scheduled thread "+threadId+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Scheduled thread not found"))
+			if err != nil {
+				utils.Log("RescheduleThread", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("RescheduleThread", "Unable to get poll of scheduled thread "+threadId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("RescheduleThread", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	// The poll must stay open for some time after the thread is published, as when the thread was scheduled
+	if pollJson != nil {
+		var poll models.CreatePollRequest
+		err = json.Unmarshal(pollJson, &poll)
+
+		if err != nil {
+			utils.Log("RescheduleThread", "Unable to decode poll of scheduled thread "+threadId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("RescheduleThread", "Unable to write response", err)
+			}
+			return
+		}
+
+		if poll.CloseTime != nil && !poll.CloseTime.After(rescheduleRequest.PublishAt) {
+			utils.Log("RescheduleThread", "Poll of scheduled thread "+threadId+" closes before publish time",
+				errors.New("invalid poll"))
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("Invalid poll"))
+			if err != nil {
+				utils.Log("RescheduleThread", "Unable to write response", err)
+			}
+			return
+		}
+	}
+
+	pgScheduledThread, err := queries.RescheduleThread(ctx, database.RescheduleThreadParams{
+		ID:          pgThreadId,
+		Creator:     verifiedUsername,
+		PublishTime: pgtype.Timestamptz{Time: rescheduleRequest.PublishAt, Valid: true},
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("RescheduleThread", "Scheduled thread "+threadId+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Scheduled thread not found"))
+			if err != nil {
+				utils.Log("RescheduleThread", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("RescheduleThread", "Unable to reschedule thread "+threadId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("RescheduleThread", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	// Return scheduled thread as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(database.FormatPgScheduledThread(pgScheduledThread))
+
+	if jsonErr != nil {
+		utils.Log("RescheduleThread", "Unable to encode scheduled thread as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("RescheduleThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("RescheduleThread", "Thread "+threadId+" rescheduled by: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,145 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// RestoreThread godoc
+// @Summary Handles thread restore requests
+// @Description Restores the deleted thread with the given ID.
+// @Description The creator can restore a thread they deleted within the restore window, and moderators can restore
+// @Description any deleted thread until it is purged.
+// @Tags thread
+// @Param id path string true "Thread ID"
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "No permission to restore thread"
+// @Failure 404 "Deleted thread not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/restore [post]
+func RestoreThread(w http.ResponseWriter, r *http.Request) {
+	// Only POST
+	if r.Method != http.MethodPost {
+		utils.Log("RestoreThread", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("RestoreThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	threadId := mux.Vars(r)["id"]
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("RestoreThread", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("RestoreThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Create thread UUID for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("RestoreThread", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("RestoreThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	deletedThread, err := queries.GetDeletedThread(ctx, pgThreadId)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("RestoreThread", "Deleted thread "+threadId+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Deleted thread not found"))
+			if err != nil {
+				utils.Log("RestoreThread", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("RestoreThread", "Unable to get deleted thread "+threadId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("RestoreThread", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	role, err := queries.GetUserRole(ctx, verifiedUsername)
+
+	if err != nil {
+		utils.Log("RestoreThread", "Unable to get role of user "+verifiedUsername, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("RestoreThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Creators can only restore threads they deleted themselves, within the restore window
+	isCreatorRestore := deletedThread.Creator == verifiedUsername &&
+		deletedThread.DeletedBy.String == verifiedUsername &&
+		utils.CanAuthorRestore(deletedThread.DeletedTime.Time)
+
+	if !isCreatorRestore && !utils.IsModerator(role) {
+		utils.Log("RestoreThread", "User "+verifiedUsername+" cannot restore thread "+threadId,
+			errors.New("no permission"))
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("No permission to restore thread"))
+		if err != nil {
+			utils.Log("RestoreThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	err = queries.RestoreThread(ctx, pgThreadId)
+
+	if err != nil {
+		utils.Log("RestoreThread", "Unable to restore thread", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("RestoreThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// The restored thread can be suggested as a related thread again
+	utils.InvalidateRelatedThreads()
+
+	utils.Log("RestoreThread", "Thread restored: "+threadId+" by: "+verifiedUsername, nil)
+
+	return
+}
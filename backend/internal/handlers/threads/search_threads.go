@@ -15,13 +15,15 @@ import (
 
 // SearchThreads godoc
 // @Summary Handles thread search requests
-// @Description Retrieves threads matching the given query
+// @Description Retrieves threads matching the given query. If a category is given, only threads in that category or
+// @Description its subcategories are retrieved.
 // @Tags thread
 // @Accept json
 // @Produce json
 // @Param q query string true "Search query"
-// @Param order query string false "Sorting order, default 'created_time_desc'" Enums(created_time_asc, created_time_desc, num_comments_asc, num_comments_desc)
+// @Param order query string false "Sorting order, default 'created_time_desc'" Enums(created_time_asc, created_time_desc, num_comments_asc, num_comments_desc, score_desc, hot_desc, trending_desc, views_desc)
 // @Param p query string false "Page number, default '1'"
+// @Param category query string false "Slug of the category to search in"
 // @Success 200 {object} models.SearchThreadResponse
 // @Failure 405 "Method not allowed"
 // @Failure 500 "Internal server error"
@@ -39,13 +41,15 @@ func SearchThreads(w http.ResponseWriter, r *http.Request) {
 	}
 
 	pageSize := 10
-	availableSortOrders := []string{"created_time_asc", "created_time_desc", "num_comments_asc", "num_comments_desc"}
+	availableSortOrders := []string{"created_time_asc", "created_time_desc", "num_comments_asc", "num_comments_desc",
+		"score_desc", "hot_desc", "trending_desc", "views_desc"}
 
 	// Get details from request
 	params := r.URL.Query()
 	queryString := params.Get("q")
 	page := params.Get("p")
 	order := params.Get("order")
+	category := strings.TrimSpace(params.Get("category"))
 
 	// Check sort order
 	if order == "" || !slices.Contains(availableSortOrders, order) {
@@ -88,6 +92,8 @@ func SearchThreads(w http.ResponseWriter, r *http.Request) {
 		Sortorder: order,
 		Keywords:  formattedKeywords,
 		Tagarray:  parsedTagArray,
+		Category:  category,
+		Viewer:    utils.GetRequestUsername(r),
 	})
 
 	if err != nil {
@@ -103,6 +109,7 @@ func SearchThreads(w http.ResponseWriter, r *http.Request) {
 	totalThreads, err := queries.GetThreadsByCriteriaCount(ctx, database.GetThreadsByCriteriaCountParams{
 		Keywords: formattedKeywords,
 		Tagarray: parsedTagArray,
+		Category: category,
 	})
 
 	if err != nil {
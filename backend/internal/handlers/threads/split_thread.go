@@ -0,0 +1,401 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// SplitThread godoc
+// @Summary Handles thread split requests
+// @Description Moves up to 100 comments of the thread with the given ID to another thread. If target_thread_id is
+// @Description given, the comments are moved to that thread. Otherwise a new thread is created with the given title
+// @Description and tags, and the earliest of the comments becomes its opening post, keeping its creator and creation
+// @Description time. Comments keep their creation time, so they are ordered among the comments of the thread they are
+// @Description moved to. Deleted comments cannot be moved. Only moderators can split threads.
+// @Tags thread
+// @Accept json
+// @Produce json
+// @Param id path string true "Thread ID"
+// @Param data body models.SplitThreadRequest true "Comments to move and where to move them"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.Thread
+// @Failure 400 "Invalid data"
+// @Failure 400 "Invalid comments"
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "No permission to split threads"
+// @Failure 404 "Thread not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/split [post]
+func SplitThread(w http.ResponseWriter, r *http.Request) {
+	// Only POST
+	if r.Method != http.MethodPost {
+		utils.Log("SplitThread", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("SplitThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	threadId := mux.Vars(r)["id"]
+	var splitRequest models.SplitThreadRequest
+	err := json.NewDecoder(r.Body).Decode(&splitRequest)
+
+	if err != nil {
+		utils.Log("SplitThread", "Unable to decode JSON", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("SplitThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Parse the comment IDs, ignoring duplicates
+	var pgCommentIds []pgtype.UUID
+	seenCommentIds := make(map[pgtype.UUID]bool)
+
+	for _, commentId := range splitRequest.CommentIds {
+		var pgCommentId pgtype.UUID
+		err = pgCommentId.Scan(commentId)
+		if err != nil {
+			utils.Log("SplitThread", "Unable to scan commentId "+commentId, err)
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("Invalid comments"))
+			if err != nil {
+				utils.Log("SplitThread", "Unable to write response", err)
+			}
+			return
+		}
+		if !seenCommentIds[pgCommentId] {
+			seenCommentIds[pgCommentId] = true
+			pgCommentIds = append(pgCommentIds, pgCommentId)
+		}
+	}
+
+	if len(pgCommentIds) == 0 || len(pgCommentIds) > 100 {
+		utils.Log("SplitThread", "Invalid number of comments", errors.New("invalid input"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid comments"))
+		if err != nil {
+			utils.Log("SplitThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// A new thread needs a title, and may have tags
+	title := strings.TrimSpace(splitRequest.Title)
+	var tags []string
+	for _, tag := range splitRequest.Tags {
+		trimmedTag := strings.TrimSpace(tag)
+		if len(trimmedTag) > 0 && len(trimmedTag) <= 30 &&
+			regexp.MustCompile(`^[a-zA-Z0-9-]+$`).MatchString(trimmedTag) {
+			tags = append(tags, trimmedTag)
+		}
+	}
+
+	if splitRequest.TargetThreadId == nil && (len(tags) > 3 || len(title) == 0 || len(title) > 100) {
+		utils.Log("SplitThread", "Invalid inputs", errors.New("invalid input"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("SplitThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("SplitThread", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("SplitThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Create thread UUIDs for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("SplitThread", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("SplitThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	threadIds := []pgtype.UUID{pgThreadId}
+
+	// Comments cannot be moved to the thread they are on
+	var pgTargetThreadId pgtype.UUID
+
+	if splitRequest.TargetThreadId != nil {
+		err = pgTargetThreadId.Scan(*splitRequest.TargetThreadId)
+		if err != nil || pgTargetThreadId == pgThreadId {
+			utils.Log("SplitThread", "Invalid target thread "+*splitRequest.TargetThreadId, err)
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("Invalid data"))
+			if err != nil {
+				utils.Log("SplitThread", "Unable to write response", err)
+			}
+			return
+		}
+		threadIds = append(threadIds, pgTargetThreadId)
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Only moderators can split threads
+	role, err := queries.GetUserRole(ctx, verifiedUsername)
+
+	if err != nil {
+		utils.Log("SplitThread", "Unable to get role of user "+verifiedUsername, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("SplitThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	if !utils.IsModerator(role) {
+		utils.Log("SplitThread", "User "+verifiedUsername+" cannot split threads", errors.New("no permission"))
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("No permission to split threads"))
+		if err != nil {
+			utils.Log("SplitThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Begin a new transaction
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		utils.Log("SplitThread", "Unable to begin transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("SplitThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	var hasCommitted = false
+
+	defer func(tx pgx.Tx, ctx context.Context) {
+		if hasCommitted {
+			return
+		}
+		err := tx.Rollback(ctx)
+		if err != nil {
+			utils.Log("SplitThread", "Unable to rollback transaction", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("SplitThread", "Unable to write response", err)
+			}
+		}
+	}(tx, ctx)
+
+	qtx := queries.WithTx(tx)
+
+	// Lock the threads, they must exist and not be deleted
+	lockedThreads, err := qtx.LockThreadsForMove(ctx, threadIds)
+
+	if err != nil {
+		utils.Log("SplitThread", "Unable to lock thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("SplitThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	if len(lockedThreads) != len(threadIds) {
+		utils.Log("SplitThread", "Thread "+threadId+" or target thread not found", pgx.ErrNoRows)
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("Thread not found"))
+		if err != nil {
+			utils.Log("SplitThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// All comments must be on the thread and not be deleted
+	numComments, err := qtx.CountMovableComments(ctx, database.CountMovableCommentsParams{
+		CommentIds: pgCommentIds,
+		ThreadID:   pgThreadId,
+	})
+
+	if err != nil {
+		utils.Log("SplitThread", "Unable to count comments of thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("SplitThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	if int(numComments) != len(pgCommentIds) {
+		utils.Log("SplitThread", "Comments are not on thread "+threadId, errors.New("invalid comments"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid comments"))
+		if err != nil {
+			utils.Log("SplitThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Create the new thread from the earliest comment, which is then no longer a comment
+	if splitRequest.TargetThreadId == nil {
+		newThread, err := qtx.MoveCommentToThread(ctx, database.MoveCommentToThreadParams{
+			CommentIds: pgCommentIds,
+			ThreadID:   pgThreadId,
+			Title:      title,
+		})
+
+		if err != nil {
+			utils.Log("SplitThread", "Unable to create thread from comment", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("SplitThread", "Unable to write response", err)
+			}
+			return
+		}
+
+		err = qtx.DeleteMovedComment(ctx, newThread.CommentID)
+
+		if err != nil {
+			utils.Log("SplitThread", "Unable to delete comment turned into thread", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("SplitThread", "Unable to write response", err)
+			}
+			return
+		}
+
+		// Create tags for the thread
+		err = qtx.AddNewTags(ctx, tags)
+
+		if err != nil {
+			utils.Log("SplitThread", "Unable to create tags", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("SplitThread", "Unable to write response", err)
+			}
+			return
+		}
+
+		err = qtx.AddThreadTags(ctx, database.AddThreadTagsParams{
+			ThreadID: newThread.ThreadID,
+			Tagarray: tags,
+		})
+
+		if err != nil {
+			utils.Log("SplitThread", "Unable to add tags to thread", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("SplitThread", "Unable to write response", err)
+			}
+			return
+		}
+
+		pgTargetThreadId = newThread.ThreadID
+	}
+
+	// Move the remaining comments
+	_, err = qtx.MoveComments(ctx, database.MoveCommentsParams{
+		TargetThreadID: pgTargetThreadId,
+		ThreadID:       pgThreadId,
+		CommentIds:     pgCommentIds,
+	})
+
+	if err != nil {
+		utils.Log("SplitThread", "Unable to move comments of thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("SplitThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		utils.Log("SplitThread", "Unable to commit transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("SplitThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	hasCommitted = true
+
+	// A new thread may have been created with its own tags
+	utils.InvalidateRelatedThreads()
+
+	targetThreadId := database.FormatPgUuid(pgTargetThreadId)
+	thread, err := getMovedThread(ctx, queries, pgTargetThreadId, verifiedUsername)
+
+	if err != nil {
+		utils.Log("SplitThread", "Unable to get thread "+targetThreadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("SplitThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return the thread the comments were moved to as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(thread)
+
+	if jsonErr != nil {
+		utils.Log("SplitThread", "Unable to encode thread as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("SplitThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("SplitThread", "Comments of thread "+threadId+" moved to "+targetThreadId+" by: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,126 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgtype"
+	"io"
+	"net/http"
+)
+
+// SubscribeThread godoc
+// @Summary Handles thread subscription requests
+// @Description Subscribes the user to new comments on the thread with the given ID, or mutes the thread if is_muted is
+// @Description true. Users are subscribed automatically to threads they create or comment on unless they have muted them.
+// @Tags thread
+// @Accept json
+// @Param id path string true "Thread ID"
+// @Param data body models.SubscriptionRequest false "Subscription data"
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 400 "Invalid data"
+// @Failure 401 "Invalid JWT token"
+// @Failure 404 "Thread not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/subscription [put]
+func SubscribeThread(w http.ResponseWriter, r *http.Request) {
+	// Only PUT
+	if r.Method != http.MethodPut {
+		utils.Log("SubscribeThread", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("SubscribeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request, the body is optional
+	threadId := mux.Vars(r)["id"]
+	var subscriptionRequest models.SubscriptionRequest
+	err := json.NewDecoder(r.Body).Decode(&subscriptionRequest)
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		utils.Log("SubscribeThread", "Unable to decode JSON", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("SubscribeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("SubscribeThread", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("SubscribeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Create thread UUID for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("SubscribeThread", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("SubscribeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	numRows, err := queries.SetThreadSubscription(ctx, database.SetThreadSubscriptionParams{
+		ThreadID: pgThreadId,
+		Username: verifiedUsername,
+		IsMuted:  subscriptionRequest.IsMuted,
+	})
+
+	if err != nil {
+		utils.Log("SubscribeThread", "Unable to subscribe to thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("SubscribeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	if numRows == 0 {
+		utils.Log("SubscribeThread", "Thread "+threadId+" not found", errors.New("thread not found"))
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("Thread not found"))
+		if err != nil {
+			utils.Log("SubscribeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	if subscriptionRequest.IsMuted {
+		utils.Log("SubscribeThread", "Thread "+threadId+" muted by: "+verifiedUsername, nil)
+	} else {
+		utils.Log("SubscribeThread", "Thread "+threadId+" subscribed to by: "+verifiedUsername, nil)
+	}
+
+	return
+}
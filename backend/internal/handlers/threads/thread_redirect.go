@@ -0,0 +1,38 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"strings"
+)
+
+// redirectToMergedThread Redirects to the same URL with the thread ID replaced by the ID of the thread that the
+// thread with the given ID was merged into. Returns false if the thread was not merged into another thread.
+func redirectToMergedThread(ctx context.Context, queries *database.Queries, w http.ResponseWriter, r *http.Request,
+	threadId string, pgThreadId pgtype.UUID) bool {
+	pgTargetThreadId, err := queries.GetThreadRedirect(ctx, pgThreadId)
+
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("redirectToMergedThread", "Unable to get redirect of thread "+threadId, err)
+		}
+		return false
+	}
+
+	targetThreadId := database.FormatPgUuid(pgTargetThreadId)
+
+	location := *r.URL
+	location.Path = strings.Replace(r.URL.Path, "/thread/"+threadId, "/thread/"+targetThreadId, 1)
+	location.RawPath = ""
+
+	http.Redirect(w, r, location.String(), http.StatusMovedPermanently)
+
+	utils.Log("redirectToMergedThread", "Redirected from "+threadId+" to "+targetThreadId, nil)
+
+	return true
+}
@@ -0,0 +1,136 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// UnlockThread godoc
+// @Summary Handles thread unlock requests
+// @Description Unlocks the thread with the given ID so that new comments can be added again.
+// @Description Moderators can unlock any thread, while the creator cannot unlock a thread locked by a moderator.
+// @Tags thread
+// @Param id path string true "Thread ID"
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "No permission to unlock thread"
+// @Failure 404 "Thread not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/unlock [post]
+func UnlockThread(w http.ResponseWriter, r *http.Request) {
+	// Only POST
+	if r.Method != http.MethodPost {
+		utils.Log("UnlockThread", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("UnlockThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	threadId := mux.Vars(r)["id"]
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("UnlockThread", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("UnlockThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Create thread UUID for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("UnlockThread", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UnlockThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	lockDetails, err := queries.GetThreadLockDetails(ctx, pgThreadId)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("UnlockThread", "Thread "+threadId+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Thread not found"))
+			if err != nil {
+				utils.Log("UnlockThread", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("UnlockThread", "Unable to get thread "+threadId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("UnlockThread", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	role, err := queries.GetUserRole(ctx, verifiedUsername)
+
+	if err != nil {
+		utils.Log("UnlockThread", "Unable to get role of user "+verifiedUsername, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UnlockThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	if !utils.CanChangeThreadLock(verifiedUsername, role, lockDetails.Creator, lockDetails.LockedBy.String) {
+		utils.Log("UnlockThread", "User "+verifiedUsername+" cannot unlock thread "+threadId,
+			errors.New("no permission"))
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("No permission to unlock thread"))
+		if err != nil {
+			utils.Log("UnlockThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	err = queries.UnlockThread(ctx, pgThreadId)
+
+	if err != nil {
+		utils.Log("UnlockThread", "Unable to unlock thread", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UnlockThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("UnlockThread", "Thread unlocked: "+threadId+" by: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,119 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"strings"
+)
+
+// UnpinThread godoc
+// @Summary Handles thread unpin requests
+// @Description Removes the pin of the thread with the given ID. Only available to moderators.
+// @Tags thread
+// @Param id path string true "Thread ID"
+// @Param tag query string false "Tag the thread is pinned for, default the pin that applies everywhere"
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "No permission to unpin thread"
+// @Failure 404 "Pin not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/pin [delete]
+func UnpinThread(w http.ResponseWriter, r *http.Request) {
+	// Only DELETE
+	if r.Method != http.MethodDelete {
+		utils.Log("UnpinThread", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("UnpinThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	threadId := mux.Vars(r)["id"]
+	tag := strings.TrimSpace(r.URL.Query().Get("tag"))
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("UnpinThread", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("UnpinThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Check if user is a moderator
+	role, err := queries.GetUserRole(ctx, verifiedUsername)
+
+	if err != nil || !utils.IsModerator(role) {
+		utils.Log("UnpinThread", "User is not a moderator: "+verifiedUsername, err)
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("No permission to unpin thread"))
+		if err != nil {
+			utils.Log("UnpinThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Create thread UUID for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("UnpinThread", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UnpinThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	numUnpinned, err := queries.UnpinThread(ctx, database.UnpinThreadParams{
+		ThreadID: pgThreadId,
+		TagName:  tag,
+	})
+
+	if err != nil {
+		utils.Log("UnpinThread", "Unable to unpin thread", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UnpinThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	if numUnpinned == 0 {
+		utils.Log("UnpinThread", "Pin of thread "+threadId+" not found", errors.New("pin not found"))
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("Pin not found"))
+		if err != nil {
+			utils.Log("UnpinThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("UnpinThread", "Thread unpinned: "+threadId+" by: "+verifiedUsername, nil)
+
+	return
+}
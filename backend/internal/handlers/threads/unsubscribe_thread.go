@@ -0,0 +1,103 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// UnsubscribeThread godoc
+// @Summary Handles thread unsubscription requests
+// @Description Removes the subscription or mute of the user on the thread with the given ID.
+// @Description The user is subscribed again automatically if they comment on the thread.
+// @Tags thread
+// @Param id path string true "Thread ID"
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 401 "Invalid JWT token"
+// @Failure 404 "Subscription not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/subscription [delete]
+func UnsubscribeThread(w http.ResponseWriter, r *http.Request) {
+	// Only DELETE
+	if r.Method != http.MethodDelete {
+		utils.Log("UnsubscribeThread", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("UnsubscribeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	threadId := mux.Vars(r)["id"]
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("UnsubscribeThread", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("UnsubscribeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Create thread UUID for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("UnsubscribeThread", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UnsubscribeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	numRows, err := queries.DeleteThreadSubscription(ctx, database.DeleteThreadSubscriptionParams{
+		ThreadID: pgThreadId,
+		Username: verifiedUsername,
+	})
+
+	if err != nil {
+		utils.Log("UnsubscribeThread", "Unable to remove subscription to thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UnsubscribeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	if numRows == 0 {
+		utils.Log("UnsubscribeThread", "Subscription to thread "+threadId+" not found", errors.New("subscription not found"))
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("Subscription not found"))
+		if err != nil {
+			utils.Log("UnsubscribeThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("UnsubscribeThread", "Subscription to thread "+threadId+" removed by: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,245 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"slices"
+)
+
+// UpdatePoll godoc
+// @Summary Handles poll creation and update requests
+// @Description Attaches a poll to the thread with the given ID, or replaces its poll. Only the creator of the thread
+// @Description can do this. Once the poll has votes, its options and whether it is multiple choice or anonymous
+// @Description are locked, and only its closing time can be changed.
+// @Tags thread
+// @Accept json
+// @Produce json
+// @Param id path string true "Thread ID"
+// @Param data body models.CreatePollRequest true "Poll data"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.Poll
+// @Failure 400 "Invalid poll"
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "Not thread creator"
+// @Failure 405 "Method not allowed"
+// @Failure 409 "Poll options are locked"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/poll [put]
+func UpdatePoll(w http.ResponseWriter, r *http.Request) {
+	// Only PUT
+	if r.Method != http.MethodPut {
+		utils.Log("UpdatePoll", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("UpdatePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	threadId := mux.Vars(r)["id"]
+	var pollUpdate models.CreatePollRequest
+	err := json.NewDecoder(r.Body).Decode(&pollUpdate)
+
+	if err != nil || !utils.NormalizePoll(&pollUpdate) {
+		utils.Log("UpdatePoll", "Invalid poll", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid poll"))
+		if err != nil {
+			utils.Log("UpdatePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("UpdatePoll", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("UpdatePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Create thread UUID for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("UpdatePoll", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UpdatePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Begin a new transaction
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		utils.Log("UpdatePoll", "Unable to begin transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UpdatePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	var hasCommitted = false
+
+	defer func(tx pgx.Tx, ctx context.Context) {
+		if hasCommitted {
+			return
+		}
+		err := tx.Rollback(ctx)
+		if err != nil {
+			utils.Log("UpdatePoll", "Unable to rollback transaction", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("UpdatePoll", "Unable to write response", err)
+			}
+		}
+	}(tx, ctx)
+
+	qtx := queries.WithTx(tx)
+
+	// Check if user is the creator of the thread
+	isThreadCreator, err := qtx.CheckThreadCreator(ctx, database.CheckThreadCreatorParams{
+		ID:      pgThreadId,
+		Creator: verifiedUsername,
+	})
+
+	if err != nil || !isThreadCreator {
+		utils.Log("UpdatePoll", "User is not the creator of thread "+threadId, err)
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("Not thread creator"))
+		if err != nil {
+			utils.Log("UpdatePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Lock the poll, if any, so that no votes are added while it is being changed
+	pgPoll, err := qtx.GetPollForUpdate(ctx, pgThreadId)
+	hasPoll := err == nil
+
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		utils.Log("UpdatePoll", "Unable to get poll of thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UpdatePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Options and how votes are counted cannot change once votes exist
+	if hasPoll && pgPoll.HasVotes {
+		currentPoll, err := getPoll(ctx, qtx, pgThreadId, verifiedUsername)
+
+		if err != nil || currentPoll == nil {
+			utils.Log("UpdatePoll", "Unable to get poll of thread "+threadId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("UpdatePoll", "Unable to write response", err)
+			}
+			return
+		}
+
+		var currentOptions []string
+		for _, option := range currentPoll.Options {
+			currentOptions = append(currentOptions, option.Text)
+		}
+
+		if !slices.Equal(currentOptions, pollUpdate.Options) ||
+			currentPoll.IsMultipleChoice != pollUpdate.IsMultipleChoice ||
+			currentPoll.IsAnonymous != pollUpdate.IsAnonymous {
+			utils.Log("UpdatePoll", "Poll of thread "+threadId+" already has votes", errors.New("poll options are locked"))
+			w.WriteHeader(http.StatusConflict)
+			_, err := w.Write([]byte("Poll options are locked"))
+			if err != nil {
+				utils.Log("UpdatePoll", "Unable to write response", err)
+			}
+			return
+		}
+	}
+
+	err = setPoll(ctx, qtx, pgThreadId, pollUpdate, !hasPoll || !pgPoll.HasVotes)
+
+	if err != nil {
+		utils.Log("UpdatePoll", "Unable to update poll of thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UpdatePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		utils.Log("UpdatePoll", "Unable to commit transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UpdatePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	hasCommitted = true
+
+	poll, err := getPoll(ctx, queries, pgThreadId, verifiedUsername)
+
+	if err != nil || poll == nil {
+		utils.Log("UpdatePoll", "Unable to get poll of thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UpdatePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return poll as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(poll)
+
+	if jsonErr != nil {
+		utils.Log("UpdatePoll", "Unable to encode poll as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UpdatePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("UpdatePoll", "Poll of thread "+threadId+" updated by: "+verifiedUsername, nil)
+
+	return
+}
@@ -101,6 +101,19 @@ func UpdateThread(w http.ResponseWriter, r *http.Request) {
 	defer database.CloseConnection(conn)
 	queries := database.New(conn)
 
+	// Render the body, linking the mentions of existing users
+	bodyHtml, err := database.RenderBody(ctx, queries, body)
+
+	if err != nil {
+		utils.Log("UpdateThread", "Unable to render body", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UpdateThread", "Unable to write response", err)
+		}
+		return
+	}
+
 	// Begin a new transaction
 	tx, err := conn.Begin(ctx)
 
@@ -161,12 +174,32 @@ func UpdateThread(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Save the current version of the thread as a revision
+	_, err = qtx.CreateThreadRevision(ctx, database.CreateThreadRevisionParams{
+		ID:       pgThreadId,
+		Title:    title,
+		Body:     body,
+		Tagarray: tags,
+		Editor:   verifiedUsername})
+	if err != nil {
+		utils.Log("UpdateThread", "Unable to save thread revision", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("UpdateThread", "Unable to write response", err)
+		}
+		return
+	}
+
 	// Update the thread
 	err = qtx.UpdateThread(ctx, database.UpdateThreadParams{
-		ID:      pgThreadId,
-		Title:   title,
-		Body:    body,
-		Creator: verifiedUsername})
+		ID:              pgThreadId,
+		Title:           title,
+		Body:            body,
+		BodyHtml:        bodyHtml,
+		BodyHtmlVersion: utils.MarkdownVersion,
+		BodySimhash:     pgtype.Int8{Int64: utils.SimHash(body), Valid: true},
+		Creator:         verifiedUsername})
 	if err != nil {
 		utils.Log("UpdateThread", "Unable to update thread", err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -237,6 +270,27 @@ func UpdateThread(w http.ResponseWriter, r *http.Request) {
 
 	hasCommitted = true
 
+	// Record the users mentioned and notify those mentioned for the first time, once the thread has been saved
+	_, err = queries.SetThreadMentions(ctx, database.SetThreadMentionsParams{
+		ThreadID:           pgThreadId,
+		CanonicalUsernames: utils.ExtractMentions(body),
+	})
+	if err != nil {
+		utils.Log("UpdateThread", "Unable to record mentions", err)
+	}
+
+	// The changed tags may change the related threads of other threads
+	utils.InvalidateRelatedThreads()
+
+	// The draft is no longer needed once it has been posted
+	_, err = queries.DeleteDraft(ctx, database.DeleteDraftParams{
+		Username: verifiedUsername,
+		Context:  utils.EditThreadDraftContext(threadId),
+	})
+	if err != nil {
+		utils.Log("UpdateThread", "Unable to delete draft", err)
+	}
+
 	utils.Log("UpdateThread", "Thread "+threadId+" updated", nil)
 
 	return
@@ -0,0 +1,257 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// VotePoll godoc
+// @Summary Handles poll vote requests
+// @Description Votes on the poll of the thread with the given ID, replacing any previous votes of the user.
+// @Description Exactly one option must be chosen if the poll is single choice.
+// @Tags thread
+// @Accept json
+// @Produce json
+// @Param id path string true "Thread ID"
+// @Param data body models.PollVoteRequest true "Positions of the options voted for"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.Poll
+// @Failure 400 "Invalid data"
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "Email not verified"
+// @Failure 403 "Poll is closed"
+// @Failure 404 "Poll not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/poll/vote [put]
+func VotePoll(w http.ResponseWriter, r *http.Request) {
+	// Only PUT
+	if r.Method != http.MethodPut {
+		utils.Log("VotePoll", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("VotePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	threadId := mux.Vars(r)["id"]
+	var vote models.PollVoteRequest
+	err := json.NewDecoder(r.Body).Decode(&vote)
+
+	if err != nil {
+		utils.Log("VotePoll", "Unable to decode JSON", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("VotePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("VotePoll", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("VotePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Check if user has verified their email
+	isVerified, err := queries.CheckUserVerified(ctx, verifiedUsername)
+
+	if err != nil || !isVerified {
+		utils.Log("VotePoll", "User has not verified their email: "+verifiedUsername, err)
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("Email not verified"))
+		if err != nil {
+			utils.Log("VotePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Create thread UUID for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("VotePoll", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("VotePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Begin a new transaction
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		utils.Log("VotePoll", "Unable to begin transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("VotePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	var hasCommitted = false
+
+	defer func(tx pgx.Tx, ctx context.Context) {
+		if hasCommitted {
+			return
+		}
+		err := tx.Rollback(ctx)
+		if err != nil {
+			utils.Log("VotePoll", "Unable to rollback transaction", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("VotePoll", "Unable to write response", err)
+			}
+		}
+	}(tx, ctx)
+
+	qtx := queries.WithTx(tx)
+
+	// Lock the poll so that the previous votes of the user are replaced one request at a time
+	pgPoll, err := qtx.GetPollForUpdate(ctx, pgThreadId)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("VotePoll", "Poll of thread "+threadId+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Poll not found"))
+			if err != nil {
+				utils.Log("VotePoll", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("VotePoll", "Unable to get poll of thread "+threadId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("VotePoll", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	if pgPoll.IsClosed {
+		utils.Log("VotePoll", "Poll of thread "+threadId+" is closed", errors.New("poll is closed"))
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("Poll is closed"))
+		if err != nil {
+			utils.Log("VotePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	if !utils.IsValidPollVote(vote.Options, pgPoll.NumOptions, pgPoll.IsMultipleChoice) {
+		utils.Log("VotePoll", "Invalid vote", errors.New("invalid vote"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("VotePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Replace the previous votes of the user
+	_, err = qtx.DeletePollVotes(ctx, database.DeletePollVotesParams{
+		ThreadID: pgThreadId,
+		Username: verifiedUsername,
+	})
+
+	if err != nil {
+		utils.Log("VotePoll", "Unable to remove previous votes on poll of thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("VotePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	err = qtx.AddPollVotes(ctx, database.AddPollVotesParams{
+		ThreadID:  pgThreadId,
+		Positions: vote.Options,
+		Username:  verifiedUsername,
+	})
+
+	if err != nil {
+		utils.Log("VotePoll", "Unable to vote on poll of thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("VotePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		utils.Log("VotePoll", "Unable to commit transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("VotePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	hasCommitted = true
+
+	poll, err := getPoll(ctx, queries, pgThreadId, verifiedUsername)
+
+	if err != nil || poll == nil {
+		utils.Log("VotePoll", "Unable to get poll of thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("VotePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return poll as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(poll)
+
+	if jsonErr != nil {
+		utils.Log("VotePoll", "Unable to encode poll as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("VotePoll", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("VotePoll", "Poll of thread "+threadId+" voted on by: "+verifiedUsername, nil)
+
+	return
+}
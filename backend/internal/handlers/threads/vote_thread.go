@@ -0,0 +1,165 @@
+package threads
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// VoteThread godoc
+// @Summary Handles thread vote requests
+// @Description Upvotes or downvotes the thread with the given ID, replacing any previous vote of the user
+// @Tags thread
+// @Accept json
+// @Produce json
+// @Param id path string true "Thread ID"
+// @Param data body models.VoteRequest true "Vote data"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.VoteResponse
+// @Failure 400 "Invalid data"
+// @Failure 401 "Invalid JWT token"
+// @Failure 403 "Email not verified"
+// @Failure 404 "Thread not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /thread/{id}/vote [put]
+func VoteThread(w http.ResponseWriter, r *http.Request) {
+	// Only PUT
+	if r.Method != http.MethodPut {
+		utils.Log("VoteThread", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("VoteThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request
+	threadId := mux.Vars(r)["id"]
+	var vote models.VoteRequest
+	err := json.NewDecoder(r.Body).Decode(&vote)
+
+	if err != nil || (vote.Value != 1 && vote.Value != -1) {
+		utils.Log("VoteThread", "Invalid vote", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("VoteThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("VoteThread", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("VoteThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Check if user has verified their email
+	isVerified, err := queries.CheckUserVerified(ctx, verifiedUsername)
+
+	if err != nil || !isVerified {
+		utils.Log("VoteThread", "User has not verified their email: "+verifiedUsername, err)
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("Email not verified"))
+		if err != nil {
+			utils.Log("VoteThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Create thread UUID for pg
+	var pgThreadId pgtype.UUID
+
+	err = pgThreadId.Scan(threadId)
+	if err != nil {
+		utils.Log("VoteThread", "Unable to scan threadId", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("VoteThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Set the vote, the score is updated by a trigger
+	myVote, err := queries.SetThreadVote(ctx, database.SetThreadVoteParams{
+		ThreadID: pgThreadId,
+		Username: verifiedUsername,
+		Value:    int16(vote.Value),
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("VoteThread", "Thread "+threadId+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("Thread not found"))
+			if err != nil {
+				utils.Log("VoteThread", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("VoteThread", "Unable to vote on thread "+threadId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("VoteThread", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	score, err := queries.GetThreadScore(ctx, pgThreadId)
+
+	if err != nil {
+		utils.Log("VoteThread", "Unable to get score of thread "+threadId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("VoteThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return the new score as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(models.VoteResponse{
+		Score:  score,
+		MyVote: int32(myVote),
+	})
+
+	if jsonErr != nil {
+		utils.Log("VoteThread", "Unable to encode vote as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("VoteThread", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("VoteThread", "Thread "+threadId+" voted on by: "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,295 @@
+package user
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ChangeUsername godoc
+// @Summary Handles username change requests
+// @Description Changes the username of the current user. All threads and comments are moved to the new username,
+// @Description and links to the old username redirect to the new one. Returns a new token for the new username.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param data body models.ChangeUsernameRequest true "New username"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.AuthResponse
+// @Failure 400 "Invalid data"
+// @Failure 400 "Username is reserved"
+// @Failure 400 "Username already exists"
+// @Failure 401 "Invalid JWT token"
+// @Failure 405 "Method not allowed"
+// @Failure 429 "Username changed too recently"
+// @Failure 500 "Internal server error"
+// @Router /user/rename [post]
+func ChangeUsername(w http.ResponseWriter, r *http.Request) {
+	// Only POST
+	if r.Method != http.MethodPost {
+		utils.Log("ChangeUsername", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("ChangeUsername", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get new username from request
+	var changeRequest models.ChangeUsernameRequest
+	err := json.NewDecoder(r.Body).Decode(&changeRequest)
+	if err != nil {
+		utils.Log("ChangeUsername", "Unable to decode JSON", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("ChangeUsername", "Unable to write response", err)
+		}
+		return
+	}
+
+	newUsername := utils.NormaliseUsername(changeRequest.Username)
+
+	// Validate new username
+	usernameErr := utils.ValidateUsername(newUsername)
+	if errors.Is(usernameErr, utils.ErrReservedUsername) {
+		utils.Log("ChangeUsername", "Reserved username: "+newUsername, usernameErr)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Username is reserved"))
+		if err != nil {
+			utils.Log("ChangeUsername", "Unable to write response", err)
+		}
+		return
+	}
+
+	if usernameErr != nil {
+		utils.Log("ChangeUsername", "Invalid username: "+newUsername, usernameErr)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("ChangeUsername", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("ChangeUsername", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("ChangeUsername", "Unable to write response", err)
+		}
+		return
+	}
+
+	if newUsername == verifiedUsername {
+		utils.Log("ChangeUsername", "Username unchanged: "+newUsername, errors.New("username unchanged"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("ChangeUsername", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	oldDetails, err := queries.GetUsernameDetails(ctx, verifiedUsername)
+
+	if err != nil {
+		utils.Log("ChangeUsername", "Unable to get username details of "+verifiedUsername, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("ChangeUsername", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Check if the user was renamed too recently
+	cooldown := time.Duration(utils.USERNAME_CHANGE_COOLDOWN_DAYS) * 24 * time.Hour
+	if oldDetails.LastRenamedTime.Valid && time.Since(oldDetails.LastRenamedTime.Time) < cooldown {
+		utils.Log("ChangeUsername", "Username of "+verifiedUsername+" changed too recently",
+			errors.New("username changed too recently"))
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, err := w.Write([]byte("Username can only be changed once every " +
+			strconv.Itoa(utils.USERNAME_CHANGE_COOLDOWN_DAYS) + " days"))
+		if err != nil {
+			utils.Log("ChangeUsername", "Unable to write response", err)
+		}
+		return
+	}
+
+	canonicalUsername := utils.CanonicalUsername(newUsername)
+	usernameSkeleton := utils.UsernameSkeleton(newUsername)
+
+	// Check if the new username or a look-alike belongs to, or was recently freed by, someone else
+	isTaken, err := queries.CheckUsernameTaken(ctx, database.CheckUsernameTakenParams{
+		CanonicalUsername: canonicalUsername,
+		UsernameSkeleton:  usernameSkeleton,
+		Username:          verifiedUsername,
+		HoldDays:          int32(utils.USERNAME_HOLD_DAYS)})
+
+	if err != nil {
+		utils.Log("ChangeUsername", "Unable to check if username is taken: "+newUsername, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("ChangeUsername", "Unable to write response", err)
+		}
+		return
+	}
+
+	if isTaken {
+		utils.Log("ChangeUsername", "Username already exists: "+newUsername, errors.New("username already exists"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Username already exists"))
+		if err != nil {
+			utils.Log("ChangeUsername", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Begin a new transaction
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		utils.Log("ChangeUsername", "Unable to begin transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("ChangeUsername", "Unable to write response", err)
+		}
+		return
+	}
+
+	var hasCommitted = false
+
+	defer func(tx pgx.Tx, ctx context.Context) {
+		if hasCommitted {
+			return
+		}
+		err := tx.Rollback(ctx)
+		if err != nil {
+			utils.Log("ChangeUsername", "Unable to rollback transaction", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("ChangeUsername", "Unable to write response", err)
+			}
+		}
+	}(tx, ctx)
+
+	qtx := queries.WithTx(tx)
+
+	// Rename the user, which also updates their threads, comments and other references
+	err = qtx.RenameUser(ctx, database.RenameUserParams{
+		OldUsername:       verifiedUsername,
+		NewUsername:       newUsername,
+		CanonicalUsername: canonicalUsername,
+		UsernameSkeleton:  usernameSkeleton})
+
+	if err != nil {
+		// 23505 is a unique violation, which happens if someone else took the username at the same time
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			utils.Log("ChangeUsername", "Username already exists: "+newUsername, err)
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("Username already exists"))
+			if err != nil {
+				utils.Log("ChangeUsername", "Unable to write response", err)
+			}
+			return
+		}
+		utils.Log("ChangeUsername", "Unable to rename user", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("ChangeUsername", "Unable to write response", err)
+		}
+		return
+	}
+
+	err = qtx.AddUsernameHistory(ctx, database.AddUsernameHistoryParams{
+		Username:             newUsername,
+		OldUsername:          verifiedUsername,
+		OldCanonicalUsername: oldDetails.CanonicalUsername,
+		OldUsernameSkeleton:  oldDetails.UsernameSkeleton})
+
+	if err != nil {
+		utils.Log("ChangeUsername", "Unable to add username history", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("ChangeUsername", "Unable to write response", err)
+		}
+		return
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		utils.Log("ChangeUsername", "Unable to commit transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("ChangeUsername", "Unable to write response", err)
+		}
+		return
+	}
+
+	hasCommitted = true
+
+	// Generate JWT token for the new username
+	newToken, err := utils.CreateJWT(newUsername)
+	if err != nil {
+		utils.Log("ChangeUsername", "Unable to generate JWT token", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("ChangeUsername", "Unable to write response", err)
+		}
+		return
+	}
+
+	isVerified, err := queries.CheckUserVerified(ctx, newUsername)
+	if err != nil {
+		utils.Log("ChangeUsername", "Unable to check if user is verified", err)
+	}
+
+	// Return new username and token as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(models.AuthResponse{
+		Username:   newUsername,
+		Token:      newToken,
+		IsVerified: isVerified})
+
+	if jsonErr != nil {
+		utils.Log("ChangeUsername", "Unable to encode JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("ChangeUsername", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("ChangeUsername", "User "+verifiedUsername+" renamed to "+newUsername, nil)
+
+	return
+}
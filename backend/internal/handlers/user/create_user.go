@@ -7,23 +7,35 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"golang.org/x/crypto/bcrypt"
 	"net/http"
-	"regexp"
+	"net/mail"
 	"strings"
 )
 
 // CreateUser godoc
 // @Summary Handles registration requests
-// @Description Registers a new user with the given username and password
+// @Description Registers a new user with the given username and password.
+// @Description Depending on the registration mode, an email or invite code may also be required.
 // @Tags user
 // @Accept json
 // @Produce json
 // @Param data body models.AuthRequest true "Username and password"
+// @Param X-Pow-Challenge header string false "Proof-of-work challenge from /challenge, if required"
+// @Param X-Pow-Solution header string false "Proof-of-work solution, if required"
 // @Success 200 {object} models.AuthResponse
 // @Failure 400 "Username already exists"
+// @Failure 400 "Email already exists"
 // @Failure 400 "Invalid data"
 // @Failure 400 "Incorrect username/password"
+// @Failure 400 "Username is reserved"
+// @Failure 400 "Invalid email"
+// @Failure 400 "Invalid invite code"
+// @Failure 400 "Invalid proof of work"
+// @Failure 403 "Registration is closed"
 // @Failure 405 "Method not allowed"
 // @Failure 500 "Internal server error"
 // @Router /user/create [post]
@@ -52,14 +64,51 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	username := strings.TrimSpace(creds.Username)
+	// Check if registration is open
+	if utils.REGISTRATION_MODE == utils.RegistrationClosed {
+		utils.Log("CreateUser", "Registration is closed", errors.New("registration is closed"))
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte("Registration is closed"))
+		if err != nil {
+			utils.Log("CreateUser", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Check proof-of-work solution, which is only used up once the user is about to be created, so that the same
+	// solution can be sent again with a different username if the username is invalid or taken
+	powChallenge := r.Header.Get("X-Pow-Challenge")
+	if utils.IsPowRequired(utils.PowRegister) {
+		err := utils.CheckPowSolution(utils.PowRegister, powChallenge, r.Header.Get("X-Pow-Solution"))
+		if err != nil {
+			utils.Log("CreateUser", "Invalid proof of work", err)
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("Invalid proof of work"))
+			if err != nil {
+				utils.Log("CreateUser", "Unable to write response", err)
+			}
+			return
+		}
+	}
+
+	username := utils.NormaliseUsername(creds.Username)
 	password := creds.Password
+	email := strings.TrimSpace(creds.Email)
+	inviteCode := strings.TrimSpace(creds.InviteCode)
 
 	// Validate username and password
-	if len(username) < 1 ||
-		len(username) > 30 ||
-		len(password) < 6 ||
-		regexp.MustCompile(`\s`).MatchString(username) {
+	usernameErr := utils.ValidateUsername(username)
+	if errors.Is(usernameErr, utils.ErrReservedUsername) {
+		utils.Log("CreateUser", "Reserved username: "+username, usernameErr)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Username is reserved"))
+		if err != nil {
+			utils.Log("CreateUser", "Unable to write response", err)
+		}
+		return
+	}
+
+	if usernameErr != nil || len(password) < 6 {
 		utils.Log("CreateUser", "Invalid username or password: "+username+"; "+password,
 			errors.New("invalid username or password"))
 		w.WriteHeader(http.StatusBadRequest)
@@ -70,14 +119,45 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	canonicalUsername := utils.CanonicalUsername(username)
+	usernameSkeleton := utils.UsernameSkeleton(username)
+
+	// Validate email, which is only required if emails must be verified
+	if email != "" || utils.REGISTRATION_MODE == utils.RegistrationEmail {
+		parsedEmail, err := mail.ParseAddress(email)
+		if err != nil || parsedEmail.Address != email || len(email) > 254 {
+			utils.Log("CreateUser", "Invalid email: "+email, errors.New("invalid email"))
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("Invalid email"))
+			if err != nil {
+				utils.Log("CreateUser", "Unable to write response", err)
+			}
+			return
+		}
+	}
+
+	// Invite code is required if registration is invite-only
+	if utils.REGISTRATION_MODE == utils.RegistrationInvite && inviteCode == "" {
+		utils.Log("CreateUser", "Missing invite code", errors.New("missing invite code"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid invite code"))
+		if err != nil {
+			utils.Log("CreateUser", "Unable to write response", err)
+		}
+		return
+	}
+
 	// Connect to database
 	ctx := context.Background()
 	conn := database.GetConnection()
 	defer database.CloseConnection(conn)
 	queries := database.New(conn)
 
-	// Check if username exists
-	isExistingUser, err := queries.CheckUserExists(ctx, username)
+	// Check if username or a look-alike username exists
+	isExistingUser, err := queries.CheckUsernameTaken(ctx, database.CheckUsernameTakenParams{
+		CanonicalUsername: canonicalUsername,
+		UsernameSkeleton:  usernameSkeleton,
+		HoldDays:          int32(utils.USERNAME_HOLD_DAYS)})
 
 	if err != nil {
 		utils.Log("CreateUser", "Unable to check if user exists: "+username, err)
@@ -99,6 +179,45 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check if email exists
+	if email != "" {
+		isExistingEmail, err := queries.CheckEmailExists(ctx, email)
+
+		if err != nil {
+			utils.Log("CreateUser", "Unable to check if email exists: "+email, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("CreateUser", "Unable to write response", err)
+			}
+			return
+		}
+
+		if isExistingEmail {
+			utils.Log("CreateUser", "Email already exists: "+email, errors.New("email already exists"))
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("Email already exists"))
+			if err != nil {
+				utils.Log("CreateUser", "Unable to write response", err)
+			}
+			return
+		}
+	}
+
+	// Use up the proof-of-work challenge, now that the user is about to be created
+	if utils.IsPowRequired(utils.PowRegister) {
+		err := utils.UsePowChallenge(powChallenge)
+		if err != nil {
+			utils.Log("CreateUser", "Invalid proof of work", err)
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("Invalid proof of work"))
+			if err != nil {
+				utils.Log("CreateUser", "Unable to write response", err)
+			}
+			return
+		}
+	}
+
 	// Create user
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -111,11 +230,65 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = queries.CreateUser(ctx, database.CreateUserParams{
-		Username: username,
-		Password: string(hashedPassword)})
+	// Begin a new transaction
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		utils.Log("CreateUser", "Unable to begin transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("CreateUser", "Unable to write response", err)
+		}
+		return
+	}
+
+	var hasCommitted = false
+
+	defer func(tx pgx.Tx, ctx context.Context) {
+		if hasCommitted {
+			return
+		}
+		err := tx.Rollback(ctx)
+		if err != nil {
+			utils.Log("CreateUser", "Unable to rollback transaction", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("CreateUser", "Unable to write response", err)
+			}
+		}
+	}(tx, ctx)
+
+	qtx := queries.WithTx(tx)
+
+	// Users must verify their email before posting if email verification is required
+	isVerified := utils.REGISTRATION_MODE != utils.RegistrationEmail
+
+	err = qtx.CreateUser(ctx, database.CreateUserParams{
+		Username:          username,
+		CanonicalUsername: canonicalUsername,
+		UsernameSkeleton:  usernameSkeleton,
+		Password:          string(hashedPassword),
+		Email:             pgtype.Text{String: email, Valid: email != ""},
+		IsVerified:        isVerified})
 
 	if err != nil {
+		// 23505 is a unique violation, which happens if another user registered with
+		// the same or a look-alike username or the same email at the same time
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			message := "Username already exists"
+			if pgErr.ConstraintName == "users_email_unique" {
+				message = "Email already exists"
+			}
+			utils.Log("CreateUser", message+": "+username, err)
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte(message))
+			if err != nil {
+				utils.Log("CreateUser", "Unable to write response", err)
+			}
+			return
+		}
 		utils.Log("CreateUser", "Unable to create user", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		_, err := w.Write([]byte("Internal server error"))
@@ -125,6 +298,65 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Use up the invite code
+	if utils.REGISTRATION_MODE == utils.RegistrationInvite {
+		_, err = qtx.RedeemInviteCode(ctx, inviteCode)
+
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				utils.Log("CreateUser", "Invalid invite code: "+inviteCode, err)
+				w.WriteHeader(http.StatusBadRequest)
+				_, err := w.Write([]byte("Invalid invite code"))
+				if err != nil {
+					utils.Log("CreateUser", "Unable to write response", err)
+				}
+			} else {
+				utils.Log("CreateUser", "Unable to redeem invite code", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				_, err := w.Write([]byte("Internal server error"))
+				if err != nil {
+					utils.Log("CreateUser", "Unable to write response", err)
+				}
+			}
+			return
+		}
+
+		err = qtx.AddInviteCodeUse(ctx, database.AddInviteCodeUseParams{
+			Code:     inviteCode,
+			Username: username})
+
+		if err != nil {
+			utils.Log("CreateUser", "Unable to record invite code use", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("CreateUser", "Unable to write response", err)
+			}
+			return
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		utils.Log("CreateUser", "Unable to commit transaction", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("CreateUser", "Unable to write response", err)
+		}
+		return
+	}
+
+	hasCommitted = true
+
+	// Send verification email. The user can request another one if this fails.
+	if !isVerified {
+		err = sendVerificationEmail(ctx, queries, username, email)
+		if err != nil {
+			utils.Log("CreateUser", "Unable to send verification email to "+username, err)
+		}
+	}
+
 	// Generate JWT token
 	token, err := utils.CreateJWT(username)
 	if err != nil {
@@ -140,8 +372,9 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 	// Return username and token as JSON object
 	w.Header().Set("Content-Type", "application/json")
 	jsonErr := json.NewEncoder(w).Encode(models.AuthResponse{
-		Username: username,
-		Token:    token})
+		Username:   username,
+		Token:      token,
+		IsVerified: isVerified})
 
 	if jsonErr != nil {
 		utils.Log("CreateUser", "Unable to encode JSON", err)
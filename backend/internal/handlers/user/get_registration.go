@@ -0,0 +1,49 @@
+package user
+
+import (
+	"backend/internal/models"
+	"backend/internal/utils"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// GetRegistration godoc
+// @Summary Handles registration mode requests
+// @Description Returns the registration mode, which decides whether an email or invite code is required to register.
+// @Tags user
+// @Produce json
+// @Success 200 {object} models.RegistrationResponse
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /registration [get]
+func GetRegistration(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetRegistration", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetRegistration", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Return registration mode as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(models.RegistrationResponse{
+		Mode: utils.REGISTRATION_MODE,
+	})
+
+	if jsonErr != nil {
+		utils.Log("GetRegistration", "Unable to encode registration mode as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetRegistration", "Unable to write response", err)
+		}
+		return
+	}
+
+	return
+}
@@ -0,0 +1,153 @@
+package user
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+// GetUserComments godoc
+// @Summary Handles user comment retrieval requests
+// @Description Retrieves the comments created by the given user, together with the title of their thread
+// @Tags user
+// @Produce json
+// @Param username path string true "Username"
+// @Param order query string false "Sorting order, default 'created_time_desc'" Enums(created_time_asc, created_time_desc, score_desc)
+// @Param p query string false "Page number, default '1'"
+// @Success 200 {object} models.GetUserCommentsResponse
+// @Success 301 "User has been renamed"
+// @Failure 404 "User not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /user/{username}/comments [get]
+func GetUserComments(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetUserComments", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetUserComments", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Number of comments per page
+	pageSize := 10
+
+	// Get details from request
+	username := mux.Vars(r)["username"]
+	canonicalUsername := utils.CanonicalUsername(username)
+	order := r.FormValue("order")
+	page := r.FormValue("p")
+
+	// Available sorting orders
+	availableSortOrders := []string{"created_time_asc", "created_time_desc", "score_desc"}
+
+	// Check sort order
+	if order == "" || !slices.Contains(availableSortOrders, order) {
+		// Default sorting order - latest comments first
+		order = "created_time_desc"
+	}
+
+	// Check page number
+	pageNumber, err := strconv.Atoi(page)
+	offset := 0
+	if err == nil && pageNumber > 1 {
+		offset = (pageNumber - 1) * pageSize
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Check if user exists
+	isExistingUser, err := queries.CheckUserExists(ctx, canonicalUsername)
+
+	if err != nil {
+		utils.Log("GetUserComments", "Unable to check if user exists: "+username, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetUserComments", "Unable to write response", err)
+		}
+		return
+	}
+
+	if !isExistingUser {
+		// Redirect if the user has been renamed
+		if redirectToRenamedUser(ctx, queries, w, r, username, canonicalUsername) {
+			return
+		}
+
+		utils.Log("GetUserComments", "User "+username+" not found", errors.New("user not found"))
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("User not found"))
+		if err != nil {
+			utils.Log("GetUserComments", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get the comments
+	pgComments, err := queries.GetCommentsByCreator(ctx, database.GetCommentsByCreatorParams{
+		Creator:   canonicalUsername,
+		Viewer:    utils.GetRequestUsername(r),
+		Sortorder: order,
+		Offset:    int32(offset),
+		Limit:     int32(pageSize),
+	})
+
+	if err != nil {
+		utils.Log("GetUserComments", "Unable to get comments", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetUserComments", "Unable to write response", err)
+		}
+		return
+	}
+
+	commentsCount, err := queries.GetCommentCountByCreator(ctx, canonicalUsername)
+
+	if err != nil {
+		utils.Log("GetUserComments", "Unable to get comment count", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetUserComments", "Unable to write response", err)
+		}
+		return
+	}
+
+	var response models.GetUserCommentsResponse
+	response.Comments = database.FormatPgUserComments(pgComments)
+	response.Count = int32(commentsCount)
+
+	// Return comments as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(response)
+
+	if jsonErr != nil {
+		utils.Log("GetUserComments", "Unable to encode comments as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetUserComments", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetUserComments", "Comments retrieved for user: "+username, nil)
+
+	return
+}
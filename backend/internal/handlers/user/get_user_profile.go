@@ -0,0 +1,96 @@
+package user
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+)
+
+// GetUserProfile godoc
+// @Summary Handles user profile retrieval requests
+// @Description Retrieves the number of threads and comments created by the given user
+// @Tags user
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} models.UserProfile
+// @Success 301 "User has been renamed"
+// @Failure 404 "User not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /user/{username} [get]
+func GetUserProfile(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetUserProfile", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetUserProfile", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get details from request url
+	username := mux.Vars(r)["username"]
+	canonicalUsername := utils.CanonicalUsername(username)
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	pgProfile, err := queries.GetUserProfile(ctx, canonicalUsername)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Redirect if the user has been renamed
+			if redirectToRenamedUser(ctx, queries, w, r, username, canonicalUsername) {
+				return
+			}
+
+			utils.Log("GetUserProfile", "User "+username+" not found", err)
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("User not found"))
+			if err != nil {
+				utils.Log("GetUserProfile", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("GetUserProfile", "Unable to get profile of user "+username, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("GetUserProfile", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	// Return profile as JSON object
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(models.UserProfile{
+		Username:    pgProfile.Username,
+		NumThreads:  int32(pgProfile.NumThreads),
+		NumComments: int32(pgProfile.NumComments),
+	})
+
+	if jsonErr != nil {
+		utils.Log("GetUserProfile", "Unable to encode profile as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetUserProfile", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetUserProfile", "Profile retrieved for user: "+username, nil)
+
+	return
+}
@@ -0,0 +1,152 @@
+package user
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+// GetUserThreads godoc
+// @Summary Handles user thread retrieval requests
+// @Description Retrieves the threads created by the given user
+// @Tags user
+// @Produce json
+// @Param username path string true "Username"
+// @Param order query string false "Sorting order, default 'created_time_desc'" Enums(created_time_asc, created_time_desc, num_comments_asc, num_comments_desc, score_desc)
+// @Param p query string false "Page number, default '1'"
+// @Success 200 {object} models.SearchThreadResponse
+// @Success 301 "User has been renamed"
+// @Failure 404 "User not found"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /user/{username}/threads [get]
+func GetUserThreads(w http.ResponseWriter, r *http.Request) {
+	// Only GET
+	if r.Method != http.MethodGet {
+		utils.Log("GetUserThreads", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("GetUserThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	pageSize := 10
+	availableSortOrders := []string{"created_time_asc", "created_time_desc", "num_comments_asc", "num_comments_desc",
+		"score_desc"}
+
+	// Get details from request
+	username := mux.Vars(r)["username"]
+	canonicalUsername := utils.CanonicalUsername(username)
+	params := r.URL.Query()
+	page := params.Get("p")
+	order := params.Get("order")
+
+	// Check sort order
+	if order == "" || !slices.Contains(availableSortOrders, order) {
+		// Default sorting order - latest threads first
+		order = "created_time_desc"
+	}
+
+	// Check page number
+	pageNumber, err := strconv.Atoi(page)
+	offset := 0
+	if err == nil && pageNumber > 1 {
+		offset = (pageNumber - 1) * pageSize
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Check if user exists
+	isExistingUser, err := queries.CheckUserExists(ctx, canonicalUsername)
+
+	if err != nil {
+		utils.Log("GetUserThreads", "Unable to check if user exists: "+username, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetUserThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	if !isExistingUser {
+		// Redirect if the user has been renamed
+		if redirectToRenamedUser(ctx, queries, w, r, username, canonicalUsername) {
+			return
+		}
+
+		utils.Log("GetUserThreads", "User "+username+" not found", errors.New("user not found"))
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte("User not found"))
+		if err != nil {
+			utils.Log("GetUserThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get threads
+	threads, err := queries.GetThreadsByCriteria(ctx, database.GetThreadsByCriteriaParams{
+		Limit:     int32(pageSize),
+		Offset:    int32(offset),
+		Sortorder: order,
+		Creator:   canonicalUsername,
+		Viewer:    utils.GetRequestUsername(r),
+	})
+
+	if err != nil {
+		utils.Log("GetUserThreads", "Unable to get threads", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetUserThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	totalThreads, err := queries.GetThreadsByCriteriaCount(ctx, database.GetThreadsByCriteriaCountParams{
+		Creator: canonicalUsername,
+	})
+
+	if err != nil {
+		utils.Log("GetUserThreads", "Unable to get threads count", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetUserThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	jsonErr := json.NewEncoder(w).Encode(models.SearchThreadResponse{
+		TotalThreads: int32(totalThreads),
+		Threads:      database.FormatPgThreads(threads),
+	})
+
+	if jsonErr != nil {
+		utils.Log("GetUserThreads", "Unable to encode threads as JSON", jsonErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("GetUserThreads", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("GetUserThreads", "Threads retrieved for user: "+username, nil)
+
+	return
+}
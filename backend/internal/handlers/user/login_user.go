@@ -9,7 +9,6 @@ import (
 	"errors"
 	"golang.org/x/crypto/bcrypt"
 	"net/http"
-	"strings"
 )
 
 // LoginUser godoc
@@ -49,7 +48,7 @@ func LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	username := strings.TrimSpace(creds.Username)
+	username := utils.CanonicalUsername(creds.Username)
 	password := creds.Password
 
 	// Connect to database
@@ -120,8 +119,9 @@ func LoginUser(w http.ResponseWriter, r *http.Request) {
 	// Return username and token as JSON object
 	w.Header().Set("Content-Type", "application/json")
 	jsonErr := json.NewEncoder(w).Encode(models.AuthResponse{
-		Username: user.Username,
-		Token:    token})
+		Username:   user.Username,
+		Token:      token,
+		IsVerified: user.IsVerified})
 
 	if jsonErr != nil {
 		utils.Log("LoginUser", "Unable to encode JSON", err)
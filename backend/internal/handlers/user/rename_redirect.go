@@ -0,0 +1,36 @@
+package user
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// redirectToRenamedUser Redirects to the same URL with the username replaced by the current username of the user
+// who was previously known by the given canonical username. Returns false if no user was known by that username.
+func redirectToRenamedUser(ctx context.Context, queries *database.Queries, w http.ResponseWriter, r *http.Request,
+	username string, canonicalUsername string) bool {
+	newUsername, err := queries.GetRenamedUsername(ctx, canonicalUsername)
+
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("redirectToRenamedUser", "Unable to get renamed username of "+username, err)
+		}
+		return false
+	}
+
+	location := *r.URL
+	location.Path = strings.Replace(r.URL.Path, "/user/"+username, "/user/"+url.PathEscape(newUsername), 1)
+	location.RawPath = ""
+
+	http.Redirect(w, r, location.String(), http.StatusMovedPermanently)
+
+	utils.Log("redirectToRenamedUser", "Redirected from "+username+" to "+newUsername, nil)
+
+	return true
+}
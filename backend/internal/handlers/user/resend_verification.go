@@ -0,0 +1,92 @@
+package user
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ResendVerification godoc
+// @Summary Handles verification email resend requests
+// @Description Sends a new verification email to the current user
+// @Tags user
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 400 "Email already verified"
+// @Failure 401 "Invalid JWT token"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /user/verify/resend [post]
+func ResendVerification(w http.ResponseWriter, r *http.Request) {
+	// Only POST
+	if r.Method != http.MethodPost {
+		utils.Log("ResendVerification", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("ResendVerification", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get and verify JWT token from request header
+	token := r.Header.Get("Authorization")[7:]
+	verifiedUsername, err := utils.VerifyJWT(token)
+
+	if err != nil {
+		utils.Log("ResendVerification", "Unable to verify JWT token", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte("Invalid JWT token"))
+		if err != nil {
+			utils.Log("ResendVerification", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	userEmail, err := queries.GetUserEmail(ctx, verifiedUsername)
+
+	if err != nil {
+		utils.Log("ResendVerification", "Unable to get email of "+verifiedUsername, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("ResendVerification", "Unable to write response", err)
+		}
+		return
+	}
+
+	if userEmail.IsVerified || !userEmail.Email.Valid {
+		utils.Log("ResendVerification", "Email already verified for "+verifiedUsername,
+			errors.New("email already verified"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Email already verified"))
+		if err != nil {
+			utils.Log("ResendVerification", "Unable to write response", err)
+		}
+		return
+	}
+
+	err = sendVerificationEmail(ctx, queries, verifiedUsername, userEmail.Email.String)
+
+	if err != nil {
+		utils.Log("ResendVerification", "Unable to send verification email", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte("Internal server error"))
+		if err != nil {
+			utils.Log("ResendVerification", "Unable to write response", err)
+		}
+		return
+	}
+
+	utils.Log("ResendVerification", "Verification email resent to "+verifiedUsername, nil)
+
+	return
+}
@@ -0,0 +1,39 @@
+package user
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"os"
+)
+
+// sendVerificationEmail Creates a new email verification token for the user and emails it to them.
+// Any previously issued tokens for the user are invalidated.
+func sendVerificationEmail(ctx context.Context, queries *database.Queries, username string, email string) error {
+	token, err := utils.GenerateToken(32)
+	if err != nil {
+		return err
+	}
+
+	err = queries.DeleteEmailVerifications(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	err = queries.CreateEmailVerification(ctx, database.CreateEmailVerificationParams{
+		TokenHash: utils.HashToken(token),
+		Username:  username})
+	if err != nil {
+		return err
+	}
+
+	appUrl := os.Getenv("APP_URL")
+	if appUrl == "" {
+		appUrl = "http://localhost:3000"
+	}
+
+	return utils.SendMail(email, "Verify your email",
+		"Hi "+username+",\n\n"+
+			"Please verify your email by opening the link below. The link is valid for 24 hours.\n\n"+
+			appUrl+"/verify?token="+token+"\n")
+}
@@ -0,0 +1,91 @@
+package user
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"strings"
+)
+
+// VerifyEmail godoc
+// @Summary Handles email verification requests
+// @Description Verifies the email of the user who was sent the given token
+// @Tags user
+// @Accept json
+// @Param data body models.VerifyEmailRequest true "Verification token"
+// @Success 200
+// @Failure 400 "Invalid data"
+// @Failure 400 "Invalid or expired token"
+// @Failure 405 "Method not allowed"
+// @Failure 500 "Internal server error"
+// @Router /user/verify [post]
+func VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	// Only POST
+	if r.Method != http.MethodPost {
+		utils.Log("VerifyEmail", "Method not allowed", errors.New("method not allowed"))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, err := w.Write([]byte("Method not allowed"))
+		if err != nil {
+			utils.Log("VerifyEmail", "Unable to write response", err)
+		}
+		return
+	}
+
+	// Get token from request
+	var verifyRequest models.VerifyEmailRequest
+	err := json.NewDecoder(r.Body).Decode(&verifyRequest)
+	if err != nil {
+		utils.Log("VerifyEmail", "Unable to decode JSON", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte("Invalid data"))
+		if err != nil {
+			utils.Log("VerifyEmail", "Unable to write response", err)
+		}
+		return
+	}
+
+	token := strings.TrimSpace(verifyRequest.Token)
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	// Verify the user who owns the token
+	username, err := queries.VerifyUserEmail(ctx, utils.HashToken(token))
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.Log("VerifyEmail", "Invalid or expired token", err)
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("Invalid or expired token"))
+			if err != nil {
+				utils.Log("VerifyEmail", "Unable to write response", err)
+			}
+		} else {
+			utils.Log("VerifyEmail", "Unable to verify email", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("Internal server error"))
+			if err != nil {
+				utils.Log("VerifyEmail", "Unable to write response", err)
+			}
+		}
+		return
+	}
+
+	// Tokens are single use
+	err = queries.DeleteEmailVerifications(ctx, username)
+	if err != nil {
+		utils.Log("VerifyEmail", "Unable to delete verification tokens of "+username, err)
+	}
+
+	utils.Log("VerifyEmail", "Email verified for user: "+username, nil)
+
+	return
+}
@@ -0,0 +1,76 @@
+package jobs
+
+import (
+	"backend/internal/database"
+	"backend/internal/storage"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// How often unattached uploads and unused files are cleaned up.
+const attachmentCleanupInterval = time.Hour
+
+// Number of hours a file is kept after it was last uploaded, even if no attachment uses it, so that the files of
+// uploads in progress are not deleted.
+const blobGraceHours = 1
+
+// StartAttachmentJob Starts a background job that deletes attachments that were never attached to a thread or
+// comment, and removes files that are no longer used by any attachment from the blob store.
+func StartAttachmentJob() {
+	go func() {
+		ticker := time.NewTicker(attachmentCleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			cleanUpAttachments()
+			<-ticker.C
+		}
+	}()
+}
+
+// cleanUpAttachments Deletes attachments that are past the time limit for attaching them, then deletes files that
+// are not used by any attachment, including those of purged threads and comments.
+func cleanUpAttachments() {
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	if conn == nil {
+		utils.Log("AttachmentJob", "Unable to connect to database", errors.New("no database connection"))
+		return
+	}
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	numAttachments, err := queries.DeleteOrphanedAttachments(ctx, int32(utils.ATTACHMENT_ORPHAN_HOURS))
+
+	if err != nil {
+		utils.Log("AttachmentJob", "Unable to delete unattached attachments", err)
+		return
+	}
+
+	// Files are removed from the index first, so a file is never in the index without being in the blob store
+	hashes, err := queries.DeleteUnusedBlobs(ctx, blobGraceHours)
+
+	if err != nil {
+		utils.Log("AttachmentJob", "Unable to delete unused files", err)
+		return
+	}
+
+	numFiles := 0
+	for _, hash := range hashes {
+		err := storage.Blobs.Delete(ctx, hash)
+		if err != nil {
+			utils.Log("AttachmentJob", "Unable to delete file "+hash+" from blob store", err)
+			continue
+		}
+		numFiles++
+	}
+
+	if numAttachments > 0 || numFiles > 0 {
+		utils.Log("AttachmentJob", "Deleted "+strconv.FormatInt(numAttachments, 10)+" unattached attachments and "+
+			strconv.Itoa(numFiles)+" unused files", nil)
+	}
+}
@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// How often inactive threads are checked for.
+const autoLockInterval = time.Hour
+
+// StartAutoLockJob Starts a background job that locks threads without new comments for longer than AUTO_LOCK_DAYS.
+// Does nothing if auto-locking is disabled.
+func StartAutoLockJob() {
+	if utils.AUTO_LOCK_DAYS <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(autoLockInterval)
+		defer ticker.Stop()
+
+		for {
+			lockInactiveThreads()
+			<-ticker.C
+		}
+	}()
+}
+
+// lockInactiveThreads Locks threads that have been inactive for longer than AUTO_LOCK_DAYS.
+func lockInactiveThreads() {
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	if conn == nil {
+		utils.Log("AutoLockJob", "Unable to connect to database", errors.New("no database connection"))
+		return
+	}
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	numThreads, err := queries.AutoLockInactiveThreads(ctx, database.AutoLockInactiveThreadsParams{
+		LockReason:   "Automatically locked after " + strconv.Itoa(utils.AUTO_LOCK_DAYS) + " days of inactivity",
+		InactiveDays: int32(utils.AUTO_LOCK_DAYS),
+	})
+
+	if err != nil {
+		utils.Log("AutoLockJob", "Unable to lock inactive threads", err)
+		return
+	}
+
+	if numThreads > 0 {
+		utils.Log("AutoLockJob", "Locked "+strconv.FormatInt(numThreads, 10)+" inactive threads", nil)
+	}
+}
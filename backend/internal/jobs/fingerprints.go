@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// How often bodies of threads without a fingerprint are fingerprinted. Threads created from a split comment, or that
+// existed before fingerprints were added, have no fingerprint until then.
+const fingerprintInterval = 5 * time.Minute
+
+// Maximum number of bodies fingerprinted at a time.
+const fingerprintBatchSize = 100
+
+// StartFingerprintJob Starts a background job that computes the SimHash fingerprints of thread bodies that do not
+// have one yet, so that they can be found as near-duplicates.
+func StartFingerprintJob() {
+	go func() {
+		ticker := time.NewTicker(fingerprintInterval)
+		defer ticker.Stop()
+
+		for {
+			fingerprintThreads()
+			<-ticker.C
+		}
+	}()
+}
+
+// fingerprintThreads Fingerprints thread bodies in batches until none are left without a fingerprint.
+func fingerprintThreads() {
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	if conn == nil {
+		utils.Log("FingerprintJob", "Unable to connect to database", errors.New("no database connection"))
+		return
+	}
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	numThreads := 0
+	for {
+		threads, err := queries.GetUnfingerprintedThreads(ctx, fingerprintBatchSize)
+
+		if err != nil {
+			utils.Log("FingerprintJob", "Unable to get threads without fingerprints", err)
+			return
+		}
+
+		for _, thread := range threads {
+			// Threads edited in the meantime already have an up-to-date fingerprint and are left unchanged
+			err := queries.SetThreadFingerprint(ctx, database.SetThreadFingerprintParams{
+				ID:          thread.ID,
+				Body:        thread.Body,
+				BodySimhash: utils.SimHash(thread.Body),
+			})
+
+			if err != nil {
+				utils.Log("FingerprintJob", "Unable to fingerprint body of thread "+database.FormatPgUuid(thread.ID), err)
+				return
+			}
+		}
+
+		numThreads += len(threads)
+		if len(threads) < fingerprintBatchSize {
+			break
+		}
+	}
+
+	if numThreads > 0 {
+		utils.Log("FingerprintJob", "Fingerprinted the bodies of "+strconv.Itoa(numThreads)+" threads", nil)
+	}
+}
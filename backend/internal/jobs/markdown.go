@@ -0,0 +1,116 @@
+package jobs
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"strconv"
+)
+
+// Maximum number of bodies rendered at a time.
+const renderBatchSize = 100
+
+// StartMarkdownJob Starts a background job that renders the bodies of threads and comments whose cached HTML is
+// missing or was rendered by a different version of the renderer. Runs once, when the server starts.
+func StartMarkdownJob() {
+	go renderStaleBodies()
+}
+
+// renderStaleBodies Renders stale thread and comment bodies in batches until none are left.
+func renderStaleBodies() {
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	if conn == nil {
+		utils.Log("MarkdownJob", "Unable to connect to database", errors.New("no database connection"))
+		return
+	}
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	numThreads := 0
+	for {
+		staleThreads, err := queries.GetStaleThreadBodies(ctx, database.GetStaleThreadBodiesParams{
+			Limit:           renderBatchSize,
+			BodyHtmlVersion: utils.MarkdownVersion,
+		})
+
+		if err != nil {
+			utils.Log("MarkdownJob", "Unable to get stale thread bodies", err)
+			return
+		}
+
+		for _, staleThread := range staleThreads {
+			bodyHtml, err := database.RenderBody(ctx, queries, staleThread.Body)
+
+			if err != nil {
+				utils.Log("MarkdownJob", "Unable to render body of thread "+database.FormatPgUuid(staleThread.ID), err)
+				return
+			}
+
+			// Threads edited in the meantime already have up-to-date HTML and are left unchanged
+			err = queries.SetThreadBodyHtml(ctx, database.SetThreadBodyHtmlParams{
+				ID:              staleThread.ID,
+				Body:            staleThread.Body,
+				BodyHtml:        bodyHtml,
+				BodyHtmlVersion: utils.MarkdownVersion,
+			})
+
+			if err != nil {
+				utils.Log("MarkdownJob", "Unable to render body of thread "+database.FormatPgUuid(staleThread.ID), err)
+				return
+			}
+		}
+
+		numThreads += len(staleThreads)
+		if len(staleThreads) < renderBatchSize {
+			break
+		}
+	}
+
+	numComments := 0
+	for {
+		staleComments, err := queries.GetStaleCommentBodies(ctx, database.GetStaleCommentBodiesParams{
+			Limit:           renderBatchSize,
+			BodyHtmlVersion: utils.MarkdownVersion,
+		})
+
+		if err != nil {
+			utils.Log("MarkdownJob", "Unable to get stale comment bodies", err)
+			return
+		}
+
+		for _, staleComment := range staleComments {
+			bodyHtml, err := database.RenderBody(ctx, queries, staleComment.Body)
+
+			if err != nil {
+				utils.Log("MarkdownJob", "Unable to render body of comment "+database.FormatPgUuid(staleComment.ID), err)
+				return
+			}
+
+			// Comments edited in the meantime already have up-to-date HTML and are left unchanged
+			err = queries.SetCommentBodyHtml(ctx, database.SetCommentBodyHtmlParams{
+				ID:              staleComment.ID,
+				Body:            staleComment.Body,
+				BodyHtml:        bodyHtml,
+				BodyHtmlVersion: utils.MarkdownVersion,
+			})
+
+			if err != nil {
+				utils.Log("MarkdownJob", "Unable to render body of comment "+database.FormatPgUuid(staleComment.ID), err)
+				return
+			}
+		}
+
+		numComments += len(staleComments)
+		if len(staleComments) < renderBatchSize {
+			break
+		}
+	}
+
+	if numThreads > 0 || numComments > 0 {
+		utils.Log("MarkdownJob", "Rendered the bodies of "+strconv.Itoa(numThreads)+" threads and "+
+			strconv.Itoa(numComments)+" comments", nil)
+	}
+}
@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// StartNotificationJob Starts a background job that notifies the subscribers of a thread of new comments,
+// so that creating a comment does not wait for the notifications to be created.
+func StartNotificationJob() {
+	go func() {
+		for commentId := range utils.CommentNotifications {
+			notifySubscribers(commentId)
+		}
+	}()
+}
+
+// notifySubscribers Creates notifications of a new comment for the subscribers of its thread.
+func notifySubscribers(commentId string) {
+	var pgCommentId pgtype.UUID
+
+	err := pgCommentId.Scan(commentId)
+	if err != nil {
+		utils.Log("NotificationJob", "Unable to scan commentId", err)
+		return
+	}
+
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	if conn == nil {
+		utils.Log("NotificationJob", "Unable to connect to database", errors.New("no database connection"))
+		return
+	}
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	_, err = queries.CreateCommentNotifications(ctx, pgCommentId)
+
+	if err != nil {
+		utils.Log("NotificationJob", "Unable to create notifications of comment "+commentId, err)
+		return
+	}
+}
@@ -0,0 +1,88 @@
+package jobs
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// StartPurgeJob Starts a background job that permanently deletes threads and comments once they have been
+// deleted for longer than the retention period.
+func StartPurgeJob() {
+	go func() {
+		ticker := time.NewTicker(time.Duration(utils.PURGE_INTERVAL_MINUTES) * time.Minute)
+		defer ticker.Stop()
+
+		for {
+			purgeDeleted()
+			<-ticker.C
+		}
+	}()
+
+	utils.Log("PurgeJob", "Purging deleted threads and comments every "+
+		strconv.Itoa(utils.PURGE_INTERVAL_MINUTES)+" minutes", nil)
+}
+
+// purgeDeleted Permanently deletes threads and comments that are past the retention period,
+// together with tags that are no longer used, pins and drafts that have expired, and old read notifications.
+func purgeDeleted() {
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	if conn == nil {
+		utils.Log("PurgeJob", "Unable to connect to database", errors.New("no database connection"))
+		return
+	}
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	retentionDays := int32(utils.DELETED_RETENTION_DAYS)
+
+	// Each step is independent of the others, so a failing step is logged and the remaining steps still run
+	numComments, err := queries.PurgeDeletedComments(ctx, retentionDays)
+
+	if err != nil {
+		utils.Log("PurgeJob", "Unable to purge deleted comments", err)
+	}
+
+	numThreads, err := queries.PurgeDeletedThreads(ctx, retentionDays)
+
+	if err != nil {
+		utils.Log("PurgeJob", "Unable to purge deleted threads", err)
+	}
+
+	// Expired pins no longer apply, so they are cleaned up as well
+	_, err = queries.DeleteExpiredPins(ctx)
+
+	if err != nil {
+		utils.Log("PurgeJob", "Unable to delete expired pins", err)
+	}
+
+	_, err = queries.DeleteOldNotifications(ctx, int32(utils.NOTIFICATION_RETENTION_DAYS))
+
+	if err != nil {
+		utils.Log("PurgeJob", "Unable to delete old notifications", err)
+	}
+
+	_, err = queries.DeleteExpiredDrafts(ctx, int32(utils.DRAFT_EXPIRY_DAYS))
+
+	if err != nil {
+		utils.Log("PurgeJob", "Unable to delete expired drafts", err)
+	}
+
+	if numThreads == 0 && numComments == 0 {
+		return
+	}
+
+	err = queries.DeleteUnusedTags(ctx)
+
+	if err != nil {
+		utils.Log("PurgeJob", "Unable to delete unused tags", err)
+	}
+
+	utils.Log("PurgeJob", "Purged "+strconv.FormatInt(numThreads, 10)+" threads and "+
+		strconv.FormatInt(numComments, 10)+" comments", nil)
+}
@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"time"
+)
+
+// StartRankingJob Starts a background job that periodically refreshes the hot and trending scores of threads,
+// so that listing threads by these orders does not compute them on every request.
+func StartRankingJob() {
+	go func() {
+		ticker := time.NewTicker(time.Duration(utils.RANKING_REFRESH_MINUTES) * time.Minute)
+		defer ticker.Stop()
+
+		for {
+			refreshRankings()
+			<-ticker.C
+		}
+	}()
+}
+
+// refreshRankings Recomputes the hot and trending scores of all threads.
+func refreshRankings() {
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	if conn == nil {
+		utils.Log("RankingJob", "Unable to connect to database", errors.New("no database connection"))
+		return
+	}
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	_, err := queries.RefreshThreadRankings(ctx, int32(utils.TRENDING_WINDOW_HOURS))
+
+	if err != nil {
+		utils.Log("RankingJob", "Unable to refresh thread rankings", err)
+		return
+	}
+}
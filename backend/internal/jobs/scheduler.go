@@ -0,0 +1,170 @@
+package jobs
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"time"
+)
+
+// How often scheduled threads that are due are checked for.
+const publishInterval = time.Minute
+
+// Maximum number of scheduled threads published at a time.
+const publishBatchSize = 100
+
+// StartSchedulerJob Starts a background job that publishes scheduled threads once their publish time has passed.
+func StartSchedulerJob() {
+	go func() {
+		ticker := time.NewTicker(publishInterval)
+		defer ticker.Stop()
+
+		for {
+			publishScheduledThreads()
+			<-ticker.C
+		}
+	}()
+}
+
+// publishScheduledThreads Publishes scheduled threads whose publish time has passed, together with their tags, polls and
+// attachments.
+func publishScheduledThreads() {
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	if conn == nil {
+		utils.Log("SchedulerJob", "Unable to connect to database", errors.New("no database connection"))
+		return
+	}
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	dueThreads, err := queries.GetDueScheduledThreads(ctx, publishBatchSize)
+
+	if err != nil {
+		utils.Log("SchedulerJob", "Unable to get due scheduled threads", err)
+		return
+	}
+
+	for _, dueThread := range dueThreads {
+		threadId := database.FormatPgUuid(dueThread.ID)
+
+		err := publishScheduledThread(ctx, conn, queries, dueThread)
+
+		if err != nil {
+			utils.Log("SchedulerJob", "Unable to publish scheduled thread "+threadId, err)
+			continue
+		}
+
+		// Users mentioned in the thread are notified when it is published, the thread keeps its ID
+		_, err = queries.SetThreadMentions(ctx, database.SetThreadMentionsParams{
+			ThreadID:           dueThread.ID,
+			CanonicalUsernames: utils.ExtractMentions(dueThread.Body),
+		})
+		if err != nil {
+			utils.Log("SchedulerJob", "Unable to record mentions of thread "+threadId, err)
+		}
+
+		// The new thread may change the related threads of other threads
+		utils.InvalidateRelatedThreads()
+
+		utils.Log("SchedulerJob", "Thread: "+threadId+" published for: "+dueThread.Creator, nil)
+	}
+}
+
+// publishScheduledThread Creates the thread, its tags, its poll and its attachments from a scheduled thread in a
+// single transaction.
+func publishScheduledThread(ctx context.Context, conn *pgx.Conn, queries *database.Queries,
+	dueThread database.ScheduledThread) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := queries.WithTx(tx)
+
+	bodyHtml, err := database.RenderBody(ctx, qtx, dueThread.Body)
+	if err != nil {
+		return err
+	}
+
+	// Fails with pgx.ErrNoRows if the thread has been cancelled or rescheduled in the meantime
+	pgThreadId, err := qtx.PublishScheduledThread(ctx, database.PublishScheduledThreadParams{
+		ID:              dueThread.ID,
+		BodyHtml:        bodyHtml,
+		BodyHtmlVersion: utils.MarkdownVersion,
+		BodySimhash:     utils.SimHash(dueThread.Body),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = qtx.AddNewTags(ctx, dueThread.Tags)
+	if err != nil {
+		return err
+	}
+
+	err = qtx.AddThreadTags(ctx, database.AddThreadTagsParams{
+		ThreadID: pgThreadId,
+		Tagarray: dueThread.Tags,
+	})
+	if err != nil {
+		return err
+	}
+
+	if dueThread.Poll != nil {
+		err = publishScheduledPoll(ctx, qtx, pgThreadId, dueThread.Poll)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Attachments that were deleted in the meantime are skipped
+	if len(dueThread.AttachmentIds) > 0 {
+		_, err = qtx.AttachToThread(ctx, database.AttachToThreadParams{
+			ThreadID:      pgThreadId,
+			AttachmentIds: dueThread.AttachmentIds,
+			Uploader:      dueThread.Creator,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// publishScheduledPoll Attaches the poll of a scheduled thread, kept as JSON, to the published thread.
+func publishScheduledPoll(ctx context.Context, qtx *database.Queries, pgThreadId pgtype.UUID, pollJson []byte) error {
+	var poll models.CreatePollRequest
+	err := json.Unmarshal(pollJson, &poll)
+	if err != nil {
+		return err
+	}
+
+	var pgCloseTime pgtype.Timestamptz
+	if poll.CloseTime != nil {
+		pgCloseTime = pgtype.Timestamptz{Time: *poll.CloseTime, Valid: true}
+	}
+
+	err = qtx.SetPoll(ctx, database.SetPollParams{
+		ThreadID:         pgThreadId,
+		IsMultipleChoice: poll.IsMultipleChoice,
+		IsAnonymous:      poll.IsAnonymous,
+		CloseTime:        pgCloseTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	return qtx.AddPollOptions(ctx, database.AddPollOptionsParams{
+		ThreadID: pgThreadId,
+		Options:  poll.Options,
+	})
+}
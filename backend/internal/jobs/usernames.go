@@ -0,0 +1,117 @@
+package jobs
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"strconv"
+)
+
+// Maximum number of usernames whose skeletons are recomputed at a time.
+const skeletonBatchSize = 100
+
+// StartUsernameSkeletonJob Starts a background job that recomputes the stored skeletons of current and previous
+// usernames, so that skeletons stored by an earlier version of the skeleton algorithm still catch look-alike usernames.
+// Runs once, when the server starts.
+func StartUsernameSkeletonJob() {
+	go recomputeUsernameSkeletons()
+}
+
+// recomputeUsernameSkeletons Updates the username skeletons of users and of previous usernames that differ from the
+// skeleton computed by UsernameSkeleton.
+func recomputeUsernameSkeletons() {
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	if conn == nil {
+		utils.Log("UsernameSkeletonJob", "Unable to connect to database", errors.New("no database connection"))
+		return
+	}
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	numUsers := 0
+	afterUsername := ""
+	for {
+		users, err := queries.GetUsernameSkeletons(ctx, database.GetUsernameSkeletonsParams{
+			Limit:         skeletonBatchSize,
+			AfterUsername: afterUsername,
+		})
+
+		if err != nil {
+			utils.Log("UsernameSkeletonJob", "Unable to get username skeletons", err)
+			return
+		}
+
+		for _, user := range users {
+			usernameSkeleton := utils.UsernameSkeleton(user.Username)
+			if usernameSkeleton == user.UsernameSkeleton {
+				continue
+			}
+
+			// Fails if another user already has the skeleton, in which case the user keeps the old skeleton
+			err = queries.SetUsernameSkeleton(ctx, database.SetUsernameSkeletonParams{
+				UsernameSkeleton: usernameSkeleton,
+				Username:         user.Username,
+			})
+
+			if err != nil {
+				utils.Log("UsernameSkeletonJob", "Unable to set username skeleton of user "+user.Username, err)
+				continue
+			}
+
+			numUsers++
+		}
+
+		if len(users) < skeletonBatchSize {
+			break
+		}
+		afterUsername = users[len(users)-1].Username
+	}
+
+	numOldUsernames := 0
+	afterId := pgtype.UUID{Valid: true}
+	for {
+		oldUsernames, err := queries.GetOldUsernameSkeletons(ctx, database.GetOldUsernameSkeletonsParams{
+			Limit:   skeletonBatchSize,
+			AfterID: afterId,
+		})
+
+		if err != nil {
+			utils.Log("UsernameSkeletonJob", "Unable to get old username skeletons", err)
+			return
+		}
+
+		for _, oldUsername := range oldUsernames {
+			usernameSkeleton := utils.UsernameSkeleton(oldUsername.OldUsername)
+			if usernameSkeleton == oldUsername.OldUsernameSkeleton {
+				continue
+			}
+
+			err = queries.SetOldUsernameSkeleton(ctx, database.SetOldUsernameSkeletonParams{
+				OldUsernameSkeleton: usernameSkeleton,
+				ID:                  oldUsername.ID,
+			})
+
+			if err != nil {
+				utils.Log("UsernameSkeletonJob", "Unable to set username skeleton of old username "+
+					oldUsername.OldUsername, err)
+				continue
+			}
+
+			numOldUsernames++
+		}
+
+		if len(oldUsernames) < skeletonBatchSize {
+			break
+		}
+		afterId = oldUsernames[len(oldUsernames)-1].ID
+	}
+
+	if numUsers > 0 || numOldUsernames > 0 {
+		utils.Log("UsernameSkeletonJob", "Recomputed the username skeletons of "+strconv.Itoa(numUsers)+
+			" users and "+strconv.Itoa(numOldUsernames)+" old usernames", nil)
+	}
+}
@@ -0,0 +1,88 @@
+package jobs
+
+import (
+	"backend/internal/database"
+	"backend/internal/utils"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"strconv"
+	"time"
+)
+
+// viewKey Identifies the views of a thread by a viewer.
+type viewKey struct {
+	threadId string
+	viewer   string
+}
+
+// StartViewJob Starts a background job that collects the views recorded by requests and periodically adds them to
+// the view counts of threads, so that recording a view never waits for the database.
+func StartViewJob() {
+	go func() {
+		ticker := time.NewTicker(time.Duration(utils.VIEW_FLUSH_SECONDS) * time.Second)
+		defer ticker.Stop()
+
+		// Only the first view of a thread by each viewer since the last flush is kept
+		pendingViews := map[viewKey]time.Time{}
+
+		for {
+			select {
+			case view := <-utils.ThreadViews:
+				key := viewKey{threadId: view.ThreadID, viewer: view.Viewer}
+				if _, ok := pendingViews[key]; !ok {
+					pendingViews[key] = view.ViewedTime
+				}
+			case <-ticker.C:
+				countViews(pendingViews)
+				pendingViews = map[viewKey]time.Time{}
+			}
+		}
+	}()
+}
+
+// countViews Adds the given views to the view counts of threads, ignoring repeated views within VIEW_WINDOW_MINUTES,
+// and removes views that are older than the window.
+func countViews(views map[viewKey]time.Time) {
+	// Connect to database
+	ctx := context.Background()
+	conn := database.GetConnection()
+	if conn == nil {
+		utils.Log("ViewJob", "Unable to connect to database", errors.New("no database connection"))
+		return
+	}
+	defer database.CloseConnection(conn)
+	queries := database.New(conn)
+
+	windowMinutes := int32(utils.VIEW_WINDOW_MINUTES)
+
+	if len(views) > 0 {
+		var params database.RecordThreadViewsParams
+		params.WindowMinutes = windowMinutes
+
+		for key, viewedTime := range views {
+			var pgThreadId pgtype.UUID
+			if err := pgThreadId.Scan(key.threadId); err != nil {
+				utils.Log("ViewJob", "Unable to scan threadId", err)
+				continue
+			}
+			params.ThreadIds = append(params.ThreadIds, pgThreadId)
+			params.Viewers = append(params.Viewers, key.viewer)
+			params.ViewedTimes = append(params.ViewedTimes, pgtype.Timestamptz{Time: viewedTime, Valid: true})
+		}
+
+		_, err := queries.RecordThreadViews(ctx, params)
+
+		if err != nil {
+			utils.Log("ViewJob", "Unable to count "+strconv.Itoa(len(views))+" thread views", err)
+			return
+		}
+	}
+
+	_, err := queries.DeleteExpiredThreadViews(ctx, windowMinutes)
+
+	if err != nil {
+		utils.Log("ViewJob", "Unable to delete expired thread views", err)
+		return
+	}
+}
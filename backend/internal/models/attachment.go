@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Attachment A file uploaded by a user to attach to a thread or comment. Width and Height are only set for images.
+// If HasThumbnail is false, images are small enough to be shown as their own thumbnail.
+type Attachment struct {
+	ID           string    `json:"id"`
+	Filename     string    `json:"filename"`
+	ContentType  string    `json:"content_type"`
+	Size         int32     `json:"size"`
+	Width        *int32    `json:"width"`
+	Height       *int32    `json:"height"`
+	HasThumbnail bool      `json:"has_thumbnail"`
+	CreatedTime  time.Time `json:"created_time"`
+}
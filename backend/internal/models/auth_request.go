@@ -1,7 +1,10 @@
 package models
 
 // AuthRequest Provides the layout for the JSON object sent by frontend to login or signup
+// Email and InviteCode are only used for signup, depending on the registration mode.
 type AuthRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	Email      string `json:"email,omitempty"`
+	InviteCode string `json:"invite_code,omitempty"`
 }
@@ -2,6 +2,7 @@ package models
 
 // AuthResponse Provides the layout for the JSON object returned by CreateUser and LoginUser
 type AuthResponse struct {
-	Username string `json:"username"`
-	Token    string `json:"token"`
+	Username   string `json:"username"`
+	Token      string `json:"token"`
+	IsVerified bool   `json:"is_verified"`
 }
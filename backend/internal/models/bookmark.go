@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Bookmark A thread or comment saved by a user
+// CommentID and CommentBody are only given for bookmarks of comments, in which case ThreadID and ThreadTitle are those
+// of the thread the comment was posted in. Creator is the creator of the bookmarked thread or comment.
+type Bookmark struct {
+	ID          string    `json:"id"`
+	ThreadID    string    `json:"thread_id"`
+	ThreadTitle string    `json:"thread_title"`
+	CommentID   *string   `json:"comment_id"`
+	CommentBody *string   `json:"comment_body"`
+	Creator     string    `json:"creator"`
+	Folder      string    `json:"folder"`
+	Note        string    `json:"note"`
+	CreatedTime time.Time `json:"created_time"`
+}
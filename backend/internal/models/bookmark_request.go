@@ -0,0 +1,8 @@
+package models
+
+// BookmarkRequest Provides the layout for the JSON object sent by frontend to bookmark a thread or comment
+// Both fields are optional. Bookmarks without a folder are not in any folder.
+type BookmarkRequest struct {
+	Folder string `json:"folder"`
+	Note   string `json:"note"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Category A category of threads. Categories are nested under their parent category, and only users with at least the
+// posting role can create threads in a category. NumThreads counts the threads of the category itself, while
+// TotalThreads also counts the threads of its subcategories.
+type Category struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Slug          string     `json:"slug"`
+	Description   string     `json:"description"`
+	ParentId      *string    `json:"parent_id"`
+	Position      int32      `json:"position"`
+	PostingRole   string     `json:"posting_role"`
+	NumThreads    int32      `json:"num_threads"`
+	TotalThreads  int32      `json:"total_threads"`
+	CreatedTime   time.Time  `json:"created_time"`
+	Subcategories []Category `json:"subcategories"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// CategoryRequest Provides the layout for the JSON object sent by frontend to create or update a category
+// ParentId is optional, categories without a parent are top-level categories. PostingRole defaults to user.
+type CategoryRequest struct {
+	Name        string  `json:"name"`
+	Slug        string  `json:"slug"`
+	Description string  `json:"description"`
+	ParentId    *string `json:"parent_id"`
+	Position    int32   `json:"position"`
+	PostingRole string  `json:"posting_role"`
+}
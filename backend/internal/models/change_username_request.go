@@ -0,0 +1,6 @@
+package models
+
+// ChangeUsernameRequest Provides the layout for the JSON object sent by frontend to change the current username
+type ChangeUsernameRequest struct {
+	Username string `json:"username"`
+}
@@ -3,10 +3,16 @@ package models
 import "time"
 
 type Comment struct {
-	ID          string    `json:"id"`
-	Body        string    `json:"body"`
-	Creator     string    `json:"creator"`
-	ThreadID    string    `json:"thread_id"`
-	CreatedTime time.Time `json:"created_time"`
-	UpdatedTime time.Time `json:"updated_time"`
+	ID           string       `json:"id"`
+	Body         string       `json:"body"`
+	BodyHtml     string       `json:"body_html"`
+	Creator      string       `json:"creator"`
+	ThreadID     string       `json:"thread_id"`
+	CreatedTime  time.Time    `json:"created_time"`
+	UpdatedTime  time.Time    `json:"updated_time"`
+	IsEdited     bool         `json:"is_edited"`
+	NumRevisions int32        `json:"num_revisions"`
+	Score        int32        `json:"score"`
+	MyVote       int32        `json:"my_vote"`
+	Attachments  []Attachment `json:"attachments,omitempty"`
 }
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// CommentRevision A previous version of a comment, together with the edit that replaced it
+type CommentRevision struct {
+	RevisionNumber int32     `json:"revision_number"`
+	Body           string    `json:"body"`
+	Editor         string    `json:"editor"`
+	EditedTime     time.Time `json:"edited_time"`
+}
@@ -1,7 +1,9 @@
 package models
 
 // CreateCommentRequest Provides the layout for the JSON object sent by frontend to create a comment
+// AttachmentIds are the IDs of attachments uploaded by the user to attach to the comment.
 type CreateCommentRequest struct {
-	ThreadId string `json:"thread_id"`
-	Body     string `json:"body"`
+	ThreadId      string   `json:"thread_id"`
+	Body          string   `json:"body"`
+	AttachmentIds []string `json:"attachment_ids"`
 }
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// CreateInviteRequest Provides the layout for the JSON object sent by frontend to create an invite code
+// If ExpiresTime is not given, the invite code does not expire.
+type CreateInviteRequest struct {
+	MaxUses     int32      `json:"max_uses"`
+	ExpiresTime *time.Time `json:"expires_time"`
+}
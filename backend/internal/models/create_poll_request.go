@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// CreatePollRequest Provides the layout for the JSON object sent by frontend to attach a poll to a thread
+// Options are shown in the order given. If CloseTime is set, votes are no longer accepted after it.
+// If IsAnonymous is set, the voters of each option are not shown.
+type CreatePollRequest struct {
+	Options          []string   `json:"options"`
+	IsMultipleChoice bool       `json:"is_multiple_choice"`
+	IsAnonymous      bool       `json:"is_anonymous"`
+	CloseTime        *time.Time `json:"close_time"`
+}
@@ -1,7 +1,17 @@
 package models
 
+import "time"
+
+// CreateThreadRequest Provides the layout for the JSON object sent by frontend to create a thread
+// If PublishAt is in the future, the thread is scheduled to be published then instead of being created now.
+// Poll is optional. AttachmentIds are the IDs of attachments uploaded by the user to attach to the thread.
+// If CategoryId is not given, the thread is created in the General category.
 type CreateThreadRequest struct {
-	Title string   `json:"title"`
-	Body  string   `json:"body"`
-	Tags  []string `json:"tags"`
+	Title         string             `json:"title"`
+	Body          string             `json:"body"`
+	Tags          []string           `json:"tags"`
+	PublishAt     *time.Time         `json:"publish_at"`
+	Poll          *CreatePollRequest `json:"poll"`
+	AttachmentIds []string           `json:"attachment_ids"`
+	CategoryId    *string            `json:"category_id"`
 }
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Draft An unsent thread or comment of the user
+// Context is what the draft is for: 'new_thread', 'reply:<thread id>', 'edit_thread:<thread id>' or
+// 'edit_comment:<comment id>'.
+type Draft struct {
+	Context     string    `json:"context"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	Tags        []string  `json:"tags"`
+	CreatedTime time.Time `json:"created_time"`
+	UpdatedTime time.Time `json:"updated_time"`
+}
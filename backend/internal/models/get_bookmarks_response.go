@@ -0,0 +1,6 @@
+package models
+
+type GetBookmarksResponse struct {
+	Bookmarks []Bookmark `json:"bookmarks"`
+	Count     int32      `json:"count"`
+}
@@ -0,0 +1,6 @@
+package models
+
+type GetNotificationsResponse struct {
+	Notifications []Notification `json:"notifications"`
+	Count         int32          `json:"count"`
+}
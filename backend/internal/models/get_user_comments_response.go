@@ -0,0 +1,6 @@
+package models
+
+type GetUserCommentsResponse struct {
+	Comments []UserComment `json:"comments"`
+	Count    int32         `json:"count"`
+}
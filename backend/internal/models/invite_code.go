@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type InviteCode struct {
+	Code        string     `json:"code"`
+	Creator     string     `json:"creator"`
+	MaxUses     int32      `json:"max_uses"`
+	NumUses     int32      `json:"num_uses"`
+	ExpiresTime *time.Time `json:"expires_time"`
+	CreatedTime time.Time  `json:"created_time"`
+}
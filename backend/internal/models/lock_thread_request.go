@@ -0,0 +1,5 @@
+package models
+
+type LockThreadRequest struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,6 @@
+package models
+
+// MergeThreadRequest Provides the layout for the JSON object sent by frontend to merge a thread into another thread
+type MergeThreadRequest struct {
+	TargetThreadId string `json:"target_thread_id"`
+}
@@ -0,0 +1,6 @@
+package models
+
+// MoveThreadRequest Provides the layout for the JSON object sent by frontend to move a thread to another category
+type MoveThreadRequest struct {
+	CategoryId string `json:"category_id"`
+}
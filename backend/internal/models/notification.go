@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Notification A notification about activity on a thread the user is subscribed to, or a mention of the user
+// Type is 'comment' for a new comment, in which case CommentID is the new comment and Actor is its creator.
+// Type is 'mention' when the user is mentioned in a thread or comment, in which case CommentID is the comment, if the
+// mention is in a comment, and Actor is the creator of the thread or comment.
+// Actor is not given if their account has been deleted.
+type Notification struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	ThreadID    string    `json:"thread_id"`
+	ThreadTitle string    `json:"thread_title"`
+	CommentID   *string   `json:"comment_id"`
+	Actor       *string   `json:"actor"`
+	IsRead      bool      `json:"is_read"`
+	CreatedTime time.Time `json:"created_time"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// PinThreadRequest Provides the layout for the JSON object sent by frontend to pin a thread
+// If Tag is not given, the thread is pinned everywhere. Announcements cannot be pinned for a tag.
+// If ExpiresTime is not given, the pin does not expire.
+type PinThreadRequest struct {
+	Tag            string     `json:"tag"`
+	IsAnnouncement bool       `json:"is_announcement"`
+	ExpiresTime    *time.Time `json:"expires_time"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Poll The poll attached to a thread, with its results. MyVotes are the positions of the options voted for by
+// the user viewing the poll.
+type Poll struct {
+	Options          []PollOption `json:"options"`
+	IsMultipleChoice bool         `json:"is_multiple_choice"`
+	IsAnonymous      bool         `json:"is_anonymous"`
+	CloseTime        *time.Time   `json:"close_time"`
+	IsClosed         bool         `json:"is_closed"`
+	NumVoters        int32        `json:"num_voters"`
+	MyVotes          []int32      `json:"my_votes"`
+}
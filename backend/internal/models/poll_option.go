@@ -0,0 +1,9 @@
+package models
+
+// PollOption An option of a poll, numbered from 1. Voters is empty if the poll is anonymous.
+type PollOption struct {
+	Position int32    `json:"position"`
+	Text     string   `json:"text"`
+	NumVotes int32    `json:"num_votes"`
+	Voters   []string `json:"voters"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// PollVoteRequest Provides the layout for the JSON object sent by frontend to vote on a poll
+// Options are the positions of the options voted for, replacing any previous votes of the user.
+type PollVoteRequest struct {
+	Options []int32 `json:"options"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// PowChallenge Provides the layout for the JSON object returned by GetChallenge
+// To solve the challenge, find a solution such that SHA-256(challenge + solution) starts with
+// at least difficulty zero bits. If Required is false, the other fields are empty.
+type PowChallenge struct {
+	Required    bool      `json:"required"`
+	Challenge   string    `json:"challenge,omitempty"`
+	Difficulty  int       `json:"difficulty,omitempty"`
+	ExpiresTime time.Time `json:"expires_time,omitempty"`
+}
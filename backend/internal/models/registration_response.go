@@ -0,0 +1,7 @@
+package models
+
+// RegistrationResponse Provides the layout for the JSON object sent to frontend with the registration mode
+// Mode is one of "open", "email", "invite" or "closed".
+type RegistrationResponse struct {
+	Mode string `json:"mode"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// RelatedThread A summary of a thread related to another thread, ranked by shared tags and full-text similarity
+type RelatedThread struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Creator     string    `json:"creator"`
+	CreatedTime time.Time `json:"created_time"`
+	NumComments int32     `json:"num_comments"`
+	Score       int32     `json:"score"`
+	Tags        []string  `json:"tags"`
+}
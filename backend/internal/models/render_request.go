@@ -0,0 +1,6 @@
+package models
+
+// RenderRequest Provides the layout for the JSON object sent by frontend to preview a thread or comment body
+type RenderRequest struct {
+	Body string `json:"body"`
+}
@@ -0,0 +1,6 @@
+package models
+
+// RenderResponse Provides the layout for the JSON object sent to frontend with the rendered HTML of a body
+type RenderResponse struct {
+	BodyHtml string `json:"body_html"`
+}
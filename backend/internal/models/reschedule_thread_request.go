@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+// RescheduleThreadRequest Provides the layout for the JSON object sent by frontend to change when a thread is published
+// PublishAt must be in the future.
+type RescheduleThreadRequest struct {
+	PublishAt time.Time `json:"publish_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// DiffSegment A run of text that is unchanged, inserted or deleted between two revisions.
+// Type is one of 'equal', 'insert', 'delete'.
+type DiffSegment struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// RevisionDiff The differences between two revisions of a thread or comment.
+// Title and tag changes are only present for threads.
+type RevisionDiff struct {
+	From        int32         `json:"from"`
+	To          int32         `json:"to"`
+	Title       []DiffSegment `json:"title,omitempty"`
+	Body        []DiffSegment `json:"body"`
+	TagsAdded   []string      `json:"tags_added,omitempty"`
+	TagsRemoved []string      `json:"tags_removed,omitempty"`
+}
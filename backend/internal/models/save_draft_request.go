@@ -0,0 +1,9 @@
+package models
+
+// SaveDraftRequest Provides the layout for the JSON object sent by frontend to save a draft
+// Unlike threads and comments, all fields may be empty. Title and Tags are ignored for drafts of comments.
+type SaveDraftRequest struct {
+	Title string   `json:"title"`
+	Body  string   `json:"body"`
+	Tags  []string `json:"tags"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ScheduledThread A thread that will be published at PublishAt. When published, the thread keeps the same ID.
+type ScheduledThread struct {
+	ID            string             `json:"id"`
+	Title         string             `json:"title"`
+	Body          string             `json:"body"`
+	Creator       string             `json:"creator"`
+	Tags          []string           `json:"tags"`
+	Poll          *CreatePollRequest `json:"poll"`
+	AttachmentIds []string           `json:"attachment_ids"`
+	CategoryId    string             `json:"category_id"`
+	PublishAt     time.Time          `json:"publish_at"`
+	CreatedTime   time.Time          `json:"created_time"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// SimilarThreadsRequest Provides the layout for the JSON object sent by frontend to find duplicates of a draft thread
+type SimilarThreadsRequest struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// SimilarThread A thread that is likely a duplicate of a draft thread
+// TitleSimilarity is the trigram similarity of the titles, from 0 to 1. IsNearDuplicate is true if the bodies are
+// nearly the same.
+type SimilarThread struct {
+	ID              string    `json:"id"`
+	Title           string    `json:"title"`
+	Creator         string    `json:"creator"`
+	CreatedTime     time.Time `json:"created_time"`
+	NumComments     int32     `json:"num_comments"`
+	TitleSimilarity float32   `json:"title_similarity"`
+	IsNearDuplicate bool      `json:"is_near_duplicate"`
+}
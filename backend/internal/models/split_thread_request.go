@@ -0,0 +1,11 @@
+package models
+
+// SplitThreadRequest Provides the layout for the JSON object sent by frontend to split comments out of a thread
+// The comments are moved to the thread with TargetThreadId if it is given. Otherwise a new thread is created with
+// Title and Tags, and the earliest of the comments becomes its opening post.
+type SplitThreadRequest struct {
+	CommentIds     []string `json:"comment_ids"`
+	TargetThreadId *string  `json:"target_thread_id"`
+	Title          string   `json:"title"`
+	Tags           []string `json:"tags"`
+}
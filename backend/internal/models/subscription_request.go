@@ -0,0 +1,7 @@
+package models
+
+// SubscriptionRequest Provides the layout for the JSON object sent by frontend to subscribe to or mute a thread
+// If IsMuted is true, the user is not notified of new comments and is not subscribed again when they comment.
+type SubscriptionRequest struct {
+	IsMuted bool `json:"is_muted"`
+}
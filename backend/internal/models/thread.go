@@ -5,12 +5,27 @@ import (
 )
 
 type Thread struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Body        string    `json:"body"`
-	Creator     string    `json:"creator"`
-	CreatedTime time.Time `json:"created_time"`
-	UpdatedTime time.Time `json:"updated_time"`
-	NumComments int32     `json:"num_comments"`
-	Tags        []string  `json:"tags"`
+	ID             string       `json:"id"`
+	Title          string       `json:"title"`
+	Body           string       `json:"body"`
+	BodyHtml       string       `json:"body_html"`
+	Creator        string       `json:"creator"`
+	CategoryId     string       `json:"category_id"`
+	CreatedTime    time.Time    `json:"created_time"`
+	UpdatedTime    time.Time    `json:"updated_time"`
+	NumComments    int32        `json:"num_comments"`
+	Tags           []string     `json:"tags"`
+	IsEdited       bool         `json:"is_edited"`
+	NumRevisions   int32        `json:"num_revisions"`
+	IsLocked       bool         `json:"is_locked"`
+	LockReason     *string      `json:"lock_reason"`
+	IsPinned       bool         `json:"is_pinned"`
+	IsAnnouncement bool         `json:"is_announcement"`
+	Score          int32        `json:"score"`
+	MyVote         int32        `json:"my_vote"`
+	ViewCount      int32        `json:"view_count"`
+	IsBookmarked   bool         `json:"is_bookmarked"`
+	IsSubscribed   bool         `json:"is_subscribed"`
+	Poll           *Poll        `json:"poll,omitempty"`
+	Attachments    []Attachment `json:"attachments,omitempty"`
 }
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// ThreadRevision A previous version of a thread, together with the edit that replaced it
+type ThreadRevision struct {
+	RevisionNumber int32     `json:"revision_number"`
+	Title          string    `json:"title"`
+	Body           string    `json:"body"`
+	Tags           []string  `json:"tags"`
+	Editor         string    `json:"editor"`
+	EditedTime     time.Time `json:"edited_time"`
+}
@@ -0,0 +1,6 @@
+package models
+
+// UnreadNotificationsResponse The number of unread notifications of the user
+type UnreadNotificationsResponse struct {
+	Count int32 `json:"count"`
+}
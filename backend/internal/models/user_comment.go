@@ -0,0 +1,7 @@
+package models
+
+// UserComment A comment together with the title of the thread it was posted in
+type UserComment struct {
+	Comment
+	ThreadTitle string `json:"thread_title"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// UserProfile Provides the layout for the JSON object returned by GetUserProfile
+type UserProfile struct {
+	Username    string `json:"username"`
+	NumThreads  int32  `json:"num_threads"`
+	NumComments int32  `json:"num_comments"`
+}
@@ -0,0 +1,6 @@
+package models
+
+// VerifyEmailRequest Provides the layout for the JSON object sent by frontend to verify an email
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// VoteRequest Provides the layout for the JSON object sent by frontend to vote on a thread or comment
+// Value must be 1 for an upvote or -1 for a downvote.
+type VoteRequest struct {
+	Value int32 `json:"value"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// VoteResponse The score of a thread or comment after the user has voted, together with the vote of the user.
+// MyVote is 0 if the user has removed their vote.
+type VoteResponse struct {
+	Score  int32 `json:"score"`
+	MyVote int32 `json:"my_vote"`
+}
@@ -1,7 +1,15 @@
 package router
 
 import (
+	"backend/internal/handlers/attachments"
+	"backend/internal/handlers/bookmarks"
+	"backend/internal/handlers/categories"
+	"backend/internal/handlers/challenges"
 	"backend/internal/handlers/comments"
+	"backend/internal/handlers/drafts"
+	"backend/internal/handlers/invites"
+	"backend/internal/handlers/markdown"
+	"backend/internal/handlers/notifications"
 	"backend/internal/handlers/threads"
 	"backend/internal/handlers/user"
 	"github.com/gorilla/mux"
@@ -24,19 +32,101 @@ func SetupRouter() *mux.Router {
 	// Authentication
 	http.HandleFunc(BASE_PATH+"user/create", user.CreateUser)
 	http.HandleFunc(BASE_PATH+"user/login", user.LoginUser)
+	http.HandleFunc(BASE_PATH+"user/verify", user.VerifyEmail)
+	http.HandleFunc(BASE_PATH+"user/verify/resend", user.ResendVerification)
+	http.HandleFunc(BASE_PATH+"user/rename", user.ChangeUsername)
+	http.HandleFunc(BASE_PATH+"registration", user.GetRegistration)
+
+	// Proof-of-work challenges
+	http.HandleFunc(BASE_PATH+"challenge", challenges.GetChallenge)
+
+	// Markdown previews
+	http.HandleFunc(BASE_PATH+"render", markdown.RenderMarkdown)
+	http.HandleFunc(BASE_PATH+"render/stylesheet", markdown.GetHighlightStylesheet)
+
+	// Invite codes
+	http.HandleFunc(BASE_PATH+"invite", invites.GetInvites)
+	http.HandleFunc(BASE_PATH+"invite/create", invites.CreateInvite)
+	r.HandleFunc(BASE_PATH+"invite/{code}", invites.DeleteInvite).Methods("DELETE")
+
+	// Categories
+	http.HandleFunc(BASE_PATH+"category", categories.GetCategories)
+	http.HandleFunc(BASE_PATH+"category/create", categories.CreateCategory)
+	r.HandleFunc(BASE_PATH+"category/{id}", categories.UpdateCategory).Methods("PUT")
+	r.HandleFunc(BASE_PATH+"category/{id}", categories.DeleteCategory).Methods("DELETE")
+
+	// User activity
+	r.HandleFunc(BASE_PATH+"user/{username}", user.GetUserProfile).Methods("GET")
+	r.HandleFunc(BASE_PATH+"user/{username}/threads", user.GetUserThreads).Methods("GET")
+	r.HandleFunc(BASE_PATH+"user/{username}/comments", user.GetUserComments).Methods("GET")
 
 	// Comments
 	r.HandleFunc(BASE_PATH+"thread/{thread_id}/comments", comments.GetComments).Methods("GET")
 	http.HandleFunc(BASE_PATH+"comment/create", comments.CreateComment)
 	r.HandleFunc(BASE_PATH+"comment/{id}", comments.UpdateComment).Methods("PUT")
 	r.HandleFunc(BASE_PATH+"comment/{id}", comments.DeleteComment).Methods("DELETE")
+	r.HandleFunc(BASE_PATH+"comment/{id}/restore", comments.RestoreComment).Methods("POST")
+	r.HandleFunc(BASE_PATH+"comment/{id}/revisions", comments.GetCommentRevisions).Methods("GET")
+	r.HandleFunc(BASE_PATH+"comment/{id}/revisions/diff", comments.GetCommentRevisionDiff).Methods("GET")
+	r.HandleFunc(BASE_PATH+"comment/{id}/vote", comments.VoteComment).Methods("PUT")
+	r.HandleFunc(BASE_PATH+"comment/{id}/vote", comments.DeleteCommentVote).Methods("DELETE")
 
 	// Threads
 	//r.HandleFunc(BASE_PATH+"threads", threads.GetThreads).Methods("GET")
 	r.HandleFunc(BASE_PATH+"thread/{id}", threads.GetThread).Methods("GET")
+	r.HandleFunc(BASE_PATH+"thread/{id}/related", threads.GetRelatedThreads).Methods("GET")
+	http.HandleFunc(BASE_PATH+"thread/scheduled", threads.GetScheduledThreads)
+	r.HandleFunc(BASE_PATH+"thread/scheduled/{id}", threads.RescheduleThread).Methods("PUT")
+	r.HandleFunc(BASE_PATH+"thread/scheduled/{id}", threads.CancelScheduledThread).Methods("DELETE")
 	http.HandleFunc(BASE_PATH+"thread/create", threads.CreateThread)
+	http.HandleFunc(BASE_PATH+"thread/similar", threads.GetSimilarThreads)
 	r.HandleFunc(BASE_PATH+"thread/{id}", threads.UpdateThread).Methods("PUT")
 	r.HandleFunc(BASE_PATH+"thread/{id}", threads.DeleteThread).Methods("DELETE")
+	r.HandleFunc(BASE_PATH+"thread/{id}/restore", threads.RestoreThread).Methods("POST")
+	r.HandleFunc(BASE_PATH+"thread/{id}/revisions", threads.GetThreadRevisions).Methods("GET")
+	r.HandleFunc(BASE_PATH+"thread/{id}/revisions/diff", threads.GetThreadRevisionDiff).Methods("GET")
+	r.HandleFunc(BASE_PATH+"thread/{id}/lock", threads.LockThread).Methods("POST")
+	r.HandleFunc(BASE_PATH+"thread/{id}/unlock", threads.UnlockThread).Methods("POST")
+	r.HandleFunc(BASE_PATH+"thread/{id}/merge", threads.MergeThread).Methods("POST")
+	r.HandleFunc(BASE_PATH+"thread/{id}/split", threads.SplitThread).Methods("POST")
+	r.HandleFunc(BASE_PATH+"thread/{id}/move", threads.MoveThread).Methods("POST")
+	r.HandleFunc(BASE_PATH+"thread/{id}/pin", threads.PinThread).Methods("POST")
+	r.HandleFunc(BASE_PATH+"thread/{id}/pin", threads.UnpinThread).Methods("DELETE")
+	r.HandleFunc(BASE_PATH+"thread/{id}/vote", threads.VoteThread).Methods("PUT")
+	r.HandleFunc(BASE_PATH+"thread/{id}/vote", threads.DeleteThreadVote).Methods("DELETE")
+	r.HandleFunc(BASE_PATH+"thread/{id}/subscription", threads.SubscribeThread).Methods("PUT")
+	r.HandleFunc(BASE_PATH+"thread/{id}/subscription", threads.UnsubscribeThread).Methods("DELETE")
+	r.HandleFunc(BASE_PATH+"thread/{id}/poll", threads.UpdatePoll).Methods("PUT")
+	r.HandleFunc(BASE_PATH+"thread/{id}/poll/vote", threads.VotePoll).Methods("PUT")
+	r.HandleFunc(BASE_PATH+"thread/{id}/poll/vote", threads.DeletePollVote).Methods("DELETE")
+
+	// Announcements
+	http.HandleFunc(BASE_PATH+"announcement", threads.GetAnnouncements)
+
+	// Bookmarks
+	http.HandleFunc(BASE_PATH+"bookmark", bookmarks.GetBookmarks)
+	http.HandleFunc(BASE_PATH+"bookmark/folders", bookmarks.GetBookmarkFolders)
+	r.HandleFunc(BASE_PATH+"thread/{id}/bookmark", bookmarks.BookmarkThread).Methods("PUT")
+	r.HandleFunc(BASE_PATH+"thread/{id}/bookmark", bookmarks.UnbookmarkThread).Methods("DELETE")
+	r.HandleFunc(BASE_PATH+"comment/{id}/bookmark", bookmarks.BookmarkComment).Methods("PUT")
+	r.HandleFunc(BASE_PATH+"comment/{id}/bookmark", bookmarks.UnbookmarkComment).Methods("DELETE")
+
+	// Notifications
+	http.HandleFunc(BASE_PATH+"notification", notifications.GetNotifications)
+	http.HandleFunc(BASE_PATH+"notification/unread", notifications.GetUnreadNotificationCount)
+	http.HandleFunc(BASE_PATH+"notification/read", notifications.MarkAllNotificationsRead)
+	r.HandleFunc(BASE_PATH+"notification/{id}/read", notifications.MarkNotificationRead).Methods("POST")
+
+	// Drafts
+	http.HandleFunc(BASE_PATH+"draft", drafts.GetDrafts)
+	r.HandleFunc(BASE_PATH+"draft/{context}", drafts.GetDraft).Methods("GET")
+	r.HandleFunc(BASE_PATH+"draft/{context}", drafts.SaveDraft).Methods("PUT")
+	r.HandleFunc(BASE_PATH+"draft/{context}", drafts.DeleteDraft).Methods("DELETE")
+
+	// Attachments
+	http.HandleFunc(BASE_PATH+"attachment", attachments.UploadAttachment)
+	r.HandleFunc(BASE_PATH+"attachment/{id}", attachments.GetAttachment).Methods("GET")
+	r.HandleFunc(BASE_PATH+"attachment/{id}/thumbnail", attachments.GetAttachmentThumbnail).Methods("GET")
 
 	// Search Threads
 	http.HandleFunc(BASE_PATH+"thread", threads.SearchThreads)
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"backend/internal/utils"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+)
+
+// BlobStore Stores files by key. Keys are the SHA-256 hashes of the contents of the files, so files with the same
+// contents are only stored once.
+type BlobStore interface {
+	// Put Stores a file under the key, replacing any file already stored under it.
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get Returns the contents of the file stored under the key, or ErrBlobNotFound. The caller closes the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete Removes the file stored under the key. Removing a file that does not exist is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// ErrBlobNotFound Returned by BlobStore.Get when no file is stored under the key.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// ErrInvalidKey Returned when a key is not a hex-encoded SHA-256 hash.
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// Blobs The blob store used by the server, set by InitBlobStore.
+var Blobs BlobStore
+
+var keyPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// BlobKey Returns the key of a file, which is the hex-encoded SHA-256 hash of its contents.
+func BlobKey(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// isValidKey Returns true if the key is a hex-encoded SHA-256 hash, so it is safe to use in paths and URLs.
+func isValidKey(key string) bool {
+	return keyPattern.MatchString(key)
+}
+
+// InitBlobStore Initializes the blob store from STORAGE_BACKEND, which is either 'local' (default) to store files
+// under STORAGE_PATH, or 's3' to store them in the S3_BUCKET bucket of an S3-compatible service at S3_ENDPOINT.
+func InitBlobStore() {
+	backend, _ := os.LookupEnv("STORAGE_BACKEND")
+
+	switch backend {
+	case "s3":
+		region := os.Getenv("S3_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+
+		s3Store := NewS3BlobStore(os.Getenv("S3_ENDPOINT"), os.Getenv("S3_BUCKET"), region,
+			os.Getenv("S3_ACCESS_KEY"), os.Getenv("S3_SECRET_KEY"))
+
+		if s3Store.Endpoint == "" || s3Store.Bucket == "" {
+			utils.Log("main", "S3_ENDPOINT and S3_BUCKET must be set to use S3 storage",
+				errors.New("missing S3 settings"))
+			os.Exit(1)
+		}
+
+		Blobs = s3Store
+		utils.Log("main", "Attachments stored in S3 bucket: "+s3Store.Bucket+" at: "+s3Store.Endpoint, nil)
+	default:
+		if backend != "" && backend != "local" {
+			utils.Log("main", "Invalid value for STORAGE_BACKEND, using local", errors.New("invalid storage backend"))
+		}
+
+		path := os.Getenv("STORAGE_PATH")
+		if path == "" {
+			path = "uploads"
+		}
+
+		Blobs = NewLocalBlobStore(path)
+		utils.Log("main", "Attachments stored in: "+path, nil)
+	}
+}
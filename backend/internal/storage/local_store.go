@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LocalBlobStore Stores files on the local filesystem under Root. Files are spread over subdirectories named after
+// the first characters of their keys, so that no directory holds too many files.
+type LocalBlobStore struct {
+	Root string
+}
+
+// NewLocalBlobStore Returns a blob store that stores files under root, which is created when the first file is stored.
+func NewLocalBlobStore(root string) *LocalBlobStore {
+	return &LocalBlobStore{Root: root}
+}
+
+// path Returns the path of the file stored under the key.
+func (s *LocalBlobStore) path(key string) string {
+	return filepath.Join(s.Root, key[0:2], key[2:4], key)
+}
+
+// Put Stores a file under the key. The file is written to a temporary file first and then renamed, so that a
+// partially written file is never read.
+func (s *LocalBlobStore) Put(_ context.Context, key string, data []byte, _ string) error {
+	if !isValidKey(key) {
+		return ErrInvalidKey
+	}
+
+	path := s.path(key)
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(data)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+// Get Opens the file stored under the key.
+func (s *LocalBlobStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	if !isValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	file, err := os.Open(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	return file, err
+}
+
+// Delete Removes the file stored under the key.
+func (s *LocalBlobStore) Delete(_ context.Context, key string) error {
+	if !isValidKey(key) {
+		return ErrInvalidKey
+	}
+
+	err := os.Remove(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Hash of an empty request body, used to sign requests without a body.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3BlobStore Stores files in a bucket of an S3-compatible service such as Amazon S3 or MinIO. Objects are addressed
+// with path-style URLs, which all S3-compatible services support, and requests are signed with AWS Signature
+// Version 4.
+type S3BlobStore struct {
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	client    *http.Client
+}
+
+// NewS3BlobStore Returns a blob store that stores files in the bucket of the S3-compatible service at endpoint,
+// e.g. 'http://localhost:9000'. The bucket must already exist.
+func NewS3BlobStore(endpoint string, bucket string, region string, accessKey string, secretKey string) *S3BlobStore {
+	return &S3BlobStore{
+		Endpoint:  strings.TrimSuffix(endpoint, "/"),
+		Bucket:    bucket,
+		Region:    region,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Put Uploads a file to the bucket under the key.
+func (s *S3BlobStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if !isValidKey(key) {
+		return ErrInvalidKey
+	}
+
+	request, err := s.newRequest(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", contentType)
+
+	response, err := s.do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return s.responseError(response)
+	}
+	return nil
+}
+
+// Get Downloads the file stored in the bucket under the key.
+func (s *S3BlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !isValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	request, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		return response.Body, nil
+	case http.StatusNotFound:
+		response.Body.Close()
+		return nil, ErrBlobNotFound
+	default:
+		defer response.Body.Close()
+		return nil, s.responseError(response)
+	}
+}
+
+// Delete Removes the file stored in the bucket under the key. S3 does not report missing objects when deleting.
+func (s *S3BlobStore) Delete(ctx context.Context, key string) error {
+	if !isValidKey(key) {
+		return ErrInvalidKey
+	}
+
+	request, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := s.do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusNoContent && response.StatusCode != http.StatusOK &&
+		response.StatusCode != http.StatusNotFound {
+		return s.responseError(response)
+	}
+	return nil
+}
+
+// newRequest Creates a request for the object stored under the key, with the given body if any.
+func (s *S3BlobStore) newRequest(ctx context.Context, method string, key string, body []byte) (*http.Request, error) {
+	objectUrl := s.Endpoint + "/" + url.PathEscape(s.Bucket) + "/" + key
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, objectUrl, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+
+	payloadHash := emptyPayloadHash
+	if body != nil {
+		hash := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(hash[:])
+	}
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	return request, nil
+}
+
+// do Signs and sends a request.
+func (s *S3BlobStore) do(request *http.Request) (*http.Response, error) {
+	s.sign(request, time.Now().UTC())
+	return s.client.Do(request)
+}
+
+// sign Adds an AWS Signature Version 4 Authorization header to the request. The host, content hash and date headers
+// are signed, which is the minimum required by S3.
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func (s *S3BlobStore) sign(request *http.Request, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := request.Header.Get("X-Amz-Content-Sha256")
+	request.Header.Set("X-Amz-Date", amzDate)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + request.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalRequestHash[:])
+
+	signingKey := hmacSha256([]byte("AWS4"+s.SecretKey), date)
+	signingKey = hmacSha256(signingKey, s.Region)
+	signingKey = hmacSha256(signingKey, "s3")
+	signingKey = hmacSha256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(signingKey, stringToSign))
+
+	request.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.AccessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// responseError Returns an error describing an unexpected response, including the start of its body, which holds
+// the S3 error code.
+func (s *S3BlobStore) responseError(response *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+	return errors.New("unexpected response from S3: " + strconv.Itoa(response.StatusCode) + " " +
+		strings.TrimSpace(string(body)))
+}
+
+// hmacSha256 Returns the HMAC-SHA256 of the data with the key.
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Maximum size of an uploaded file in megabytes.
+var ATTACHMENT_MAX_SIZE_MB = 5
+
+// Number of hours after which attachments that have not been attached to a thread or comment are deleted.
+var ATTACHMENT_ORPHAN_HOURS = 24
+
+// MaxAttachments Maximum number of attachments of a thread or comment.
+const MaxAttachments = 10
+
+// Maximum length of the filename of an attachment.
+const maxFilenameLength = 255
+
+// Types of files that can be uploaded, as detected from their contents.
+var attachmentTypes = []string{"image/png", "image/jpeg", "image/gif", "application/pdf", "text/plain; charset=utf-8"}
+
+// Types of files that are shown inline by browsers rather than downloaded.
+var inlineAttachmentTypes = []string{"image/png", "image/jpeg", "image/gif"}
+
+// InitAttachmentPolicy Initializes the maximum size of uploaded files and how long unattached uploads are kept.
+func InitAttachmentPolicy() {
+	ATTACHMENT_MAX_SIZE_MB = max(GetEnvInt("ATTACHMENT_MAX_SIZE_MB", ATTACHMENT_MAX_SIZE_MB), 1)
+	ATTACHMENT_ORPHAN_HOURS = max(GetEnvInt("ATTACHMENT_ORPHAN_HOURS", ATTACHMENT_ORPHAN_HOURS), 1)
+
+	Log("main", "Attachments limited to "+strconv.Itoa(ATTACHMENT_MAX_SIZE_MB)+" MB, unattached uploads deleted after "+
+		strconv.Itoa(ATTACHMENT_ORPHAN_HOURS)+" hours", nil)
+}
+
+// AttachmentMaxSize Returns the maximum size of an uploaded file in bytes.
+func AttachmentMaxSize() int64 {
+	return int64(ATTACHMENT_MAX_SIZE_MB) << 20
+}
+
+// SniffAttachmentType Returns the type of the file detected from its first bytes, ignoring the type claimed by the
+// client, and true if files of that type can be uploaded.
+func SniffAttachmentType(data []byte) (string, bool) {
+	contentType := http.DetectContentType(data)
+	return contentType, slices.Contains(attachmentTypes, contentType)
+}
+
+// IsImageType Returns true if files of the type are images, which have their metadata stripped and thumbnails made.
+func IsImageType(contentType string) bool {
+	return strings.HasPrefix(contentType, "image/")
+}
+
+// IsInlineAttachmentType Returns true if files of the type can be shown in the browser. Other files are downloaded,
+// so that documents cannot run scripts on the site.
+func IsInlineAttachmentType(contentType string) bool {
+	return slices.Contains(inlineAttachmentTypes, contentType)
+}
+
+// NormalizeFilename Returns the name of an uploaded file without its directory, control characters or invalid UTF-8,
+// shortened to 255 bytes while keeping its extension. Returns 'attachment' if nothing is left.
+func NormalizeFilename(filename string) string {
+	filename = strings.ToValidUTF8(filename, "")
+	filename = filepath.Base(strings.ReplaceAll(filename, "\\", "/"))
+	filename = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, filename)
+	filename = strings.TrimSpace(filename)
+
+	if filename == "" || filename == "." || filename == ".." || filename == "/" {
+		return "attachment"
+	}
+
+	if len(filename) > maxFilenameLength {
+		extension := filepath.Ext(filename)
+		if len(extension) > 16 {
+			extension = ""
+		}
+		name := filename[:maxFilenameLength-len(extension)]
+		for !utf8.ValidString(name) {
+			name = name[:len(name)-1]
+		}
+		filename = name + extension
+	}
+
+	return filename
+}
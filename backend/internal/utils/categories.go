@@ -0,0 +1,10 @@
+package utils
+
+import "regexp"
+
+// DefaultCategoryId The ID of the General category, which threads are created in if no category is given.
+// It is created together with the database schema and cannot be deleted.
+const DefaultCategoryId = "00000000-0000-0000-0000-000000000001"
+
+// Category slugs are lowercase words of letters and digits separated by single hyphens, as in the database schema.
+var CATEGORY_SLUG_PATTERN = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
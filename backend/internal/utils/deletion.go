@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"strconv"
+	"time"
+)
+
+// Number of days the author of a deleted thread or comment can restore it. Moderators can restore it until it is purged.
+var RESTORE_WINDOW_DAYS = 7
+
+// Number of days deleted threads and comments are kept before they are purged.
+var DELETED_RETENTION_DAYS = 30
+
+// Number of minutes between purges of deleted threads and comments.
+var PURGE_INTERVAL_MINUTES = 60
+
+// InitDeletionPolicy Initializes the restore window and retention period of deleted threads and comments.
+func InitDeletionPolicy() {
+	RESTORE_WINDOW_DAYS = max(GetEnvInt("RESTORE_WINDOW_DAYS", RESTORE_WINDOW_DAYS), 0)
+	DELETED_RETENTION_DAYS = max(GetEnvInt("DELETED_RETENTION_DAYS", DELETED_RETENTION_DAYS), 0)
+	PURGE_INTERVAL_MINUTES = max(GetEnvInt("PURGE_INTERVAL_MINUTES", PURGE_INTERVAL_MINUTES), 1)
+
+	Log("main", "Deleted threads and comments are kept for "+strconv.Itoa(DELETED_RETENTION_DAYS)+" days", nil)
+}
+
+// CanAuthorRestore Returns true if the author of an item deleted at the given time can still restore it.
+func CanAuthorRestore(deletedTime time.Time) bool {
+	return time.Since(deletedTime) < time.Duration(RESTORE_WINDOW_DAYS)*24*time.Hour
+}
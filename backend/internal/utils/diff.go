@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"backend/internal/models"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var wordPattern = regexp.MustCompile(`\s+|[^\s]+`)
+
+// DiffLines Returns the line-by-line differences between two texts.
+func DiffLines(oldText string, newText string) []models.DiffSegment {
+	return diffTokens(strings.SplitAfter(oldText, "\n"), strings.SplitAfter(newText, "\n"))
+}
+
+// DiffWords Returns the word-by-word differences between two texts, keeping the whitespace between words.
+func DiffWords(oldText string, newText string) []models.DiffSegment {
+	return diffTokens(wordPattern.FindAllString(oldText, -1), wordPattern.FindAllString(newText, -1))
+}
+
+// DiffTags Returns the tags that are in newTags but not in oldTags, and the tags that are in oldTags but not in newTags.
+func DiffTags(oldTags []string, newTags []string) (added []string, removed []string) {
+	added = []string{}
+	removed = []string{}
+	for _, tag := range newTags {
+		if !slices.Contains(oldTags, tag) {
+			added = append(added, tag)
+		}
+	}
+	for _, tag := range oldTags {
+		if !slices.Contains(newTags, tag) {
+			removed = append(removed, tag)
+		}
+	}
+	return added, removed
+}
+
+// diffTokens Computes a shortest edit between two token sequences using their longest common subsequence,
+// merging consecutive tokens of the same type into a single segment.
+func diffTokens(oldTokens []string, newTokens []string) []models.DiffSegment {
+	// Skip the common prefix and suffix, which are usually most of the text
+	prefixLength := 0
+	for prefixLength < len(oldTokens) && prefixLength < len(newTokens) &&
+		oldTokens[prefixLength] == newTokens[prefixLength] {
+		prefixLength++
+	}
+	suffixLength := 0
+	for suffixLength < len(oldTokens)-prefixLength && suffixLength < len(newTokens)-prefixLength &&
+		oldTokens[len(oldTokens)-1-suffixLength] == newTokens[len(newTokens)-1-suffixLength] {
+		suffixLength++
+	}
+
+	a := oldTokens[prefixLength : len(oldTokens)-suffixLength]
+	b := newTokens[prefixLength : len(newTokens)-suffixLength]
+
+	// lcs[i*(len(b)+1)+j] is the length of the longest common subsequence of a[i:] and b[j:]
+	width := len(b) + 1
+	lcs := make([]int32, (len(a)+1)*width)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			} else {
+				lcs[i*width+j] = max(lcs[(i+1)*width+j], lcs[i*width+j+1])
+			}
+		}
+	}
+
+	segments := []models.DiffSegment{}
+	appendSegment := func(segmentType string, text string) {
+		if text == "" {
+			return
+		}
+		if last := len(segments) - 1; last >= 0 && segments[last].Type == segmentType {
+			segments[last].Text += text
+			return
+		}
+		segments = append(segments, models.DiffSegment{Type: segmentType, Text: text})
+	}
+
+	appendSegment("equal", strings.Join(oldTokens[:prefixLength], ""))
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if a[i] == b[j] {
+			appendSegment("equal", a[i])
+			i++
+			j++
+		} else if lcs[(i+1)*width+j] >= lcs[i*width+j+1] {
+			appendSegment("delete", a[i])
+			i++
+		} else {
+			appendSegment("insert", b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		appendSegment("delete", a[i])
+	}
+	for ; j < len(b); j++ {
+		appendSegment("insert", b[j])
+	}
+
+	appendSegment("equal", strings.Join(oldTokens[len(oldTokens)-suffixLength:], ""))
+
+	return segments
+}
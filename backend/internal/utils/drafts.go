@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Number of days after their last update that drafts are deleted.
+var DRAFT_EXPIRY_DAYS = 30
+
+// DraftNewThread Context of the draft of a new thread.
+const DraftNewThread = "new_thread"
+
+var draftContextPattern = regexp.MustCompile(
+	`^(new_thread|(reply|edit_thread|edit_comment):[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})$`)
+
+// InitDraftPolicy Initializes how long drafts are kept after their last update.
+func InitDraftPolicy() {
+	DRAFT_EXPIRY_DAYS = max(GetEnvInt("DRAFT_EXPIRY_DAYS", DRAFT_EXPIRY_DAYS), 1)
+
+	Log("main", "Drafts expire after "+strconv.Itoa(DRAFT_EXPIRY_DAYS)+" days", nil)
+}
+
+// IsValidDraftContext Returns true if the context is one of 'new_thread', 'reply:<thread id>',
+// 'edit_thread:<thread id>' or 'edit_comment:<comment id>'. Contexts are lowercase.
+func IsValidDraftContext(context string) bool {
+	return draftContextPattern.MatchString(context)
+}
+
+// ReplyDraftContext Returns the context of the draft of a new comment on a thread.
+func ReplyDraftContext(threadId string) string {
+	return "reply:" + strings.ToLower(threadId)
+}
+
+// EditThreadDraftContext Returns the context of the draft of an edit of a thread.
+func EditThreadDraftContext(threadId string) string {
+	return "edit_thread:" + strings.ToLower(threadId)
+}
+
+// EditCommentDraftContext Returns the context of the draft of an edit of a comment.
+func EditCommentDraftContext(commentId string) string {
+	return "edit_comment:" + strings.ToLower(commentId)
+}
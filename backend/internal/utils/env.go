@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+)
+
+// GetEnvInt Returns the value of the environment variable as an integer.
+// Returns defaultValue if the variable is not set or is not a valid integer.
+func GetEnvInt(name string, defaultValue int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsedValue, err := strconv.Atoi(value)
+	if err != nil {
+		Log("main", "Invalid value for "+name+", using "+strconv.Itoa(defaultValue), err)
+		return defaultValue
+	}
+
+	return parsedValue
+}
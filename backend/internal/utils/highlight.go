@@ -0,0 +1,338 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"html"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Languages of code blocks that are highlighted, by name or alias. '*' highlights all known languages.
+var HIGHLIGHT_LANGUAGES = []string{"bash", "c", "c++", "c#", "css", "go", "haskell", "html", "java", "javascript",
+	"json", "kotlin", "php", "python", "ruby", "rust", "sql", "typescript", "yaml"}
+
+// Minimum score of the language detected from a code block without a language, see languageSignatures.
+const minDetectionScore = 3
+
+// signature A pattern that is typical of a language, and how strongly it suggests the language.
+type signature struct {
+	pattern *regexp.Regexp
+	weight  int
+}
+
+// Patterns typical of the default HIGHLIGHT_LANGUAGES, used to detect the language of code blocks without a
+// language when the lexers cannot tell it from the code themselves.
+var languageSignatures = map[string][]signature{
+	"bash": {
+		{regexp.MustCompile(`^#!.*\b(ba|z)?sh\b`), 5},
+		{regexp.MustCompile(`(?m)^\s*(if|while)\s+\[`), 2},
+		{regexp.MustCompile(`(?m);\s*(then|do)\s*$`), 2},
+		{regexp.MustCompile(`(?m)^\s*(fi|done|esac)\s*$`), 2},
+		{regexp.MustCompile(`(?m)^\s*echo\s`), 1},
+	},
+	"c": {
+		{regexp.MustCompile(`(?m)^\s*#include\s*<\w+\.h>`), 3},
+		{regexp.MustCompile(`\bint\s+main\s*\(`), 2},
+		{regexp.MustCompile(`\b(printf|scanf|malloc|free)\(`), 1},
+	},
+	"c++": {
+		{regexp.MustCompile(`(?m)^\s*#include\s*<\w+>`), 3},
+		{regexp.MustCompile(`\bstd::`), 3},
+		{regexp.MustCompile(`\b(cout|cin)\s*(<<|>>)`), 2},
+		{regexp.MustCompile(`\btemplate\s*<`), 2},
+	},
+	"c#": {
+		{regexp.MustCompile(`(?m)^\s*using\s+System(\.\w+)*;`), 3},
+		{regexp.MustCompile(`\bConsole\.(Write|WriteLine|ReadLine)\(`), 3},
+		{regexp.MustCompile(`\bstatic\s+(void|int|async\s+Task)\s+Main\(`), 2},
+		{regexp.MustCompile(`\{\s*get;\s*(private\s+)?set;\s*\}`), 3},
+	},
+	"css": {
+		{regexp.MustCompile(`(?m)^\s*[a-z-]+\s*:\s*[^;{}]+;\s*$`), 2},
+		{regexp.MustCompile(`(?m)^\s*[.#]?[\w-]+(\s*[,>+~]?\s*[.#:]?[\w-]+)*\s*\{\s*$`), 1},
+		{regexp.MustCompile(`@media\b`), 3},
+		{regexp.MustCompile(`\b\d+(px|em|rem|vh|vw)\b`), 2},
+	},
+	"go": {
+		{regexp.MustCompile(`(?m)^package\s+\w+\s*$`), 3},
+		{regexp.MustCompile(`\bfunc\s+(\(\w+\s+\*?\w+\)\s*)?\w+\(`), 2},
+		{regexp.MustCompile(`\bfmt\.\w+\(`), 2},
+		{regexp.MustCompile(`:=`), 1},
+		{regexp.MustCompile(`(?m)^import\s+\(`), 2},
+	},
+	"haskell": {
+		{regexp.MustCompile(`(?m)^\w+\s*::\s*\S`), 3},
+		{regexp.MustCompile(`(?m)^import\s+(qualified\s+)?[A-Z][\w.]*`), 2},
+		{regexp.MustCompile(`\b(putStrLn|mapM_)\b`), 2},
+		{regexp.MustCompile(`\s<-\s`), 1},
+	},
+	"html": {
+		{regexp.MustCompile(`(?i)<!DOCTYPE\s+html`), 5},
+		{regexp.MustCompile(`(?i)<(html|head|body|div|span|p|a|ul|li|table)\b[^>]*>`), 2},
+		{regexp.MustCompile(`</\w+>`), 1},
+	},
+	"java": {
+		{regexp.MustCompile(`\bpublic\s+(abstract\s+|final\s+)?(class|interface|enum)\s+\w+`), 2},
+		{regexp.MustCompile(`\bpublic\s+static\s+void\s+main\s*\(\s*String`), 3},
+		{regexp.MustCompile(`\bSystem\.(out|err)\.print`), 3},
+		{regexp.MustCompile(`(?m)^\s*import\s+java\.`), 3},
+		{regexp.MustCompile(`@Override\b`), 2},
+	},
+	"javascript": {
+		{regexp.MustCompile(`\bconsole\.(log|error|warn)\(`), 2},
+		{regexp.MustCompile(`\bfunction\s*\w*\s*\(`), 1},
+		{regexp.MustCompile(`\b(const|let|var)\s+\w+\s*=`), 1},
+		{regexp.MustCompile(`=>`), 1},
+		{regexp.MustCompile(`\brequire\(['"]`), 2},
+		{regexp.MustCompile(`\bdocument\.\w+`), 2},
+		{regexp.MustCompile(`===|!==`), 1},
+	},
+	"kotlin": {
+		{regexp.MustCompile(`\bfun\s+\w+\s*\(`), 2},
+		{regexp.MustCompile(`\bval\s+\w+\s*[=:]`), 1},
+		{regexp.MustCompile(`\bprintln\(`), 1},
+		{regexp.MustCompile(`\bdata\s+class\b`), 3},
+		{regexp.MustCompile(`\$\{?\w+\}?"`), 1},
+	},
+	"php": {
+		{regexp.MustCompile(`<\?php`), 5},
+		{regexp.MustCompile(`\$_(GET|POST|REQUEST|SERVER|SESSION|COOKIE)\b`), 3},
+		{regexp.MustCompile(`(?m)^\s*\$\w+\s*=`), 1},
+		{regexp.MustCompile(`\becho\s`), 1},
+	},
+	"python": {
+		{regexp.MustCompile(`^#!.*\bpython`), 5},
+		{regexp.MustCompile(`(?m)^\s*def\s+\w+\s*\(.*\)\s*(->\s*[^:]+)?:\s*$`), 3},
+		{regexp.MustCompile(`(?m)^\s*(from\s+[\w.]+\s+)?import\s+[\w.]+(\s+as\s+\w+)?\s*$`), 1},
+		{regexp.MustCompile(`__name__\s*==\s*['"]__main__['"]`), 3},
+		{regexp.MustCompile(`(?m)^\s*(elif|except|class)\b.*:\s*$`), 2},
+		{regexp.MustCompile(`\bprint\(`), 1},
+		{regexp.MustCompile(`\bself\.`), 1},
+	},
+	"ruby": {
+		{regexp.MustCompile(`(?m)^\s*def\s+\w+[?!]?(\(.*\))?\s*$`), 2},
+		{regexp.MustCompile(`(?m)^\s*end\s*$`), 2},
+		{regexp.MustCompile(`(?m)^\s*(puts|require)\s`), 2},
+		{regexp.MustCompile(`\.each\s+do\s*\|`), 3},
+		{regexp.MustCompile(`#\{`), 1},
+	},
+	"rust": {
+		{regexp.MustCompile(`\bfn\s+\w+\s*(<[^>]*>)?\(`), 2},
+		{regexp.MustCompile(`\blet\s+(mut\s+)?\w+`), 1},
+		{regexp.MustCompile(`\b(println|print|format|vec)!\(`), 3},
+		{regexp.MustCompile(`(?m)^\s*(impl|use\s+std::|pub\s+fn)\b`), 2},
+		{regexp.MustCompile(`&mut\s`), 2},
+	},
+	"sql": {
+		{regexp.MustCompile(`(?im)^\s*select\b.*\bfrom\b`), 2},
+		{regexp.MustCompile(`\b(SELECT|FROM|WHERE|ORDER BY|GROUP BY|JOIN)\b`), 1},
+		{regexp.MustCompile(`(?i)\b(insert\s+into|create\s+table|delete\s+from|update\s+\w+\s+set)\b`), 3},
+		{regexp.MustCompile(`(?i)\bwhere\b`), 1},
+		{regexp.MustCompile(`(?m);\s*$`), 1},
+	},
+	"typescript": {
+		{regexp.MustCompile(`\b(interface|type)\s+\w+\s*(=\s*)?\{`), 2},
+		{regexp.MustCompile(`\w\??:\s*(string|number|boolean|any|void|unknown)\b`), 2},
+		{regexp.MustCompile(`\b(const|let)\s+\w+\s*:\s*\w+`), 2},
+		{regexp.MustCompile(`\bexport\s+(type|interface)\b`), 2},
+	},
+	"yaml": {
+		{regexp.MustCompile(`^---\s*$`), 2},
+		{regexp.MustCompile(`(?m)^[\w-]+:\s*$`), 1},
+		{regexp.MustCompile(`(?m)^[\w-]+:\s+[^\s{;]`), 1},
+		{regexp.MustCompile(`(?m)^\s*-\s+\w`), 1},
+	},
+}
+
+// CodeBlockOptions Options of a fenced code block, set in its info string after the language,
+// e.g. ```go {linenos=true hl_lines=[2,"4-5"] linenostart=10}
+type CodeBlockOptions struct {
+	LineNumbers    bool
+	LineNumberBase int
+	HighlightLines [][2]int
+}
+
+// InitHighlightPolicy Initializes the languages of code blocks that are highlighted.
+func InitHighlightPolicy() {
+	if languages, isSet := os.LookupEnv("HIGHLIGHT_LANGUAGES"); isSet {
+		HIGHLIGHT_LANGUAGES = []string{}
+		for _, language := range strings.Split(languages, ",") {
+			language = strings.ToLower(strings.TrimSpace(language))
+			if len(language) > 0 {
+				HIGHLIGHT_LANGUAGES = append(HIGHLIGHT_LANGUAGES, language)
+			}
+		}
+	}
+
+	Log("main", "Code blocks highlighted for: ["+strings.Join(HIGHLIGHT_LANGUAGES, ", ")+"]", nil)
+}
+
+// HighlightCode Returns the HTML of a code block. The code is highlighted if its language, or the language detected
+// from the code if none is given, is one of HIGHLIGHT_LANGUAGES. Otherwise, the code is returned as plain text.
+func HighlightCode(code string, language string, options CodeBlockOptions) string {
+	var lexer chroma.Lexer
+	if len(language) > 0 {
+		lexer = lexers.Get(language)
+	} else {
+		lexer = detectLanguage(code)
+	}
+
+	if lexer == nil || !isHighlightedLanguage(lexer) {
+		return plainCodeBlock(code, language)
+	}
+
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		Log("HighlightCode", "Unable to tokenise code", err)
+		return plainCodeBlock(code, language)
+	}
+
+	// CSS classes are used instead of inline styles, so that themes are stylesheets
+	formatter := chromahtml.New(
+		chromahtml.WithClasses(true),
+		chromahtml.WithLineNumbers(options.LineNumbers),
+		chromahtml.BaseLineNumber(max(options.LineNumberBase, 1)),
+		chromahtml.HighlightLines(options.HighlightLines),
+	)
+
+	var output bytes.Buffer
+	err = formatter.Format(&output, styles.Fallback, iterator)
+	if err != nil {
+		Log("HighlightCode", "Unable to format code", err)
+		return plainCodeBlock(code, language)
+	}
+
+	return output.String() + "\n"
+}
+
+// detectLanguage Returns the lexer of the language of a code block without a language, or nil if it cannot be
+// told. The lexers are asked first, but only a few of them can recognise their language, so the language signatures
+// of HIGHLIGHT_LANGUAGES are scored if they cannot. The language with the highest score wins, as long as the score is
+// at least minDetectionScore and no other language has the same score.
+func detectLanguage(code string) chroma.Lexer {
+	lexer := lexers.Analyse(code)
+	if lexer != nil && isHighlightedLanguage(lexer) {
+		return lexer
+	}
+
+	// JSON has no typical patterns, but can be told by whether it is valid
+	trimmed := strings.TrimSpace(code)
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+		lexer = lexers.Get("json")
+		if lexer != nil && isHighlightedLanguage(lexer) {
+			return lexer
+		}
+	}
+
+	var bestLexer chroma.Lexer
+	bestScore := 0
+	isTied := false
+	for language, signatures := range languageSignatures {
+		lexer := lexers.Get(language)
+		if lexer == nil || !isHighlightedLanguage(lexer) {
+			continue
+		}
+
+		score := 0
+		for _, signature := range signatures {
+			if signature.pattern.MatchString(code) {
+				score += signature.weight
+			}
+		}
+
+		if score > bestScore {
+			bestLexer, bestScore, isTied = lexer, score, false
+		} else if score == bestScore {
+			isTied = true
+		}
+	}
+
+	if bestScore < minDetectionScore || isTied {
+		return nil
+	}
+	return bestLexer
+}
+
+// HighlightStylesheet Returns the stylesheet of a theme for highlighted code, or false if there is no such theme.
+func HighlightStylesheet(theme string) (string, bool) {
+	style, isFound := styles.Registry[strings.ToLower(theme)]
+	if !isFound {
+		return "", false
+	}
+
+	var output bytes.Buffer
+	err := chromahtml.New(chromahtml.WithClasses(true)).WriteCSS(&output, style)
+	if err != nil {
+		Log("HighlightStylesheet", "Unable to write stylesheet of theme "+theme, err)
+		return "", false
+	}
+
+	return output.String(), true
+}
+
+// ParseHighlightLines Parses the lines to highlight from numbers, "a-b" ranges, and lists of these.
+// Lines are numbered as they are shown, starting from linenostart. Invalid values are ignored.
+func ParseHighlightLines(value interface{}) [][2]int {
+	var ranges [][2]int
+
+	switch value := value.(type) {
+	case float64:
+		ranges = append(ranges, [2]int{int(value), int(value)})
+	case []byte:
+		for _, field := range strings.Fields(strings.ReplaceAll(string(value), ",", " ")) {
+			start, end, isRange := strings.Cut(field, "-")
+			startLine, err := strconv.Atoi(start)
+			if err != nil {
+				continue
+			}
+			endLine := startLine
+			if isRange {
+				endLine, err = strconv.Atoi(end)
+				if err != nil {
+					continue
+				}
+			}
+			ranges = append(ranges, [2]int{startLine, endLine})
+		}
+	case []interface{}:
+		for _, item := range value {
+			ranges = append(ranges, ParseHighlightLines(item)...)
+		}
+	}
+
+	return ranges
+}
+
+// isHighlightedLanguage Returns true if the name or an alias of the language is one of HIGHLIGHT_LANGUAGES.
+func isHighlightedLanguage(lexer chroma.Lexer) bool {
+	config := lexer.Config()
+	if slices.Contains(HIGHLIGHT_LANGUAGES, "*") ||
+		slices.Contains(HIGHLIGHT_LANGUAGES, strings.ToLower(config.Name)) {
+		return true
+	}
+
+	for _, alias := range config.Aliases {
+		if slices.Contains(HIGHLIGHT_LANGUAGES, strings.ToLower(alias)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// plainCodeBlock Returns the HTML of a code block that is not highlighted.
+func plainCodeBlock(code string, language string) string {
+	if len(language) > 0 {
+		return `<pre><code class="language-` + html.EscapeString(language) + `">` + html.EscapeString(code) +
+			"</code></pre>\n"
+	}
+	return "<pre><code>" + html.EscapeString(code) + "</code></pre>\n"
+}
@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		language string
+	}{
+		{
+			name: "Python with shebang",
+			code: "#!/usr/bin/env python3\nimport sys\n\ndef main(args):\n    for arg in args:\n        print(arg)\n\n" +
+				"if __name__ == '__main__':\n    main(sys.argv)\n",
+			language: "Python",
+		},
+		{
+			name:     "Python function",
+			code:     "def add(a, b):\n    return a + b\n",
+			language: "Python",
+		},
+		{
+			name: "Java",
+			code: "public class Main {\n    public static void main(String[] args) {\n" +
+				"        System.out.println(\"Hello\");\n    }\n}\n",
+			language: "Java",
+		},
+		{
+			name:     "PHP",
+			code:     "<?php\n$name = $_GET['name'];\necho \"Hello \" . $name;\n",
+			language: "PHP",
+		},
+		{
+			name:     "Go",
+			code:     "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n",
+			language: "Go",
+		},
+		{
+			name:     "JavaScript",
+			code:     "const xs = [1, 2, 3];\nfunction double(x) {\n  return x * 2;\n}\nconsole.log(xs.map(double));\n",
+			language: "JavaScript",
+		},
+		{
+			name:     "TypeScript",
+			code:     "interface User {\n  name: string;\n}\nconst user: User = { name: \"a\" };\n",
+			language: "TypeScript",
+		},
+		{
+			name:     "C",
+			code:     "#include <stdio.h>\n\nint main(void) {\n    printf(\"hello\\n\");\n    return 0;\n}\n",
+			language: "C",
+		},
+		{
+			name:     "C++",
+			code:     "#include <iostream>\nint main() {\n    std::cout << \"hi\" << std::endl;\n}\n",
+			language: "C++",
+		},
+		{
+			name: "C#",
+			code: "using System;\n\nclass Program {\n    static void Main() {\n" +
+				"        Console.WriteLine(\"Hi\");\n    }\n}\n",
+			language: "C#",
+		},
+		{
+			name:     "Rust",
+			code:     "fn main() {\n    let mut v = Vec::new();\n    v.push(1);\n    println!(\"{:?}\", v);\n}\n",
+			language: "Rust",
+		},
+		{
+			name:     "Kotlin",
+			code:     "fun main() {\n    val name = \"Kotlin\"\n    println(\"Hello, $name\")\n}\n",
+			language: "Kotlin",
+		},
+		{
+			name: "Ruby",
+			code: "class Greeter\n  def initialize(name)\n    @name = name\n  end\n\n  def greet\n" +
+				"    puts \"Hello #{@name}\"\n  end\nend\n",
+			language: "Ruby",
+		},
+		{
+			name:     "Bash",
+			code:     "#!/bin/bash\nfor f in *.txt; do\n  echo \"$f\"\ndone\n",
+			language: "Bash",
+		},
+		{
+			name:     "SQL",
+			code:     "SELECT id, name FROM users WHERE age > 18 ORDER BY name;\n",
+			language: "SQL",
+		},
+		{
+			name:     "HTML",
+			code:     "<!DOCTYPE html>\n<html><body><p>Hi</p></body></html>\n",
+			language: "HTML",
+		},
+		{
+			name:     "CSS",
+			code:     "body {\n  color: red;\n  margin: 0 auto;\n}\n",
+			language: "CSS",
+		},
+		{
+			name:     "JSON",
+			code:     "{\"a\": 1, \"b\": [true, null]}\n",
+			language: "JSON",
+		},
+		{
+			name:     "YAML",
+			code:     "name: test\nitems:\n  - one\n  - two\n",
+			language: "YAML",
+		},
+		{
+			name:     "Haskell",
+			code:     "main :: IO ()\nmain = putStrLn \"hello\"\n",
+			language: "Haskell",
+		},
+		{
+			name:     "plain text",
+			code:     "This is just some plain text that I wrote\nto describe my problem with the homework.\n",
+			language: "",
+		},
+		{
+			name:     "English that mentions code",
+			code:     "Select the file from the menu, then run it again.\n",
+			language: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			language := ""
+			if lexer := detectLanguage(test.code); lexer != nil {
+				language = lexer.Config().Name
+			}
+			if language != test.language {
+				t.Errorf("detectLanguage() = %q, want %q", language, test.language)
+			}
+		})
+	}
+}
+
+func TestDetectLanguageOnlyDetectsHighlightedLanguages(t *testing.T) {
+	highlightLanguages := HIGHLIGHT_LANGUAGES
+	defer func() { HIGHLIGHT_LANGUAGES = highlightLanguages }()
+
+	HIGHLIGHT_LANGUAGES = []string{"go"}
+	if lexer := detectLanguage("def add(a, b):\n    return a + b\n"); lexer != nil {
+		t.Errorf("detectLanguage() = %q, want nil", lexer.Config().Name)
+	}
+}
+
+func TestHighlightCodeWithoutLanguage(t *testing.T) {
+	output := HighlightCode("def add(a, b):\n    return a + b\n", "", CodeBlockOptions{})
+	if !strings.Contains(output, `class="chroma"`) || !strings.Contains(output, `<span class="k">def</span>`) {
+		t.Errorf("HighlightCode() = %q, want highlighted Python", output)
+	}
+
+	output = HighlightCode("Just some text", "", CodeBlockOptions{})
+	if output != "<pre><code>Just some text</code></pre>\n" {
+		t.Errorf("HighlightCode() = %q, want plain code block", output)
+	}
+}
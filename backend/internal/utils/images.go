@@ -0,0 +1,287 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+// Maximum width and height of thumbnails.
+const thumbnailSize = 320
+
+// Maximum number of pixels of an uploaded image, so that a small file cannot decode into a huge image.
+const maxImagePixels = 40_000_000
+
+// Quality of re-encoded JPEG images and of JPEG thumbnails.
+const (
+	jpegQuality          = 90
+	jpegThumbnailQuality = 80
+)
+
+// ErrImageTooLarge Returned by ProcessImage when the image has too many pixels.
+var ErrImageTooLarge = errors.New("image too large")
+
+// ProcessedImage An uploaded image ready to be stored. Thumbnail is nil if the image is small enough to be shown as
+// its own thumbnail.
+type ProcessedImage struct {
+	Data          []byte
+	Width         int
+	Height        int
+	Thumbnail     []byte
+	ThumbnailType string
+}
+
+// ProcessImage Strips the metadata of an uploaded image, such as its EXIF data, and makes its thumbnail.
+// JPEG and PNG images are decoded and encoded again, which drops everything but the pixels. JPEG images are rotated
+// according to their EXIF orientation first, as browsers would. GIF images cannot hold EXIF data and are kept as
+// uploaded so that animations are not lost.
+func ProcessImage(data []byte, contentType string) (ProcessedImage, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return ProcessedImage{}, err
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return ProcessedImage{}, ErrImageTooLarge
+	}
+
+	var img image.Image
+	var buffer bytes.Buffer
+
+	switch contentType {
+	case "image/jpeg":
+		img, err = jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return ProcessedImage{}, err
+		}
+		img = orientImage(img, jpegOrientation(data))
+		err = jpeg.Encode(&buffer, img, &jpeg.Options{Quality: jpegQuality})
+	case "image/png":
+		img, err = png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return ProcessedImage{}, err
+		}
+		err = png.Encode(&buffer, img)
+	case "image/gif":
+		img, err = gif.Decode(bytes.NewReader(data))
+		if err != nil {
+			return ProcessedImage{}, err
+		}
+		_, err = buffer.Write(data)
+	default:
+		return ProcessedImage{}, errors.New("unsupported image type: " + contentType)
+	}
+
+	if err != nil {
+		return ProcessedImage{}, err
+	}
+
+	bounds := img.Bounds()
+	processed := ProcessedImage{
+		Data:   buffer.Bytes(),
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+	}
+
+	if processed.Width <= thumbnailSize && processed.Height <= thumbnailSize {
+		return processed, nil
+	}
+
+	// Thumbnails of JPEG images stay JPEG, others are PNG to keep their transparency
+	thumbnail := scaleImage(img, thumbnailSize)
+	var thumbnailBuffer bytes.Buffer
+	if contentType == "image/jpeg" {
+		processed.ThumbnailType = "image/jpeg"
+		err = jpeg.Encode(&thumbnailBuffer, thumbnail, &jpeg.Options{Quality: jpegThumbnailQuality})
+	} else {
+		processed.ThumbnailType = "image/png"
+		err = png.Encode(&thumbnailBuffer, thumbnail)
+	}
+	if err != nil {
+		return ProcessedImage{}, err
+	}
+	processed.Thumbnail = thumbnailBuffer.Bytes()
+
+	return processed, nil
+}
+
+// toRGBA Returns a copy of the image as an *image.RGBA with its origin at (0, 0), so its pixels can be read directly.
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// scaleImage Shrinks the image to fit within size by size pixels, keeping its aspect ratio. Each pixel of the result
+// is the average of the pixels it covers in the image.
+func scaleImage(img image.Image, size int) *image.RGBA {
+	src := toRGBA(img)
+	width, height := src.Rect.Dx(), src.Rect.Dy()
+
+	scaledWidth, scaledHeight := size, size
+	if width > height {
+		scaledHeight = max(height*size/width, 1)
+	} else {
+		scaledWidth = max(width*size/height, 1)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, scaledWidth, scaledHeight))
+	for y := 0; y < scaledHeight; y++ {
+		y0, y1 := y*height/scaledHeight, max((y+1)*height/scaledHeight, y*height/scaledHeight+1)
+		for x := 0; x < scaledWidth; x++ {
+			x0, x1 := x*width/scaledWidth, max((x+1)*width/scaledWidth, x*width/scaledWidth+1)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				offset := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += int(src.Pix[offset+c])
+					}
+					offset += 4
+				}
+			}
+
+			count := (y1 - y0) * (x1 - x0)
+			offset := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[offset+c] = uint8(sum[c] / count)
+			}
+		}
+	}
+
+	return dst
+}
+
+// orientImage Rotates and flips the image so that it is upright, according to its EXIF orientation from 1 to 8.
+// See https://www.exif.org/Exif2-2.PDF, page 18.
+func orientImage(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	src := toRGBA(img)
+	width, height := src.Rect.Dx(), src.Rect.Dy()
+
+	// Orientations 5 to 8 swap the width and height
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = width-1-x, y
+			case 3:
+				sx, sy = width-1-x, height-1-y
+			case 4:
+				sx, sy = x, height-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, height-1-x
+			case 7:
+				sx, sy = width-1-y, height-1-x
+			case 8:
+				sx, sy = width-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+
+	return dst
+}
+
+// jpegOrientation Returns the EXIF orientation of a JPEG image, or 1 (upright) if it has none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	// Walk the segments of the image until the APP1 segment holding the EXIF data
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return 1
+		}
+
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF:
+			// Fill byte before a marker
+			i++
+			continue
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD8):
+			// Markers without a segment
+			i += 2
+			continue
+		case marker == 0xD9 || marker == 0xDA:
+			// The image data starts, EXIF data always comes before it
+			return 1
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+
+		i += 2 + length
+	}
+
+	return 1
+}
+
+// exifOrientation Returns the orientation tag of the first image file directory of EXIF data, or 1 if it has none.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[0:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	directory := int64(order.Uint32(tiff[4:8]))
+	if directory+2 > int64(len(tiff)) {
+		return 1
+	}
+
+	numEntries := int64(order.Uint16(tiff[directory:]))
+	for n := int64(0); n < numEntries; n++ {
+		entry := directory + 2 + n*12
+		if entry+12 > int64(len(tiff)) {
+			return 1
+		}
+
+		// Tag 0x0112 is the orientation, a short value stored in the entry itself
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+
+	return 1
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"errors"
 	"github.com/golang-jwt/jwt/v5"
+	"net/http"
 	"os"
 	"time"
 )
@@ -62,3 +63,19 @@ func VerifyJWT(tokenString string) (string, error) {
 
 	return "", err
 }
+
+// GetRequestUsername Returns the username in the JWT token of a request, or an empty string if the request does not
+// have a valid token. Used by endpoints that do not require logging in, but return more details to logged in users.
+func GetRequestUsername(r *http.Request) string {
+	authorization := r.Header.Get("Authorization")
+	if len(authorization) <= 7 {
+		return ""
+	}
+
+	username, err := VerifyJWT(authorization[7:])
+	if err != nil {
+		return ""
+	}
+
+	return username
+}
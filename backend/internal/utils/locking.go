@@ -0,0 +1,24 @@
+package utils
+
+import "strconv"
+
+// Number of days without new comments after which a thread is locked automatically. 0 disables auto-locking.
+var AUTO_LOCK_DAYS = 0
+
+// InitLockPolicy Initializes the period of inactivity after which threads are locked automatically.
+func InitLockPolicy() {
+	AUTO_LOCK_DAYS = max(GetEnvInt("AUTO_LOCK_DAYS", AUTO_LOCK_DAYS), 0)
+
+	if AUTO_LOCK_DAYS > 0 {
+		Log("main", "Threads are locked after "+strconv.Itoa(AUTO_LOCK_DAYS)+" days of inactivity", nil)
+	}
+}
+
+// CanChangeThreadLock Returns true if a user can lock or unlock a thread.
+// Moderators can always change the lock, while the creator of the thread cannot override a lock set by someone else.
+func CanChangeThreadLock(username string, role string, creator string, lockedBy string) bool {
+	if IsModerator(role) {
+		return true
+	}
+	return username == creator && (lockedBy == "" || lockedBy == creator)
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// SendMail Sends a plain text email using the SMTP server given by the SMTP_* environment variables.
+// If no SMTP server is configured, the email is logged instead so that it can be delivered manually.
+func SendMail(to string, subject string, body string) error {
+	host := os.Getenv("SMTP_HOST")
+	port := os.Getenv("SMTP_PORT")
+	username := os.Getenv("SMTP_USERNAME")
+	password := os.Getenv("SMTP_PASSWORD")
+	from := os.Getenv("SMTP_FROM")
+
+	if host == "" {
+		Log("mail", "No SMTP server configured, email to "+to+" not sent: "+subject+"\n"+body, nil)
+		return nil
+	}
+
+	if port == "" {
+		port = "587"
+	}
+
+	if from == "" {
+		from = username
+	}
+
+	var auth smtp.Auth = nil
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	message := strings.Join([]string{
+		"From: " + from,
+		"To: " + to,
+		"Subject: " + subject,
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	err := smtp.SendMail(host+":"+port, auth, from, []string{to}, []byte(message))
+	if err != nil {
+		Log("mail", "Unable to send email to "+to, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"bytes"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// MarkdownVersion Version of the rendering pipeline used for the HTML of bodies. Bump it whenever the rendered HTML
+// changes, so that cached HTML rendered by an older version is rendered again.
+const MarkdownVersion = 5
+
+// Renders CommonMark with highlighted code blocks and mentions of users. Raw HTML in bodies is left out and links
+// with dangerous URLs are not rendered.
+var markdown = goldmark.New(
+	goldmark.WithParserOptions(
+		parser.WithInlineParsers(util.Prioritized(&mentionParser{}, 500)),
+	),
+	goldmark.WithRendererOptions(
+		renderer.WithNodeRenderers(
+			util.Prioritized(&codeBlockRenderer{}, 100),
+			util.Prioritized(&mentionRenderer{}, 100),
+		),
+	),
+)
+
+// RenderMarkdown Renders a thread or comment body as CommonMark and returns the sanitised HTML. Only mentions of the
+// given users, which map the canonical username mentioned to the current username, are rendered as links.
+func RenderMarkdown(body string, mentionedUsers map[string]string) string {
+	context := parser.NewContext()
+	context.Set(mentionedUsersKey, mentionedUsers)
+
+	var output bytes.Buffer
+	err := markdown.Convert([]byte(body), &output, parser.WithContext(context))
+	if err != nil {
+		Log("RenderMarkdown", "Unable to render markdown", err)
+		return ""
+	}
+
+	return SanitizeHtml(output.String())
+}
+
+// codeBlockRenderer Renders fenced code blocks with syntax highlighting.
+type codeBlockRenderer struct{}
+
+func (r *codeBlockRenderer) RegisterFuncs(registerer renderer.NodeRendererFuncRegisterer) {
+	registerer.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+func (r *codeBlockRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node,
+	entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	codeBlock := node.(*ast.FencedCodeBlock)
+
+	var code bytes.Buffer
+	lines := codeBlock.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		code.Write(line.Value(source))
+	}
+
+	language := string(codeBlock.Language(source))
+
+	// Options are set as attributes after the language, e.g. ```go {linenos=true hl_lines=[2,"4-5"]}
+	var options CodeBlockOptions
+	if codeBlock.Info != nil {
+		info := codeBlock.Info.Segment.Value(source)
+		if start := bytes.IndexByte(info, '{'); start >= 0 {
+			attributes, _ := parser.ParseAttributes(text.NewReader(info[start:]))
+			for _, attribute := range attributes {
+				switch string(attribute.Name) {
+				case "linenos":
+					switch value := attribute.Value.(type) {
+					case bool:
+						options.LineNumbers = value
+					case []byte:
+						options.LineNumbers = string(value) != "false"
+					}
+				case "linenostart":
+					if value, isNumber := attribute.Value.(float64); isNumber {
+						options.LineNumberBase = int(value)
+					}
+				case "hl_lines":
+					options.HighlightLines = ParseHighlightLines(attribute.Value)
+				}
+			}
+		}
+	}
+
+	_, err := w.WriteString(HighlightCode(code.String(), language, options))
+	if err != nil {
+		return ast.WalkStop, err
+	}
+
+	return ast.WalkSkipChildren, nil
+}
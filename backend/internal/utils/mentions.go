@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxMentions Maximum number of users mentioned in a thread or comment. Further mentions are still rendered as
+// links, but the users are not notified.
+const MaxMentions = 20
+
+// Characters a mention may contain after the @, which are those allowed in usernames by default.
+var mentionPattern = regexp.MustCompile(`^@([\p{L}\p{M}\p{N}_.-]+)`)
+
+// KindMention The kind of mention nodes in the Markdown syntax tree.
+var KindMention = ast.NewNodeKind("Mention")
+
+// Key of the current usernames of the mentioned users by canonical username in the context of the parser, set by
+// RenderMarkdown.
+var mentionedUsersKey = parser.NewContextKey()
+
+// mentionNode A mention of a user, such as @username. CurrentUsername is the username the mentioned user has now,
+// which differs from Username if the user has been renamed, and is empty if the user does not exist.
+type mentionNode struct {
+	ast.BaseInline
+	Username        string
+	CurrentUsername string
+}
+
+func (n *mentionNode) Kind() ast.NodeKind {
+	return KindMention
+}
+
+func (n *mentionNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Username": n.Username}, nil)
+}
+
+// mentionParser Parses mentions of users. A mention is an @ followed by a username, which does not follow a letter,
+// digit or @ so that email addresses are not mentions. Periods at the end of a mention are left out, as they are
+// more likely to end a sentence than a username.
+type mentionParser struct{}
+
+func (p *mentionParser) Trigger() []byte {
+	return []byte{'@'}
+}
+
+func (p *mentionParser) Parse(_ ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	preceding := block.PrecendingCharacter()
+	if unicode.IsLetter(preceding) || unicode.IsDigit(preceding) || preceding == '_' || preceding == '@' {
+		return nil
+	}
+
+	line, _ := block.PeekLine()
+	match := mentionPattern.FindSubmatch(line)
+	if match == nil {
+		return nil
+	}
+
+	username := strings.TrimRight(string(match[1]), ".")
+	if len(username) == 0 || utf8.RuneCountInString(username) > 30 {
+		return nil
+	}
+
+	block.Advance(len(username) + 1)
+
+	mentionedUsers, _ := pc.Get(mentionedUsersKey).(map[string]string)
+	return &mentionNode{Username: username, CurrentUsername: mentionedUsers[CanonicalUsername(username)]}
+}
+
+// mentionRenderer Renders mentions of existing users as links to their profiles, and other mentions as text.
+// Mentions in the text of other links are also rendered as text, as links cannot be nested.
+type mentionRenderer struct{}
+
+func (r *mentionRenderer) RegisterFuncs(registerer renderer.NodeRendererFuncRegisterer) {
+	registerer.Register(KindMention, r.renderMention)
+}
+
+func (r *mentionRenderer) renderMention(w util.BufWriter, _ []byte, node ast.Node,
+	entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	mention := node.(*mentionNode)
+	username := mention.Username
+
+	var err error
+	if mention.CurrentUsername == "" || isInLink(node) {
+		_, err = w.WriteString("@" + html.EscapeString(username))
+	} else {
+		// Mentions of previous usernames link to the profile of the renamed user
+		_, err = w.WriteString(`<a href="/user/` + url.PathEscape(mention.CurrentUsername) + `" class="mention">@` +
+			html.EscapeString(username) + `</a>`)
+	}
+	if err != nil {
+		return ast.WalkStop, err
+	}
+
+	return ast.WalkSkipChildren, nil
+}
+
+// isInLink Returns true if the node is in the text of a link.
+func isInLink(node ast.Node) bool {
+	for parent := node.Parent(); parent != nil; parent = parent.Parent() {
+		if parent.Kind() == ast.KindLink || parent.Kind() == ast.KindAutoLink {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractMentions Returns the canonical forms of the usernames mentioned in a thread or comment body, in the order
+// they are first mentioned, up to MaxMentions. Mentions in code and in the text of links are not counted, as they
+// are not rendered as mentions.
+func ExtractMentions(body string) []string {
+	return extractMentions(body, MaxMentions)
+}
+
+// ExtractAllMentions Returns the canonical forms of all the usernames mentioned in a thread or comment body, which
+// are the users that must be looked up to render the body.
+func ExtractAllMentions(body string) []string {
+	return extractMentions(body, 0)
+}
+
+// extractMentions Returns the canonical forms of the usernames mentioned in a body, up to maxMentions if it is not 0.
+func extractMentions(body string, maxMentions int) []string {
+	source := []byte(body)
+	document := markdown.Parser().Parse(text.NewReader(source))
+
+	mentions := []string{}
+	seenMentions := map[string]bool{}
+
+	_ = ast.Walk(document, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || node.Kind() != KindMention || isInLink(node) {
+			return ast.WalkContinue, nil
+		}
+
+		mention := CanonicalUsername(node.(*mentionNode).Username)
+		if !seenMentions[mention] {
+			seenMentions[mention] = true
+			mentions = append(mentions, mention)
+		}
+
+		if len(mentions) == maxMentions {
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return mentions
+}
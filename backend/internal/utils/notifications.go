@@ -0,0 +1,30 @@
+package utils
+
+import "strconv"
+
+// Number of days read notifications are kept before they are deleted.
+var NOTIFICATION_RETENTION_DAYS = 90
+
+// Maximum number of new comments waiting for their notifications to be created.
+const notificationQueueSize = 1000
+
+// CommentNotifications IDs of new comments waiting for the notification job to notify subscribers of their thread.
+var CommentNotifications = make(chan string, notificationQueueSize)
+
+// InitNotificationPolicy Initializes how long read notifications are kept.
+func InitNotificationPolicy() {
+	NOTIFICATION_RETENTION_DAYS = max(GetEnvInt("NOTIFICATION_RETENTION_DAYS", NOTIFICATION_RETENTION_DAYS), 1)
+
+	Log("main", "Read notifications are kept for "+strconv.Itoa(NOTIFICATION_RETENTION_DAYS)+" days", nil)
+}
+
+// QueueCommentNotification Queues the subscribers of the thread of a new comment to be notified in the background.
+// Never blocks, so notifications may be dropped if the queue is full.
+func QueueCommentNotification(commentId string) {
+	select {
+	case CommentNotifications <- commentId:
+	default:
+		Log("QueueCommentNotification", "Notification queue is full, dropping notifications of comment "+commentId,
+			nil)
+	}
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"backend/internal/models"
+	"strings"
+	"time"
+)
+
+// Minimum and maximum number of options of a poll.
+const (
+	minPollOptions = 2
+	maxPollOptions = 10
+)
+
+// Maximum length of the text of a poll option.
+const maxPollOptionLength = 100
+
+// NormalizePoll Trims the options of the poll and returns true if the poll is valid: it has 2 to 10 distinct
+// options of 1 to 100 characters each, and its closing time, if any, is in the future.
+func NormalizePoll(poll *models.CreatePollRequest) bool {
+	if len(poll.Options) < minPollOptions || len(poll.Options) > maxPollOptions {
+		return false
+	}
+
+	if poll.CloseTime != nil && !poll.CloseTime.After(time.Now()) {
+		return false
+	}
+
+	seenOptions := map[string]bool{}
+	for i, option := range poll.Options {
+		trimmedOption := strings.TrimSpace(option)
+		if len(trimmedOption) == 0 || len(trimmedOption) > maxPollOptionLength ||
+			seenOptions[strings.ToLower(trimmedOption)] {
+			return false
+		}
+		seenOptions[strings.ToLower(trimmedOption)] = true
+		poll.Options[i] = trimmedOption
+	}
+
+	return true
+}
+
+// IsValidPollVote Returns true if the positions are distinct options of a poll with the given number of options,
+// and only one option is chosen if the poll is single choice.
+func IsValidPollVote(positions []int32, numOptions int32, isMultipleChoice bool) bool {
+	if len(positions) == 0 || (!isMultipleChoice && len(positions) > 1) {
+		return false
+	}
+
+	seenPositions := map[int32]bool{}
+	for _, position := range positions {
+		if position < 1 || position > numOptions || seenPositions[position] {
+			return false
+		}
+		seenPositions[position] = true
+	}
+
+	return true
+}
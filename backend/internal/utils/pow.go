@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/bits"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Purposes that a proof-of-work challenge can be issued for.
+const (
+	PowRegister = "register"
+	PowThread   = "thread"
+)
+
+// How long a challenge is valid for after it is issued.
+const powChallengeTtl = 5 * time.Minute
+
+var POW_REQUIRED_FOR = []string{PowRegister}
+var POW_BASE_DIFFICULTY = 16
+var POW_MAX_DIFFICULTY = 24
+var POW_TARGET_RATE = 10
+
+var powMutex sync.Mutex
+
+// Times at which challenges were issued in the last minute, by purpose.
+var powIssuedTimes = map[string][]time.Time{}
+
+// Nonces of solved challenges that have not expired yet, to prevent a solution from being reused.
+var powUsedNonces = map[string]time.Time{}
+
+// InitProofOfWork Initializes the proof-of-work settings. Must be called before any challenges are issued.
+func InitProofOfWork() {
+	if requiredFor, isSet := os.LookupEnv("POW_REQUIRED_FOR"); isSet {
+		POW_REQUIRED_FOR = []string{}
+		for _, purpose := range strings.Split(requiredFor, ",") {
+			purpose = strings.TrimSpace(purpose)
+			if purpose == PowRegister || purpose == PowThread {
+				POW_REQUIRED_FOR = append(POW_REQUIRED_FOR, purpose)
+			}
+		}
+	}
+
+	POW_BASE_DIFFICULTY = GetEnvInt("POW_BASE_DIFFICULTY", POW_BASE_DIFFICULTY)
+	POW_MAX_DIFFICULTY = max(GetEnvInt("POW_MAX_DIFFICULTY", POW_MAX_DIFFICULTY), POW_BASE_DIFFICULTY)
+	POW_TARGET_RATE = max(GetEnvInt("POW_TARGET_RATE", POW_TARGET_RATE), 1)
+
+	Log("main", "Proof-of-work required for: ["+strings.Join(POW_REQUIRED_FOR, ", ")+"]", nil)
+}
+
+// IsPowRequired Returns true if a proof-of-work solution is required for the given purpose.
+func IsPowRequired(purpose string) bool {
+	return slices.Contains(POW_REQUIRED_FOR, purpose)
+}
+
+// CreatePowChallenge Issues a new signed challenge for the given purpose.
+// The difficulty is the number of leading zero bits required in SHA-256(challenge + solution), and increases
+// by one bit each time the number of challenges issued in the last minute doubles past POW_TARGET_RATE.
+func CreatePowChallenge(purpose string) (string, int, time.Time, error) {
+	nonce, err := GenerateToken(16)
+	if err != nil {
+		return "", 0, time.Time{}, err
+	}
+
+	now := time.Now()
+	expiresTime := now.Add(powChallengeTtl)
+
+	powMutex.Lock()
+	recentTimes := []time.Time{}
+	for _, issuedTime := range powIssuedTimes[purpose] {
+		if now.Sub(issuedTime) < time.Minute {
+			recentTimes = append(recentTimes, issuedTime)
+		}
+	}
+	powIssuedTimes[purpose] = append(recentTimes, now)
+	powMutex.Unlock()
+
+	difficulty := min(POW_BASE_DIFFICULTY+bits.Len(uint(len(recentTimes)/POW_TARGET_RATE)), POW_MAX_DIFFICULTY)
+
+	payload := strings.Join([]string{
+		purpose,
+		strconv.Itoa(difficulty),
+		strconv.FormatInt(expiresTime.Unix(), 10),
+		nonce,
+	}, ".")
+
+	return payload + "." + signPowPayload(payload), difficulty, expiresTime, nil
+}
+
+// VerifyPowSolution Checks that the solution solves a valid, unexpired and unused challenge for the given purpose,
+// and uses up the challenge.
+func VerifyPowSolution(purpose string, challenge string, solution string) error {
+	err := CheckPowSolution(purpose, challenge, solution)
+	if err != nil {
+		return err
+	}
+
+	return UsePowChallenge(challenge)
+}
+
+// CheckPowSolution Checks that the solution solves a valid, unexpired and unused challenge for the given purpose,
+// without using up the challenge, so that the request can still be rejected for other reasons before it is used.
+func CheckPowSolution(purpose string, challenge string, solution string) error {
+	parts := strings.Split(challenge, ".")
+	if len(parts) != 5 || len(solution) == 0 || len(solution) > 64 {
+		return errors.New("malformed challenge")
+	}
+
+	payload := strings.Join(parts[:4], ".")
+	if !hmac.Equal([]byte(signPowPayload(payload)), []byte(parts[4])) {
+		return errors.New("invalid challenge signature")
+	}
+
+	if parts[0] != purpose {
+		return errors.New("challenge was issued for " + parts[0])
+	}
+
+	difficulty, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return err
+	}
+
+	expiresUnix, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return err
+	}
+
+	if time.Now().After(time.Unix(expiresUnix, 0)) {
+		return errors.New("challenge has expired")
+	}
+
+	hash := sha256.Sum256([]byte(challenge + solution))
+	if countLeadingZeroBits(hash[:]) < difficulty {
+		return errors.New("solution does not meet difficulty")
+	}
+
+	powMutex.Lock()
+	defer powMutex.Unlock()
+
+	if _, isUsed := powUsedNonces[parts[3]]; isUsed {
+		return errors.New("challenge has already been used")
+	}
+
+	return nil
+}
+
+// UsePowChallenge Uses up a challenge whose solution has been checked by CheckPowSolution, as each challenge can
+// only be used once. Returns an error if the challenge has already been used in the meantime.
+func UsePowChallenge(challenge string) error {
+	parts := strings.Split(challenge, ".")
+	if len(parts) != 5 {
+		return errors.New("malformed challenge")
+	}
+
+	expiresUnix, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return err
+	}
+
+	nonce := parts[3]
+	powMutex.Lock()
+	defer powMutex.Unlock()
+
+	now := time.Now()
+	for usedNonce, usedExpiresTime := range powUsedNonces {
+		if now.After(usedExpiresTime) {
+			delete(powUsedNonces, usedNonce)
+		}
+	}
+
+	if _, isUsed := powUsedNonces[nonce]; isUsed {
+		return errors.New("challenge has already been used")
+	}
+	powUsedNonces[nonce] = time.Unix(expiresUnix, 0)
+
+	return nil
+}
+
+// signPowPayload Signs a challenge payload with the JWT secret.
+func signPowPayload(payload string) string {
+	mac := hmac.New(sha256.New, SECRET)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// countLeadingZeroBits Counts the number of leading zero bits in the given bytes.
+func countLeadingZeroBits(hash []byte) int {
+	count := 0
+	for _, b := range hash {
+		if b != 0 {
+			return count + bits.LeadingZeros8(b)
+		}
+		count += 8
+	}
+	return count
+}
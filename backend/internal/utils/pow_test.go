@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"strconv"
+	"testing"
+)
+
+// solvePowChallenge Returns a solution of the challenge, found the same way as the frontend finds it.
+func solvePowChallenge(challenge string, difficulty int) string {
+	for solution := 0; ; solution++ {
+		hash := sha256.Sum256([]byte(challenge + strconv.Itoa(solution)))
+		if countLeadingZeroBits(hash[:]) >= difficulty {
+			return strconv.Itoa(solution)
+		}
+	}
+}
+
+func TestCheckPowSolutionDoesNotUseUpChallenge(t *testing.T) {
+	baseDifficulty := POW_BASE_DIFFICULTY
+	defer func() { POW_BASE_DIFFICULTY = baseDifficulty }()
+	POW_BASE_DIFFICULTY = 8
+
+	challenge, difficulty, _, err := CreatePowChallenge(PowRegister)
+	if err != nil {
+		t.Fatal(err)
+	}
+	solution := solvePowChallenge(challenge, difficulty)
+
+	// A request rejected for another reason can be sent again with the same solution
+	for i := 0; i < 2; i++ {
+		if err := CheckPowSolution(PowRegister, challenge, solution); err != nil {
+			t.Fatalf("CheckPowSolution: %v", err)
+		}
+	}
+
+	if err := CheckPowSolution(PowThread, challenge, solution); err == nil {
+		t.Error("CheckPowSolution with another purpose succeeded")
+	}
+
+	if err := UsePowChallenge(challenge); err != nil {
+		t.Fatalf("UsePowChallenge: %v", err)
+	}
+
+	if err := CheckPowSolution(PowRegister, challenge, solution); err == nil {
+		t.Error("CheckPowSolution after UsePowChallenge succeeded")
+	}
+	if err := UsePowChallenge(challenge); err == nil {
+		t.Error("UsePowChallenge twice succeeded")
+	}
+	if err := VerifyPowSolution(PowRegister, challenge, solution); err == nil {
+		t.Error("VerifyPowSolution after UsePowChallenge succeeded")
+	}
+}
@@ -0,0 +1,18 @@
+package utils
+
+import "strconv"
+
+// Number of minutes between refreshes of the hot and trending scores of threads.
+var RANKING_REFRESH_MINUTES = 5
+
+// Number of hours of comments and votes counted towards the trending score of a thread.
+var TRENDING_WINDOW_HOURS = 24
+
+// InitRankingPolicy Initializes how often thread rankings are refreshed and the window used for trending threads.
+func InitRankingPolicy() {
+	RANKING_REFRESH_MINUTES = max(GetEnvInt("RANKING_REFRESH_MINUTES", RANKING_REFRESH_MINUTES), 1)
+	TRENDING_WINDOW_HOURS = max(GetEnvInt("TRENDING_WINDOW_HOURS", TRENDING_WINDOW_HOURS), 1)
+
+	Log("main", "Thread rankings are refreshed every "+strconv.Itoa(RANKING_REFRESH_MINUTES)+
+		" minutes, trending over the last "+strconv.Itoa(TRENDING_WINDOW_HOURS)+" hours", nil)
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"os"
+	"slices"
+)
+
+const (
+	// RegistrationOpen Anyone can register and post immediately.
+	RegistrationOpen = "open"
+	// RegistrationEmail Anyone can register, but must verify their email before posting.
+	RegistrationEmail = "email"
+	// RegistrationInvite Only users with a valid invite code can register.
+	RegistrationInvite = "invite"
+	// RegistrationClosed Nobody can register.
+	RegistrationClosed = "closed"
+)
+
+var REGISTRATION_MODE = RegistrationOpen
+
+// InitRegistrationMode Initializes the registration mode. Must be called before any registration requests are handled.
+func InitRegistrationMode() {
+	mode := os.Getenv("REGISTRATION_MODE")
+	availableModes := []string{RegistrationOpen, RegistrationEmail, RegistrationInvite, RegistrationClosed}
+
+	if mode == "" || !slices.Contains(availableModes, mode) {
+		Log("main", "No valid registration mode provided, using '"+RegistrationOpen+"'", nil)
+		mode = RegistrationOpen
+	}
+
+	REGISTRATION_MODE = mode
+}
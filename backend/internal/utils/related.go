@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"backend/internal/models"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Number of minutes that the related threads of a thread are cached for.
+var RELATED_THREADS_CACHE_MINUTES = 60
+
+// MaxRelatedThreads Maximum number of related threads returned for a thread.
+const MaxRelatedThreads = 5
+
+// Maximum number of threads whose related threads are cached. The cache is cleared when it is full.
+const relatedThreadsCacheSize = 10000
+
+type relatedThreadsEntry struct {
+	threads     []models.RelatedThread
+	expiresTime time.Time
+}
+
+var relatedThreadsMutex sync.Mutex
+
+// Cached related threads, by thread ID.
+var relatedThreadsCache = map[string]relatedThreadsEntry{}
+
+// Incremented each time the cache is cleared, so that results computed before then are not cached.
+var relatedThreadsGeneration = 0
+
+// InitRelatedThreadsPolicy Initializes how long related threads are cached for.
+func InitRelatedThreadsPolicy() {
+	RELATED_THREADS_CACHE_MINUTES = max(GetEnvInt("RELATED_THREADS_CACHE_MINUTES", RELATED_THREADS_CACHE_MINUTES), 1)
+
+	Log("main", "Related threads are cached for "+strconv.Itoa(RELATED_THREADS_CACHE_MINUTES)+" minutes", nil)
+}
+
+// GetCachedRelatedThreads Returns the cached related threads of the thread with the given ID and whether they were
+// cached. The returned generation must be passed to CacheRelatedThreads when caching newly computed related threads.
+func GetCachedRelatedThreads(threadId string) ([]models.RelatedThread, int, bool) {
+	relatedThreadsMutex.Lock()
+	defer relatedThreadsMutex.Unlock()
+
+	entry, isCached := relatedThreadsCache[threadId]
+	if !isCached || time.Now().After(entry.expiresTime) {
+		return nil, relatedThreadsGeneration, false
+	}
+
+	return entry.threads, relatedThreadsGeneration, true
+}
+
+// CacheRelatedThreads Caches the related threads of the thread with the given ID, unless the cache has been cleared
+// since the given generation was returned by GetCachedRelatedThreads.
+func CacheRelatedThreads(threadId string, threads []models.RelatedThread, generation int) {
+	relatedThreadsMutex.Lock()
+	defer relatedThreadsMutex.Unlock()
+
+	if generation != relatedThreadsGeneration {
+		return
+	}
+
+	if len(relatedThreadsCache) >= relatedThreadsCacheSize {
+		relatedThreadsCache = map[string]relatedThreadsEntry{}
+	}
+
+	relatedThreadsCache[threadId] = relatedThreadsEntry{
+		threads:     threads,
+		expiresTime: time.Now().Add(time.Duration(RELATED_THREADS_CACHE_MINUTES) * time.Minute),
+	}
+}
+
+// InvalidateRelatedThreads Clears the cached related threads of all threads. Must be called when the tags of a thread
+// change or a thread is added or removed, as this can change the related threads of any thread sharing a tag with it.
+func InvalidateRelatedThreads() {
+	relatedThreadsMutex.Lock()
+	defer relatedThreadsMutex.Unlock()
+
+	relatedThreadsCache = map[string]relatedThreadsEntry{}
+	relatedThreadsGeneration++
+}
@@ -0,0 +1,32 @@
+package utils
+
+const (
+	// RoleUser A regular user.
+	RoleUser = "user"
+	// RoleModerator A user who can moderate threads and comments of other users.
+	RoleModerator = "moderator"
+	// RoleAdmin A user who can moderate and manage the forum.
+	RoleAdmin = "admin"
+)
+
+// IsModerator Returns true if the given role is allowed to moderate threads and comments of other users.
+func IsModerator(role string) bool {
+	return role == RoleModerator || role == RoleAdmin
+}
+
+// HasRole Returns true if the given role has at least the permissions of the required role.
+func HasRole(role string, requiredRole string) bool {
+	switch requiredRole {
+	case RoleAdmin:
+		return role == RoleAdmin
+	case RoleModerator:
+		return IsModerator(role)
+	default:
+		return true
+	}
+}
+
+// IsValidRole Returns true if the given role is one of the roles of users.
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleModerator || role == RoleAdmin
+}
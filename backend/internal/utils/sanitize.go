@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"golang.org/x/net/html"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Elements allowed in sanitised HTML, with the attributes allowed on each of them.
+// Other elements are removed but their text is kept.
+var allowedElements = map[string][]string{
+	"a":          {"href", "title", "class"},
+	"blockquote": {},
+	"br":         {},
+	"code":       {"class"},
+	"del":        {},
+	"em":         {},
+	"h1":         {},
+	"h2":         {},
+	"h3":         {},
+	"h4":         {},
+	"h5":         {},
+	"h6":         {},
+	"hr":         {},
+	"img":        {"src", "alt", "title"},
+	"li":         {},
+	"ol":         {"start"},
+	"p":          {},
+	"pre":        {"class"},
+	"span":       {"class"},
+	"strong":     {},
+	"ul":         {},
+}
+
+// Elements removed together with their text.
+var removedElements = map[string]bool{
+	"embed":    true,
+	"iframe":   true,
+	"noscript": true,
+	"object":   true,
+	"script":   true,
+	"style":    true,
+	"template": true,
+	"textarea": true,
+	"title":    true,
+}
+
+// Elements that have no closing tag.
+var voidElements = map[string]bool{
+	"br":  true,
+	"hr":  true,
+	"img": true,
+}
+
+// Schemes allowed in links and image sources. Relative URLs are allowed too.
+var allowedUrlSchemes = []string{"http", "https", "mailto"}
+
+// Classes allowed on each element. Links are marked as mentions of users, code elements with the language of the
+// code, and the other elements with the classes of highlighted code.
+var classPatterns = map[string]*regexp.Regexp{
+	"a":    regexp.MustCompile(`^mention$`),
+	"code": regexp.MustCompile(`^language-[a-zA-Z0-9_+#-]+$`),
+	"pre":  regexp.MustCompile(`^chroma$`),
+	"span": regexp.MustCompile(`^[a-z0-9]+( [a-z0-9]+)*$`),
+}
+
+var numberPattern = regexp.MustCompile(`^[0-9]{1,9}$`)
+
+// SanitizeHtml Removes all elements and attributes that are not in the allow-list from the HTML, so that it can be
+// shown to users without allowing scripts or styles to be injected. Links are marked as nofollow.
+func SanitizeHtml(input string) string {
+	tokenizer := html.NewTokenizer(strings.NewReader(input))
+	var output strings.Builder
+	var openElements []string
+	removedDepth := 0
+
+	for {
+		tokenType := tokenizer.Next()
+
+		switch tokenType {
+		case html.ErrorToken:
+			// Close the elements that are still open at the end of the input
+			for i := len(openElements) - 1; i >= 0; i-- {
+				output.WriteString("</" + openElements[i] + ">")
+			}
+			return output.String()
+
+		case html.TextToken:
+			if removedDepth == 0 {
+				output.WriteString(html.EscapeString(string(tokenizer.Text())))
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if removedElements[token.Data] {
+				if tokenType == html.StartTagToken {
+					removedDepth++
+				}
+				continue
+			}
+
+			allowedAttributes, isAllowed := allowedElements[token.Data]
+			if removedDepth > 0 || !isAllowed {
+				continue
+			}
+
+			output.WriteString("<" + token.Data)
+			for _, attribute := range token.Attr {
+				if slices.Contains(allowedAttributes, attribute.Key) && isAllowedAttributeValue(token.Data, attribute) {
+					output.WriteString(" " + attribute.Key + `="` + html.EscapeString(attribute.Val) + `"`)
+				}
+			}
+			if token.Data == "a" {
+				output.WriteString(` rel="nofollow noopener noreferrer"`)
+			}
+			output.WriteString(">")
+
+			if !voidElements[token.Data] {
+				if tokenType == html.SelfClosingTagToken {
+					output.WriteString("</" + token.Data + ">")
+				} else {
+					openElements = append(openElements, token.Data)
+				}
+			}
+
+		case html.EndTagToken:
+			token := tokenizer.Token()
+			if removedElements[token.Data] {
+				removedDepth = max(removedDepth-1, 0)
+				continue
+			}
+
+			if removedDepth > 0 {
+				continue
+			}
+
+			// Close the element and any elements left open inside it
+			for i := len(openElements) - 1; i >= 0; i-- {
+				if openElements[i] == token.Data {
+					for j := len(openElements) - 1; j >= i; j-- {
+						output.WriteString("</" + openElements[j] + ">")
+					}
+					openElements = openElements[:i]
+					break
+				}
+			}
+		}
+	}
+}
+
+// isAllowedAttributeValue Returns true if the value of an allowed attribute of the element is safe.
+func isAllowedAttributeValue(element string, attribute html.Attribute) bool {
+	switch attribute.Key {
+	case "href", "src":
+		return isAllowedUrl(attribute.Val)
+	case "class":
+		return classPatterns[element] != nil && classPatterns[element].MatchString(attribute.Val)
+	case "start":
+		return numberPattern.MatchString(attribute.Val)
+	default:
+		return true
+	}
+}
+
+// isAllowedUrl Returns true if the URL is relative or uses one of the allowed schemes.
+func isAllowedUrl(rawUrl string) bool {
+	parsedUrl, err := url.Parse(strings.TrimSpace(rawUrl))
+	if err != nil {
+		return false
+	}
+
+	return parsedUrl.Scheme == "" || slices.Contains(allowedUrlSchemes, strings.ToLower(parsedUrl.Scheme))
+}
@@ -0,0 +1,191 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeHtml(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		output string
+	}{
+		{
+			name:   "javascript link",
+			input:  `<a href="javascript:alert(1)">x</a>`,
+			output: `<a rel="nofollow noopener noreferrer">x</a>`,
+		},
+		{
+			name:   "javascript link in capitals with whitespace",
+			input:  `<a href=" JavaScript:alert(1)">x</a>`,
+			output: `<a rel="nofollow noopener noreferrer">x</a>`,
+		},
+		{
+			name:   "data link",
+			input:  `<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">x</a>`,
+			output: `<a rel="nofollow noopener noreferrer">x</a>`,
+		},
+		{
+			name:   "data image",
+			input:  `<img src="data:image/svg+xml,<svg onload=alert(1)>" alt="x">`,
+			output: `<img alt="x">`,
+		},
+		{
+			name:   "decimal entity in scheme",
+			input:  `<a href="&#106;avascript:alert(1)">x</a>`,
+			output: `<a rel="nofollow noopener noreferrer">x</a>`,
+		},
+		{
+			name:   "hexadecimal entity in scheme",
+			input:  `<a href="jav&#x61;script:alert(1)">x</a>`,
+			output: `<a rel="nofollow noopener noreferrer">x</a>`,
+		},
+		{
+			name:   "named entity for colon",
+			input:  `<a href="javascript&colon;alert(1)">x</a>`,
+			output: `<a rel="nofollow noopener noreferrer">x</a>`,
+		},
+		{
+			name:   "tab in scheme",
+			input:  `<a href="jav&#x09;ascript:alert(1)">x</a>`,
+			output: `<a rel="nofollow noopener noreferrer">x</a>`,
+		},
+		{
+			name:   "event handler attributes",
+			input:  `<img src="/a.png" onerror="alert(1)"><p onclick="alert(1)" onmouseover=alert(1)>x</p>`,
+			output: `<img src="/a.png"><p>x</p>`,
+		},
+		{
+			name:   "style attribute",
+			input:  `<p style="background:url(javascript:alert(1))">x</p>`,
+			output: `<p>x</p>`,
+		},
+		{
+			name:   "script element",
+			input:  `<p>a<script>alert("<p>")</script>b</p>`,
+			output: `<p>ab</p>`,
+		},
+		{
+			name:   "self-closing script element",
+			input:  `<script src="https://example.com/x.js"/>x`,
+			output: `x`,
+		},
+		{
+			name:   "style element",
+			input:  `<style>body { display: none; }</style><p>x</p>`,
+			output: `<p>x</p>`,
+		},
+		{
+			name:   "script inside svg",
+			input:  `<svg><script>alert(1)</script><text>x</text></svg>`,
+			output: `x`,
+		},
+		{
+			name:   "iframe",
+			input:  `<iframe src="https://example.com">fallback</iframe>x`,
+			output: `x`,
+		},
+		{
+			name:   "unclosed tags",
+			input:  `<p><strong>bold <em>both`,
+			output: `<p><strong>bold <em>both</em></strong></p>`,
+		},
+		{
+			name:   "misnested tags",
+			input:  `<strong><em>x</strong>y</em>`,
+			output: `<strong><em>x</em></strong>y`,
+		},
+		{
+			name:   "unclosed script",
+			input:  `<p>x</p><script>alert(1)`,
+			output: `<p>x</p>`,
+		},
+		{
+			name:   "unfinished tag",
+			input:  `<p>x</p><img src=x onerror=alert(1)//`,
+			output: `<p>x</p>`,
+		},
+		{
+			name:   "escaped text stays escaped",
+			input:  `<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>`,
+			output: `<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>`,
+		},
+		{
+			name:   "quotes in attribute values",
+			input:  `<a href="/x" title='a" onclick="alert(1)'>x</a>`,
+			output: `<a href="/x" title="a&#34; onclick=&#34;alert(1)" rel="nofollow noopener noreferrer">x</a>`,
+		},
+		{
+			name:   "disallowed elements keep their text",
+			input:  `<div><marquee>x</marquee></div>`,
+			output: `x`,
+		},
+		{
+			name:   "disallowed classes",
+			input:  `<a href="/x" class="button">x</a><p class="x">y</p><span class="a;b">z</span>`,
+			output: `<a href="/x" rel="nofollow noopener noreferrer">x</a><p>y</p><span>z</span>`,
+		},
+		{
+			name:   "invalid list start",
+			input:  `<ol start="1;x"><li>x</li></ol><ol start="3"><li>y</li></ol>`,
+			output: `<ol><li>x</li></ol><ol start="3"><li>y</li></ol>`,
+		},
+		{
+			name:  "allowed links",
+			input: `<a href="https://example.com/?a=1&amp;b=2">x</a><a href="mailto:a@example.com">y</a>`,
+			output: `<a href="https://example.com/?a=1&amp;b=2" rel="nofollow noopener noreferrer">x</a>` +
+				`<a href="mailto:a@example.com" rel="nofollow noopener noreferrer">y</a>`,
+		},
+		{
+			name:   "mention link",
+			input:  `<a href="/user/bob" class="mention">@bob</a>`,
+			output: `<a href="/user/bob" class="mention" rel="nofollow noopener noreferrer">@bob</a>`,
+		},
+		{
+			name: "highlighted code",
+			input: `<pre class="chroma"><code><span class="line"><span class="cl"><span class="k">def</span>` +
+				`</span></span></code></pre>`,
+			output: `<pre class="chroma"><code><span class="line"><span class="cl"><span class="k">def</span>` +
+				`</span></span></code></pre>`,
+		},
+		{
+			name:   "code language",
+			input:  `<pre><code class="language-c++">x</code></pre>`,
+			output: `<pre><code class="language-c++">x</code></pre>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if output := SanitizeHtml(test.input); output != test.output {
+				t.Errorf("SanitizeHtml(%q) = %q, want %q", test.input, output, test.output)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownKeepsMentionsAndHighlighting(t *testing.T) {
+	output := RenderMarkdown("Thanks @bob!\n\n```go\nfunc main() {}\n```\n", map[string]string{"bob": "Bob"})
+
+	expected := []string{
+		`<a href="/user/Bob" class="mention" rel="nofollow noopener noreferrer">@bob</a>`,
+		`<pre class="chroma">`,
+		`<span class="kd">func</span>`,
+	}
+	for _, fragment := range expected {
+		if !strings.Contains(output, fragment) {
+			t.Errorf("RenderMarkdown() = %q, want it to contain %q", output, fragment)
+		}
+	}
+}
+
+func TestRenderMarkdownRemovesRawHtml(t *testing.T) {
+	output := RenderMarkdown("<script>alert(1)</script>\n\n[x](javascript:alert(1)) <img src=x onerror=alert(1)>", nil)
+
+	for _, fragment := range []string{"<script", "javascript:", "onerror"} {
+		if strings.Contains(output, fragment) {
+			t.Errorf("RenderMarkdown() = %q, want it not to contain %q", output, fragment)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Number of hours within which a user cannot post a thread with the same title and body again, or 0 to allow reposts.
+var REPOST_WINDOW_HOURS = 0
+
+// MaxSimilarThreads Maximum number of similar threads returned for a draft thread.
+const MaxSimilarThreads = 5
+
+// NearDuplicateDistance Maximum number of bits in which the body fingerprints of near-duplicate threads differ.
+// Bodies of forum threads are short, so changing a single word changes several bits, while the fingerprints of
+// unrelated bodies differ in about half of their bits.
+const NearDuplicateDistance = 10
+
+// Number of consecutive words hashed together when fingerprinting a body.
+const simHashShingleSize = 2
+
+// InitRepostPolicy Initializes the time window within which reposts are rejected.
+func InitRepostPolicy() {
+	REPOST_WINDOW_HOURS = max(GetEnvInt("REPOST_WINDOW_HOURS", REPOST_WINDOW_HOURS), 0)
+
+	if REPOST_WINDOW_HOURS > 0 {
+		Log("main", "Reposts are rejected within "+strconv.Itoa(REPOST_WINDOW_HOURS)+" hours", nil)
+	} else {
+		Log("main", "Reposts are allowed", nil)
+	}
+}
+
+// SimHash Returns a 64-bit SimHash fingerprint of the text, computed from its case-folded word shingles.
+// Texts that share most of their shingles have fingerprints that differ in only a few bits.
+func SimHash(text string) int64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	numShingles := max(len(words)-simHashShingleSize+1, min(len(words), 1))
+
+	var weights [64]int
+	for i := 0; i < numShingles; i++ {
+		shingle := strings.Join(words[i:min(i+simHashShingleSize, len(words))], " ")
+
+		hash := fnv.New64a()
+		_, _ = hash.Write([]byte(shingle))
+		sum := hash.Sum64()
+
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for bit := 0; bit < 64; bit++ {
+		if weights[bit] > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+
+	return int64(fingerprint)
+}
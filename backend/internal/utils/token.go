@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// GenerateToken Generates a random hex-encoded token from the given number of random bytes.
+func GenerateToken(numBytes int) (string, error) {
+	buf := make([]byte, numBytes)
+	_, err := rand.Read(buf)
+	if err != nil {
+		Log("token", "Unable to generate random token", err)
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// HashToken Hashes a token with SHA-256 so that it can be stored without revealing the token itself.
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
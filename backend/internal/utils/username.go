@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"errors"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrInvalidUsername = errors.New("invalid username")
+var ErrReservedUsername = errors.New("reserved username")
+
+// Usernames may only contain letters, combining marks, digits, underscores, hyphens and periods by default.
+var USERNAME_PATTERN = regexp.MustCompile(`^[\p{L}\p{M}\p{N}_.-]+$`)
+
+var RESERVED_USERNAMES = []string{
+	"admin", "administrator", "anonymous", "api", "create", "deleted", "help", "login", "me", "mod",
+	"moderator", "null", "rename", "root", "staff", "support", "system", "undefined", "verify",
+}
+
+// Characters that look alike, mapped to the character they are most likely to be mistaken for.
+// This is a subset of the Unicode confusables data (UTS #39) covering Latin look-alikes.
+var confusables = map[rune]string{
+	// Digits
+	'0': "o", '1': "l",
+	// Latin
+	'ı': "i", 'ɩ': "i", 'ǀ': "l", 'ɡ': "g", 'ʋ': "u", 'ɑ': "a",
+	// Greek
+	'α': "a", 'β': "b", 'γ': "y", 'ε': "e", 'η': "n", 'ι': "i", 'κ': "k", 'ν': "v", 'ο': "o", 'ρ': "p",
+	'τ': "t", 'υ': "u", 'χ': "x", 'ω': "w",
+	// Cyrillic
+	'а': "a", 'в': "b", 'г': "r", 'е': "e", 'з': "3", 'і': "i", 'ј': "j", 'к': "k", 'н': "h",
+	'о': "o", 'п': "n", 'р': "p", 'с': "c", 'т': "t", 'у': "y", 'х': "x", 'ь': "b", 'һ': "h", 'ӏ': "l",
+	'ԁ': "d", 'ԛ': "q", 'ѕ': "s", 'ԝ': "w", 'ү': "y", 'ѵ': "v",
+	// Armenian
+	'օ': "o", 'ս': "u", 'ց': "g", 'հ': "h", 'ո': "n",
+}
+
+// Capital letters that look like a lowercase l. These are mapped before case folding, which would turn them into an i.
+var capitalConfusables = map[rune]rune{
+	'I': 'l', 'Ι': 'l', 'І': 'l', 'Ӏ': 'l',
+}
+
+// Sequences of characters that look like a single character, mapped to that character as in UTS #39.
+var confusableSequences = strings.NewReplacer("rn", "m")
+
+// Number of days a user must wait between username changes.
+var USERNAME_CHANGE_COOLDOWN_DAYS = 30
+
+// Number of days a username freed by a username change cannot be registered by someone else.
+var USERNAME_HOLD_DAYS = 90
+
+var caseFolder = cases.Fold()
+
+// InitUsernamePolicy Initializes the allowed username pattern, reserved usernames and username change limits
+// from the environment.
+func InitUsernamePolicy() {
+	if pattern := os.Getenv("USERNAME_PATTERN"); pattern != "" {
+		compiledPattern, err := regexp.Compile(pattern)
+		if err != nil {
+			Log("main", "Invalid username pattern, using default", err)
+		} else {
+			USERNAME_PATTERN = compiledPattern
+		}
+	}
+
+	if reservedUsernames, isSet := os.LookupEnv("RESERVED_USERNAMES"); isSet {
+		RESERVED_USERNAMES = []string{}
+		for _, reservedUsername := range strings.Split(reservedUsernames, ",") {
+			if trimmed := strings.TrimSpace(reservedUsername); trimmed != "" {
+				RESERVED_USERNAMES = append(RESERVED_USERNAMES, trimmed)
+			}
+		}
+	}
+
+	USERNAME_CHANGE_COOLDOWN_DAYS = max(GetEnvInt("USERNAME_CHANGE_COOLDOWN_DAYS", USERNAME_CHANGE_COOLDOWN_DAYS), 0)
+	USERNAME_HOLD_DAYS = max(GetEnvInt("USERNAME_HOLD_DAYS", USERNAME_HOLD_DAYS), 0)
+}
+
+// NormaliseUsername Returns the NFKC normalised form of a username, which is the form that is displayed.
+func NormaliseUsername(username string) string {
+	return norm.NFKC.String(strings.TrimSpace(username))
+}
+
+// CanonicalUsername Returns the case-folded NFKC form of a username.
+// Two usernames with the same canonical form refer to the same user.
+func CanonicalUsername(username string) string {
+	return norm.NFKC.String(caseFolder.String(NormaliseUsername(username)))
+}
+
+// UsernameSkeleton Returns the confusable skeleton of a username, following the skeleton algorithm of UTS #39.
+// Usernames with the same skeleton look alike, so only one of them may be registered.
+func UsernameSkeleton(username string) string {
+	uncapitalised := strings.Map(func(r rune) rune {
+		if replacement, isConfusable := capitalConfusables[r]; isConfusable {
+			return replacement
+		}
+		return r
+	}, norm.NFD.String(NormaliseUsername(username)))
+
+	var skeleton strings.Builder
+	for _, r := range norm.NFD.String(CanonicalUsername(uncapitalised)) {
+		if unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Cf, r) {
+			// Drop combining marks and invisible characters so that e.g. "ádmin" looks like "admin"
+			continue
+		}
+		if replacement, isConfusable := confusables[r]; isConfusable {
+			skeleton.WriteString(replacement)
+		} else {
+			skeleton.WriteRune(r)
+		}
+	}
+	return norm.NFD.String(confusableSequences.Replace(skeleton.String()))
+}
+
+// ValidateUsername Checks that a normalised username has between 1 and 30 characters, only contains allowed
+// characters and does not look like a reserved username.
+func ValidateUsername(username string) error {
+	length := utf8.RuneCountInString(username)
+	if length < 1 || length > 30 || !USERNAME_PATTERN.MatchString(username) {
+		return ErrInvalidUsername
+	}
+
+	for _, r := range username {
+		if unicode.IsSpace(r) || !unicode.IsGraphic(r) || unicode.Is(unicode.Cf, r) {
+			// Reject whitespace, zero-width and other invisible characters regardless of the pattern
+			return ErrInvalidUsername
+		}
+	}
+
+	if IsReservedUsername(username) {
+		return ErrReservedUsername
+	}
+
+	return nil
+}
+
+// IsReservedUsername Returns true if the username looks like one of the reserved usernames.
+func IsReservedUsername(username string) bool {
+	// Reserved usernames may not be imitated in any case, so i is not told apart from the l a capital I is mapped to
+	skeleton := strings.ReplaceAll(UsernameSkeleton(username), "i", "l")
+	return slices.ContainsFunc(RESERVED_USERNAMES, func(reservedUsername string) bool {
+		return strings.ReplaceAll(UsernameSkeleton(reservedUsername), "i", "l") == skeleton
+	})
+}
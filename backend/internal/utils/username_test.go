@@ -0,0 +1,71 @@
+package utils
+
+import "testing"
+
+func TestUsernameSkeleton(t *testing.T) {
+	tests := []struct {
+		name      string
+		usernames []string
+		skeleton  string
+	}{
+		{name: "rn looks like m", usernames: []string{"morty", "rnorty"}, skeleton: "morty"},
+		{name: "capital I, l and 1", usernames: []string{"llya", "Ilya", "1lya", "ӏlya"}, skeleton: "llya"},
+		{name: "0 and o", usernames: []string{"bob", "b0b", "B0B"}, skeleton: "bob"},
+		{name: "Cyrillic look-alikes", usernames: []string{"paypal", "раураl", "PayPal"}, skeleton: "paypal"},
+		{name: "Greek look-alikes", usernames: []string{"token", "τοκεn"}, skeleton: "token"},
+		{name: "combining marks", usernames: []string{"adele", "adèle", "adéle"}, skeleton: "adele"},
+		{name: "seeded testuser", usernames: []string{"testuser"}, skeleton: "testuser"},
+		{name: "seeded testadmin", usernames: []string{"testadmin", "testadrnin", "tеstаdmin"}, skeleton: "testadmin"},
+		// Same canonical username as testadmin, which is already unique
+		{name: "capitalised testadmin", usernames: []string{"TESTADMIN", "testadmln"}, skeleton: "testadmln"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, username := range test.usernames {
+				if skeleton := UsernameSkeleton(username); skeleton != test.skeleton {
+					t.Errorf("UsernameSkeleton(%q) = %q, want %q", username, skeleton, test.skeleton)
+				}
+			}
+		})
+	}
+}
+
+func TestUsernameSkeletonKeepsDistinctUsernamesApart(t *testing.T) {
+	tests := [][2]string{
+		{"mia", "rnla"},
+		{"ilya", "llya"},
+		{"testadmin", "testadmln"},
+		{"anna", "arma"},
+	}
+
+	for _, test := range tests {
+		if UsernameSkeleton(test[0]) == UsernameSkeleton(test[1]) {
+			t.Errorf("UsernameSkeleton(%q) = UsernameSkeleton(%q) = %q", test[0], test[1], UsernameSkeleton(test[0]))
+		}
+	}
+}
+
+func TestIsReservedUsername(t *testing.T) {
+	tests := []struct {
+		username   string
+		isReserved bool
+	}{
+		{"admin", true},
+		{"ADMIN", true},
+		{"AdmIn", true},
+		{"admln", true},
+		{"adrnin", true},
+		{"аdmin", true},
+		{"Administrator", true},
+		{"AdmIn1strator", true},
+		{"admins", false},
+		{"testadmin", false},
+	}
+
+	for _, test := range tests {
+		if isReserved := IsReservedUsername(test.username); isReserved != test.isReserved {
+			t.Errorf("IsReservedUsername(%q) = %t, want %t", test.username, isReserved, test.isReserved)
+		}
+	}
+}
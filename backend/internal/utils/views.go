@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Number of minutes within which repeated views of a thread by the same viewer are only counted once.
+var VIEW_WINDOW_MINUTES = 30
+
+// Number of seconds between writes of recorded views to the database.
+var VIEW_FLUSH_SECONDS = 30
+
+// Header set by a reverse proxy to the IP address of the client. If empty, the address of the connection is used.
+var CLIENT_IP_HEADER = ""
+
+// Maximum number of views waiting to be counted. Views are dropped when it is full rather than delaying requests.
+const viewQueueSize = 10000
+
+// ThreadView A view of a thread waiting to be counted.
+type ThreadView struct {
+	ThreadID   string
+	Viewer     string
+	ViewedTime time.Time
+}
+
+// ThreadViews Views of threads waiting to be counted by the view job.
+var ThreadViews = make(chan ThreadView, viewQueueSize)
+
+// InitViewPolicy Initializes the time window of view deduplication and how often views are counted.
+func InitViewPolicy() {
+	VIEW_WINDOW_MINUTES = max(GetEnvInt("VIEW_WINDOW_MINUTES", VIEW_WINDOW_MINUTES), 1)
+	VIEW_FLUSH_SECONDS = max(GetEnvInt("VIEW_FLUSH_SECONDS", VIEW_FLUSH_SECONDS), 1)
+	CLIENT_IP_HEADER = os.Getenv("CLIENT_IP_HEADER")
+
+	Log("main", "Thread views are counted once per viewer every "+strconv.Itoa(VIEW_WINDOW_MINUTES)+" minutes", nil)
+}
+
+// RecordThreadView Queues a view of a thread by the given logged in user, or by the IP address of the request if the
+// username is empty, to be counted. Never blocks, so views may be dropped under heavy load.
+func RecordThreadView(r *http.Request, threadId string, username string) {
+	view := ThreadView{
+		ThreadID:   threadId,
+		Viewer:     getViewer(r, username),
+		ViewedTime: time.Now(),
+	}
+
+	select {
+	case ThreadViews <- view:
+	default:
+		Log("RecordThreadView", "View queue is full, dropping view of thread "+threadId, nil)
+	}
+}
+
+// getViewer Returns the username of a logged in user, or a keyed hash of the IP address of the request otherwise,
+// so that IP addresses are not stored.
+func getViewer(r *http.Request, username string) string {
+	if username != "" {
+		return "user:" + username
+	}
+
+	ip := ""
+	if CLIENT_IP_HEADER != "" {
+		ip = strings.TrimSpace(strings.Split(r.Header.Get(CLIENT_IP_HEADER), ",")[0])
+	}
+	if ip == "" {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		ip = host
+	}
+
+	mac := hmac.New(sha256.New, SECRET)
+	mac.Write([]byte(ip))
+	return "ip:" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -2,86 +2,567 @@
 -- docker run --rm -v "%cd%:/src" -w /src sqlc/sqlc generate
 SELECT 1;
 
--- Returns 1 if the user with the given username exists.
+-- Returns 1 if the user with the given canonical username exists.
 -- name: CheckUserExists :one
 SELECT EXISTS
-    (SELECT 1 FROM users WHERE LOWER(username) = LOWER($1))
+    (SELECT 1 FROM users WHERE canonical_username = $1)
 AS is_existing_user;
 
 
--- Creates a new user with the given username and password.
+-- Returns the current usernames of the users with the given canonical usernames. Canonical usernames no user has
+-- are resolved to the user who most recently had them, as in GetRenamedUsername.
+-- name: GetMentionedUsers :many
+SELECT u.canonical_username AS mentioned_username, u.username
+FROM users u
+WHERE u.canonical_username = ANY(@canonical_usernames::text[])
+UNION ALL
+SELECT renamed.old_canonical_username AS mentioned_username, renamed.username
+FROM (
+    SELECT DISTINCT ON (h.old_canonical_username) h.old_canonical_username, h.username
+    FROM username_history h
+    WHERE h.old_canonical_username = ANY(@canonical_usernames::text[])
+    AND NOT EXISTS (SELECT 1 FROM users u WHERE u.canonical_username = h.old_canonical_username)
+    ORDER BY h.old_canonical_username, h.changed_time DESC
+) renamed;
+
+
+-- Returns 1 if a user other than the given user has the given canonical username or a look-alike username,
+-- or had it within the last hold_days days.
+-- name: CheckUsernameTaken :one
+SELECT (EXISTS
+    (SELECT 1 FROM users u
+     WHERE (u.canonical_username = $1 OR u.username_skeleton = $2)
+     AND u.username <> @username::text)
+OR EXISTS
+    (SELECT 1 FROM username_history h
+     WHERE (h.old_canonical_username = $1 OR h.old_username_skeleton = $2)
+     AND h.username <> @username::text
+     AND h.changed_time > NOW() - MAKE_INTERVAL(days => @hold_days::int)))::boolean
+AS is_username_taken;
+
+
+-- Returns the current username of the user who most recently had the given canonical username.
+-- name: GetRenamedUsername :one
+SELECT username
+FROM username_history
+WHERE old_canonical_username = $1
+ORDER BY changed_time DESC
+LIMIT 1;
+
+
+-- Returns the current canonical username, username skeleton and the last time the user was renamed.
+-- name: GetUsernameDetails :one
+SELECT canonical_username, username_skeleton, last_renamed_time
+FROM users
+WHERE username = $1;
+
+
+-- Renames a user. References to the old username in other tables are updated by ON UPDATE CASCADE.
+-- name: RenameUser :exec
+UPDATE users
+SET username = @new_username::text,
+    canonical_username = @canonical_username::text,
+    username_skeleton = @username_skeleton::text,
+    last_renamed_time = NOW()
+WHERE username = @old_username::text;
+
+
+-- Records the previous username of a renamed user.
+-- name: AddUsernameHistory :exec
+INSERT INTO username_history (username, old_username, old_canonical_username, old_username_skeleton)
+VALUES ($1, $2, $3, $4);
+
+
+-- Returns the usernames and username skeletons of users ordered by username, starting after the given username.
+-- name: GetUsernameSkeletons :many
+SELECT username, username_skeleton
+FROM users
+WHERE username > @after_username::text
+ORDER BY username
+LIMIT $1;
+
+
+-- Sets the username skeleton of a user.
+-- name: SetUsernameSkeleton :exec
+UPDATE users
+SET username_skeleton = @username_skeleton::text
+WHERE username = @username::text;
+
+
+-- Returns the previous usernames and their skeletons ordered by ID, starting after the given ID.
+-- name: GetOldUsernameSkeletons :many
+SELECT id, old_username, old_username_skeleton
+FROM username_history
+WHERE id > @after_id::uuid
+ORDER BY id
+LIMIT $1;
+
+
+-- Sets the username skeleton of a previous username.
+-- name: SetOldUsernameSkeleton :exec
+UPDATE username_history
+SET old_username_skeleton = @old_username_skeleton::text
+WHERE id = @id::uuid;
+
+
+-- Returns 1 if a user with the given email exists.
+-- name: CheckEmailExists :one
+SELECT EXISTS
+    (SELECT 1 FROM users WHERE LOWER(email) = LOWER($1))
+AS is_existing_email;
+
+
+-- Creates a new user with the given username, password and email.
 -- name: CreateUser :exec
-INSERT INTO users (username, password)
-VALUES ($1, $2);
+INSERT INTO users (username, canonical_username, username_skeleton, password, email, is_verified)
+VALUES ($1, $2, $3, $4, $5, $6);
 
 
--- Returns a username and their password hash.
+-- Returns a username, their password hash and whether their email has been verified.
 -- name: GetPasswordHash :one
-SELECT username, password
+SELECT username, password, is_verified
+FROM users
+WHERE canonical_username = $1;
+
+
+-- Returns the email of a user and whether it has been verified.
+-- name: GetUserEmail :one
+SELECT email, is_verified
+FROM users
+WHERE username = $1;
+
+
+-- Returns whether the user has verified their email.
+-- name: CheckUserVerified :one
+SELECT is_verified
+FROM users
+WHERE username = $1;
+
+
+-- Returns the role of the user.
+-- name: GetUserRole :one
+SELECT role
 FROM users
-WHERE LOWER(username) = LOWER($1);
+WHERE username = $1;
+
+
+-- Creates a new email verification token for a user, valid for 24 hours.
+-- name: CreateEmailVerification :exec
+INSERT INTO email_verifications (token_hash, username, expires_time)
+VALUES ($1, $2, NOW() + INTERVAL '24 hours');
+
+
+-- Marks the user who owns the given unexpired verification token as verified. Returns the username.
+-- name: VerifyUserEmail :one
+UPDATE users
+SET is_verified = TRUE
+WHERE username = (
+    SELECT username
+    FROM email_verifications
+    WHERE token_hash = $1
+    AND expires_time > NOW())
+RETURNING username;
+
+
+-- Deletes all email verification tokens of a user.
+-- name: DeleteEmailVerifications :exec
+DELETE FROM email_verifications
+WHERE username = $1;
+
+
+-- Creates a new invite code. Returns the details of the created invite code.
+-- name: CreateInviteCode :one
+INSERT INTO invite_codes (code, creator, max_uses, expires_time)
+VALUES ($1, $2, $3, $4)
+RETURNING code, creator, max_uses, num_uses, expires_time, created_time;
+
+
+-- Returns all invite codes, latest first.
+-- name: GetInviteCodes :many
+SELECT code, creator, max_uses, num_uses, expires_time, created_time
+FROM invite_codes
+ORDER BY created_time DESC;
 
 
--- Creates a new thread with the given title, body, and creator. Returns the details of the created thread.
+-- Uses up one use of an invite code if it is still valid. Returns the code if successful.
+-- name: RedeemInviteCode :one
+UPDATE invite_codes
+SET num_uses = num_uses + 1
+WHERE code = $1
+AND num_uses < max_uses
+AND (expires_time IS NULL OR expires_time > NOW())
+RETURNING code;
+
+
+-- Records that a user registered with an invite code.
+-- name: AddInviteCodeUse :exec
+INSERT INTO invite_code_uses (code, username)
+VALUES ($1, $2);
+
+
+-- Deletes the invite code with the given code.
+-- name: DeleteInviteCode :execrows
+DELETE FROM invite_codes
+WHERE code = $1;
+
+
+-- Creates a new thread with the given title, body, creator and category. Returns the details of the created thread.
 -- name: CreateThread :one
-INSERT INTO threads (title, body, creator)
-VALUES ($1, $2, $3)
-RETURNING id, title, body, creator, created_time, updated_time, num_comments;
+INSERT INTO threads (title, body, creator, body_html, body_html_version, body_simhash, category_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, title, body, creator, created_time, updated_time, num_comments, num_revisions, deleted_time, deleted_by,
+    is_locked, lock_reason, locked_time, locked_by, score, view_count, category_id;
 
 
 -- Returns the details of the thread with the given id, as well as the tags of the thread as an array.
+-- The thread is pinned if it is pinned everywhere. Deleted threads are not returned.
 -- name: GetThreadDetails :one
-SELECT t.id, t.title, t.body, t.creator, t.created_time, t.updated_time, t.num_comments,
+SELECT t.id, t.title, t.body, t.creator, t.created_time, t.updated_time, t.num_comments, t.num_revisions,
+    t.is_locked, t.lock_reason, t.score, t.view_count, t.body_html, t.body_html_version, t.category_id,
     CASE
     WHEN COUNT(tt.tag_name) > 0 THEN ARRAY_AGG(tt.tag_name ORDER BY tt.tag_name)
         ELSE '{}'::text[]
-    END AS tags
+    END AS tags,
+    EXISTS (
+        SELECT 1 FROM thread_pins tp
+        WHERE tp.thread_id = t.id
+        AND tp.tag_name = ''
+        AND (tp.expires_time IS NULL OR tp.expires_time > NOW())
+    ) AS is_pinned,
+    EXISTS (
+        SELECT 1 FROM thread_pins tp
+        WHERE tp.thread_id = t.id
+        AND tp.is_announcement
+        AND (tp.expires_time IS NULL OR tp.expires_time > NOW())
+    ) AS is_announcement,
+    -- The vote of the user viewing the thread, 0 if they have not voted or are not logged in.
+    COALESCE((
+        SELECT tv.value FROM thread_votes tv
+        WHERE tv.thread_id = t.id
+        AND tv.username = @viewer::text
+    ), 0)::integer AS my_vote,
+    EXISTS (
+        SELECT 1 FROM bookmarks b
+        WHERE b.thread_id = t.id
+        AND b.username = @viewer::text
+    ) AS is_bookmarked,
+    EXISTS (
+        SELECT 1 FROM thread_subscriptions ts
+        WHERE ts.thread_id = t.id
+        AND ts.username = @viewer::text
+        AND NOT ts.is_muted
+    ) AS is_subscribed
 FROM threads t
 LEFT JOIN thread_tags tt ON t.id = tt.thread_id
 WHERE t.id = $1
+AND t.deleted_time IS NULL
 GROUP BY t.id;
 
 
--- Returns the details of all threads.
--- Sort order should be one of 'created_time_asc', 'created_time_desc', 'num_comments_asc', 'num_comments_desc'.
+-- Returns the details of all threads that have not been deleted, with announcements and pinned threads first.
+-- Sort order should be one of 'created_time_asc', 'created_time_desc', 'num_comments_asc', 'num_comments_desc',
+-- 'score_desc', 'hot_desc', 'trending_desc', 'views_desc'.
 -- name: GetThreads :many
-SELECT t.id, t.title, t.body, t.creator, t.created_time, t.updated_time, t.num_comments,
+SELECT t.id, t.title, t.body, t.creator, t.created_time, t.updated_time, t.num_comments, t.num_revisions,
+    t.is_locked, t.lock_reason, t.score, t.view_count, t.body_html, t.body_html_version, t.category_id,
     CASE
     WHEN COUNT(tt.tag_name) > 0 THEN ARRAY_AGG(tt.tag_name ORDER BY tt.tag_name)
         ELSE '{}'::text[]
-    END AS tags
+    END AS tags,
+    EXISTS (
+        SELECT 1 FROM thread_pins tp
+        WHERE tp.thread_id = t.id
+        AND tp.tag_name = ''
+        AND (tp.expires_time IS NULL OR tp.expires_time > NOW())
+    ) AS is_pinned,
+    EXISTS (
+        SELECT 1 FROM thread_pins tp
+        WHERE tp.thread_id = t.id
+        AND tp.is_announcement
+        AND (tp.expires_time IS NULL OR tp.expires_time > NOW())
+    ) AS is_announcement,
+    -- The vote of the user viewing the thread, 0 if they have not voted or are not logged in.
+    COALESCE((
+        SELECT tv.value FROM thread_votes tv
+        WHERE tv.thread_id = t.id
+        AND tv.username = @viewer::text
+    ), 0)::integer AS my_vote,
+    EXISTS (
+        SELECT 1 FROM bookmarks b
+        WHERE b.thread_id = t.id
+        AND b.username = @viewer::text
+    ) AS is_bookmarked,
+    EXISTS (
+        SELECT 1 FROM thread_subscriptions ts
+        WHERE ts.thread_id = t.id
+        AND ts.username = @viewer::text
+        AND NOT ts.is_muted
+    ) AS is_subscribed
 FROM threads t
 LEFT JOIN thread_tags tt ON t.id = tt.thread_id
+WHERE t.deleted_time IS NULL
 GROUP BY t.id
 ORDER BY
+    is_announcement DESC,
+    is_pinned DESC,
     CASE WHEN @sortOrder::text = 'created_time_asc' THEN created_time END ASC,
     CASE WHEN @sortOrder::text = 'created_time_desc' THEN created_time END DESC,
     CASE WHEN @sortOrder::text = 'num_comments_asc' THEN num_comments END ASC,
-    CASE WHEN @sortOrder::text = 'num_comments_desc' THEN num_comments END DESC
+    CASE WHEN @sortOrder::text = 'num_comments_desc' THEN num_comments END DESC,
+    CASE WHEN @sortOrder::text = 'score_desc' THEN score END DESC,
+    CASE WHEN @sortOrder::text = 'hot_desc' THEN hot_score END DESC,
+    CASE WHEN @sortOrder::text = 'trending_desc' THEN trending_score END DESC,
+    CASE WHEN @sortOrder::text = 'views_desc' THEN view_count END DESC
 LIMIT $1
 OFFSET $2;
 
 
--- Checks if a user is the creator of a thread.
+-- Checks if a user is the creator of a thread that has not been deleted.
 -- name: CheckThreadCreator :one
 SELECT EXISTS
-    (SELECT 1 FROM threads WHERE id = $1 AND creator = $2)
+    (SELECT 1 FROM threads WHERE id = $1 AND creator = $2 AND deleted_time IS NULL)
 AS is_thread_creator;
 
 
 -- Updates the thread with the given id.
 -- name: UpdateThread :exec
 UPDATE threads
-SET title = $1, body = $2, updated_time = NOW()
+SET title = $1, body = $2, body_html = $5, body_html_version = $6, body_simhash = $7, updated_time = NOW()
 WHERE id = $3
 AND creator = $4;
 
 
--- Deletes the thread with the given id.
+-- Soft deletes the thread with the given id. The thread is purged once it has been deleted for longer than the
+-- retention period.
 -- name: DeleteThread :exec
-DELETE FROM threads
+UPDATE threads
+SET deleted_time = NOW(), deleted_by = creator
+WHERE id = $1
+AND creator = $2
+AND deleted_time IS NULL;
+
+
+-- Returns the creator and deletion details of the deleted thread with the given id.
+-- name: GetDeletedThread :one
+SELECT id, creator, deleted_time, deleted_by
+FROM threads
 WHERE id = $1
-AND creator = $2;
+AND deleted_time IS NOT NULL;
+
+
+-- Restores the deleted thread with the given id.
+-- name: RestoreThread :exec
+UPDATE threads
+SET deleted_time = NULL, deleted_by = NULL
+WHERE id = $1;
+
+
+-- Returns the creator and lock details of the thread with the given id, if it has not been deleted.
+-- name: GetThreadLockDetails :one
+SELECT id, creator, is_locked, locked_by
+FROM threads
+WHERE id = $1
+AND deleted_time IS NULL;
+
+
+-- Locks the thread with the given id so that no new comments can be added. An empty reason is stored as NULL.
+-- name: LockThread :exec
+UPDATE threads
+SET is_locked = TRUE, lock_reason = NULLIF(@lock_reason::text, ''), locked_time = NOW(), locked_by = @locked_by::text
+WHERE id = @id;
+
+
+-- Unlocks the thread with the given id.
+-- name: UnlockThread :exec
+UPDATE threads
+SET is_locked = FALSE, lock_reason = NULL, locked_time = NULL, locked_by = NULL
+WHERE id = $1;
+
+
+-- Locks threads without new comments for more than the given number of days.
+-- name: AutoLockInactiveThreads :execrows
+UPDATE threads t
+SET is_locked = TRUE, lock_reason = @lock_reason::text, locked_time = NOW(), locked_by = NULL
+WHERE NOT t.is_locked
+AND t.deleted_time IS NULL
+AND GREATEST(t.created_time, (
+    SELECT MAX(c.created_time)
+    FROM comments c
+    WHERE c.thread_id = t.id
+    AND c.deleted_time IS NULL
+)) < NOW() - MAKE_INTERVAL(days => @inactive_days::integer);
+
+
+-- Recomputes the ranking scores of all threads that have not been deleted. Returns the number of threads updated.
+-- The hot score decays with the time since the last comment, so threads without activity for a week are no longer hot.
+-- The trending score counts the comments and net votes within the last trending_hours hours.
+-- name: RefreshThreadRankings :execrows
+UPDATE threads t
+SET hot_score = r.hot_score, trending_score = r.trending_score
+FROM (
+    SELECT a.id,
+        CASE
+            WHEN a.last_activity_time > NOW() - INTERVAL '7 days' THEN
+                (GREATEST(a.score + a.num_comments, 0) + 1)::double precision /
+                POWER(EXTRACT(EPOCH FROM NOW() - a.last_activity_time) / 3600 + 2, 1.5)
+            ELSE 0
+        END AS hot_score,
+        (a.num_recent_comments + a.recent_votes)::double precision AS trending_score
+    FROM (
+        SELECT t.id, t.score, t.num_comments,
+            GREATEST(t.created_time, MAX(c.created_time)) AS last_activity_time,
+            COUNT(c.id) FILTER (
+                WHERE c.created_time > NOW() - MAKE_INTERVAL(hours => @trending_hours::integer)
+            ) AS num_recent_comments,
+            COALESCE((
+                SELECT SUM(tv.value) FROM thread_votes tv
+                WHERE tv.thread_id = t.id
+                AND tv.updated_time > NOW() - MAKE_INTERVAL(hours => @trending_hours::integer)
+            ), 0) AS recent_votes
+        FROM threads t
+        LEFT JOIN comments c ON c.thread_id = t.id AND c.deleted_time IS NULL
+        WHERE t.deleted_time IS NULL
+        GROUP BY t.id
+    ) a
+) r
+WHERE t.id = r.id
+AND (t.hot_score <> r.hot_score OR t.trending_score <> r.trending_score);
+
+
+-- Records a batch of thread views and adds them to the view counts of the threads. A view is only counted if the
+-- viewer has not viewed the thread within the last window_minutes minutes. Each viewer must appear at most once per
+-- thread in the batch. Returns the number of threads whose view count changed.
+-- name: RecordThreadViews :execrows
+WITH counted_views AS (
+    INSERT INTO thread_views (thread_id, viewer, viewed_time)
+    SELECT v.thread_id, v.viewer, v.viewed_time
+    FROM (
+        -- Arrays of the same length are unnested in parallel.
+        SELECT UNNEST(@thread_ids::uuid[]) AS thread_id,
+            UNNEST(@viewers::text[]) AS viewer,
+            UNNEST(@viewed_times::timestamptz[]) AS viewed_time
+    ) v
+    JOIN threads t ON t.id = v.thread_id
+    WHERE t.deleted_time IS NULL
+    ON CONFLICT (thread_id, viewer) DO UPDATE
+    SET viewed_time = EXCLUDED.viewed_time
+    WHERE thread_views.viewed_time <= EXCLUDED.viewed_time - MAKE_INTERVAL(mins => @window_minutes::integer)
+    RETURNING thread_id
+)
+UPDATE threads t
+SET view_count = t.view_count + cv.num_views
+FROM (
+    SELECT thread_id, COUNT(*) AS num_views
+    FROM counted_views
+    GROUP BY thread_id
+) cv
+WHERE t.id = cv.thread_id;
+
+
+-- Deletes recorded views that are older than the time window, as they no longer affect which views are counted.
+-- name: DeleteExpiredThreadViews :execrows
+DELETE FROM thread_views
+WHERE viewed_time <= NOW() - MAKE_INTERVAL(mins => @window_minutes::integer);
+
+
+-- Returns the active announcements, latest first.
+-- name: GetAnnouncements :many
+SELECT t.id, t.title, t.body, t.creator, t.created_time, t.updated_time, t.num_comments, t.num_revisions,
+    t.is_locked, t.lock_reason, t.score, t.view_count, t.body_html, t.body_html_version, t.category_id,
+    CASE
+    WHEN COUNT(tt.tag_name) > 0 THEN ARRAY_AGG(tt.tag_name ORDER BY tt.tag_name)
+        ELSE '{}'::text[]
+    END AS tags,
+    TRUE AS is_pinned,
+    TRUE AS is_announcement,
+    COALESCE((
+        SELECT tv.value FROM thread_votes tv
+        WHERE tv.thread_id = t.id
+        AND tv.username = @viewer::text
+    ), 0)::integer AS my_vote,
+    EXISTS (
+        SELECT 1 FROM bookmarks b
+        WHERE b.thread_id = t.id
+        AND b.username = @viewer::text
+    ) AS is_bookmarked,
+    EXISTS (
+        SELECT 1 FROM thread_subscriptions ts
+        WHERE ts.thread_id = t.id
+        AND ts.username = @viewer::text
+        AND NOT ts.is_muted
+    ) AS is_subscribed
+FROM threads t
+JOIN thread_pins tp ON t.id = tp.thread_id
+LEFT JOIN thread_tags tt ON t.id = tt.thread_id
+WHERE tp.is_announcement
+AND (tp.expires_time IS NULL OR tp.expires_time > NOW())
+AND t.deleted_time IS NULL
+GROUP BY t.id, tp.pinned_time
+ORDER BY tp.pinned_time DESC;
+
+
+-- Checks if a thread exists and has not been deleted.
+-- name: CheckThreadExists :one
+SELECT EXISTS
+    (SELECT 1 FROM threads WHERE id = $1 AND deleted_time IS NULL)
+AS is_existing_thread;
+
+
+-- Pins a thread everywhere, or only for the given tag. Pinning a thread again replaces the previous pin.
+-- name: PinThread :exec
+INSERT INTO thread_pins (thread_id, tag_name, is_announcement, pinned_by, expires_time)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (thread_id, tag_name) DO UPDATE
+SET is_announcement = EXCLUDED.is_announcement,
+    pinned_by = EXCLUDED.pinned_by,
+    pinned_time = NOW(),
+    expires_time = EXCLUDED.expires_time;
+
+
+-- Unpins a thread everywhere, or only for the given tag.
+-- name: UnpinThread :execrows
+DELETE FROM thread_pins
+WHERE thread_id = $1
+AND tag_name = $2;
+
+
+-- Deletes pins that have expired.
+-- name: DeleteExpiredPins :execrows
+DELETE FROM thread_pins
+WHERE expires_time <= NOW();
+
+
+-- Sets the vote of a user on a thread that has not been deleted, replacing their previous vote.
+-- name: SetThreadVote :one
+INSERT INTO thread_votes (thread_id, username, value)
+SELECT t.id, @username::text, @value::smallint
+FROM threads t
+WHERE t.id = @thread_id
+AND t.deleted_time IS NULL
+ON CONFLICT (thread_id, username) DO UPDATE
+SET value = EXCLUDED.value, updated_time = NOW()
+RETURNING value;
+
+
+-- Removes the vote of a user on a thread.
+-- name: DeleteThreadVote :exec
+DELETE FROM thread_votes
+WHERE thread_id = $1
+AND username = $2;
+
+
+-- Returns the score of the thread with the given id.
+-- name: GetThreadScore :one
+SELECT score
+FROM threads
+WHERE id = $1
+AND deleted_time IS NULL;
+
+
+-- Permanently deletes threads that were deleted more than the given number of days ago.
+-- name: PurgeDeletedThreads :execrows
+DELETE FROM threads
+WHERE deleted_time < NOW() - MAKE_INTERVAL(days => @retention_days::integer);
 
 
 -- Returns the tags of the thread with the given id.
@@ -125,19 +606,94 @@ SELECT $1 as thread_id,
 ON CONFLICT DO NOTHING;
 
 
--- Returns the threads that match the keywords and tags.
+-- Saves the current title, body and tags of a thread as a revision before it is edited.
+-- Nothing is saved if the new title, body and tags are the same as the current ones.
+-- name: CreateThreadRevision :execrows
+INSERT INTO thread_revisions (thread_id, revision_number, title, body, tags, editor)
+SELECT t.id, t.num_revisions + 1, t.title, t.body,
+    ARRAY(SELECT tt.tag_name FROM thread_tags tt WHERE tt.thread_id = t.id ORDER BY tt.tag_name),
+    @editor::text
+FROM threads t
+WHERE t.id = @id
+AND (
+    t.title <> @title::text
+    OR t.body <> @body::text
+    OR ARRAY(SELECT tt.tag_name FROM thread_tags tt WHERE tt.thread_id = t.id ORDER BY tt.tag_name)
+        <> ARRAY(SELECT DISTINCT new_tag FROM UNNEST(@tagArray::text[]) AS new_tag ORDER BY new_tag)
+);
+
+
+-- Returns the revisions of the thread with the given id, oldest first.
+-- name: GetThreadRevisions :many
+SELECT thread_id, revision_number, title, body, tags, editor, edited_time
+FROM thread_revisions
+WHERE thread_id = $1
+ORDER BY revision_number ASC;
+
+
+-- Returns a single revision of the thread with the given id.
+-- name: GetThreadRevision :one
+SELECT thread_id, revision_number, title, body, tags, editor, edited_time
+FROM thread_revisions
+WHERE thread_id = $1
+AND revision_number = $2;
+
+
+-- Returns the threads that match the keywords, tags and creator.
 -- If the keyword is provided, only threads that match all the keywords will be returned.
 -- If the tags are provided, only threads that match all the tags will be returned.
+-- If the canonical username of a creator is provided, only threads created by that user will be returned.
+-- If the slug of a category is provided, only threads in that category or its subcategories will be returned.
+-- Announcements and pinned threads are returned first, regardless of the sort order. Deleted threads are not returned.
 -- name: GetThreadsByCriteria :many
-SELECT t.id, t.title, t.body, t.creator, t.created_time, t.updated_time, t.num_comments,
+WITH RECURSIVE subcategories AS (
+    -- The category with the given slug and all of its subcategories.
+    SELECT c.id FROM categories c WHERE c.slug = @category::text
+    UNION
+    SELECT c.id FROM categories c JOIN subcategories s ON c.parent_id = s.id
+)
+SELECT t.id, t.title, t.body, t.creator, t.created_time, t.updated_time, t.num_comments, t.num_revisions,
+    t.is_locked, t.lock_reason, t.score, t.view_count, t.body_html, t.body_html_version, t.category_id,
     -- Concatenate all the tags of the thread into an array.
     CASE
        WHEN COUNT(tt.tag_name) > 0 THEN ARRAY_AGG(tt.tag_name ORDER BY tt.tag_name)
        ELSE '{}'::text[]
-    END AS tags
+    END AS tags,
+    -- Threads pinned everywhere or for one of the searched tags are pinned.
+    EXISTS (
+        SELECT 1 FROM thread_pins tp
+        WHERE tp.thread_id = t.id
+        AND (tp.tag_name = '' OR tp.tag_name = ANY(@tagArray::text[]))
+        AND (tp.expires_time IS NULL OR tp.expires_time > NOW())
+    ) AS is_pinned,
+    EXISTS (
+        SELECT 1 FROM thread_pins tp
+        WHERE tp.thread_id = t.id
+        AND tp.is_announcement
+        AND (tp.expires_time IS NULL OR tp.expires_time > NOW())
+    ) AS is_announcement,
+    -- The vote of the user viewing the thread, 0 if they have not voted or are not logged in.
+    COALESCE((
+        SELECT tv.value FROM thread_votes tv
+        WHERE tv.thread_id = t.id
+        AND tv.username = @viewer::text
+    ), 0)::integer AS my_vote,
+    EXISTS (
+        SELECT 1 FROM bookmarks b
+        WHERE b.thread_id = t.id
+        AND b.username = @viewer::text
+    ) AS is_bookmarked,
+    EXISTS (
+        SELECT 1 FROM thread_subscriptions ts
+        WHERE ts.thread_id = t.id
+        AND ts.username = @viewer::text
+        AND NOT ts.is_muted
+    ) AS is_subscribed
 FROM threads t
 LEFT JOIN thread_tags tt ON t.id = tt.thread_id
 WHERE
+    t.deleted_time IS NULL
+AND
     -- Handle the case where the keyword is empty (NULL).
     CASE
         WHEN LENGTH(@keywords::text) > 0 THEN TO_TSVECTOR('simple', t.title || ' ' || t.body) @@ TO_TSQUERY
@@ -156,20 +712,49 @@ AND
         )
         ELSE TRUE
     END
+AND
+    -- Handle the case where the creator is empty.
+    CASE
+        WHEN LENGTH(@creator::text) > 0 THEN t.creator = (
+            SELECT u.username FROM users u WHERE u.canonical_username = @creator::text
+        )
+        ELSE TRUE
+    END
+AND
+    -- Handle the case where the category is empty, otherwise include the threads of its subcategories.
+    CASE
+        WHEN LENGTH(@category::text) > 0 THEN t.category_id IN (SELECT s.id FROM subcategories s)
+        ELSE TRUE
+    END
 GROUP BY t.id
 ORDER BY
+    -- Announcements and pinned threads are always returned first.
+    is_announcement DESC,
+    is_pinned DESC,
     CASE WHEN @sortOrder::text = 'created_time_asc' THEN created_time END ASC,
     CASE WHEN @sortOrder::text = 'created_time_desc' THEN created_time END DESC,
     CASE WHEN @sortOrder::text = 'num_comments_asc' THEN num_comments END ASC,
-    CASE WHEN @sortOrder::text = 'num_comments_desc' THEN num_comments END DESC
+    CASE WHEN @sortOrder::text = 'num_comments_desc' THEN num_comments END DESC,
+    CASE WHEN @sortOrder::text = 'score_desc' THEN score END DESC,
+    CASE WHEN @sortOrder::text = 'hot_desc' THEN hot_score END DESC,
+    CASE WHEN @sortOrder::text = 'trending_desc' THEN trending_score END DESC,
+    CASE WHEN @sortOrder::text = 'views_desc' THEN view_count END DESC
 LIMIT $1
 OFFSET $2;
 
--- Counts the total number of threads that match the keywords and tags.
+-- Counts the total number of threads that match the keywords, tags, creator and category.
 -- name: GetThreadsByCriteriaCount :one
+WITH RECURSIVE subcategories AS (
+    -- The category with the given slug and all of its subcategories.
+    SELECT c.id FROM categories c WHERE c.slug = @category::text
+    UNION
+    SELECT c.id FROM categories c JOIN subcategories s ON c.parent_id = s.id
+)
 SELECT COUNT(*) AS total_items
 FROM threads t
 WHERE
+    t.deleted_time IS NULL
+  AND
     CASE
         WHEN LENGTH(@keywords::text) > 0 THEN TO_TSVECTOR('simple', t.title || ' ' || t.body) @@ TO_TSQUERY('simple', @keywords::text)
         ELSE TRUE
@@ -184,52 +769,1182 @@ WHERE
           HAVING COUNT(DISTINCT tt.tag_name) = ARRAY_LENGTH(@tagArray::text[], 1)
         )
         ELSE TRUE
+    END
+  AND
+    CASE
+        WHEN LENGTH(@creator::text) > 0 THEN t.creator = (
+            SELECT u.username FROM users u WHERE u.canonical_username = @creator::text
+        )
+        ELSE TRUE
+    END
+  AND
+    CASE
+        WHEN LENGTH(@category::text) > 0 THEN t.category_id IN (SELECT s.id FROM subcategories s)
+        ELSE TRUE
     END;
 
 
 -- Creates a new comment with the given body, creator, and thread_id. Returns the details of the created comment.
+-- No comment is created if the thread does not exist or has been deleted.
 -- name: CreateComment :one
-INSERT INTO comments (body, creator, thread_id)
-VALUES ($1, $2, $3)
-RETURNING id, body, creator, thread_id, created_time, updated_time;
+INSERT INTO comments (body, body_html, body_html_version, creator, thread_id)
+SELECT @body::text, @body_html::text, @body_html_version::integer, @creator::text, t.id
+FROM threads t
+WHERE t.id = @thread_id
+AND t.deleted_time IS NULL
+RETURNING id, body, body_html, body_html_version, creator, thread_id, created_time, updated_time, num_revisions,
+    deleted_time, deleted_by, score;
 
 
--- Get comments for a thread.
--- Sort order should be one of 'created_time_asc', 'created_time_desc'.
+-- Get comments for a thread. Deleted comments and comments of deleted threads are not returned.
+-- Sort order should be one of 'created_time_asc', 'created_time_desc', 'score_desc'.
 -- name: GetComments :many
-SELECT id, body, creator, thread_id, created_time, updated_time
-FROM comments
-WHERE thread_id = $1
+SELECT sqlc.embed(c),
+    -- The vote of the user viewing the comment, 0 if they have not voted or are not logged in.
+    COALESCE((
+        SELECT cv.value FROM comment_votes cv
+        WHERE cv.comment_id = c.id
+        AND cv.username = @viewer::text
+    ), 0)::integer AS my_vote
+FROM comments c
+JOIN threads t ON c.thread_id = t.id
+WHERE c.thread_id = $1
+AND c.deleted_time IS NULL
+AND t.deleted_time IS NULL
 ORDER BY
-    CASE WHEN @sortOrder::text = 'created_time_asc' THEN created_time END ASC,
-    CASE WHEN @sortOrder::text = 'created_time_desc' THEN created_time END DESC
+    CASE WHEN @sortOrder::text = 'created_time_asc' THEN c.created_time END ASC,
+    CASE WHEN @sortOrder::text = 'created_time_desc' THEN c.created_time END DESC,
+    CASE WHEN @sortOrder::text = 'score_desc' THEN c.score END DESC
 LIMIT $2
 OFFSET $3;
 
 
--- Counts the total number of comments for a thread.
+-- Counts the total number of comments for a thread that have not been deleted.
 -- name: GetCommentCount :one
 SELECT COUNT(*) AS total_items
-FROM comments
-WHERE thread_id = $1;
+FROM comments c
+JOIN threads t ON c.thread_id = t.id
+WHERE c.thread_id = $1
+AND c.deleted_time IS NULL
+AND t.deleted_time IS NULL;
 
--- Checks if a user is the creator of a comment.
+-- Checks if a user is the creator of a comment that has not been deleted.
 -- name: CheckCommentCreator :one
 SELECT EXISTS
-    (SELECT 1 FROM comments WHERE id = $1 AND creator = $2)
+    (SELECT 1 FROM comments WHERE id = $1 AND creator = $2 AND deleted_time IS NULL)
 AS is_comment_creator;
 
 
 -- Updates the comment with the given id.
 -- name: UpdateComment :exec
 UPDATE comments
-SET body = $1, updated_time = NOW()
+SET body = $1, body_html = $4, body_html_version = $5, updated_time = NOW()
 WHERE id = $2
 AND creator = $3;
 
 
--- Deletes the comment with the given id.
+-- Returns the comment with the given id. Deleted comments and comments of deleted threads are not returned.
+-- name: GetComment :one
+SELECT c.id, c.body, c.body_html, c.body_html_version, c.creator, c.thread_id, c.created_time, c.updated_time,
+    c.num_revisions, c.deleted_time, c.deleted_by, c.score
+FROM comments c
+JOIN threads t ON c.thread_id = t.id
+WHERE c.id = $1
+AND c.deleted_time IS NULL
+AND t.deleted_time IS NULL;
+
+
+-- Saves the current body of a comment as a revision before it is edited.
+-- Nothing is saved if the new body is the same as the current one.
+-- name: CreateCommentRevision :execrows
+INSERT INTO comment_revisions (comment_id, revision_number, body, editor)
+SELECT c.id, c.num_revisions + 1, c.body, @editor::text
+FROM comments c
+WHERE c.id = @id
+AND c.body <> @body::text;
+
+
+-- Returns the revisions of the comment with the given id, oldest first.
+-- name: GetCommentRevisions :many
+SELECT comment_id, revision_number, body, editor, edited_time
+FROM comment_revisions
+WHERE comment_id = $1
+ORDER BY revision_number ASC;
+
+
+-- Returns a single revision of the comment with the given id.
+-- name: GetCommentRevision :one
+SELECT comment_id, revision_number, body, editor, edited_time
+FROM comment_revisions
+WHERE comment_id = $1
+AND revision_number = $2;
+
+
+-- Soft deletes the comment with the given id. The comment is purged once it has been deleted for longer than the
+-- retention period.
 -- name: DeleteComment :exec
+UPDATE comments
+SET deleted_time = NOW(), deleted_by = creator
+WHERE id = $1
+AND creator = $2
+AND deleted_time IS NULL;
+
+
+-- Returns the creator and deletion details of the deleted comment with the given id.
+-- name: GetDeletedComment :one
+SELECT id, creator, deleted_time, deleted_by
+FROM comments
+WHERE id = $1
+AND deleted_time IS NOT NULL;
+
+
+-- Restores the deleted comment with the given id.
+-- name: RestoreComment :exec
+UPDATE comments
+SET deleted_time = NULL, deleted_by = NULL
+WHERE id = $1;
+
+
+-- Sets the vote of a user on a comment that has not been deleted, replacing their previous vote.
+-- name: SetCommentVote :one
+INSERT INTO comment_votes (comment_id, username, value)
+SELECT c.id, @username::text, @value::smallint
+FROM comments c
+WHERE c.id = @comment_id
+AND c.deleted_time IS NULL
+ON CONFLICT (comment_id, username) DO UPDATE
+SET value = EXCLUDED.value, updated_time = NOW()
+RETURNING value;
+
+
+-- Removes the vote of a user on a comment.
+-- name: DeleteCommentVote :exec
+DELETE FROM comment_votes
+WHERE comment_id = $1
+AND username = $2;
+
+
+-- Returns the score of the comment with the given id.
+-- name: GetCommentScore :one
+SELECT score
+FROM comments
+WHERE id = $1
+AND deleted_time IS NULL;
+
+
+-- Permanently deletes comments that were deleted more than the given number of days ago.
+-- name: PurgeDeletedComments :execrows
+DELETE FROM comments
+WHERE deleted_time < NOW() - MAKE_INTERVAL(days => @retention_days::integer);
+
+
+-- Returns the username of a user and the number of threads and comments they have created that have not been deleted.
+-- name: GetUserProfile :one
+SELECT u.username,
+    (SELECT COUNT(*) FROM threads t WHERE t.creator = u.username AND t.deleted_time IS NULL) AS num_threads,
+    (SELECT COUNT(*) FROM comments c JOIN threads t ON c.thread_id = t.id
+        WHERE c.creator = u.username AND c.deleted_time IS NULL AND t.deleted_time IS NULL) AS num_comments
+FROM users u
+WHERE u.canonical_username = $1;
+
+
+-- Get comments created by a user, together with the title of the thread they belong to.
+-- Deleted comments and comments of deleted threads are not returned.
+-- Sort order should be one of 'created_time_asc', 'created_time_desc', 'score_desc'.
+-- name: GetCommentsByCreator :many
+SELECT c.id, c.body, c.body_html, c.body_html_version, c.creator, c.thread_id, c.created_time, c.updated_time,
+    c.num_revisions, c.score,
+    COALESCE((
+        SELECT cv.value FROM comment_votes cv
+        WHERE cv.comment_id = c.id
+        AND cv.username = @viewer::text
+    ), 0)::integer AS my_vote,
+    t.title AS thread_title
+FROM comments c
+JOIN threads t ON c.thread_id = t.id
+WHERE c.creator = (SELECT u.username FROM users u WHERE u.canonical_username = @creator::text)
+AND c.deleted_time IS NULL
+AND t.deleted_time IS NULL
+ORDER BY
+    CASE WHEN @sortOrder::text = 'created_time_asc' THEN c.created_time END ASC,
+    CASE WHEN @sortOrder::text = 'created_time_desc' THEN c.created_time END DESC,
+    CASE WHEN @sortOrder::text = 'score_desc' THEN c.score END DESC
+LIMIT $1
+OFFSET $2;
+
+
+-- Counts the total number of comments created by a user that have not been deleted.
+-- name: GetCommentCountByCreator :one
+SELECT COUNT(*) AS total_items
+FROM comments c
+JOIN threads t ON c.thread_id = t.id
+WHERE c.creator = (SELECT u.username FROM users u WHERE u.canonical_username = $1)
+AND c.deleted_time IS NULL
+AND t.deleted_time IS NULL;
+
+
+-- Bookmarks a thread that has not been deleted for a user, or updates the folder and note of an existing bookmark.
+-- name: SetThreadBookmark :execrows
+INSERT INTO bookmarks (username, thread_id, folder, note)
+SELECT @username::text, t.id, @folder::text, @note::text
+FROM threads t
+WHERE t.id = @thread_id
+AND t.deleted_time IS NULL
+ON CONFLICT (username, thread_id) WHERE thread_id IS NOT NULL DO UPDATE
+SET folder = EXCLUDED.folder, note = EXCLUDED.note;
+
+
+-- Bookmarks a comment that has not been deleted for a user, or updates the folder and note of an existing bookmark.
+-- name: SetCommentBookmark :execrows
+INSERT INTO bookmarks (username, comment_id, folder, note)
+SELECT @username::text, c.id, @folder::text, @note::text
+FROM comments c
+JOIN threads t ON c.thread_id = t.id
+WHERE c.id = @comment_id
+AND c.deleted_time IS NULL
+AND t.deleted_time IS NULL
+ON CONFLICT (username, comment_id) WHERE comment_id IS NOT NULL DO UPDATE
+SET folder = EXCLUDED.folder, note = EXCLUDED.note;
+
+
+-- Removes the bookmark of a user on a thread.
+-- name: DeleteThreadBookmark :execrows
+DELETE FROM bookmarks
+WHERE thread_id = @thread_id
+AND username = @username::text;
+
+
+-- Removes the bookmark of a user on a comment.
+-- name: DeleteCommentBookmark :execrows
+DELETE FROM bookmarks
+WHERE comment_id = @comment_id
+AND username = @username::text;
+
+
+-- Get the bookmarks of a user, latest first, together with the thread or comment they point to.
+-- If a folder is provided, only bookmarks in that folder are returned.
+-- Bookmarks of deleted threads and comments are not returned, but are kept in case they are restored.
+-- name: GetBookmarks :many
+SELECT b.id, b.folder, b.note, b.created_time,
+    t.id AS thread_id, t.title AS thread_title,
+    c.id AS comment_id, c.body AS comment_body,
+    COALESCE(c.creator, t.creator)::text AS creator
+FROM bookmarks b
+LEFT JOIN comments c ON b.comment_id = c.id
+JOIN threads t ON t.id = COALESCE(b.thread_id, c.thread_id)
+WHERE b.username = @username::text
+AND (LENGTH(@folder::text) = 0 OR b.folder = @folder::text)
+AND t.deleted_time IS NULL
+AND c.deleted_time IS NULL
+ORDER BY b.created_time DESC
+LIMIT $1
+OFFSET $2;
+
+
+-- Counts the bookmarks of a user that would be returned by GetBookmarks.
+-- name: GetBookmarkCount :one
+SELECT COUNT(*) AS total_items
+FROM bookmarks b
+LEFT JOIN comments c ON b.comment_id = c.id
+JOIN threads t ON t.id = COALESCE(b.thread_id, c.thread_id)
+WHERE b.username = @username::text
+AND (LENGTH(@folder::text) = 0 OR b.folder = @folder::text)
+AND t.deleted_time IS NULL
+AND c.deleted_time IS NULL;
+
+
+-- Get the names of the folders that a user has bookmarks in.
+-- name: GetBookmarkFolders :many
+SELECT DISTINCT folder
+FROM bookmarks
+WHERE username = $1
+AND folder <> ''
+ORDER BY folder;
+
+
+-- Subscribes a user to a thread that has not been deleted, or mutes the thread for the user.
+-- name: SetThreadSubscription :execrows
+INSERT INTO thread_subscriptions (thread_id, username, is_muted)
+SELECT t.id, @username::text, @is_muted::boolean
+FROM threads t
+WHERE t.id = @thread_id
+AND t.deleted_time IS NULL
+ON CONFLICT (thread_id, username) DO UPDATE
+SET is_muted = EXCLUDED.is_muted;
+
+
+-- Removes the subscription of a user to a thread, including a mute.
+-- The user is subscribed again automatically if they comment on the thread.
+-- name: DeleteThreadSubscription :execrows
+DELETE FROM thread_subscriptions
+WHERE thread_id = @thread_id
+AND username = @username::text;
+
+
+-- Notifies the users subscribed to the thread of a new comment, except for the creator of the comment and users
+-- mentioned in it.
+-- Returns the number of users notified.
+-- name: CreateCommentNotifications :execrows
+INSERT INTO notifications (username, type, thread_id, comment_id, actor)
+SELECT ts.username, 'comment', c.thread_id, c.id, c.creator
+FROM comments c
+JOIN thread_subscriptions ts ON ts.thread_id = c.thread_id
+WHERE c.id = $1
+AND c.deleted_time IS NULL
+AND NOT ts.is_muted
+AND ts.username <> c.creator
+-- Users mentioned in the comment are notified of the mention instead
+AND NOT EXISTS (
+    SELECT 1 FROM comment_mentions cm
+    WHERE cm.comment_id = c.id
+    AND cm.username = ts.username
+);
+
+
+-- Get the notifications of a user, latest first, together with the title of the thread.
+-- If unread_only is true, only unread notifications are returned.
+-- Notifications about deleted threads and comments are not returned.
+-- name: GetNotifications :many
+SELECT n.id, n.type, n.thread_id, t.title AS thread_title, n.comment_id, n.actor, n.is_read, n.created_time
+FROM notifications n
+JOIN threads t ON n.thread_id = t.id
+LEFT JOIN comments c ON n.comment_id = c.id
+WHERE n.username = @username::text
+AND (NOT @unread_only::boolean OR NOT n.is_read)
+AND t.deleted_time IS NULL
+AND c.deleted_time IS NULL
+ORDER BY n.created_time DESC
+LIMIT $1
+OFFSET $2;
+
+
+-- Counts the notifications of a user that would be returned by GetNotifications.
+-- name: GetNotificationCount :one
+SELECT COUNT(*) AS total_items
+FROM notifications n
+JOIN threads t ON n.thread_id = t.id
+LEFT JOIN comments c ON n.comment_id = c.id
+WHERE n.username = @username::text
+AND (NOT @unread_only::boolean OR NOT n.is_read)
+AND t.deleted_time IS NULL
+AND c.deleted_time IS NULL;
+
+
+-- Marks a notification of a user as read.
+-- name: MarkNotificationRead :execrows
+UPDATE notifications
+SET is_read = TRUE
+WHERE id = $1
+AND username = @username::text;
+
+
+-- Marks all notifications of a user as read.
+-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications
+SET is_read = TRUE
+WHERE username = $1
+AND NOT is_read;
+
+
+-- Permanently deletes read notifications that are older than the retention period. Returns the number deleted.
+-- name: DeleteOldNotifications :execrows
+DELETE FROM notifications
+WHERE is_read
+AND created_time < NOW() - MAKE_INTERVAL(days => @retention_days::integer);
+
+
+-- Saves a draft of a user, replacing any previous draft for the same context. Returns the saved draft.
+-- name: SaveDraft :one
+INSERT INTO drafts (username, context, title, body, tags)
+VALUES (@username::text, @context::text, @title::text, @body::text, @tagArray::text[])
+ON CONFLICT (username, context) DO UPDATE
+SET title = EXCLUDED.title, body = EXCLUDED.body, tags = EXCLUDED.tags, updated_time = NOW()
+RETURNING context, title, body, tags, created_time, updated_time;
+
+
+-- Get the drafts of a user, most recently updated first.
+-- name: GetDrafts :many
+SELECT context, title, body, tags, created_time, updated_time
+FROM drafts
+WHERE username = $1
+ORDER BY updated_time DESC;
+
+
+-- Get the draft of a user for the given context.
+-- name: GetDraft :one
+SELECT context, title, body, tags, created_time, updated_time
+FROM drafts
+WHERE username = @username::text
+AND context = @context::text;
+
+
+-- Deletes the draft of a user for the given context.
+-- name: DeleteDraft :execrows
+DELETE FROM drafts
+WHERE username = @username::text
+AND context = @context::text;
+
+
+-- Deletes drafts that have not been updated for longer than the expiry period. Returns the number deleted.
+-- name: DeleteExpiredDrafts :execrows
+DELETE FROM drafts
+WHERE updated_time < NOW() - MAKE_INTERVAL(days => @expiry_days::integer);
+
+
+-- Schedules a thread to be published at the given time. Returns the scheduled thread.
+-- name: CreateScheduledThread :one
+INSERT INTO scheduled_threads (title, body, creator, tags, poll, attachment_ids, category_id, publish_time)
+VALUES (@title::text, @body::text, @creator::text, @tagArray::text[], sqlc.narg(poll)::jsonb, @attachment_ids::uuid[],
+    @category_id::uuid, @publish_time::timestamptz)
+RETURNING id, title, body, creator, tags, poll, attachment_ids, category_id, publish_time, created_time;
+
+
+-- Get the threads scheduled by a user, earliest publish time first.
+-- name: GetScheduledThreads :many
+SELECT id, title, body, creator, tags, poll, attachment_ids, category_id, publish_time, created_time
+FROM scheduled_threads
+WHERE creator = $1
+ORDER BY publish_time;
+
+
+-- Get the poll of a thread scheduled by a user, as JSON.
+-- name: GetScheduledThreadPoll :one
+SELECT poll
+FROM scheduled_threads
+WHERE id = $1
+AND creator = @creator::text;
+
+
+-- Changes the publish time of a thread scheduled by a user. Returns the scheduled thread.
+-- name: RescheduleThread :one
+UPDATE scheduled_threads
+SET publish_time = @publish_time::timestamptz
+WHERE id = $1
+AND creator = @creator::text
+RETURNING id, title, body, creator, tags, poll, attachment_ids, category_id, publish_time, created_time;
+
+
+-- Cancels a thread scheduled by a user.
+-- name: DeleteScheduledThread :execrows
+DELETE FROM scheduled_threads
+WHERE id = $1
+AND creator = @creator::text;
+
+
+-- Get scheduled threads whose publish time has passed, earliest first.
+-- name: GetDueScheduledThreads :many
+SELECT id, title, body, creator, tags, poll, attachment_ids, category_id, publish_time, created_time
+FROM scheduled_threads
+WHERE publish_time <= NOW()
+ORDER BY publish_time
+LIMIT $1;
+
+
+-- Publishes a scheduled thread whose publish time has passed, keeping its ID.
+-- The thread is created at its publish time, which is not in the future. Returns the ID of the thread.
+-- name: PublishScheduledThread :one
+WITH due_thread AS (
+    DELETE FROM scheduled_threads st
+    WHERE st.id = $1
+    AND st.publish_time <= NOW()
+    RETURNING st.id, st.title, st.body, st.creator, st.category_id, st.publish_time
+)
+INSERT INTO threads (id, title, body, body_html, body_html_version, body_simhash, creator, category_id, created_time,
+    updated_time)
+SELECT dt.id, dt.title, dt.body, @body_html::text, @body_html_version::integer, @body_simhash::bigint, dt.creator,
+    dt.category_id, dt.publish_time, dt.publish_time
+FROM due_thread dt
+RETURNING threads.id;
+
+
+-- Creates the poll of a thread, or replaces its settings if it already has one.
+-- name: SetPoll :exec
+INSERT INTO polls (thread_id, is_multiple_choice, is_anonymous, close_time)
+VALUES (@thread_id, @is_multiple_choice::boolean, @is_anonymous::boolean, sqlc.narg(close_time)::timestamptz)
+ON CONFLICT (thread_id) DO UPDATE
+SET is_multiple_choice = EXCLUDED.is_multiple_choice,
+    is_anonymous = EXCLUDED.is_anonymous,
+    close_time = EXCLUDED.close_time;
+
+
+-- Removes the options of a poll. Fails if any of the options have been voted for.
+-- name: DeletePollOptions :exec
+DELETE FROM poll_options
+WHERE thread_id = $1;
+
+
+-- Adds options to a poll, numbered from 1 in the given order.
+-- name: AddPollOptions :exec
+INSERT INTO poll_options (thread_id, position, text)
+SELECT @thread_id, o.position, o.text
+FROM UNNEST(@options::text[]) WITH ORDINALITY AS o(text, position);
+
+
+-- Gets the poll of a thread, locking it until the end of the transaction so that votes and changes to the poll
+-- are applied one at a time.
+-- name: GetPollForUpdate :one
+SELECT p.is_multiple_choice,
+    (p.close_time IS NOT NULL AND p.close_time <= NOW())::boolean AS is_closed,
+    (SELECT COUNT(*) FROM poll_options po WHERE po.thread_id = p.thread_id)::integer AS num_options,
+    EXISTS (SELECT 1 FROM poll_votes pv WHERE pv.thread_id = p.thread_id) AS has_votes
+FROM polls p
+JOIN threads t ON t.id = p.thread_id
+WHERE p.thread_id = $1
+AND t.deleted_time IS NULL
+FOR UPDATE OF p;
+
+
+-- Gets the poll of a thread.
+-- name: GetPoll :one
+SELECT p.is_multiple_choice, p.is_anonymous, p.close_time,
+    (p.close_time IS NOT NULL AND p.close_time <= NOW())::boolean AS is_closed,
+    (SELECT COUNT(DISTINCT pv.username) FROM poll_votes pv WHERE pv.thread_id = p.thread_id)::integer AS num_voters
+FROM polls p
+JOIN threads t ON t.id = p.thread_id
+WHERE p.thread_id = $1
+AND t.deleted_time IS NULL;
+
+
+-- Gets the options of a poll with their votes. Voters are listed in the order they voted.
+-- name: GetPollOptions :many
+SELECT po.position, po.text,
+    COUNT(pv.username)::integer AS num_votes,
+    COALESCE(ARRAY_AGG(pv.username ORDER BY pv.created_time, pv.username)
+        FILTER (WHERE pv.username IS NOT NULL), '{}')::text[] AS voters,
+    -- Whether the user viewing the poll voted for the option, false if they are not logged in.
+    COALESCE(BOOL_OR(pv.username = @viewer::text), FALSE)::boolean AS is_my_vote
+FROM poll_options po
+LEFT JOIN poll_votes pv ON pv.thread_id = po.thread_id AND pv.position = po.position
+WHERE po.thread_id = @thread_id
+GROUP BY po.thread_id, po.position
+ORDER BY po.position;
+
+
+-- Removes the votes of a user on a poll.
+-- name: DeletePollVotes :execrows
+DELETE FROM poll_votes
+WHERE thread_id = $1
+AND username = $2;
+
+
+-- Adds the votes of a user on a poll.
+-- name: AddPollVotes :exec
+INSERT INTO poll_votes (thread_id, position, username)
+SELECT @thread_id, UNNEST(@positions::integer[]), @username::text;
+
+
+-- Get threads whose cached HTML was rendered by a different version of the renderer, including deleted threads.
+-- name: GetStaleThreadBodies :many
+SELECT id, body
+FROM threads
+WHERE body_html_version <> @body_html_version::integer
+LIMIT $1;
+
+
+-- Caches the HTML of the body of a thread, unless the body has changed since it was rendered.
+-- name: SetThreadBodyHtml :exec
+UPDATE threads
+SET body_html = @body_html::text, body_html_version = @body_html_version::integer
+WHERE id = @id
+AND body = @body::text;
+
+
+-- Get comments whose cached HTML was rendered by a different version of the renderer, including deleted comments.
+-- name: GetStaleCommentBodies :many
+SELECT id, body
+FROM comments
+WHERE body_html_version <> @body_html_version::integer
+LIMIT $1;
+
+
+-- Caches the HTML of the body of a comment, unless the body has changed since it was rendered.
+-- name: SetCommentBodyHtml :exec
+UPDATE comments
+SET body_html = @body_html::text, body_html_version = @body_html_version::integer
+WHERE id = @id
+AND body = @body::text;
+
+
+-- Adds a file to the blob store index, or marks it as used again if it is already stored.
+-- name: CreateBlob :exec
+INSERT INTO blobs (hash, size, content_type)
+VALUES (@hash::text, @size::integer, @content_type::text)
+ON CONFLICT (hash) DO UPDATE
+SET last_used_time = NOW();
+
+
+-- Creates an attachment that is not attached to a thread or comment yet. Returns the ID and creation time.
+-- name: CreateAttachment :one
+INSERT INTO attachments (uploader, filename, blob_hash, thumbnail_hash, width, height)
+VALUES (@uploader::text, @filename::text, @blob_hash::text, sqlc.narg(thumbnail_hash)::text,
+    sqlc.narg(width)::integer, sqlc.narg(height)::integer)
+RETURNING id, created_time;
+
+
+-- Gets an attachment with the hashes of its files. Attachments of deleted threads and comments are not returned.
+-- name: GetAttachment :one
+SELECT a.id, a.filename, a.blob_hash, b.content_type, b.size, a.thumbnail_hash,
+    tb.content_type AS thumbnail_content_type, tb.size AS thumbnail_size
+FROM attachments a
+JOIN blobs b ON b.hash = a.blob_hash
+LEFT JOIN blobs tb ON tb.hash = a.thumbnail_hash
+LEFT JOIN threads t ON t.id = a.thread_id
+LEFT JOIN comments c ON c.id = a.comment_id
+WHERE a.id = $1
+AND t.deleted_time IS NULL
+AND c.deleted_time IS NULL;
+
+
+-- Get the attachments of a thread and of the given comments, in the order they were uploaded.
+-- name: GetAttachments :many
+SELECT a.id, a.filename, b.content_type, b.size, a.width, a.height,
+    (a.thumbnail_hash IS NOT NULL)::boolean AS has_thumbnail, a.thread_id, a.comment_id, a.created_time
+FROM attachments a
+JOIN blobs b ON b.hash = a.blob_hash
+WHERE a.thread_id = sqlc.narg(thread_id)
+OR a.comment_id = ANY(@comment_ids::uuid[])
+ORDER BY a.created_time, a.id;
+
+
+-- Counts the given attachments that were uploaded by a user and can still be attached, which are those not attached
+-- to a thread, a comment or a scheduled thread.
+-- name: CountAttachableAttachments :one
+SELECT COUNT(*)::integer
+FROM attachments a
+WHERE a.id = ANY(@attachment_ids::uuid[])
+AND a.uploader = @uploader::text
+AND a.thread_id IS NULL
+AND a.comment_id IS NULL
+AND NOT EXISTS (SELECT 1 FROM scheduled_threads st WHERE a.id = ANY(st.attachment_ids));
+
+
+-- Attaches attachments uploaded by a user to a thread, skipping any that are already attached.
+-- Returns the number attached.
+-- name: AttachToThread :execrows
+UPDATE attachments
+SET thread_id = @thread_id
+WHERE id = ANY(@attachment_ids::uuid[])
+AND uploader = @uploader::text
+AND thread_id IS NULL
+AND comment_id IS NULL;
+
+
+-- Attaches attachments uploaded by a user to a comment, skipping any that are already attached.
+-- Returns the number attached.
+-- name: AttachToComment :execrows
+UPDATE attachments
+SET comment_id = @comment_id
+WHERE id = ANY(@attachment_ids::uuid[])
+AND uploader = @uploader::text
+AND thread_id IS NULL
+AND comment_id IS NULL;
+
+
+-- Deletes attachments that were not attached to a thread, a comment or a scheduled thread within the time limit.
+-- Returns the number deleted.
+-- name: DeleteOrphanedAttachments :execrows
+DELETE FROM attachments a
+WHERE a.thread_id IS NULL
+AND a.comment_id IS NULL
+AND a.created_time < NOW() - MAKE_INTERVAL(hours => @orphan_hours::integer)
+AND NOT EXISTS (SELECT 1 FROM scheduled_threads st WHERE a.id = ANY(st.attachment_ids));
+
+
+-- Deletes blobs that are not used by any attachment and have not been uploaded again within the grace period, so that
+-- blobs of uploads in progress are kept. Returns the hashes of the deleted blobs.
+-- name: DeleteUnusedBlobs :many
+DELETE FROM blobs b
+WHERE b.last_used_time < NOW() - MAKE_INTERVAL(hours => @grace_hours::integer)
+AND NOT EXISTS (SELECT 1 FROM attachments a WHERE a.blob_hash = b.hash OR a.thumbnail_hash = b.hash)
+RETURNING b.hash;
+
+
+-- Records the users mentioned in the current version of a thread, out of the given canonical usernames, leaving out
+-- users that do not exist and the creator of the thread. Usernames of renamed users are resolved as in
+-- GetMentionedUsers. Users no longer mentioned are kept but are no longer current.
+-- Users mentioned in the thread for the first time are notified. Returns the number of users notified.
+-- name: SetThreadMentions :execrows
+WITH mentioned_users AS (
+    SELECT u.username
+    FROM users u
+    JOIN threads t ON t.id = @thread_id
+    WHERE (u.canonical_username = ANY(@canonical_usernames::text[])
+        OR u.username IN (
+            SELECT DISTINCT ON (h.old_canonical_username) h.username
+            FROM username_history h
+            WHERE h.old_canonical_username = ANY(@canonical_usernames::text[])
+            AND NOT EXISTS (SELECT 1 FROM users ou WHERE ou.canonical_username = h.old_canonical_username)
+            ORDER BY h.old_canonical_username, h.changed_time DESC
+        ))
+    AND u.username <> t.creator
+),
+updated_mentions AS (
+    UPDATE thread_mentions tm
+    SET is_current = tm.username IN (SELECT mu.username FROM mentioned_users mu)
+    WHERE tm.thread_id = @thread_id
+),
+new_mentions AS (
+    INSERT INTO thread_mentions (thread_id, username)
+    SELECT @thread_id, mu.username
+    FROM mentioned_users mu
+    WHERE NOT EXISTS (
+        SELECT 1 FROM thread_mentions tm
+        WHERE tm.thread_id = @thread_id
+        AND tm.username = mu.username
+    )
+    ON CONFLICT (thread_id, username) DO NOTHING
+    RETURNING username
+)
+INSERT INTO notifications (username, type, thread_id, actor)
+SELECT nm.username, 'mention', t.id, t.creator
+FROM new_mentions nm
+JOIN threads t ON t.id = @thread_id;
+
+
+-- Records the users mentioned in the current version of a comment in the same way as SetThreadMentions.
+-- Returns the number of users notified.
+-- name: SetCommentMentions :execrows
+WITH mentioned_users AS (
+    SELECT u.username
+    FROM users u
+    JOIN comments c ON c.id = @comment_id
+    WHERE (u.canonical_username = ANY(@canonical_usernames::text[])
+        OR u.username IN (
+            SELECT DISTINCT ON (h.old_canonical_username) h.username
+            FROM username_history h
+            WHERE h.old_canonical_username = ANY(@canonical_usernames::text[])
+            AND NOT EXISTS (SELECT 1 FROM users ou WHERE ou.canonical_username = h.old_canonical_username)
+            ORDER BY h.old_canonical_username, h.changed_time DESC
+        ))
+    AND u.username <> c.creator
+),
+updated_mentions AS (
+    UPDATE comment_mentions cm
+    SET is_current = cm.username IN (SELECT mu.username FROM mentioned_users mu)
+    WHERE cm.comment_id = @comment_id
+),
+new_mentions AS (
+    INSERT INTO comment_mentions (comment_id, username)
+    SELECT @comment_id, mu.username
+    FROM mentioned_users mu
+    WHERE NOT EXISTS (
+        SELECT 1 FROM comment_mentions cm
+        WHERE cm.comment_id = @comment_id
+        AND cm.username = mu.username
+    )
+    ON CONFLICT (comment_id, username) DO NOTHING
+    RETURNING username
+)
+INSERT INTO notifications (username, type, thread_id, comment_id, actor)
+SELECT nm.username, 'mention', c.thread_id, c.id, c.creator
+FROM new_mentions nm
+JOIN comments c ON c.id = @comment_id;
+
+
+-- Locks the given threads until the end of the transaction so that they do not change while comments are moved
+-- between them. Threads are locked in order of their ids to avoid deadlocks. Deleted threads are not returned.
+-- name: LockThreadsForMove :many
+SELECT id, title
+FROM threads
+WHERE id = ANY(@thread_ids::uuid[])
+AND deleted_time IS NULL
+ORDER BY id
+FOR UPDATE;
+
+
+-- Returns the number of the given comments that are on the given thread and have not been deleted.
+-- name: CountMovableComments :one
+SELECT COUNT(*)::integer
+FROM comments
+WHERE id = ANY(@comment_ids::uuid[])
+AND thread_id = @thread_id
+AND deleted_time IS NULL;
+
+
+-- Turns the opening post of a thread into a comment on the thread it is merged into, together with its votes,
+-- revisions, attachments and mentions. The comment keeps the creator and creation time of the opening post.
+-- Returns the id of the comment.
+-- name: MoveThreadPostToComment :one
+WITH new_comment AS (
+    INSERT INTO comments (body, body_html, body_html_version, creator, thread_id, created_time, updated_time)
+    SELECT t.body, t.body_html, t.body_html_version, t.creator, @target_thread_id::uuid, t.created_time, t.updated_time
+    FROM threads t
+    WHERE t.id = @thread_id::uuid
+    RETURNING id
+),
+moved_votes AS (
+    INSERT INTO comment_votes (comment_id, username, value, created_time, updated_time)
+    SELECT nc.id, tv.username, tv.value, tv.created_time, tv.updated_time
+    FROM thread_votes tv, new_comment nc
+    WHERE tv.thread_id = @thread_id::uuid
+),
+moved_revisions AS (
+    INSERT INTO comment_revisions (comment_id, revision_number, body, editor, edited_time)
+    SELECT nc.id, tr.revision_number, tr.body, tr.editor, tr.edited_time
+    FROM thread_revisions tr, new_comment nc
+    WHERE tr.thread_id = @thread_id::uuid
+),
+moved_attachments AS (
+    UPDATE attachments a
+    SET thread_id = NULL, comment_id = nc.id
+    FROM new_comment nc
+    WHERE a.thread_id = @thread_id::uuid
+),
+moved_mentions AS (
+    INSERT INTO comment_mentions (comment_id, username, is_current, created_time)
+    SELECT nc.id, tm.username, tm.is_current, tm.created_time
+    FROM thread_mentions tm, new_comment nc
+    WHERE tm.thread_id = @thread_id::uuid
+)
+SELECT id FROM new_comment;
+
+
+-- Moves the subscriptions, bookmarks and notifications of a thread to the thread it is merged into. Users who are
+-- subscribed to or have bookmarked both threads keep their subscription or bookmark of the thread merged into.
+-- Notifications about the opening post of the merged thread are moved to the comment it was turned into.
+-- name: MoveThreadFollowers :exec
+WITH moved_subscriptions AS (
+    INSERT INTO thread_subscriptions (thread_id, username, is_muted, created_time)
+    SELECT @target_thread_id::uuid, ts.username, ts.is_muted, ts.created_time
+    FROM thread_subscriptions ts
+    WHERE ts.thread_id = @thread_id::uuid
+    ON CONFLICT (thread_id, username) DO NOTHING
+),
+moved_bookmarks AS (
+    INSERT INTO bookmarks (username, thread_id, folder, note, created_time)
+    SELECT b.username, @target_thread_id::uuid, b.folder, b.note, b.created_time
+    FROM bookmarks b
+    WHERE b.thread_id = @thread_id::uuid
+    ON CONFLICT DO NOTHING
+)
+UPDATE notifications
+SET thread_id = @target_thread_id::uuid, comment_id = COALESCE(comment_id, @comment_id::uuid)
+WHERE thread_id = @thread_id::uuid;
+
+
+-- Moves comments of a thread to another thread, or all of its comments if no comment ids are given. Notifications
+-- about the comments are moved with them and their creators are subscribed to the other thread unless they have
+-- muted it. The number of comments of both threads is updated by the comment trigger.
+-- Returns the number of comments moved.
+-- name: MoveComments :one
+WITH moved_comments AS (
+    UPDATE comments c
+    SET thread_id = @target_thread_id::uuid
+    WHERE c.thread_id = @thread_id::uuid
+    AND (sqlc.narg(comment_ids)::uuid[] IS NULL OR c.id = ANY(sqlc.narg(comment_ids)::uuid[]))
+    RETURNING c.id, c.creator
+),
+moved_notifications AS (
+    UPDATE notifications n
+    SET thread_id = @target_thread_id::uuid
+    FROM moved_comments mc
+    WHERE n.comment_id = mc.id
+),
+new_subscriptions AS (
+    INSERT INTO thread_subscriptions (thread_id, username)
+    SELECT DISTINCT @target_thread_id::uuid, mc.creator
+    FROM moved_comments mc
+    ON CONFLICT (thread_id, username) DO NOTHING
+)
+SELECT COUNT(*)::integer FROM moved_comments;
+
+
+-- Turns the earliest of the given comments into a new thread with the given title when comments are split out of a
+-- thread, together with its votes, revisions, attachments, mentions, bookmarks and notifications. The thread keeps the
+-- creator and creation time of the comment. The comment itself is left to be deleted with DeleteMovedComment.
+-- Returns the ids of the thread and of the comment.
+-- name: MoveCommentToThread :one
+WITH first_comment AS (
+    SELECT c.id, c.body, c.body_html, c.body_html_version, c.creator, c.created_time, c.updated_time
+    FROM comments c
+    WHERE c.id = ANY(@comment_ids::uuid[])
+    AND c.thread_id = @thread_id::uuid
+    AND c.deleted_time IS NULL
+    ORDER BY c.created_time, c.id
+    LIMIT 1
+),
+new_thread AS (
+    INSERT INTO threads (title, body, body_html, body_html_version, creator, category_id, created_time, updated_time)
+    SELECT @title::text, fc.body, fc.body_html, fc.body_html_version, fc.creator,
+        (SELECT t.category_id FROM threads t WHERE t.id = @thread_id::uuid), fc.created_time, fc.updated_time
+    FROM first_comment fc
+    RETURNING id
+),
+moved_votes AS (
+    INSERT INTO thread_votes (thread_id, username, value, created_time, updated_time)
+    SELECT nt.id, cv.username, cv.value, cv.created_time, cv.updated_time
+    FROM comment_votes cv, first_comment fc, new_thread nt
+    WHERE cv.comment_id = fc.id
+),
+moved_revisions AS (
+    INSERT INTO thread_revisions (thread_id, revision_number, title, body, editor, edited_time)
+    SELECT nt.id, cr.revision_number, @title::text, cr.body, cr.editor, cr.edited_time
+    FROM comment_revisions cr, first_comment fc, new_thread nt
+    WHERE cr.comment_id = fc.id
+),
+moved_attachments AS (
+    UPDATE attachments a
+    SET thread_id = nt.id, comment_id = NULL
+    FROM first_comment fc, new_thread nt
+    WHERE a.comment_id = fc.id
+),
+moved_mentions AS (
+    INSERT INTO thread_mentions (thread_id, username, is_current, created_time)
+    SELECT nt.id, cm.username, cm.is_current, cm.created_time
+    FROM comment_mentions cm, first_comment fc, new_thread nt
+    WHERE cm.comment_id = fc.id
+),
+moved_bookmarks AS (
+    UPDATE bookmarks b
+    SET thread_id = nt.id, comment_id = NULL
+    FROM first_comment fc, new_thread nt
+    WHERE b.comment_id = fc.id
+),
+moved_notifications AS (
+    UPDATE notifications n
+    SET thread_id = nt.id, comment_id = NULL
+    FROM first_comment fc, new_thread nt
+    WHERE n.comment_id = fc.id
+)
+SELECT nt.id AS thread_id, fc.id AS comment_id
+FROM new_thread nt, first_comment fc;
+
+
+-- Permanently deletes a comment that has been turned into a thread.
+-- name: DeleteMovedComment :exec
 DELETE FROM comments
+WHERE id = $1;
+
+
+-- Adds the tags and pins of a thread to the thread it is merged into. If both threads are pinned for the same tag,
+-- the pin of the thread merged into is kept.
+-- name: MoveThreadTagsAndPins :exec
+WITH moved_tags AS (
+    INSERT INTO thread_tags (thread_id, tag_name)
+    SELECT @target_thread_id::uuid, tt.tag_name
+    FROM thread_tags tt
+    WHERE tt.thread_id = @thread_id::uuid
+    ON CONFLICT (thread_id, tag_name) DO NOTHING
+)
+INSERT INTO thread_pins (thread_id, tag_name, is_announcement, pinned_by, pinned_time, expires_time)
+SELECT @target_thread_id::uuid, tp.tag_name, tp.is_announcement, tp.pinned_by, tp.pinned_time, tp.expires_time
+FROM thread_pins tp
+WHERE tp.thread_id = @thread_id::uuid
+ON CONFLICT (thread_id, tag_name) DO NOTHING;
+
+
+-- Returns the ids of the threads with polls out of the given threads.
+-- name: GetThreadsWithPolls :many
+SELECT thread_id
+FROM polls
+WHERE thread_id = ANY(@thread_ids::uuid[]);
+
+
+-- Moves the poll of a thread, with its options and votes, to the thread it is merged into, which must not have a poll.
+-- Does nothing if the thread has no poll. The poll of the thread is deleted together with the thread.
+-- name: MovePoll :exec
+WITH moved_poll AS (
+    INSERT INTO polls (thread_id, is_multiple_choice, is_anonymous, close_time, created_time)
+    SELECT @target_thread_id::uuid, p.is_multiple_choice, p.is_anonymous, p.close_time, p.created_time
+    FROM polls p
+    WHERE p.thread_id = @thread_id::uuid
+    RETURNING thread_id
+),
+moved_options AS (
+    INSERT INTO poll_options (thread_id, position, text)
+    SELECT mp.thread_id, po.position, po.text
+    FROM poll_options po
+    CROSS JOIN moved_poll mp
+    WHERE po.thread_id = @thread_id::uuid
+)
+INSERT INTO poll_votes (thread_id, position, username, created_time)
+SELECT mp.thread_id, pv.position, pv.username, pv.created_time
+FROM poll_votes pv
+CROSS JOIN moved_poll mp
+WHERE pv.thread_id = @thread_id::uuid;
+
+
+-- Leaves a redirect from a thread that is merged into another thread, and updates redirects to the merged thread to
+-- point to the thread it is merged into.
+-- name: CreateThreadRedirect :exec
+WITH updated_redirects AS (
+    UPDATE thread_redirects
+    SET target_thread_id = @target_thread_id::uuid
+    WHERE target_thread_id = @thread_id::uuid
+)
+INSERT INTO thread_redirects (thread_id, target_thread_id, title, created_by)
+VALUES (@thread_id::uuid, @target_thread_id::uuid, @title::text, @created_by::text);
+
+
+-- Moves a thread to another category. Deleted threads are not moved. Returns the id of the thread.
+-- name: MoveThreadToCategory :one
+UPDATE threads
+SET category_id = @category_id::uuid
+WHERE id = @id::uuid
+AND deleted_time IS NULL
+RETURNING id;
+
+
+-- Permanently deletes a thread that has been merged into another thread.
+-- name: DeleteMergedThread :exec
+DELETE FROM threads
+WHERE id = $1;
+
+
+-- Returns the id of the thread that the thread with the given id was merged into.
+-- name: GetThreadRedirect :one
+SELECT target_thread_id
+FROM thread_redirects
+WHERE thread_id = $1;
+
+
+-- Returns the threads most related to the given thread, ranked by the number of tags they share with it and by the
+-- full-text similarity of their titles and bodies to its title. Any word of the title can match, and a shared tag
+-- counts as much as a text rank of 0.1. Deleted threads and the thread itself are not returned.
+-- name: GetRelatedThreads :many
+WITH source AS (
+    SELECT t.id,
+        TO_TSQUERY('simple', REPLACE(PLAINTO_TSQUERY('simple', t.title)::text, ' & ', ' | ')) AS query
+    FROM threads t
+    WHERE t.id = @thread_id
+    AND t.deleted_time IS NULL
+),
+candidates AS (
+    SELECT t.id,
+        (
+            SELECT COUNT(*)
+            FROM thread_tags tt
+            JOIN thread_tags stt ON stt.tag_name = tt.tag_name AND stt.thread_id = s.id
+            WHERE tt.thread_id = t.id
+        ) AS shared_tags,
+        TS_RANK(TO_TSVECTOR('simple', t.title || ' ' || t.body), s.query) AS text_rank
+    FROM threads t, source s
+    WHERE t.id <> s.id
+    AND t.deleted_time IS NULL
+)
+SELECT t.id, t.title, t.creator, t.created_time, t.num_comments, t.score,
+    ARRAY(
+        SELECT tt.tag_name
+        FROM thread_tags tt
+        WHERE tt.thread_id = t.id
+        ORDER BY tt.tag_name
+    )::text[] AS tags
+FROM candidates c
+JOIN threads t ON t.id = c.id
+WHERE c.shared_tags > 0
+OR c.text_rank > 0
+ORDER BY c.shared_tags + c.text_rank * 10 DESC, t.score DESC, t.created_time DESC
+LIMIT @max_threads::integer;
+
+
+-- Returns threads that are likely duplicates of a new thread with the given title and body fingerprint. Threads are
+-- returned if their titles are similar, with a trigram similarity of at least pg_trgm.similarity_threshold (0.3 by
+-- default), or if their body fingerprints differ in at most the given number of bits. Near-duplicate bodies are
+-- returned first, then the most similar titles. Deleted threads are not returned.
+-- name: GetSimilarThreads :many
+WITH candidates AS (
+    SELECT t.id
+    FROM threads t
+    WHERE t.title % @title::text
+    AND t.deleted_time IS NULL
+    UNION
+    SELECT t.id
+    FROM threads t
+    WHERE LENGTH(REPLACE((t.body_simhash # @body_simhash::bigint)::bit(64)::text, '0', '')) <= @max_distance::integer
+    AND t.deleted_time IS NULL
+)
+SELECT t.id, t.title, t.creator, t.created_time, t.num_comments,
+    SIMILARITY(t.title, @title::text)::real AS title_similarity,
+    COALESCE(
+        LENGTH(REPLACE((t.body_simhash # @body_simhash::bigint)::bit(64)::text, '0', '')) <= @max_distance::integer,
+        FALSE
+    )::boolean AS is_near_duplicate
+FROM candidates c
+JOIN threads t ON t.id = c.id
+ORDER BY is_near_duplicate DESC, title_similarity DESC, t.created_time DESC
+LIMIT @max_threads::integer;
+
+
+-- Returns 1 if the user has created a thread with the same title and body within the last given number of hours.
+-- Titles are compared case-insensitively, and whitespace is ignored at the ends and collapsed elsewhere.
+-- name: CheckRepost :one
+SELECT EXISTS (
+    SELECT 1 FROM threads t
+    WHERE t.creator = @creator::text
+    AND LOWER(REGEXP_REPLACE(TRIM(t.title), '\s+', ' ', 'g')) = LOWER(REGEXP_REPLACE(TRIM(@title::text), '\s+', ' ', 'g'))
+    AND REGEXP_REPLACE(TRIM(t.body), '\s+', ' ', 'g') = REGEXP_REPLACE(TRIM(@body::text), '\s+', ' ', 'g')
+    AND t.created_time > NOW() - MAKE_INTERVAL(hours => @window_hours::integer)
+    AND t.deleted_time IS NULL
+) AS is_repost;
+
+
+-- Returns threads whose bodies have not been fingerprinted yet.
+-- name: GetUnfingerprintedThreads :many
+SELECT id, body
+FROM threads
+WHERE body_simhash IS NULL
+LIMIT $1;
+
+
+-- Sets the fingerprint of the body of a thread, unless the body has been changed since it was read.
+-- name: SetThreadFingerprint :exec
+UPDATE threads
+SET body_simhash = @body_simhash::bigint
+WHERE id = @id
+AND body = @body::text;
+
+
+-- Returns all categories, ordered by their position and then by name.
+-- name: GetCategories :many
+SELECT id, name, slug, description, parent_id, position, posting_role, num_threads, created_time
+FROM categories
+ORDER BY position, name;
+
+
+-- Returns the category with the given id.
+-- name: GetCategory :one
+SELECT id, name, slug, description, parent_id, position, posting_role, num_threads, created_time
+FROM categories
+WHERE id = $1;
+
+
+-- Creates a new category. Returns the details of the created category.
+-- name: CreateCategory :one
+INSERT INTO categories (name, slug, description, parent_id, position, posting_role)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, name, slug, description, parent_id, position, posting_role, num_threads, created_time;
+
+
+-- Updates the category with the given id. Returns the details of the updated category.
+-- name: UpdateCategory :one
+UPDATE categories
+SET name = $2,
+    slug = $3,
+    description = $4,
+    parent_id = $5,
+    position = $6,
+    posting_role = $7
 WHERE id = $1
-AND creator = $2;
+RETURNING id, name, slug, description, parent_id, position, posting_role, num_threads, created_time;
+
+
+-- Returns 1 if the category with the given id is the given ancestor or one of its subcategories.
+-- name: IsCategoryDescendant :one
+WITH RECURSIVE ancestors AS (
+    SELECT c.id, c.parent_id FROM categories c WHERE c.id = @category_id::uuid
+    UNION
+    SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+)
+SELECT EXISTS (
+    SELECT 1 FROM ancestors a WHERE a.id = @ancestor_id::uuid
+) AS is_descendant;
+
+
+-- Deletes the category with the given id, unless it has subcategories or threads, including deleted and scheduled
+-- threads.
+-- name: DeleteCategory :execrows
+DELETE FROM categories c
+WHERE c.id = $1
+AND NOT EXISTS (SELECT 1 FROM categories sc WHERE sc.parent_id = c.id)
+AND NOT EXISTS (SELECT 1 FROM threads t WHERE t.category_id = c.id)
+AND NOT EXISTS (SELECT 1 FROM scheduled_threads st WHERE st.category_id = c.id);
@@ -1,47 +1,210 @@
 -- RESET DATABASE
 
+DROP TABLE IF EXISTS thread_redirects;
+DROP TABLE IF EXISTS comment_mentions;
+DROP TABLE IF EXISTS thread_mentions;
+DROP TABLE IF EXISTS attachments;
+DROP TABLE IF EXISTS blobs;
+DROP TABLE IF EXISTS poll_votes;
+DROP TABLE IF EXISTS poll_options;
+DROP TABLE IF EXISTS polls;
+DROP TABLE IF EXISTS scheduled_threads;
+DROP TABLE IF EXISTS drafts;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS thread_subscriptions;
+DROP TABLE IF EXISTS bookmarks;
+DROP TABLE IF EXISTS thread_views;
+DROP TABLE IF EXISTS comment_votes;
+DROP TABLE IF EXISTS thread_votes;
+DROP TABLE IF EXISTS thread_pins;
+DROP TABLE IF EXISTS comment_revisions;
+DROP TABLE IF EXISTS thread_revisions;
 DROP TABLE IF EXISTS thread_tags;
 DROP TABLE IF EXISTS tags;
 DROP TABLE IF EXISTS comments;
 DROP TABLE IF EXISTS threads;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS invite_code_uses;
+DROP TABLE IF EXISTS invite_codes;
+DROP TABLE IF EXISTS email_verifications;
+DROP TABLE IF EXISTS username_history;
 DROP TABLE IF EXISTS users;
 
+-- CREATE EXTENSIONS
+
+-- Trigram similarity, used to find threads with titles similar to the title of a new thread
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
 -- CREATE TABLES
 
 CREATE TABLE IF NOT EXISTS users (
     username VARCHAR(64) PRIMARY KEY,
-    password TEXT NOT NULL
+    -- Case-folded NFKC form of the username, used for lookups
+    canonical_username VARCHAR(64) NOT NULL,
+    -- Confusable skeleton of the username, so that look-alike usernames cannot be registered
+    username_skeleton VARCHAR(128) NOT NULL,
+    password TEXT NOT NULL,
+    email TEXT,
+    is_verified BOOLEAN NOT NULL DEFAULT TRUE,
+    role VARCHAR(16) NOT NULL DEFAULT 'user',
+    created_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_renamed_time TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT valid_role CHECK (role IN ('user', 'moderator', 'admin'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_canonical_username_unique ON users (canonical_username);
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_skeleton_unique ON users (username_skeleton);
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_unique ON users (LOWER(email));
+
+-- Previous usernames of users, so that old links still resolve and freed usernames are held for a while.
+-- username is the current username of the user, and is updated when the user is renamed again.
+CREATE TABLE IF NOT EXISTS username_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    username VARCHAR(64) NOT NULL,
+    old_username VARCHAR(64) NOT NULL,
+    old_canonical_username VARCHAR(64) NOT NULL,
+    old_username_skeleton VARCHAR(128) NOT NULL,
+    changed_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_username FOREIGN KEY (username) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS username_history_old_canonical_username ON username_history (old_canonical_username);
+
+CREATE INDEX IF NOT EXISTS username_history_old_username_skeleton ON username_history (old_username_skeleton);
+
+CREATE TABLE IF NOT EXISTS email_verifications (
+    token_hash TEXT PRIMARY KEY,
+    username VARCHAR(64) NOT NULL,
+    expires_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT fk_username FOREIGN KEY (username) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS invite_codes (
+    code VARCHAR(64) PRIMARY KEY,
+    creator VARCHAR(64) NOT NULL,
+    max_uses INTEGER NOT NULL DEFAULT 1,
+    num_uses INTEGER NOT NULL DEFAULT 0,
+    expires_time TIMESTAMP WITH TIME ZONE,
+    created_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_creator FOREIGN KEY (creator) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT max_uses_positive CHECK (max_uses > 0),
+    CONSTRAINT num_uses_within_max_uses CHECK (num_uses >= 0 AND num_uses <= max_uses)
+);
+
+CREATE TABLE IF NOT EXISTS invite_code_uses (
+    code VARCHAR(64) NOT NULL,
+    username VARCHAR(64) NOT NULL,
+    used_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (code, username),
+    CONSTRAINT fk_code FOREIGN KEY (code) REFERENCES invite_codes(code) ON DELETE CASCADE,
+    CONSTRAINT fk_username FOREIGN KEY (username) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Categories that threads are posted in, which can have subcategories. Categories are listed by position and then by
+-- name among categories with the same parent. Only users with at least the posting role can create threads in a
+-- category. The number of threads that have not been deleted is kept up to date by a trigger.
+CREATE TABLE IF NOT EXISTS categories (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(64) NOT NULL,
+    slug VARCHAR(64) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    parent_id UUID,
+    position INTEGER NOT NULL DEFAULT 0,
+    posting_role VARCHAR(16) NOT NULL DEFAULT 'user',
+    num_threads INTEGER NOT NULL DEFAULT 0,
+    created_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_parent FOREIGN KEY (parent_id) REFERENCES categories(id) ON DELETE RESTRICT,
+    CONSTRAINT valid_slug CHECK (slug ~ '^[a-z0-9]+(-[a-z0-9]+)*$'),
+    CONSTRAINT valid_posting_role CHECK (posting_role IN ('user', 'moderator', 'admin')),
+    CONSTRAINT parent_not_self CHECK (parent_id <> id),
+    CONSTRAINT num_threads_not_negative CHECK (num_threads >= 0)
 );
 
+CREATE UNIQUE INDEX IF NOT EXISTS categories_slug_unique ON categories (slug);
+
+-- The category of threads posted without a category. It cannot be deleted.
+INSERT INTO categories (id, name, slug, description)
+VALUES ('00000000-0000-0000-0000-000000000001', 'General', 'general', 'Discussions that do not fit in another category')
+ON CONFLICT DO NOTHING;
+
 CREATE TABLE IF NOT EXISTS threads (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     title TEXT NOT NULL,
     body TEXT NOT NULL,
+    -- The body rendered as HTML, cached when the body changes. Stale if rendered by an older version of the renderer.
+    body_html TEXT NOT NULL DEFAULT '',
+    body_html_version INTEGER NOT NULL DEFAULT 0,
+    -- SimHash fingerprint of the body, used to find near-duplicate threads. NULL until it has been computed.
+    body_simhash BIGINT,
     creator VARCHAR(64) NOT NULL,
     created_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
     updated_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
     num_comments INTEGER NOT NULL DEFAULT 0,
-    CONSTRAINT fk_creator FOREIGN KEY (creator) REFERENCES users(username) ON DELETE CASCADE,
+    num_revisions INTEGER NOT NULL DEFAULT 0,
+    deleted_time TIMESTAMP WITH TIME ZONE,
+    deleted_by VARCHAR(64),
+    is_locked BOOLEAN NOT NULL DEFAULT FALSE,
+    lock_reason TEXT,
+    locked_time TIMESTAMP WITH TIME ZONE,
+    locked_by VARCHAR(64),
+    score INTEGER NOT NULL DEFAULT 0,
+    hot_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    trending_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    view_count INTEGER NOT NULL DEFAULT 0,
+    category_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001',
+    CONSTRAINT fk_creator FOREIGN KEY (creator) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_deleted_by FOREIGN KEY (deleted_by) REFERENCES users(username) ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT fk_locked_by FOREIGN KEY (locked_by) REFERENCES users(username) ON DELETE SET NULL ON UPDATE CASCADE,
     CONSTRAINT created_time_not_future CHECK (created_time <= NOW()),
     CONSTRAINT updated_time_not_future CHECK (updated_time <= NOW()),
     CONSTRAINT updated_time_not_before_created_time CHECK (updated_time >= created_time),
-    CONSTRAINT num_comments_not_negative CHECK (num_comments >= 0)
+    CONSTRAINT num_comments_not_negative CHECK (num_comments >= 0),
+    CONSTRAINT num_revisions_not_negative CHECK (num_revisions >= 0),
+    CONSTRAINT view_count_not_negative CHECK (view_count >= 0)
 );
 
+-- Used to find soft-deleted threads that are due to be purged
+CREATE INDEX IF NOT EXISTS threads_deleted_time ON threads (deleted_time) WHERE deleted_time IS NOT NULL;
+
+-- Used to list threads by their periodically refreshed ranking scores
+CREATE INDEX IF NOT EXISTS threads_hot_score ON threads (hot_score DESC) WHERE deleted_time IS NULL;
+CREATE INDEX IF NOT EXISTS threads_trending_score ON threads (trending_score DESC) WHERE deleted_time IS NULL;
+
+-- Used to list and count the threads of a category
+CREATE INDEX IF NOT EXISTS threads_category_id ON threads (category_id);
+
+-- Used to find threads with similar titles
+CREATE INDEX IF NOT EXISTS threads_title_trgm ON threads USING GIN (title gin_trgm_ops) WHERE deleted_time IS NULL;
+
 CREATE TABLE IF NOT EXISTS comments (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     body TEXT NOT NULL,
+    -- The body rendered as HTML, cached when the body changes. Stale if rendered by an older version of the renderer.
+    body_html TEXT NOT NULL DEFAULT '',
+    body_html_version INTEGER NOT NULL DEFAULT 0,
     creator VARCHAR(64) NOT NULL,
     thread_id UUID NOT NULL,
     created_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
     updated_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
-    CONSTRAINT fk_creator FOREIGN KEY (creator) REFERENCES users(username) ON DELETE CASCADE,
+    num_revisions INTEGER NOT NULL DEFAULT 0,
+    deleted_time TIMESTAMP WITH TIME ZONE,
+    deleted_by VARCHAR(64),
+    score INTEGER NOT NULL DEFAULT 0,
+    CONSTRAINT fk_creator FOREIGN KEY (creator) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE,
     CONSTRAINT fk_thread FOREIGN KEY (thread_id) REFERENCES threads(id) ON DELETE CASCADE,
+    CONSTRAINT fk_deleted_by FOREIGN KEY (deleted_by) REFERENCES users(username) ON DELETE SET NULL ON UPDATE CASCADE,
     CONSTRAINT created_time_not_future CHECK (created_time <= NOW()),
     CONSTRAINT updated_time_not_future CHECK (updated_time <= NOW()),
-    CONSTRAINT updated_time_not_before_created_time CHECK (updated_time >= created_time)
+    CONSTRAINT updated_time_not_before_created_time CHECK (updated_time >= created_time),
+    CONSTRAINT num_revisions_not_negative CHECK (num_revisions >= 0)
 );
 
+-- Used to find soft-deleted comments that are due to be purged
+CREATE INDEX IF NOT EXISTS comments_deleted_time ON comments (deleted_time) WHERE deleted_time IS NOT NULL;
+
 CREATE TABLE IF NOT EXISTS tags (
     name VARCHAR(64) PRIMARY KEY
 );
@@ -53,3 +216,292 @@ CREATE TABLE IF NOT EXISTS thread_tags (
     CONSTRAINT fk_thread FOREIGN KEY (thread_id) REFERENCES threads(id) ON DELETE CASCADE,
     CONSTRAINT fk_tag FOREIGN KEY (tag_name) REFERENCES tags(name) ON DELETE CASCADE
 );
+
+-- Previous versions of threads. Revision n holds the content of the thread before its n-th edit,
+-- together with the user who made that edit and when it was made.
+CREATE TABLE IF NOT EXISTS thread_revisions (
+    thread_id UUID NOT NULL,
+    revision_number INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    editor VARCHAR(64) NOT NULL,
+    edited_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (thread_id, revision_number),
+    CONSTRAINT fk_thread FOREIGN KEY (thread_id) REFERENCES threads(id) ON DELETE CASCADE,
+    CONSTRAINT fk_editor FOREIGN KEY (editor) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT revision_number_positive CHECK (revision_number > 0)
+);
+
+-- Previous versions of comments. Revision n holds the content of the comment before its n-th edit.
+CREATE TABLE IF NOT EXISTS comment_revisions (
+    comment_id UUID NOT NULL,
+    revision_number INTEGER NOT NULL,
+    body TEXT NOT NULL,
+    editor VARCHAR(64) NOT NULL,
+    edited_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (comment_id, revision_number),
+    CONSTRAINT fk_comment FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+    CONSTRAINT fk_editor FOREIGN KEY (editor) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT revision_number_positive CHECK (revision_number > 0)
+);
+
+-- Pinned threads. An empty tag name pins the thread everywhere, otherwise it is only pinned when searching for that tag.
+-- Announcements are always pinned everywhere and are shown above other pinned threads.
+CREATE TABLE IF NOT EXISTS thread_pins (
+    thread_id UUID NOT NULL,
+    tag_name VARCHAR(64) NOT NULL DEFAULT '',
+    is_announcement BOOLEAN NOT NULL DEFAULT FALSE,
+    pinned_by VARCHAR(64),
+    pinned_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_time TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (thread_id, tag_name),
+    CONSTRAINT fk_thread FOREIGN KEY (thread_id) REFERENCES threads(id) ON DELETE CASCADE,
+    CONSTRAINT fk_pinned_by FOREIGN KEY (pinned_by) REFERENCES users(username) ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT announcement_pinned_everywhere CHECK (NOT is_announcement OR tag_name = '')
+);
+
+-- Votes of users on threads. A value of 1 is an upvote and -1 is a downvote.
+CREATE TABLE IF NOT EXISTS thread_votes (
+    thread_id UUID NOT NULL,
+    username VARCHAR(64) NOT NULL,
+    value SMALLINT NOT NULL,
+    created_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (thread_id, username),
+    CONSTRAINT fk_thread FOREIGN KEY (thread_id) REFERENCES threads(id) ON DELETE CASCADE,
+    CONSTRAINT fk_username FOREIGN KEY (username) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT valid_value CHECK (value IN (-1, 1))
+);
+
+-- Votes of users on comments. A value of 1 is an upvote and -1 is a downvote.
+CREATE TABLE IF NOT EXISTS comment_votes (
+    comment_id UUID NOT NULL,
+    username VARCHAR(64) NOT NULL,
+    value SMALLINT NOT NULL,
+    created_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (comment_id, username),
+    CONSTRAINT fk_comment FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+    CONSTRAINT fk_username FOREIGN KEY (username) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT valid_value CHECK (value IN (-1, 1))
+);
+
+-- The last counted view of a thread by each viewer, used to count a view at most once per viewer per time window.
+-- The viewer is either the username of a logged in user or a hash of the IP address of the request.
+CREATE TABLE IF NOT EXISTS thread_views (
+    thread_id UUID NOT NULL,
+    viewer TEXT NOT NULL,
+    viewed_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (thread_id, viewer),
+    CONSTRAINT fk_thread FOREIGN KEY (thread_id) REFERENCES threads(id) ON DELETE CASCADE
+);
+
+-- Used to clean up views that are older than the time window
+CREATE INDEX IF NOT EXISTS thread_views_viewed_time ON thread_views (viewed_time);
+
+-- Threads and comments saved by users, optionally in a folder and with a note.
+-- Each bookmark is either of a thread or of a comment.
+CREATE TABLE IF NOT EXISTS bookmarks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    username VARCHAR(64) NOT NULL,
+    thread_id UUID,
+    comment_id UUID,
+    folder VARCHAR(64) NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    created_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_username FOREIGN KEY (username) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_thread FOREIGN KEY (thread_id) REFERENCES threads(id) ON DELETE CASCADE,
+    CONSTRAINT fk_comment FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+    CONSTRAINT thread_or_comment CHECK ((thread_id IS NULL) <> (comment_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS bookmarks_username_thread_id ON bookmarks (username, thread_id)
+    WHERE thread_id IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS bookmarks_username_comment_id ON bookmarks (username, comment_id)
+    WHERE comment_id IS NOT NULL;
+
+-- Used to list the bookmarks of a user, latest first
+CREATE INDEX IF NOT EXISTS bookmarks_username_created_time ON bookmarks (username, created_time DESC);
+
+-- Users subscribed to new comments on threads. Users are subscribed automatically to threads they create or comment
+-- on. Muted subscriptions are kept so that users are not subscribed again automatically.
+CREATE TABLE IF NOT EXISTS thread_subscriptions (
+    thread_id UUID NOT NULL,
+    username VARCHAR(64) NOT NULL,
+    is_muted BOOLEAN NOT NULL DEFAULT FALSE,
+    created_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (thread_id, username),
+    CONSTRAINT fk_thread FOREIGN KEY (thread_id) REFERENCES threads(id) ON DELETE CASCADE,
+    CONSTRAINT fk_username FOREIGN KEY (username) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Notifications of users about activity on threads they are subscribed to.
+-- The actor is the user who caused the notification, such as the creator of a new comment.
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    username VARCHAR(64) NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    thread_id UUID NOT NULL,
+    comment_id UUID,
+    actor VARCHAR(64),
+    is_read BOOLEAN NOT NULL DEFAULT FALSE,
+    created_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_username FOREIGN KEY (username) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_thread FOREIGN KEY (thread_id) REFERENCES threads(id) ON DELETE CASCADE,
+    CONSTRAINT fk_comment FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+    CONSTRAINT fk_actor FOREIGN KEY (actor) REFERENCES users(username) ON DELETE SET NULL ON UPDATE CASCADE
+);
+
+-- Used to list the notifications of a user, latest first
+CREATE INDEX IF NOT EXISTS notifications_username_created_time ON notifications (username, created_time DESC);
+
+-- Used to count the unread notifications of a user
+CREATE INDEX IF NOT EXISTS notifications_username_unread ON notifications (username) WHERE NOT is_read;
+
+-- Unsent threads and comments of users, saved while they are being written.
+-- The context is what the draft is for: 'new_thread', 'reply:<thread id>', 'edit_thread:<thread id>' or
+-- 'edit_comment:<comment id>'. Drafts of comments have an empty title and no tags.
+CREATE TABLE IF NOT EXISTS drafts (
+    username VARCHAR(64) NOT NULL,
+    context VARCHAR(64) NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL DEFAULT '',
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    created_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (username, context),
+    CONSTRAINT fk_username FOREIGN KEY (username) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Used to find drafts that have not been updated for longer than the expiry period
+CREATE INDEX IF NOT EXISTS drafts_updated_time ON drafts (updated_time);
+
+-- Threads that will be published at a later time. Only their creator can see them until then.
+-- When published, the thread is created with the same ID and a created time of the publish time.
+CREATE TABLE IF NOT EXISTS scheduled_threads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    creator VARCHAR(64) NOT NULL,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    -- The poll to be attached to the thread when it is published, as sent in models.CreatePollRequest
+    poll JSONB,
+    -- Attachments uploaded for the thread, attached when the thread is published
+    attachment_ids UUID[] NOT NULL DEFAULT '{}',
+    category_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001',
+    publish_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_creator FOREIGN KEY (creator) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE RESTRICT
+);
+
+-- Used to find threads that are due to be published
+CREATE INDEX IF NOT EXISTS scheduled_threads_publish_time ON scheduled_threads (publish_time);
+
+-- Polls attached to threads. A thread has at most one poll.
+CREATE TABLE IF NOT EXISTS polls (
+    thread_id UUID PRIMARY KEY,
+    is_multiple_choice BOOLEAN NOT NULL DEFAULT FALSE,
+    is_anonymous BOOLEAN NOT NULL DEFAULT FALSE,
+    close_time TIMESTAMP WITH TIME ZONE,
+    created_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_thread FOREIGN KEY (thread_id) REFERENCES threads(id) ON DELETE CASCADE
+);
+
+-- Options of polls, numbered from 1 in the order they are shown.
+CREATE TABLE IF NOT EXISTS poll_options (
+    thread_id UUID NOT NULL,
+    position INTEGER NOT NULL,
+    text VARCHAR(100) NOT NULL,
+    PRIMARY KEY (thread_id, position),
+    CONSTRAINT fk_poll FOREIGN KEY (thread_id) REFERENCES polls(thread_id) ON DELETE CASCADE
+);
+
+-- Votes of users on poll options. Options that have been voted for cannot be deleted, so options are locked
+-- once votes exist.
+CREATE TABLE IF NOT EXISTS poll_votes (
+    thread_id UUID NOT NULL,
+    position INTEGER NOT NULL,
+    username VARCHAR(64) NOT NULL,
+    created_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (thread_id, username, position),
+    CONSTRAINT fk_poll FOREIGN KEY (thread_id) REFERENCES polls(thread_id) ON DELETE CASCADE,
+    CONSTRAINT fk_option FOREIGN KEY (thread_id, position) REFERENCES poll_options(thread_id, position),
+    CONSTRAINT fk_username FOREIGN KEY (username) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Files kept in the blob store, addressed by the SHA-256 hash of their contents. Blobs that are no longer used by any
+-- attachment are deleted.
+CREATE TABLE IF NOT EXISTS blobs (
+    hash CHAR(64) PRIMARY KEY,
+    size INTEGER NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    last_used_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Files uploaded by users. Attachments that are not attached to a thread or comment in time are deleted.
+CREATE TABLE IF NOT EXISTS attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    uploader VARCHAR(64) NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    blob_hash CHAR(64) NOT NULL,
+    thumbnail_hash CHAR(64),
+    width INTEGER,
+    height INTEGER,
+    thread_id UUID,
+    comment_id UUID,
+    created_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_uploader FOREIGN KEY (uploader) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_blob FOREIGN KEY (blob_hash) REFERENCES blobs(hash),
+    CONSTRAINT fk_thumbnail FOREIGN KEY (thumbnail_hash) REFERENCES blobs(hash),
+    CONSTRAINT fk_thread FOREIGN KEY (thread_id) REFERENCES threads(id) ON DELETE CASCADE,
+    CONSTRAINT fk_comment FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+    CONSTRAINT attached_to_one CHECK (thread_id IS NULL OR comment_id IS NULL)
+);
+
+-- Used to find the attachments of threads and comments
+CREATE INDEX IF NOT EXISTS attachments_thread_id ON attachments (thread_id) WHERE thread_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS attachments_comment_id ON attachments (comment_id) WHERE comment_id IS NOT NULL;
+
+-- Used to find attachments that have not been attached in time
+CREATE INDEX IF NOT EXISTS attachments_unattached ON attachments (created_time)
+WHERE thread_id IS NULL AND comment_id IS NULL;
+
+-- Users mentioned in threads. Mentions removed by an edit are kept but are no longer current, so that the user is
+-- notified only once even if they are mentioned again.
+CREATE TABLE IF NOT EXISTS thread_mentions (
+    thread_id UUID NOT NULL,
+    username VARCHAR(64) NOT NULL,
+    is_current BOOLEAN NOT NULL DEFAULT TRUE,
+    created_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (thread_id, username),
+    CONSTRAINT fk_thread FOREIGN KEY (thread_id) REFERENCES threads(id) ON DELETE CASCADE,
+    CONSTRAINT fk_username FOREIGN KEY (username) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Users mentioned in comments, kept in the same way as thread mentions.
+CREATE TABLE IF NOT EXISTS comment_mentions (
+    comment_id UUID NOT NULL,
+    username VARCHAR(64) NOT NULL,
+    is_current BOOLEAN NOT NULL DEFAULT TRUE,
+    created_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (comment_id, username),
+    CONSTRAINT fk_comment FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+    CONSTRAINT fk_username FOREIGN KEY (username) REFERENCES users(username) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Threads that were merged into another thread, so that links to them lead to the thread they were merged into.
+-- Redirects to a thread that is merged again are updated to point to the new thread.
+CREATE TABLE IF NOT EXISTS thread_redirects (
+    thread_id UUID PRIMARY KEY,
+    target_thread_id UUID NOT NULL,
+    title TEXT NOT NULL,
+    created_by VARCHAR(64),
+    created_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_target_thread FOREIGN KEY (target_thread_id) REFERENCES threads(id) ON DELETE CASCADE,
+    CONSTRAINT fk_created_by FOREIGN KEY (created_by) REFERENCES users(username) ON DELETE SET NULL ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS thread_redirects_target_thread_id ON thread_redirects (target_thread_id);
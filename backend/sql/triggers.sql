@@ -1,5 +1,6 @@
 -- CREATE TRIGGERS
 
+-- Soft-deleted comments are not counted. Comments moved to another thread are counted again in both threads.
 CREATE OR REPLACE FUNCTION update_comments_count()
     RETURNS TRIGGER AS
 $$
@@ -10,18 +11,21 @@ BEGIN
             SELECT COUNT(*)
             FROM comments c
             WHERE c.thread_id = t.id
+            AND c.deleted_time IS NULL
         )
         WHERE t.id = OLD.thread_id;
         RETURN OLD;
 
-    ELSIF TG_OP = 'INSERT' THEN
+    ELSIF TG_OP = 'INSERT' OR TG_OP = 'UPDATE' THEN
         UPDATE threads t
         SET num_comments = (
             SELECT COUNT(*)
             FROM comments c
             WHERE c.thread_id = t.id
+            AND c.deleted_time IS NULL
         )
-        WHERE t.id = NEW.thread_id;
+        WHERE t.id = NEW.thread_id
+        OR (TG_OP = 'UPDATE' AND t.id = OLD.thread_id);
         RETURN NEW;
     END IF;
 END;
@@ -29,6 +33,177 @@ $$
     LANGUAGE plpgsql;
 
 CREATE OR REPLACE TRIGGER on_comment
-    AFTER INSERT OR DELETE ON comments
+    AFTER INSERT OR DELETE OR UPDATE OF deleted_time, thread_id ON comments
     FOR EACH ROW
 EXECUTE FUNCTION update_comments_count();
+
+CREATE OR REPLACE FUNCTION update_thread_revisions_count()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    UPDATE threads t
+    SET num_revisions = (
+        SELECT COUNT(*)
+        FROM thread_revisions tr
+        WHERE tr.thread_id = t.id
+    )
+    WHERE t.id = NEW.thread_id;
+    RETURN NEW;
+END;
+$$
+    LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER on_thread_revision
+    AFTER INSERT ON thread_revisions
+    FOR EACH ROW
+EXECUTE FUNCTION update_thread_revisions_count();
+
+CREATE OR REPLACE FUNCTION update_comment_revisions_count()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    UPDATE comments c
+    SET num_revisions = (
+        SELECT COUNT(*)
+        FROM comment_revisions cr
+        WHERE cr.comment_id = c.id
+    )
+    WHERE c.id = NEW.comment_id;
+    RETURN NEW;
+END;
+$$
+    LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER on_comment_revision
+    AFTER INSERT ON comment_revisions
+    FOR EACH ROW
+EXECUTE FUNCTION update_comment_revisions_count();
+
+-- Scores are updated by the change in value rather than recounted, so that concurrent votes are not lost.
+CREATE OR REPLACE FUNCTION update_thread_score()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE threads
+        SET score = score - OLD.value
+        WHERE id = OLD.thread_id;
+        RETURN OLD;
+
+    ELSIF TG_OP = 'INSERT' THEN
+        UPDATE threads
+        SET score = score + NEW.value
+        WHERE id = NEW.thread_id;
+        RETURN NEW;
+
+    ELSIF TG_OP = 'UPDATE' THEN
+        UPDATE threads
+        SET score = score + NEW.value - OLD.value
+        WHERE id = NEW.thread_id;
+        RETURN NEW;
+    END IF;
+END;
+$$
+    LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER on_thread_vote
+    AFTER INSERT OR DELETE OR UPDATE OF value ON thread_votes
+    FOR EACH ROW
+EXECUTE FUNCTION update_thread_score();
+
+CREATE OR REPLACE FUNCTION update_comment_score()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE comments
+        SET score = score - OLD.value
+        WHERE id = OLD.comment_id;
+        RETURN OLD;
+
+    ELSIF TG_OP = 'INSERT' THEN
+        UPDATE comments
+        SET score = score + NEW.value
+        WHERE id = NEW.comment_id;
+        RETURN NEW;
+
+    ELSIF TG_OP = 'UPDATE' THEN
+        UPDATE comments
+        SET score = score + NEW.value - OLD.value
+        WHERE id = NEW.comment_id;
+        RETURN NEW;
+    END IF;
+END;
+$$
+    LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER on_comment_vote
+    AFTER INSERT OR DELETE OR UPDATE OF value ON comment_votes
+    FOR EACH ROW
+EXECUTE FUNCTION update_comment_score();
+
+-- Subscribes users to threads they create or comment on, unless they have muted the thread.
+CREATE OR REPLACE FUNCTION subscribe_creator()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_TABLE_NAME = 'threads' THEN
+        INSERT INTO thread_subscriptions (thread_id, username)
+        VALUES (NEW.id, NEW.creator)
+        ON CONFLICT (thread_id, username) DO NOTHING;
+    ELSE
+        INSERT INTO thread_subscriptions (thread_id, username)
+        VALUES (NEW.thread_id, NEW.creator)
+        ON CONFLICT (thread_id, username) DO NOTHING;
+    END IF;
+    RETURN NEW;
+END;
+$$
+    LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER on_thread_subscribe_creator
+    AFTER INSERT ON threads
+    FOR EACH ROW
+EXECUTE FUNCTION subscribe_creator();
+
+CREATE OR REPLACE TRIGGER on_comment_subscribe_creator
+    AFTER INSERT ON comments
+    FOR EACH ROW
+EXECUTE FUNCTION subscribe_creator();
+
+-- Deleted threads are not counted. Threads moved to another category are counted again in both categories.
+CREATE OR REPLACE FUNCTION update_category_threads_count()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE categories c
+        SET num_threads = (
+            SELECT COUNT(*)
+            FROM threads t
+            WHERE t.category_id = c.id
+            AND t.deleted_time IS NULL
+        )
+        WHERE c.id = OLD.category_id;
+        RETURN OLD;
+
+    ELSIF TG_OP = 'INSERT' OR TG_OP = 'UPDATE' THEN
+        UPDATE categories c
+        SET num_threads = (
+            SELECT COUNT(*)
+            FROM threads t
+            WHERE t.category_id = c.id
+            AND t.deleted_time IS NULL
+        )
+        WHERE c.id = NEW.category_id
+        OR (TG_OP = 'UPDATE' AND c.id = OLD.category_id);
+        RETURN NEW;
+    END IF;
+END;
+$$
+    LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER on_thread_category
+    AFTER INSERT OR DELETE OR UPDATE OF deleted_time, category_id ON threads
+    FOR EACH ROW
+EXECUTE FUNCTION update_category_threads_count();
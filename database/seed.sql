@@ -1,5 +1,8 @@
 -- username: testuser, password: testuser
-INSERT INTO users VALUES ('testuser', '$2y$10$0h0No1uuSBWp6LKnKiEIxuwt.qVjGfHWABY10PzAhR2m98Jfm3AeS');
+INSERT INTO users (username, canonical_username, username_skeleton, password) VALUES ('testuser', 'testuser', 'testuser', '$2y$10$0h0No1uuSBWp6LKnKiEIxuwt.qVjGfHWABY10PzAhR2m98Jfm3AeS');
+
+-- username: testadmin, password: testadmin
+INSERT INTO users (username, canonical_username, username_skeleton, password, role) VALUES ('testadmin', 'testadmin', 'testadmin', '$2a$10$mBUyNzwgT8hSmwO0lNw/IeExk0J5xbTIxQNvZM79bbVIigJbpYlZu', 'admin');
 
 INSERT INTO threads (id, title, body, creator) VALUES ('11223344-4444-4444-4444-000000000001', 'Hello World!', 'This is my first thread', 'testuser');
 
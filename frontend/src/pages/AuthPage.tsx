@@ -1,11 +1,12 @@
 import * as React from "react";
-import { useContext, useState } from "react";
+import { useContext, useEffect, useState } from "react";
 import Button from "@mui/material/Button";
 import TextField from "@mui/material/TextField";
 import Typography from "@mui/material/Typography";
 import { useNavigate } from "react-router-dom";
 import { Alert, CircularProgress, Divider } from "@mui/material";
 import AuthContext from "../contexts/AuthContext.tsx";
+import getProofOfWorkHeaders from "../utils/proofOfWork.ts";
 
 export default function AuthPage(
   props: Readonly<{
@@ -22,6 +23,27 @@ export default function AuthPage(
 
   const [username, setUsername] = useState("");
   const [password, setPassword] = useState("");
+  const [email, setEmail] = useState("");
+  const [inviteCode, setInviteCode] = useState("");
+
+  // Whether an email or invite code is needed to sign up depends on the registration mode of the backend
+  const [registrationMode, setRegistrationMode] = useState<"open" | "email" | "invite" | "closed">("open");
+
+  useEffect(() => {
+    if (props.type !== "signup") {
+      return;
+    }
+
+    fetch("/api/v1/registration")
+      .then((res) => {
+        if (res.ok) {
+          return res.json();
+        }
+        throw new Error(res.statusText);
+      })
+      .then((data) => setRegistrationMode(data.mode))
+      .catch(console.error);
+  }, [props.type]);
 
   const handleUsernameChange = (event: React.ChangeEvent<HTMLInputElement>) => {
     setUsername(event.target.value);
@@ -31,8 +53,18 @@ export default function AuthPage(
     setPassword(event.target.value);
   };
 
+  const handleEmailChange = (event: React.ChangeEvent<HTMLInputElement>) => {
+    setEmail(event.target.value);
+  };
+
+  const handleInviteCodeChange = (event: React.ChangeEvent<HTMLInputElement>) => {
+    setInviteCode(event.target.value);
+  };
+
   const [isInvalidUsername, setIsInvalidUsername] = useState(false);
   const [isInvalidPassword, setIsInvalidPassword] = useState(false);
+  const [isInvalidEmail, setIsInvalidEmail] = useState(false);
+  const [isInvalidInviteCode, setIsInvalidInviteCode] = useState(false);
   const [isLoading, setIsLoading] = useState(false);
   const [isError, setIsError] = useState(false);
   const [errorMessage, setErrorMessage] = useState("");
@@ -57,6 +89,26 @@ export default function AuthPage(
     }
   };
 
+  const checkInvalidEmail = () => {
+    if (props.type === "signup" && registrationMode === "email" && !/^[^\s@]+@[^\s@]+$/.test(email.trim())) {
+      setIsInvalidEmail(true);
+      return true;
+    } else {
+      setIsInvalidEmail(false);
+      return false;
+    }
+  };
+
+  const checkInvalidInviteCode = () => {
+    if (props.type === "signup" && registrationMode === "invite" && inviteCode.trim().length === 0) {
+      setIsInvalidInviteCode(true);
+      return true;
+    } else {
+      setIsInvalidInviteCode(false);
+      return false;
+    }
+  };
+
   const handleSubmit = () => {
     setIsError(false);
     setErrorMessage("");
@@ -64,8 +116,10 @@ export default function AuthPage(
 
     const isInvalidUsername = checkInvalidUsername();
     const isInvalidPassword = checkInvalidPassword();
+    const isInvalidEmail = checkInvalidEmail();
+    const isInvalidInviteCode = checkInvalidInviteCode();
 
-    if (isInvalidUsername || isInvalidPassword) {
+    if (isInvalidUsername || isInvalidPassword || isInvalidEmail || isInvalidInviteCode) {
       setIsLoading(false);
       return;
     }
@@ -98,16 +152,28 @@ export default function AuthPage(
         }),
       }).then(handleApiResponse);
     } else if (props.type === "signup") {
-      fetch("/api/v1/user/create", {
-        method: "POST",
-        headers: {
-          "Content-Type": "application/json",
-        },
-        body: JSON.stringify({
-          username,
-          password,
-        }),
-      }).then(handleApiResponse);
+      getProofOfWorkHeaders("register")
+        .then((powHeaders) =>
+          fetch("/api/v1/user/create", {
+            method: "POST",
+            headers: {
+              "Content-Type": "application/json",
+              ...powHeaders,
+            },
+            body: JSON.stringify({
+              username,
+              password,
+              ...(registrationMode === "email" ? { email: email.trim() } : {}),
+              ...(registrationMode === "invite" ? { invite_code: inviteCode.trim() } : {}),
+            }),
+          }),
+        )
+        .then(handleApiResponse)
+        .catch((error: Error) => {
+          setIsLoading(false);
+          setIsError(true);
+          setErrorMessage(error.message);
+        });
     } else {
       throw new Error("Invalid auth page type");
     }
@@ -162,6 +228,55 @@ export default function AuthPage(
               }
             }}
           />
+          {props.type === "signup" && registrationMode === "email" && (
+            <TextField
+              margin="normal"
+              required
+              fullWidth
+              name="email"
+              label="Email"
+              type="email"
+              id="email"
+              autoComplete="email"
+              value={email}
+              onChange={handleEmailChange}
+              error={isInvalidEmail}
+              helperText={"You will be sent an email to verify your account before you can post"}
+              onKeyDown={(event) => {
+                if (event.key === "Enter") {
+                  handleSubmit();
+                }
+              }}
+            />
+          )}
+          {props.type === "signup" && registrationMode === "invite" && (
+            <TextField
+              margin="normal"
+              required
+              fullWidth
+              name="invite_code"
+              label="Invite code"
+              id="invite_code"
+              autoComplete="off"
+              value={inviteCode}
+              onChange={handleInviteCodeChange}
+              error={isInvalidInviteCode}
+              helperText={"Signing up requires an invite code from an existing user"}
+              onKeyDown={(event) => {
+                if (event.key === "Enter") {
+                  handleSubmit();
+                }
+              }}
+            />
+          )}
+          {props.type === "signup" && registrationMode === "closed" && (
+            <Alert
+              severity="info"
+              className={"mt-3"}
+            >
+              Signing up is currently closed
+            </Alert>
+          )}
           {isError && (
             <Alert
               severity="error"
@@ -178,7 +293,7 @@ export default function AuthPage(
             className={"h-11"}
             sx={{ mt: 3, mb: 2 }}
             onClick={handleSubmit}
-            disabled={isLoading}
+            disabled={isLoading || (props.type === "signup" && registrationMode === "closed")}
           >
             {isLoading ? <CircularProgress size={28} /> : mainButtonLabel}
           </Button>
@@ -191,7 +306,11 @@ export default function AuthPage(
             onClick={() => {
               setUsername("");
               setPassword("");
+              setEmail("");
+              setInviteCode("");
               setIsInvalidPassword(false);
+              setIsInvalidEmail(false);
+              setIsInvalidInviteCode(false);
               setIsInvalidUsername(false);
               setIsError(false);
               setErrorMessage("");
@@ -8,6 +8,7 @@ import { Link, useNavigate } from "react-router-dom";
 import Thread from "../models/Thread.tsx";
 import { useContext, useEffect, useState } from "react";
 import AuthContext from "../contexts/AuthContext.tsx";
+import getProofOfWorkHeaders from "../utils/proofOfWork.ts";
 
 export default function ThreadEditorPage(
   props: Readonly<{
@@ -96,29 +97,38 @@ export default function ThreadEditorPage(
     if (checkInvalidTitleAndBody()) {
       return;
     }
-    fetch("/api/v1/thread/create", {
-      method: "POST",
-      headers: {
-        "Content-Type": "application/json",
-        Authorization: "Bearer " + auth.token,
-      },
-      body: JSON.stringify({
-        title: title,
-        body: body,
-        tags: tags,
-      }),
-    }).then((res) => {
-      if (!res.ok) {
-        res.text().then((text) => {
-          setIsError(true);
-          setErrorMessage(text);
-        });
-      } else {
-        res.json().then((data) => {
-          navigate("/viewthread/" + data.id);
-        });
-      }
-    });
+    getProofOfWorkHeaders("thread")
+      .then((powHeaders) =>
+        fetch("/api/v1/thread/create", {
+          method: "POST",
+          headers: {
+            "Content-Type": "application/json",
+            Authorization: "Bearer " + auth.token,
+            ...powHeaders,
+          },
+          body: JSON.stringify({
+            title: title,
+            body: body,
+            tags: tags,
+          }),
+        }),
+      )
+      .then((res) => {
+        if (!res.ok) {
+          res.text().then((text) => {
+            setIsError(true);
+            setErrorMessage(text);
+          });
+        } else {
+          res.json().then((data) => {
+            navigate("/viewthread/" + data.id);
+          });
+        }
+      })
+      .catch((error: Error) => {
+        setIsError(true);
+        setErrorMessage(error.message);
+      });
   };
 
   const handleEditThread = () => {
@@ -0,0 +1,36 @@
+// Counts the number of leading zero bits in a hash
+const countLeadingZeroBits = (hash: Uint8Array) => {
+  let count = 0;
+  for (const byte of hash) {
+    if (byte !== 0) {
+      return count + Math.clz32(byte) - 24;
+    }
+    count += 8;
+  }
+  return count;
+};
+
+// Fetches a proof-of-work challenge for the given purpose and solves it.
+// Returns the headers to send with the protected request, which are empty if no proof-of-work is required.
+export default async function getProofOfWorkHeaders(purpose: "register" | "thread"): Promise<Record<string, string>> {
+  const res = await fetch(`/api/v1/challenge?purpose=${purpose}`);
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  const { required, challenge, difficulty } = await res.json();
+  if (!required) {
+    return {};
+  }
+
+  const encoder = new TextEncoder();
+  for (let solution = 0; ; solution++) {
+    const hash = await crypto.subtle.digest("SHA-256", encoder.encode(challenge + solution));
+    if (countLeadingZeroBits(new Uint8Array(hash)) >= difficulty) {
+      return {
+        "X-Pow-Challenge": challenge,
+        "X-Pow-Solution": solution.toString(),
+      };
+    }
+  }
+}